@@ -0,0 +1,287 @@
+package Handlers
+
+import (
+	"bytes"
+	"cryptotracker/REST-API/middleware"
+	"cryptotracker/internal/audit"
+	"cryptotracker/internal/rbac"
+	"cryptotracker/internal/services"
+	"cryptotracker/models"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type AdminHandler struct {
+	adminService        services.AdminService
+	notificationService services.NotificationService
+}
+
+func NewAdminHandler(adminService services.AdminService, notificationService services.NotificationService) *AdminHandler {
+	return &AdminHandler{adminService: adminService, notificationService: notificationService}
+}
+
+type requestActionRequest struct {
+	Status string `json:"status"`
+}
+
+type roleChangeRequest struct {
+	Role string `json:"role"`
+}
+
+type twoFactorPolicyRequest struct {
+	Role     string `json:"role"`
+	Required bool   `json:"required"`
+}
+
+// Profiles lists every user.
+func (h *AdminHandler) Profiles(w http.ResponseWriter, r *http.Request) {
+	users, err := h.adminService.ViewUserProfiles(middleware.Username(r))
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if users == nil {
+		users = []*models.User{}
+	}
+	for _, user := range users {
+		user.Password = ""
+	}
+	middleware.WriteJSON(w, http.StatusOK, map[string]interface{}{"users": users})
+}
+
+// DeleteUser deletes a user. Their account and data are kept, and can be restored, until the
+// purge_after time in the response.
+func (h *AdminHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	username := mux.Vars(r)["username"]
+	if username == middleware.Username(r) {
+		middleware.WriteError(w, http.StatusBadRequest, "admins cannot delete their own account")
+		return
+	}
+
+	purgeAfter, err := h.adminService.DeleteUser(username, middleware.Username(r), services.AuditSourceREST)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, map[string]interface{}{"username": username, "status": models.AccountDeleted, "purge_after": purgeAfter})
+}
+
+// DeactivateUser blocks a user from logging in and pauses their alerts.
+func (h *AdminHandler) DeactivateUser(w http.ResponseWriter, r *http.Request) {
+	username := mux.Vars(r)["username"]
+	if err := h.adminService.DeactivateUser(username, middleware.Username(r), services.AuditSourceREST); err != nil {
+		writeServiceError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, map[string]interface{}{"username": username, "status": models.AccountDeactivated})
+}
+
+// RestoreUser reactivates a deactivated user, or restores a deleted one before it is purged.
+func (h *AdminHandler) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	username := mux.Vars(r)["username"]
+	if err := h.adminService.RestoreUser(username, middleware.Username(r), services.AuditSourceREST); err != nil {
+		writeServiceError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, map[string]interface{}{"username": username, "status": models.AccountActive})
+}
+
+// DelegateUser promotes a user to admin.
+func (h *AdminHandler) DelegateUser(w http.ResponseWriter, r *http.Request) {
+	h.changeRole(w, r, rbac.Admin)
+}
+
+// ChangeRole promotes or demotes a user to the role in the body.
+func (h *AdminHandler) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	var req roleChangeRequest
+	if !decodeJSON(w, r, "RoleChangeRequest", &req) {
+		return
+	}
+	h.changeRole(w, r, req.Role)
+}
+
+func (h *AdminHandler) changeRole(w http.ResponseWriter, r *http.Request, role string) {
+	username := mux.Vars(r)["username"]
+	if err := h.adminService.ChangeUserRole(username, role, middleware.Username(r), services.AuditSourceREST); err != nil {
+		writeServiceError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, map[string]interface{}{"username": username, "role": role})
+}
+
+// ForceLogout ends every session of a user.
+func (h *AdminHandler) ForceLogout(w http.ResponseWriter, r *http.Request) {
+	username := mux.Vars(r)["username"]
+	count, err := h.adminService.ForceLogout(username, middleware.Username(r), services.AuditSourceREST)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, map[string]interface{}{"username": username, "revoked_sessions": count})
+}
+
+// UnavailableCryptoRequests lists every request to add a cryptocurrency.
+func (h *AdminHandler) UnavailableCryptoRequests(w http.ResponseWriter, r *http.Request) {
+	requests, err := h.adminService.ManageUserRequests(middleware.Username(r))
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeRequests(w, requests)
+}
+
+// SpecificUserUnavailableCryptoRequests lists the requests made by one user.
+func (h *AdminHandler) SpecificUserUnavailableCryptoRequests(w http.ResponseWriter, r *http.Request) {
+	username := mux.Vars(r)["username"]
+
+	requests, err := h.adminService.ManageUserRequests(middleware.Username(r))
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	var matching []*models.UnavailableCryptoRequest
+	for _, request := range requests {
+		if request.UserName == username {
+			matching = append(matching, request)
+		}
+	}
+	writeRequests(w, matching)
+}
+
+// ActOnUnavailableCryptoRequestsBySymbol approves or rejects every request for a symbol and
+// notifies the requesters.
+func (h *AdminHandler) ActOnUnavailableCryptoRequestsBySymbol(w http.ResponseWriter, r *http.Request) {
+	symbol := mux.Vars(r)["crypto"]
+
+	var req requestActionRequest
+	if !decodeJSON(w, r, "RequestActionRequest", &req) {
+		return
+	}
+
+	status := "Rejected"
+	if strings.EqualFold(req.Status, "approve") || strings.EqualFold(req.Status, "approved") {
+		status = "Approved"
+	}
+
+	requests, err := h.adminService.ManageSpecificCryptoRequests(symbol, middleware.Username(r))
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if len(requests) == 0 {
+		middleware.WriteError(w, http.StatusNotFound, "no requests found for "+symbol)
+		return
+	}
+
+	if err := h.adminService.UpdateRequestStatus(requests, status, middleware.Username(r), services.AuditSourceREST); err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.notificationService.NotifyRequestDecisions(requests); err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeRequests(w, requests)
+}
+
+// TwoFactorPolicy makes two-factor authentication mandatory, or optional again, for a role.
+func (h *AdminHandler) TwoFactorPolicy(w http.ResponseWriter, r *http.Request) {
+	var req twoFactorPolicyRequest
+	if !decodeJSON(w, r, "TwoFactorPolicyRequest", &req) {
+		return
+	}
+
+	if err := h.adminService.SetTwoFactorRequired(req.Role, req.Required, middleware.Username(r), services.AuditSourceREST); err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, req)
+}
+
+// AuditLog searches the audit log, newest first, by the actor, action, target, source,
+// since, until and limit query parameters.
+func (h *AdminHandler) AuditLog(w http.ResponseWriter, r *http.Request) {
+	filter, err := audit.ParseFilter(r.URL.Query())
+	if err != nil {
+		middleware.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	events, err := h.adminService.GetAuditLog(filter, middleware.Username(r))
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if events == nil {
+		events = []*models.AuditEvent{}
+	}
+	middleware.WriteJSON(w, http.StatusOK, map[string]interface{}{"events": events})
+}
+
+// VerifyAuditLog checks the audit log's hash chain.
+func (h *AdminHandler) VerifyAuditLog(w http.ResponseWriter, r *http.Request) {
+	verification, err := h.adminService.VerifyAuditLog(middleware.Username(r))
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, verification)
+}
+
+// ExportAuditLog downloads every audit log entry matching the search as CSV, or as JSON with
+// ?format=json.
+func (h *AdminHandler) ExportAuditLog(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	format := strings.ToLower(query.Get("format"))
+	if format == "" {
+		format = audit.FormatCSV
+	}
+	if format != audit.FormatCSV && format != audit.FormatJSON {
+		middleware.WriteError(w, http.StatusBadRequest, "format must be "+audit.FormatCSV+" or "+audit.FormatJSON)
+		return
+	}
+	query.Del("format")
+
+	filter, err := audit.ParseFilter(query)
+	if err != nil {
+		middleware.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var body bytes.Buffer
+	if _, err := h.adminService.ExportAuditLog(&body, filter, format, middleware.Username(r), services.AuditSourceREST); err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	contentType := "text/csv"
+	if format == audit.FormatJSON {
+		contentType = "application/json"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=audit-log-"+time.Now().UTC().Format("20060102T150405Z")+"."+format)
+	w.WriteHeader(http.StatusOK)
+	w.Write(body.Bytes())
+}
+
+func writeRequests(w http.ResponseWriter, requests []*models.UnavailableCryptoRequest) {
+	if requests == nil {
+		requests = []*models.UnavailableCryptoRequest{}
+	}
+	middleware.WriteJSON(w, http.StatusOK, map[string]interface{}{"requests": requests})
+}
@@ -0,0 +1,516 @@
+package Handlers
+
+import (
+	"cryptotracker/REST-API/middleware"
+	"cryptotracker/REST-API/openapi"
+	"cryptotracker/internal/services"
+	"cryptotracker/models"
+	"cryptotracker/pkg/logger"
+	"cryptotracker/pkg/utils"
+	"cryptotracker/pkg/validation"
+	"errors"
+	"github.com/gorilla/mux"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+type AuthHandler struct {
+	authService  services.AuthService
+	tokenService services.TokenService
+}
+
+func NewAuthHandler(authService services.AuthService, tokenService services.TokenService) *AuthHandler {
+	return &AuthHandler{authService: authService, tokenService: tokenService}
+}
+
+type loginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type loginResponse struct {
+	*services.TokenPair
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+type twoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresIn         int    `json:"expires_in"`
+}
+
+type twoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+type twoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type signupRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Email    string `json:"email"`
+	Mobile   string `json:"mobile"`
+	Timezone string `json:"timezone"`
+}
+
+type passwordResetRequest struct {
+	Identifier string `json:"identifier"`
+}
+
+type passwordResetConfirmRequest struct {
+	Code        string `json:"code"`
+	NewPassword string `json:"new_password"`
+}
+
+type verifyEmailRequest struct {
+	Code string `json:"code"`
+}
+
+type apiKeyRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"`
+}
+
+type apiKeyResponse struct {
+	*models.APIKey
+	Key string `json:"key"`
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// LoginHandler checks a username and password and issues an access and refresh token. Users
+// with two-factor authentication get a challenge token instead, to send to /login/2fa with
+// their code.
+func (h *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	var req loginRequest
+	if !decodeJSON(w, r, "LoginRequest", &req) {
+		return
+	}
+
+	user, role, err := h.authService.Login(req.Username, req.Password, clientIP(r))
+	if writeThrottled(w, err) {
+		return
+	}
+	if err == services.ErrAccountDeactivated || err == services.ErrAccountDeleted {
+		middleware.WriteError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		// Unknown users and wrong passwords get the same answer
+		middleware.WriteError(w, http.StatusUnauthorized, "invalid username or password")
+		return
+	}
+
+	required, err := h.authService.TwoFactorRequired(user)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if required && !user.TwoFactorEnabled {
+		middleware.WriteError(w, http.StatusForbidden, "two-factor authentication is required for "+role+" accounts; set it up by logging in to the CLI")
+		return
+	}
+	if required {
+		challenge, err := h.tokenService.IssueTwoFactorChallenge(user.Username, role)
+		if err != nil {
+			writeServiceError(w, err, http.StatusInternalServerError)
+			return
+		}
+		middleware.WriteJSON(w, http.StatusAccepted, twoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+			ExpiresIn:         int(services.TwoFactorChallengeTTL.Seconds()),
+		})
+		return
+	}
+
+	tokens, err := h.tokenService.IssueTokens(user.Username, role, clientIP(r))
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, loginResponse{TokenPair: tokens, Username: user.Username, Role: role})
+}
+
+// TwoFactorLoginHandler completes a login by checking the authenticator or recovery code for a
+// challenge token from /login.
+func (h *AuthHandler) TwoFactorLoginHandler(w http.ResponseWriter, r *http.Request) {
+	var req twoFactorLoginRequest
+	if !decodeJSON(w, r, "TwoFactorLoginRequest", &req) {
+		return
+	}
+
+	claims, err := h.tokenService.ValidateTwoFactorChallenge(req.ChallengeToken)
+	if err != nil {
+		middleware.WriteError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	err = h.authService.VerifySecondFactor(claims.Subject, req.Code, clientIP(r))
+	if writeThrottled(w, err) {
+		return
+	}
+	if err == services.ErrInvalidTwoFactorCode || err == services.ErrTwoFactorNotEnabled {
+		middleware.WriteError(w, http.StatusUnauthorized, services.ErrInvalidTwoFactorCode.Error())
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	tokens, err := h.tokenService.IssueTokens(claims.Subject, claims.Role, clientIP(r))
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, loginResponse{TokenPair: tokens, Username: claims.Subject, Role: claims.Role})
+}
+
+// writeThrottled answers a login refused because of earlier failures with a 429 and reports
+// whether it did.
+func writeThrottled(w http.ResponseWriter, err error) bool {
+	var throttled *services.LoginThrottledError
+	if !errors.As(err, &throttled) {
+		return false
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+	middleware.WriteError(w, http.StatusTooManyRequests, throttled.Error())
+	return true
+}
+
+// SignupHandler registers a new user with the same rules as the CLI signup and emails them a
+// verification code. The account is created even if the email cannot be sent; the user can
+// ask for another from /users/me/verify-email.
+func (h *AuthHandler) SignupHandler(w http.ResponseWriter, r *http.Request) {
+	var req signupRequest
+	if !decodeJSON(w, r, "SignupRequest", &req) {
+		return
+	}
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+	if fields := validateSignup(&req); len(fields) > 0 {
+		middleware.WriteFieldErrors(w, fields)
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	user := &models.User{
+		Username: req.Username,
+		Password: hashedPassword,
+		Email:    req.Email,
+		Mobile:   req.Mobile,
+		IsAdmin:  false,
+		Role:     "user",
+		Timezone: req.Timezone,
+	}
+	if err := h.authService.Signup(user); err != nil {
+		writeServiceError(w, err, http.StatusBadRequest)
+		return
+	}
+	if err := h.authService.SendEmailVerification(user.Username); err != nil && err != services.ErrVerificationUnavailable {
+		logger.Logger.Error("Sending verification email failed", err)
+	}
+
+	user.Password = ""
+	middleware.WriteJSON(w, http.StatusCreated, user)
+}
+
+// validateSignup applies the signup rules the OpenAPI schema cannot express, so the REST API
+// accepts exactly what the CLI does.
+func validateSignup(req *signupRequest) []openapi.FieldError {
+	var fields []openapi.FieldError
+	if !validation.IsValidUsername(req.Username) {
+		fields = append(fields, openapi.FieldError{Field: "username", Message: "must be one word, alphanumeric, and can contain underscores"})
+	}
+	if !validation.IsValidPassword(req.Password) {
+		fields = append(fields, openapi.FieldError{Field: "password", Message: "must be at least 8 characters, include an uppercase letter, a number, and a special character"})
+	}
+	if !validation.IsValidEmail(req.Email) {
+		fields = append(fields, openapi.FieldError{Field: "email", Message: "must be a valid email address"})
+	}
+	if mobile, err := validation.NormalizeMobile(req.Mobile); err != nil {
+		fields = append(fields, openapi.FieldError{Field: "mobile", Message: err.Error()})
+	} else {
+		req.Mobile = mobile
+	}
+	if !validation.IsValidTimezone(req.Timezone) {
+		fields = append(fields, openapi.FieldError{Field: "timezone", Message: "must be an IANA name such as Europe/London"})
+	}
+	return fields
+}
+
+// RefreshHandler exchanges a refresh token for a new access and refresh token.
+func (h *AuthHandler) RefreshHandler(w http.ResponseWriter, r *http.Request) {
+	var req refreshRequest
+	if !decodeJSON(w, r, "RefreshRequest", &req) {
+		return
+	}
+
+	tokens, err := h.tokenService.RefreshTokens(req.RefreshToken)
+	if err == services.ErrInvalidToken {
+		middleware.WriteError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, tokens)
+}
+
+// LogoutHandler revokes the access token used for the request. A refresh token in the body is
+// revoked too; without one, all of the user's refresh tokens are.
+func (h *AuthHandler) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	var req refreshRequest
+	if r.ContentLength != 0 && !decodeJSON(w, r, "LogoutRequest", &req) {
+		return
+	}
+
+	if err := h.tokenService.RevokeTokens(middleware.Claims(r), req.RefreshToken); err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RequestPasswordResetHandler emails a reset code to the account with the given username or
+// email address. The answer is the same whether or not an account matches.
+func (h *AuthHandler) RequestPasswordResetHandler(w http.ResponseWriter, r *http.Request) {
+	var req passwordResetRequest
+	if !decodeJSON(w, r, "PasswordResetRequest", &req) {
+		return
+	}
+
+	err := h.authService.RequestPasswordReset(req.Identifier)
+	if err == services.ErrResetUnavailable {
+		middleware.WriteError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusAccepted, map[string]interface{}{"message": "if an account matches, a reset code has been emailed to it"})
+}
+
+// ConfirmPasswordResetHandler sets a new password using an emailed reset code.
+func (h *AuthHandler) ConfirmPasswordResetHandler(w http.ResponseWriter, r *http.Request) {
+	var req passwordResetConfirmRequest
+	if !decodeJSON(w, r, "PasswordResetConfirmRequest", &req) {
+		return
+	}
+	if !validation.IsValidPassword(req.NewPassword) {
+		middleware.WriteFieldErrors(w, []openapi.FieldError{{Field: "new_password", Message: "must be at least 8 characters, include an uppercase letter, a number, and a special character"}})
+		return
+	}
+
+	err := h.authService.ResetPassword(req.Code, req.NewPassword, services.AuditSourceREST)
+	if err == services.ErrInvalidResetToken {
+		middleware.WriteFieldErrors(w, []openapi.FieldError{{Field: "code", Message: err.Error()}})
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BeginTwoFactorHandler starts two-factor enrollment for the logged-in user and returns the
+// secret and the otpauth:// URI to add to an authenticator app.
+func (h *AuthHandler) BeginTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	enrollment, err := h.authService.BeginTwoFactorEnrollment(middleware.Username(r))
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, enrollment)
+}
+
+// ConfirmTwoFactorHandler turns two-factor authentication on with a code from the new secret
+// and returns the recovery codes, which are not shown again.
+func (h *AuthHandler) ConfirmTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	var req twoFactorCodeRequest
+	if !decodeJSON(w, r, "TwoFactorCodeRequest", &req) {
+		return
+	}
+
+	codes, err := h.authService.ConfirmTwoFactorEnrollment(middleware.Username(r), req.Code, services.AuditSourceREST)
+	if err == services.ErrInvalidTwoFactorCode {
+		middleware.WriteFieldErrors(w, []openapi.FieldError{{Field: "code", Message: err.Error()}})
+		return
+	}
+	if err == services.ErrTwoFactorNotStarted {
+		middleware.WriteError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, map[string]interface{}{"recovery_codes": codes})
+}
+
+// DisableTwoFactorHandler turns two-factor authentication off after checking a current code.
+// Wrong codes are throttled like those given at login.
+func (h *AuthHandler) DisableTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	var req twoFactorCodeRequest
+	if !decodeJSON(w, r, "TwoFactorCodeRequest", &req) {
+		return
+	}
+
+	err := h.authService.DisableTwoFactor(middleware.Username(r), req.Code, clientIP(r))
+	if writeThrottled(w, err) {
+		return
+	}
+	switch err {
+	case nil:
+		w.WriteHeader(http.StatusNoContent)
+	case services.ErrInvalidTwoFactorCode:
+		middleware.WriteFieldErrors(w, []openapi.FieldError{{Field: "code", Message: err.Error()}})
+	case services.ErrTwoFactorMandatory:
+		middleware.WriteError(w, http.StatusForbidden, err.Error())
+	case services.ErrTwoFactorNotEnabled:
+		middleware.WriteError(w, http.StatusConflict, err.Error())
+	default:
+		writeServiceError(w, err, http.StatusInternalServerError)
+	}
+}
+
+// VerifyEmailHandler marks the address an emailed verification code was sent to as verified.
+func (h *AuthHandler) VerifyEmailHandler(w http.ResponseWriter, r *http.Request) {
+	var req verifyEmailRequest
+	if !decodeJSON(w, r, "VerifyEmailRequest", &req) {
+		return
+	}
+
+	err := h.authService.VerifyEmail(req.Code, services.AuditSourceREST)
+	if err == services.ErrInvalidVerificationToken {
+		middleware.WriteFieldErrors(w, []openapi.FieldError{{Field: "code", Message: err.Error()}})
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResendVerificationHandler emails the logged-in user a new verification code.
+func (h *AuthHandler) ResendVerificationHandler(w http.ResponseWriter, r *http.Request) {
+	err := h.authService.SendEmailVerification(middleware.Username(r))
+	switch err {
+	case nil:
+		middleware.WriteJSON(w, http.StatusAccepted, map[string]interface{}{"message": "a verification code has been emailed to you"})
+	case services.ErrVerificationUnavailable:
+		middleware.WriteError(w, http.StatusServiceUnavailable, err.Error())
+	case services.ErrTooManyVerificationEmails:
+		middleware.WriteError(w, http.StatusTooManyRequests, err.Error())
+	default:
+		writeServiceError(w, err, http.StatusInternalServerError)
+	}
+}
+
+// SessionsHandler lists the logged-in user's live sessions, marking the one the request was
+// made from.
+func (h *AuthHandler) SessionsHandler(w http.ResponseWriter, r *http.Request) {
+	sessions, err := h.tokenService.ListSessions(middleware.Username(r))
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if sessions == nil {
+		sessions = []*models.Session{}
+	}
+	if claims := middleware.Claims(r); claims != nil {
+		for _, session := range sessions {
+			session.Current = session.ID == claims.SessionID
+		}
+	}
+	middleware.WriteJSON(w, http.StatusOK, map[string]interface{}{"sessions": sessions})
+}
+
+// RevokeSessionHandler ends one of the logged-in user's sessions, which may be the current one.
+func (h *AuthHandler) RevokeSessionHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.tokenService.RevokeSession(middleware.Username(r), mux.Vars(r)["id"]); err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// APIKeysHandler lists the logged-in user's API keys. The keys themselves are not shown.
+func (h *AuthHandler) APIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.tokenService.ListAPIKeys(middleware.Username(r))
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if keys == nil {
+		keys = []*models.APIKey{}
+	}
+	middleware.WriteJSON(w, http.StatusOK, map[string]interface{}{"api_keys": keys})
+}
+
+// CreateAPIKeyHandler mints an API key for the logged-in user. The key is in the response
+// and is not shown again.
+func (h *AuthHandler) CreateAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	var req apiKeyRequest
+	if !decodeJSON(w, r, "APIKeyRequest", &req) {
+		return
+	}
+	if req.ExpiresInDays == 0 {
+		req.ExpiresInDays = services.DefaultAPIKeyDays
+	}
+
+	key, apiKey, err := h.tokenService.CreateAPIKey(middleware.Username(r), req.Name, req.Scopes, req.ExpiresInDays, services.AuditSourceREST)
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusCreated, apiKeyResponse{APIKey: key, Key: apiKey})
+}
+
+// RevokeAPIKeyHandler revokes one of the logged-in user's API keys.
+func (h *AuthHandler) RevokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		middleware.WriteError(w, http.StatusBadRequest, "API key ID must be a number")
+		return
+	}
+
+	if err := h.tokenService.RevokeAPIKey(middleware.Username(r), id, services.AuditSourceREST); err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,309 @@
+package Handlers
+
+import (
+	"cryptotracker/REST-API/middleware"
+	"cryptotracker/REST-API/openapi"
+	"cryptotracker/internal/alerts"
+	"cryptotracker/internal/services"
+	"cryptotracker/models"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultTopCryptos = 10
+	maxTopCryptos     = 100
+)
+
+type CryptoHandler struct {
+	cryptoService services.CryptoService
+}
+
+func NewCryptoHandler(cryptoService services.CryptoService) *CryptoHandler {
+	return &CryptoHandler{cryptoService: cryptoService}
+}
+
+// alertRequest is the body of POST /cryptos/alert. Kind selects which of the kind-specific
+// fields are used and defaults to a target-price alert.
+type alertRequest struct {
+	Kind             string  `json:"kind"`
+	Symbol           string  `json:"symbol"`
+	TargetPrice      float64 `json:"target_price"`
+	Rule             string  `json:"rule"`
+	TrailPercent     float64 `json:"trail_percent"`
+	VolumeMultiplier float64 `json:"volume_multiplier"`
+	RankThreshold    int     `json:"rank_threshold"`
+	models.AlertOptions
+}
+
+// ladderRequest is the body of POST /cryptos/alert/ladder: either explicit levels or a range
+// from-to spaced step apart.
+type ladderRequest struct {
+	Symbol string    `json:"symbol"`
+	Levels []float64 `json:"levels"`
+	From   float64   `json:"from"`
+	To     float64   `json:"to"`
+	Step   float64   `json:"step"`
+	models.AlertOptions
+}
+
+type groupStatusRequest struct {
+	Status string `json:"status"`
+}
+
+// DisplayTopCryptos lists the top cryptocurrencies by market cap; ?limit= sets how many.
+func (h *CryptoHandler) DisplayTopCryptos(w http.ResponseWriter, r *http.Request) {
+	limit := defaultTopCryptos
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxTopCryptos {
+			middleware.WriteError(w, http.StatusBadRequest, "limit must be a number from 1 to "+strconv.Itoa(maxTopCryptos))
+			return
+		}
+		limit = parsed
+	}
+
+	cryptos, err := h.cryptoService.DisplayTopCryptocurrencies(limit)
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadGateway)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, map[string]interface{}{"cryptocurrencies": cryptos})
+}
+
+// DisplayCryptoByName looks up a cryptocurrency by symbol or name. Unknown ones are
+// requested for addition, as in the CLI, and answered with 202.
+func (h *CryptoHandler) DisplayCryptoByName(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["cryptoname"]
+	user := &models.User{Username: middleware.Username(r)}
+
+	price, cryptoName, symbol, err := h.cryptoService.SearchCryptocurrency(user, name)
+	if err != nil {
+		if strings.Contains(err.Error(), "request to add the cryptocurrency has been submitted") {
+			middleware.WriteJSON(w, http.StatusAccepted, map[string]interface{}{"message": "cryptocurrency not tracked; a request to add it has been submitted"})
+			return
+		}
+		writeServiceError(w, err, http.StatusBadGateway)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, map[string]interface{}{"name": cryptoName, "symbol": symbol, "price": price})
+}
+
+// SetPriceAlert creates an alert of any kind: price, rule, trailing, volume_spike or rank.
+func (h *CryptoHandler) SetPriceAlert(w http.ResponseWriter, r *http.Request) {
+	var req alertRequest
+	if !decodeJSON(w, r, "AlertRequest", &req) {
+		return
+	}
+
+	if fields := alertFieldErrors(&req); len(fields) > 0 {
+		middleware.WriteFieldErrors(w, fields)
+		return
+	}
+
+	user := &models.User{Username: middleware.Username(r)}
+	symbol := strings.ToUpper(strings.TrimSpace(req.Symbol))
+	response := map[string]interface{}{"kind": req.Kind, "symbol": symbol}
+
+	var err error
+	switch req.Kind {
+	case "", "price":
+		response["kind"] = "price"
+		response["target_price"] = req.TargetPrice
+		response["current_price"], err = h.cryptoService.SetPriceAlert(user, symbol, req.TargetPrice, &req.AlertOptions)
+	case "rule":
+		delete(response, "symbol")
+		response["rule"], err = h.cryptoService.SetRuleAlert(user, req.Rule, &req.AlertOptions)
+	case "trailing":
+		response["trail_percent"] = req.TrailPercent
+		response["current_price"], err = h.cryptoService.SetTrailingAlert(user, symbol, req.TrailPercent, &req.AlertOptions)
+	case "volume_spike":
+		response["volume_multiplier"] = req.VolumeMultiplier
+		response["current_volume_24h"], err = h.cryptoService.SetVolumeSpikeAlert(user, symbol, req.VolumeMultiplier, &req.AlertOptions)
+	case "rank":
+		response["rank_threshold"] = req.RankThreshold
+		response["current_rank"], err = h.cryptoService.SetRankAlert(user, symbol, req.RankThreshold, &req.AlertOptions)
+	}
+
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusCreated, response)
+}
+
+// alertFieldErrors checks that the body has the fields its kind needs, which the schema
+// cannot express as it has one shape for every kind.
+func alertFieldErrors(req *alertRequest) []openapi.FieldError {
+	var fields []openapi.FieldError
+	if req.Kind != "rule" && strings.TrimSpace(req.Symbol) == "" {
+		fields = append(fields, openapi.FieldError{Field: "symbol", Message: "is required"})
+	}
+
+	var missing string
+	switch req.Kind {
+	case "", "price":
+		if req.TargetPrice == 0 {
+			missing = "target_price"
+		}
+	case "rule":
+		if strings.TrimSpace(req.Rule) == "" {
+			missing = "rule"
+		}
+	case "trailing":
+		if req.TrailPercent == 0 {
+			missing = "trail_percent"
+		}
+	case "volume_spike":
+		if req.VolumeMultiplier == 0 {
+			missing = "volume_multiplier"
+		}
+	case "rank":
+		if req.RankThreshold == 0 {
+			missing = "rank_threshold"
+		}
+	}
+	if missing != "" {
+		fields = append(fields, openapi.FieldError{Field: missing, Message: "is required"})
+	}
+	return fields
+}
+
+// SetLadderAlert creates a group of alerts at several levels.
+func (h *CryptoHandler) SetLadderAlert(w http.ResponseWriter, r *http.Request) {
+	var req ladderRequest
+	if !decodeJSON(w, r, "LadderRequest", &req) {
+		return
+	}
+
+	var levels []float64
+	var err error
+	if len(req.Levels) > 0 {
+		levels, err = alerts.NormalizeLevels(req.Levels)
+		if err != nil {
+			middleware.WriteFieldErrors(w, []openapi.FieldError{{Field: "levels", Message: err.Error()}})
+			return
+		}
+	} else {
+		var fields []openapi.FieldError
+		bounds := []struct {
+			name  string
+			value float64
+		}{{"from", req.From}, {"step", req.Step}, {"to", req.To}}
+		for _, bound := range bounds {
+			if bound.value == 0 {
+				fields = append(fields, openapi.FieldError{Field: bound.name, Message: "is required without levels"})
+			}
+		}
+		if len(fields) > 0 {
+			middleware.WriteFieldErrors(w, fields)
+			return
+		}
+		levels, err = alerts.LadderLevels(req.From, req.To, req.Step)
+		if err != nil {
+			middleware.WriteFieldErrors(w, []openapi.FieldError{{Field: "step", Message: err.Error()}})
+			return
+		}
+	}
+
+	user := &models.User{Username: middleware.Username(r)}
+	group, err := h.cryptoService.SetLadderAlert(user, strings.ToUpper(strings.TrimSpace(req.Symbol)), levels, &req.AlertOptions)
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusCreated, group)
+}
+
+// AlertGroups lists the user's alert groups with their alerts.
+func (h *CryptoHandler) AlertGroups(w http.ResponseWriter, r *http.Request) {
+	owner, ok := alertOwner(w, r)
+	if !ok {
+		return
+	}
+
+	groups, err := h.cryptoService.GetAlertGroups(owner, middleware.Username(r))
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if groups == nil {
+		groups = []*models.AlertGroup{}
+	}
+	middleware.WriteJSON(w, http.StatusOK, map[string]interface{}{"groups": groups})
+}
+
+// UpdateAlertGroup pauses or resumes an alert group.
+func (h *CryptoHandler) UpdateAlertGroup(w http.ResponseWriter, r *http.Request) {
+	groupID, ok := groupIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	owner, ok := alertOwner(w, r)
+	if !ok {
+		return
+	}
+
+	var req groupStatusRequest
+	if !decodeJSON(w, r, "AlertGroupStatusRequest", &req) {
+		return
+	}
+
+	if err := h.cryptoService.SetAlertGroupStatus(owner, groupID, req.Status, middleware.Username(r)); err != nil {
+		writeServiceError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, map[string]interface{}{"id": groupID, "status": req.Status})
+}
+
+// DeleteAlertGroup deletes an alert group and all of its alerts.
+func (h *CryptoHandler) DeleteAlertGroup(w http.ResponseWriter, r *http.Request) {
+	groupID, ok := groupIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	owner, ok := alertOwner(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.cryptoService.DeleteAlertGroup(owner, groupID, middleware.Username(r)); err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func groupIDFromPath(w http.ResponseWriter, r *http.Request) (int, bool) {
+	groupID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		middleware.WriteError(w, http.StatusBadRequest, "group ID must be a number")
+		return 0, false
+	}
+	return groupID, true
+}
+
+// alertOwner is the user whose alert groups the request is for: the authenticated user, or
+// the user named by the username query parameter. The crypto service checks that the caller's
+// role may manage that user's alerts. API keys only ever manage their owner's alerts.
+func alertOwner(w http.ResponseWriter, r *http.Request) (string, bool) {
+	owner := r.URL.Query().Get("username")
+	if owner == "" || owner == middleware.Username(r) {
+		return middleware.Username(r), true
+	}
+
+	if claims := middleware.Claims(r); claims == nil || claims.IsAPIKey() {
+		middleware.WriteError(w, http.StatusForbidden, "API keys can only manage their owner's alerts")
+		return "", false
+	}
+	return owner, true
+}
@@ -0,0 +1,48 @@
+package Handlers
+
+import (
+	"cryptotracker/REST-API/middleware"
+	"cryptotracker/internal/services"
+	"cryptotracker/models"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+)
+
+type DeliveryHandler struct {
+	deliveryService services.DeliveryService
+}
+
+func NewDeliveryHandler(deliveryService services.DeliveryService) *DeliveryHandler {
+	return &DeliveryHandler{deliveryService: deliveryService}
+}
+
+// FailedDeliveries lists dead deliveries and those waiting to retry.
+func (h *DeliveryHandler) FailedDeliveries(w http.ResponseWriter, r *http.Request) {
+	deliveries, err := h.deliveryService.GetFailedDeliveries()
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if deliveries == nil {
+		deliveries = []*models.NotificationDelivery{}
+	}
+	middleware.WriteJSON(w, http.StatusOK, map[string]interface{}{"deliveries": deliveries})
+}
+
+// RequeueDelivery schedules a failed delivery to be sent again.
+func (h *DeliveryHandler) RequeueDelivery(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		middleware.WriteError(w, http.StatusBadRequest, "delivery ID must be a number")
+		return
+	}
+
+	if err := h.deliveryService.RequeueDelivery(id); err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, map[string]interface{}{"id": id, "status": "pending"})
+}
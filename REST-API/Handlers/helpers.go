@@ -0,0 +1,74 @@
+package Handlers
+
+import (
+	"cryptotracker/REST-API/middleware"
+	"cryptotracker/REST-API/openapi"
+	"cryptotracker/internal/services"
+	"cryptotracker/pkg/logger"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// maxBodyBytes caps the size of JSON request bodies.
+const maxBodyBytes = 1 << 20
+
+// decodeJSON validates the request body against the named schema of the OpenAPI document
+// and reads it into dst. On failure it writes a 400, listing the problems by field when the
+// body is well-formed JSON, and returns false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, schema string, dst interface{}) bool {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		middleware.WriteError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		middleware.WriteError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	if fields := openapi.Validate(schema, document); len(fields) > 0 {
+		middleware.WriteFieldErrors(w, fields)
+		return false
+	}
+
+	if err := json.Unmarshal(body, dst); err != nil {
+		middleware.WriteError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+// writeServiceError maps an error from a service to a response. Services report failures as
+// plain errors, so lookups that found nothing and conflicts are recognised by their message;
+// anything else gets the fallback status. Internal errors are logged and not shown to clients.
+func writeServiceError(w http.ResponseWriter, err error, fallback int) {
+	message := err.Error()
+	switch {
+	case err == services.ErrPermissionDenied:
+		middleware.WriteError(w, http.StatusForbidden, message)
+	case strings.Contains(message, "not found"):
+		middleware.WriteError(w, http.StatusNotFound, message)
+	case strings.Contains(message, "already"):
+		middleware.WriteError(w, http.StatusConflict, message)
+	case fallback >= http.StatusInternalServerError:
+		logger.Logger.Error("Request failed", err)
+		middleware.WriteError(w, fallback, "internal server error")
+	default:
+		middleware.WriteError(w, fallback, message)
+	}
+}
+
+// clientIP is the address of the client that sent the request. Forwarding headers are not
+// trusted, as they are set by the client when there is no proxy in front of the server.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,29 @@
+package Handlers
+
+import (
+	"cryptotracker/REST-API/middleware"
+	"cryptotracker/internal/services"
+	"net/http"
+)
+
+type NotificationHandler struct {
+	notificationService services.NotificationService
+}
+
+func NewNotificationHandler(notificationService services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+// CheckNotificationHandler returns the user's notifications, as shown in the CLI after login.
+func (h *NotificationHandler) CheckNotificationHandler(w http.ResponseWriter, r *http.Request) {
+	notifications, err := h.notificationService.CheckNotification(middleware.Username(r))
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if notifications == nil {
+		notifications = []services.Notification{}
+	}
+	middleware.WriteJSON(w, http.StatusOK, map[string]interface{}{"notifications": notifications})
+}
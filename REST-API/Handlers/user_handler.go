@@ -0,0 +1,93 @@
+package Handlers
+
+import (
+	"cryptotracker/REST-API/middleware"
+	"cryptotracker/REST-API/openapi"
+	"cryptotracker/internal/notify"
+	"cryptotracker/internal/services"
+	"cryptotracker/pkg/logger"
+	"cryptotracker/pkg/validation"
+	"net/http"
+)
+
+type UserHandler struct {
+	userService services.UserServices
+	authService services.AuthService
+}
+
+func NewUserHandler(userService services.UserServices, authService services.AuthService) *UserHandler {
+	return &UserHandler{userService: userService, authService: authService}
+}
+
+// UserProfile returns the authenticated user's profile.
+func (h *UserHandler) UserProfile(w http.ResponseWriter, r *http.Request) {
+	h.writeProfile(w, middleware.Username(r))
+}
+
+// UpdateProfile changes the fields present in the body and returns the updated profile. A
+// new email address is unverified until the user sends the emailed code to /verify-email.
+func (h *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	var req services.ProfileUpdate
+	if !decodeJSON(w, r, "UpdateProfileRequest", &req) {
+		return
+	}
+	if fields := validateProfile(&req); len(fields) > 0 {
+		middleware.WriteFieldErrors(w, fields)
+		return
+	}
+
+	username := middleware.Username(r)
+	emailChanged, err := h.userService.UpdateProfile(username, &req, services.AuditSourceREST)
+	if writeThrottled(w, err) {
+		return
+	}
+	if err == services.ErrWrongPassword {
+		middleware.WriteFieldErrors(w, []openapi.FieldError{{Field: "current_password", Message: err.Error()}})
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if emailChanged {
+		if err := h.authService.SendEmailVerification(username); err != nil && err != services.ErrVerificationUnavailable {
+			logger.Logger.Error("Sending verification email failed", err)
+		}
+	}
+
+	h.writeProfile(w, username)
+}
+
+// validateProfile applies the profile rules the OpenAPI schema cannot express, so the REST
+// API accepts exactly what the CLI does.
+func validateProfile(req *services.ProfileUpdate) []openapi.FieldError {
+	var fields []openapi.FieldError
+	if req.Email != nil && !validation.IsValidEmail(*req.Email) {
+		fields = append(fields, openapi.FieldError{Field: "email", Message: "must be a valid email address"})
+	}
+	if req.Mobile != nil {
+		if _, err := validation.NormalizeMobile(*req.Mobile); err != nil {
+			fields = append(fields, openapi.FieldError{Field: "mobile", Message: err.Error()})
+		}
+	}
+	if req.NewPassword != "" && req.CurrentPassword == "" {
+		fields = append(fields, openapi.FieldError{Field: "current_password", Message: "is required to change the password"})
+	}
+	if req.NewPassword != "" && !validation.IsValidPassword(req.NewPassword) {
+		fields = append(fields, openapi.FieldError{Field: "new_password", Message: "must be at least 8 characters, include an uppercase letter, a number, and a special character"})
+	}
+	return fields
+}
+
+func (h *UserHandler) writeProfile(w http.ResponseWriter, username string) {
+	user, err := h.userService.GetUserProfile(username)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	user.Password = ""
+	user.NotificationPreferences = notify.Normalize(user.NotificationPreferences)
+	middleware.WriteJSON(w, http.StatusOK, user)
+}
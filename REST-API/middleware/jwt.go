@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"context"
+	"cryptotracker/internal/rbac"
+	"cryptotracker/internal/services"
+	"net/http"
+	"strings"
+)
+
+type contextKey string
+
+const (
+	claimsKey      contextKey = "claims"
+	apiKeyScopeKey contextKey = "apiKeyScope"
+)
+
+// JWTTokenService authenticates REST requests with the bearer access tokens issued by
+// the token service.
+type JWTTokenService struct {
+	Tokens services.TokenService
+}
+
+func NewJWTTokenService(tokens services.TokenService) *JWTTokenService {
+	return &JWTTokenService{Tokens: tokens}
+}
+
+// UserMiddleware lets through any request carrying a valid access token.
+func (j *JWTTokenService) UserMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := j.authenticate(w, r)
+		if !ok {
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), claimsKey, claims)))
+	})
+}
+
+// RequirePermission returns middleware that lets through requests whose access token carries
+// a role granting the permission.
+func (j *JWTTokenService) RequirePermission(permission rbac.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := j.authenticate(w, r)
+			if !ok {
+				return
+			}
+			if !rbac.Can(claims.Role, permission) {
+				WriteError(w, http.StatusForbidden, "permission "+string(permission)+" required")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), claimsKey, claims)))
+		})
+	}
+}
+
+// AllowAPIKey returns middleware that lets API keys with the scope authenticate the routes it
+// wraps. Everywhere else only access tokens are accepted.
+func AllowAPIKey(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiKeyScopeKey, scope)))
+		})
+	}
+}
+
+// authenticate validates the request's bearer token, which may be an access token or an API
+// key, writing a 401 when it is missing or invalid and a 403 when an API key is not allowed.
+func (j *JWTTokenService) authenticate(w http.ResponseWriter, r *http.Request) (*services.TokenClaims, bool) {
+	token, found := BearerToken(r)
+	if !found {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="cryptotracker"`)
+		WriteError(w, http.StatusUnauthorized, "missing bearer token")
+		return nil, false
+	}
+
+	validate := j.Tokens.ValidateAccessToken
+	if strings.HasPrefix(token, services.APIKeyPrefix) {
+		validate = j.Tokens.ValidateAPIKey
+	}
+
+	claims, err := validate(token)
+	if err == services.ErrInvalidToken {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="cryptotracker", error="invalid_token"`)
+		WriteError(w, http.StatusUnauthorized, err.Error())
+		return nil, false
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "failed to validate token")
+		return nil, false
+	}
+
+	if claims.IsAPIKey() {
+		scope, _ := r.Context().Value(apiKeyScopeKey).(string)
+		if scope == "" {
+			WriteError(w, http.StatusForbidden, "API keys cannot be used here")
+			return nil, false
+		}
+		if !claims.HasScope(scope) {
+			WriteError(w, http.StatusForbidden, "API key lacks the "+scope+" scope")
+			return nil, false
+		}
+	}
+
+	return claims, true
+}
+
+// BearerToken returns the token from the request's Authorization header.
+func BearerToken(r *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// Claims returns the access token claims the middleware stored on the request.
+func Claims(r *http.Request) *services.TokenClaims {
+	claims, _ := r.Context().Value(claimsKey).(*services.TokenClaims)
+	return claims
+}
+
+// Username returns the authenticated user of the request.
+func Username(r *http.Request) string {
+	if claims := Claims(r); claims != nil {
+		return claims.Subject
+	}
+	return ""
+}
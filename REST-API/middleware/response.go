@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"cryptotracker/REST-API/openapi"
+	"encoding/json"
+	"net/http"
+)
+
+// ErrorResponse is the body of every error the REST API returns. Fields lists the problems
+// with a request body that failed validation.
+type ErrorResponse struct {
+	Status  int                  `json:"status"`
+	Error   string               `json:"error"`
+	Message string               `json:"message"`
+	Fields  []openapi.FieldError `json:"fields,omitempty"`
+}
+
+// WriteJSON writes v as a JSON response with the given status.
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if v != nil {
+		json.NewEncoder(w).Encode(v)
+	}
+}
+
+// WriteError writes an ErrorResponse with the given status and message.
+func WriteError(w http.ResponseWriter, status int, message string) {
+	WriteJSON(w, status, ErrorResponse{Status: status, Error: http.StatusText(status), Message: message})
+}
+
+// WriteFieldErrors writes a 400 listing the problems with each field of the request body.
+func WriteFieldErrors(w http.ResponseWriter, fields []openapi.FieldError) {
+	WriteJSON(w, http.StatusBadRequest, ErrorResponse{
+		Status:  http.StatusBadRequest,
+		Error:   http.StatusText(http.StatusBadRequest),
+		Message: "request body is invalid",
+		Fields:  fields,
+	})
+}
+
+// NotFound answers requests for unknown routes.
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	WriteError(w, http.StatusNotFound, "no route for "+r.Method+" "+r.URL.Path)
+}
+
+// MethodNotAllowed answers requests using a method a route does not support.
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	WriteError(w, http.StatusMethodNotAllowed, r.Method+" is not supported for "+r.URL.Path)
+}
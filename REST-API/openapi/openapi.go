@@ -0,0 +1,34 @@
+// Package openapi holds the OpenAPI document describing the REST API, serves it with a docs
+// page, and validates request bodies against the schemas it defines.
+//
+// openapi.json is the contract for the routes registered in cmd/main.go; change it along with
+// any route or request/response body.
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+//go:embed docs.html
+var docsPage []byte
+
+// Spec returns the OpenAPI document.
+func Spec() []byte {
+	return spec
+}
+
+// ServeSpec serves the OpenAPI document at /openapi.json.
+func ServeSpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(spec)
+}
+
+// ServeDocs serves a page rendering the OpenAPI document.
+func ServeDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(docsPage)
+}
@@ -0,0 +1,281 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FieldError describes one problem with a field of a request body. Field is a path such as
+// "notificationPreferences.quiet_from" or "levels[2]"; it is empty for the body itself.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Schema is the subset of an OpenAPI 3.0 schema object the validator understands.
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Nullable             bool               `json:"nullable"`
+	Required             []string           `json:"required"`
+	Properties           map[string]*Schema `json:"properties"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+	AllOf                []*Schema          `json:"allOf"`
+	Items                *Schema            `json:"items"`
+	Enum                 []interface{}      `json:"enum"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	Pattern              string             `json:"pattern"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+}
+
+type document struct {
+	Components struct {
+		Schemas map[string]*Schema `json:"schemas"`
+	} `json:"components"`
+}
+
+var (
+	loadOnce sync.Once
+	schemas  map[string]*Schema
+	patterns sync.Map // pattern -> *regexp.Regexp
+)
+
+func componentSchemas() map[string]*Schema {
+	loadOnce.Do(func() {
+		var doc document
+		if err := json.Unmarshal(spec, &doc); err != nil {
+			panic(fmt.Sprintf("invalid embedded OpenAPI document: %v", err))
+		}
+		schemas = doc.Components.Schemas
+	})
+	return schemas
+}
+
+// Validate checks a decoded JSON value against a schema in components/schemas and returns
+// every problem found, sorted by field.
+func Validate(schemaName string, value interface{}) []FieldError {
+	schema, ok := componentSchemas()[schemaName]
+	if !ok {
+		panic(fmt.Sprintf("OpenAPI schema %q is not defined", schemaName))
+	}
+
+	var errs []FieldError
+	validate(schema, value, "", &errs)
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs
+}
+
+func resolve(schema *Schema) *Schema {
+	for schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		resolved, ok := componentSchemas()[name]
+		if !ok {
+			panic(fmt.Sprintf("OpenAPI schema reference %q is not defined", schema.Ref))
+		}
+		schema = resolved
+	}
+	if len(schema.AllOf) == 0 {
+		return schema
+	}
+
+	// Merge allOf into one object schema so unknown properties are judged against all parts
+	merged := *schema
+	merged.AllOf = nil
+	merged.Properties = make(map[string]*Schema)
+	for name, property := range schema.Properties {
+		merged.Properties[name] = property
+	}
+	for _, part := range schema.AllOf {
+		part = resolve(part)
+		if merged.Type == "" {
+			merged.Type = part.Type
+		}
+		for name, property := range part.Properties {
+			merged.Properties[name] = property
+		}
+		merged.Required = append(merged.Required, part.Required...)
+	}
+	return &merged
+}
+
+func validate(schema *Schema, value interface{}, field string, errs *[]FieldError) {
+	schema = resolve(schema)
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if value == nil {
+		if !schema.Nullable {
+			fail("must not be null")
+		}
+		return
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		validateObject(schema, object, field, errs)
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			fail("must be an array")
+			return
+		}
+		if schema.MinItems != nil && len(items) < *schema.MinItems {
+			fail("must have at least %d items", *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(items) > *schema.MaxItems {
+			fail("must have at most %d items", *schema.MaxItems)
+		}
+		if schema.Items != nil {
+			for i, item := range items {
+				validate(schema.Items, item, fmt.Sprintf("%s[%d]", field, i), errs)
+			}
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		validateString(schema, s, fail)
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok {
+			fail("must be a number")
+			return
+		}
+		if schema.Type == "integer" && n != math.Trunc(n) {
+			fail("must be a whole number")
+			return
+		}
+		if schema.Minimum != nil {
+			if schema.ExclusiveMinimum && n <= *schema.Minimum {
+				fail("must be greater than %v", *schema.Minimum)
+			} else if n < *schema.Minimum {
+				fail("must be at least %v", *schema.Minimum)
+			}
+		}
+		if schema.Maximum != nil && n > *schema.Maximum {
+			fail("must be at most %v", *schema.Maximum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be true or false")
+			return
+		}
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		fail("must be one of %s", enumList(schema.Enum))
+	}
+}
+
+func validateObject(schema *Schema, object map[string]interface{}, field string, errs *[]FieldError) {
+	for _, name := range schema.Required {
+		if _, present := object[name]; !present {
+			*errs = append(*errs, FieldError{Field: join(field, name), Message: "is required"})
+		}
+	}
+
+	var additional *Schema
+	closed := false
+	if len(schema.AdditionalProperties) > 0 {
+		if string(schema.AdditionalProperties) == "false" {
+			closed = true
+		} else if string(schema.AdditionalProperties) != "true" {
+			additional = &Schema{}
+			if err := json.Unmarshal(schema.AdditionalProperties, additional); err != nil {
+				panic(fmt.Sprintf("invalid additionalProperties schema: %v", err))
+			}
+		}
+	}
+
+	for name, value := range object {
+		if property, ok := schema.Properties[name]; ok {
+			validate(property, value, join(field, name), errs)
+		} else if additional != nil {
+			validate(additional, value, join(field, name), errs)
+		} else if closed {
+			*errs = append(*errs, FieldError{Field: join(field, name), Message: "is not a known field"})
+		}
+	}
+}
+
+func validateString(schema *Schema, s string, fail func(string, ...interface{})) {
+	length := len([]rune(s))
+	if schema.MinLength != nil && length < *schema.MinLength {
+		if *schema.MinLength == 1 {
+			fail("must not be empty")
+		} else {
+			fail("must be at least %d characters", *schema.MinLength)
+		}
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		fail("must be at most %d characters", *schema.MaxLength)
+	}
+	if schema.Pattern != "" && s != "" && !compile(schema.Pattern).MatchString(s) {
+		fail("must match %s", schema.Pattern)
+	}
+
+	switch schema.Format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			fail("must be an RFC 3339 date-time such as 2024-09-20T18:30:00Z")
+		}
+	case "uri":
+		if u, err := url.ParseRequestURI(s); s != "" && (err != nil || u.Host == "") {
+			fail("must be an absolute URL")
+		}
+	}
+}
+
+func compile(pattern string) *regexp.Regexp {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+	re := regexp.MustCompile(pattern)
+	patterns.Store(pattern, re)
+	return re
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if allowed == value {
+			return true
+		}
+	}
+	return false
+}
+
+func enumList(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, value := range enum {
+		values[i] = fmt.Sprint(value)
+	}
+	return strings.Join(values, ", ")
+}
+
+func join(field, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"cryptotracker/REST-API/Handlers"
 	"cryptotracker/REST-API/middleware"
+	"cryptotracker/REST-API/openapi"
+	"cryptotracker/internal/rbac"
 	"cryptotracker/internal/repositories"
 	"cryptotracker/internal/services"
 	"cryptotracker/pkg/config"
@@ -11,6 +14,7 @@ import (
 	"cryptotracker/pkg/ui"
 	"github.com/gorilla/mux"
 	"net/http"
+	"time"
 )
 
 func main() {
@@ -32,14 +36,24 @@ func main() {
 	notificationRepo := repositories.NewPostgresNotificationRepository(conn)
 	notificationService := services.NewNotificationService(notificationRepo)
 
+	stablecoinRepo := repositories.NewPostgresStablecoinRepository(conn)
+	stablecoinService := services.NewStablecoinService(stablecoinRepo)
+
+	deliveryRepo := repositories.NewPostgresDeliveryRepository(conn)
+	deliveryService := services.NewDeliveryService(deliveryRepo)
+
 	authRepo := repositories.NewPostgresAuthRepository(conn)
 	authService := services.NewAuthService(authRepo, notificationService)
 
-	authHandler := Handlers.NewAuthHandler(authService)
-	userHandler := Handlers.NewUserHandler(userService)
-	adminHandler := Handlers.NewAdminHandler(adminService)
+	tokenRepo := repositories.NewPostgresTokenRepository(conn)
+	tokenService := services.NewTokenService(tokenRepo)
+
+	authHandler := Handlers.NewAuthHandler(authService, tokenService)
+	userHandler := Handlers.NewUserHandler(userService, authService)
+	adminHandler := Handlers.NewAdminHandler(adminService, notificationService)
 	cryptoHandler := Handlers.NewCryptoHandler(cryptoService)
 	notificationHandler := Handlers.NewNotificationHandler(notificationService)
+	deliveryHandler := Handlers.NewDeliveryHandler(deliveryService)
 
 	// Use the logger from logger.go
 	logger.Logger.Info("Starting the server...")
@@ -49,28 +63,64 @@ func main() {
 	// Start the HTTP server
 	go func() {
 
-		jwtMiddleware := middleware.JWTTokenService{}
+		jwtMiddleware := middleware.NewJWTTokenService(tokenService)
 
-		adminMiddleware := jwtMiddleware.AdminMiddleware
 		userMiddleware := jwtMiddleware.UserMiddleware
+		requirePermission := jwtMiddleware.RequirePermission
+		marketDataKeys := middleware.AllowAPIKey(services.ScopeMarketRead)
+		alertKeys := middleware.AllowAPIKey(services.ScopeAlertsManage)
 
+		r.HandleFunc("/openapi.json", openapi.ServeSpec).Methods("GET")
+		r.HandleFunc("/docs", openapi.ServeDocs).Methods("GET")
 		r.HandleFunc("/login", authHandler.LoginHandler).Methods("POST")
+		r.HandleFunc("/login/2fa", authHandler.TwoFactorLoginHandler).Methods("POST")
 		r.HandleFunc("/signup", authHandler.SignupHandler).Methods("POST")
+		r.HandleFunc("/refresh", authHandler.RefreshHandler).Methods("POST")
+		r.HandleFunc("/password-reset", authHandler.RequestPasswordResetHandler).Methods("POST")
+		r.HandleFunc("/password-reset/confirm", authHandler.ConfirmPasswordResetHandler).Methods("POST")
+		r.HandleFunc("/verify-email", authHandler.VerifyEmailHandler).Methods("POST")
 		r.Handle("/logout", userMiddleware(http.HandlerFunc(authHandler.LogoutHandler))).Methods("POST")
 		r.Handle("/users/me", userMiddleware(http.HandlerFunc(userHandler.UserProfile))).Methods("GET")
+		r.Handle("/users/me", userMiddleware(http.HandlerFunc(userHandler.UpdateProfile))).Methods("PATCH")
+		r.Handle("/users/me/verify-email", userMiddleware(http.HandlerFunc(authHandler.ResendVerificationHandler))).Methods("POST")
+		r.Handle("/users/me/2fa", userMiddleware(http.HandlerFunc(authHandler.BeginTwoFactorHandler))).Methods("POST")
+		r.Handle("/users/me/2fa", userMiddleware(http.HandlerFunc(authHandler.DisableTwoFactorHandler))).Methods("DELETE")
+		r.Handle("/users/me/sessions", userMiddleware(http.HandlerFunc(authHandler.SessionsHandler))).Methods("GET")
+		r.Handle("/users/me/sessions/{id}", userMiddleware(http.HandlerFunc(authHandler.RevokeSessionHandler))).Methods("DELETE")
+		r.Handle("/users/me/api-keys", userMiddleware(http.HandlerFunc(authHandler.APIKeysHandler))).Methods("GET")
+		r.Handle("/users/me/api-keys", userMiddleware(http.HandlerFunc(authHandler.CreateAPIKeyHandler))).Methods("POST")
+		r.Handle("/users/me/api-keys/{id}", userMiddleware(http.HandlerFunc(authHandler.RevokeAPIKeyHandler))).Methods("DELETE")
+		r.Handle("/users/me/2fa/confirm", userMiddleware(http.HandlerFunc(authHandler.ConfirmTwoFactorHandler))).Methods("POST")
 		r.Handle("/notifications", userMiddleware(http.HandlerFunc(notificationHandler.CheckNotificationHandler))).Methods("GET")
-		r.Handle("/cryptos", userMiddleware(http.HandlerFunc(cryptoHandler.DisplayTopCryptos))).Methods("GET")
-		r.Handle("/cryptos/{cryptoname}", userMiddleware(http.HandlerFunc(cryptoHandler.DisplayCryptoByName))).Methods("GET")
-		r.Handle("/cryptos/alert", userMiddleware(http.HandlerFunc(cryptoHandler.SetPriceAlert))).Methods("POST")
-		r.Handle("/admin/profiles", adminMiddleware(http.HandlerFunc(adminHandler.Profiles))).Methods("GET")
-		//r.Handle("/admin/profiles/{username}", adminMiddleware(http.HandlerFunc(adminHandler.SpecificUserProfile))).Methods("GET")
-		r.Handle("/admin/delete/{username}", adminMiddleware(http.HandlerFunc(adminHandler.DeleteUser))).Methods("DELETE")
-		r.Handle("/admin/delegate/{username}", adminMiddleware(http.HandlerFunc(adminHandler.DelegateUser))).Methods("PATCH")
-		r.Handle("/admin/requests", adminMiddleware(http.HandlerFunc(adminHandler.UnavailableCryptoRequests))).Methods("GET")
-		r.Handle("/admin/requests/{username}", adminMiddleware(http.HandlerFunc(adminHandler.SpecificUserUnavailableCryptoRequests))).Methods("GET")
-		r.Handle("/admin/requests/{crypto}", adminMiddleware(http.HandlerFunc(adminHandler.ActOnUnavailableCryptoRequestsBySymbol))).Methods("PUT")
-
-		logger.Logger.Info("Server listening on port :5555")
+		r.Handle("/cryptos", marketDataKeys(userMiddleware(http.HandlerFunc(cryptoHandler.DisplayTopCryptos)))).Methods("GET")
+		r.Handle("/cryptos/{cryptoname}", marketDataKeys(userMiddleware(http.HandlerFunc(cryptoHandler.DisplayCryptoByName)))).Methods("GET")
+		r.Handle("/cryptos/alert", alertKeys(requirePermission(rbac.AlertsManage)(http.HandlerFunc(cryptoHandler.SetPriceAlert)))).Methods("POST")
+		r.Handle("/cryptos/alert/ladder", alertKeys(requirePermission(rbac.AlertsManage)(http.HandlerFunc(cryptoHandler.SetLadderAlert)))).Methods("POST")
+		r.Handle("/cryptos/alert/groups", alertKeys(userMiddleware(http.HandlerFunc(cryptoHandler.AlertGroups)))).Methods("GET")
+		r.Handle("/cryptos/alert/groups/{id}", alertKeys(requirePermission(rbac.AlertsManage)(http.HandlerFunc(cryptoHandler.UpdateAlertGroup)))).Methods("PATCH")
+		r.Handle("/cryptos/alert/groups/{id}", alertKeys(requirePermission(rbac.AlertsManage)(http.HandlerFunc(cryptoHandler.DeleteAlertGroup)))).Methods("DELETE")
+		r.Handle("/admin/profiles", requirePermission(rbac.UsersView)(http.HandlerFunc(adminHandler.Profiles))).Methods("GET")
+		//r.Handle("/admin/profiles/{username}", requirePermission(rbac.UsersView)(http.HandlerFunc(adminHandler.SpecificUserProfile))).Methods("GET")
+		r.Handle("/admin/delete/{username}", requirePermission(rbac.UsersDelete)(http.HandlerFunc(adminHandler.DeleteUser))).Methods("DELETE")
+		r.Handle("/admin/delegate/{username}", requirePermission(rbac.UsersChangeRole)(http.HandlerFunc(adminHandler.DelegateUser))).Methods("PATCH")
+		r.Handle("/admin/users/{username}/deactivate", requirePermission(rbac.UsersDeactivate)(http.HandlerFunc(adminHandler.DeactivateUser))).Methods("POST")
+		r.Handle("/admin/users/{username}/restore", requirePermission(rbac.UsersDeactivate)(http.HandlerFunc(adminHandler.RestoreUser))).Methods("POST")
+		r.Handle("/admin/users/{username}/sessions", requirePermission(rbac.SessionsRevokeAny)(http.HandlerFunc(adminHandler.ForceLogout))).Methods("DELETE")
+		r.Handle("/admin/users/{username}/role", requirePermission(rbac.UsersChangeRole)(http.HandlerFunc(adminHandler.ChangeRole))).Methods("PUT")
+		r.Handle("/admin/requests", requirePermission(rbac.RequestsView)(http.HandlerFunc(adminHandler.UnavailableCryptoRequests))).Methods("GET")
+		r.Handle("/admin/requests/{username}", requirePermission(rbac.RequestsView)(http.HandlerFunc(adminHandler.SpecificUserUnavailableCryptoRequests))).Methods("GET")
+		r.Handle("/admin/requests/{crypto}", requirePermission(rbac.RequestsApprove)(http.HandlerFunc(adminHandler.ActOnUnavailableCryptoRequestsBySymbol))).Methods("PUT")
+		r.Handle("/admin/2fa-policy", requirePermission(rbac.SecurityManage)(http.HandlerFunc(adminHandler.TwoFactorPolicy))).Methods("PUT")
+		r.Handle("/admin/audit", requirePermission(rbac.AuditView)(http.HandlerFunc(adminHandler.AuditLog))).Methods("GET")
+		r.Handle("/admin/audit/verify", requirePermission(rbac.AuditView)(http.HandlerFunc(adminHandler.VerifyAuditLog))).Methods("GET")
+		r.Handle("/admin/audit/export", requirePermission(rbac.AuditView)(http.HandlerFunc(adminHandler.ExportAuditLog))).Methods("GET")
+		r.Handle("/admin/deliveries", requirePermission(rbac.DeliveriesManage)(http.HandlerFunc(deliveryHandler.FailedDeliveries))).Methods("GET")
+		r.Handle("/admin/deliveries/{id}/requeue", requirePermission(rbac.DeliveriesManage)(http.HandlerFunc(deliveryHandler.RequeueDelivery))).Methods("POST")
+
+		r.NotFoundHandler = http.HandlerFunc(middleware.NotFound)
+		r.MethodNotAllowedHandler = http.HandlerFunc(middleware.MethodNotAllowed)
+
+		logger.Logger.Info("Server listening on port :5556")
 
 		http.Handle("/", r)
 		if err := http.ListenAndServe(":5556", nil); err != nil {
@@ -78,16 +128,71 @@ func main() {
 		}
 	}()
 
+	// Background jobs get their own connection, as a pgx.Conn cannot be shared between goroutines
+	workerConn := globals.NewPgConn()
+	defer workerConn.Close(context.Background())
+	go runBackgroundJobs(
+		services.NewStablecoinService(repositories.NewPostgresStablecoinRepository(workerConn)),
+		services.NewNotificationService(repositories.NewPostgresNotificationRepository(workerConn)),
+		services.NewDeliveryService(repositories.NewPostgresDeliveryRepository(workerConn)),
+		services.NewTokenService(repositories.NewPostgresTokenRepository(workerConn)),
+		services.NewAdminService(repositories.NewPostgresAdminRepository(workerConn)),
+	)
+
 	ui.DisplayWelcomeBanner()
 
-	ui_var := ui.NewUI(userService, adminService, cryptoService, notificationService)
+	ui_var := ui.NewUI(userService, adminService, cryptoService, notificationService, tokenService)
 
 	user, Role := ui_var.AuthenticateUser(conn)
 
-	if Role == "admin" {
-		ui.ShowAdminPanel(conn, adminService)
+	if rbac.Can(Role, rbac.AdminPanel) {
+		ui.ShowAdminPanel(conn, user, adminService, stablecoinService, notificationService, deliveryService, tokenService)
 		return
 	}
 
-	ui.MainMenu(conn, user, userService, cryptoService)
+	// The session watcher polls from its own goroutine, so it also gets its own connection
+	sessionConn := globals.NewPgConn()
+	defer sessionConn.Close(context.Background())
+	watcher := ui.NewNotificationWatcher(services.NewNotificationService(repositories.NewPostgresNotificationRepository(sessionConn)), user.Username)
+
+	ui.MainMenu(conn, user, userService, cryptoService, notificationService, stablecoinService, tokenService, watcher)
+}
+
+// runBackgroundJobs runs the scheduled work one job at a time on the worker connection: it
+// sends queued notifications and due digests every minute, samples stablecoin prices every
+// DepegCheckInterval, clears out expired REST tokens and purges deleted accounts whose grace
+// period has ended.
+func runBackgroundJobs(stablecoinService services.StablecoinService, notificationService services.NotificationService, deliveryService services.DeliveryService, tokenService services.TokenService, adminService services.AdminService) {
+	ticker := time.NewTicker(services.DeliveryCheckInterval)
+	defer ticker.Stop()
+
+	var lastPegCheck time.Time
+	for {
+		now := time.Now()
+
+		if now.Sub(lastPegCheck) >= services.DepegCheckInterval {
+			if err := stablecoinService.CheckPegs(now); err != nil {
+				logger.Logger.Error("Stablecoin peg check failed", err)
+			}
+			lastPegCheck = now
+		}
+
+		if err := notificationService.SendDueDigests(now); err != nil {
+			logger.Logger.Error("Sending digests failed", err)
+		}
+
+		if _, err := deliveryService.ProcessDueDeliveries(time.Now()); err != nil {
+			logger.Logger.Error("Processing notification deliveries failed", err)
+		}
+
+		if err := tokenService.PurgeExpiredTokens(now); err != nil {
+			logger.Logger.Error("Purging expired tokens failed", err)
+		}
+
+		if _, err := adminService.PurgeDeletedUsers(now); err != nil {
+			logger.Logger.Error("Purging deleted accounts failed", err)
+		}
+
+		<-ticker.C
+	}
 }
@@ -0,0 +1,58 @@
+package alerts
+
+import (
+	"cryptotracker/models"
+	"errors"
+	"math"
+)
+
+// Peg events reported by ObservePeg.
+const (
+	PegDepegged  = "depeg"
+	PegRecovered = "depeg_recovered"
+)
+
+// ValidatePeg checks an admin-supplied stablecoin peg configuration.
+func ValidatePeg(peg *models.StablecoinPeg) error {
+	if peg.Symbol == "" {
+		return errors.New("symbol is required")
+	}
+	if peg.PegPrice <= 0 {
+		return errors.New("peg price must be greater than 0")
+	}
+	if peg.ThresholdPercent <= 0 || peg.ThresholdPercent >= 100 {
+		return errors.New("threshold must be between 0 and 100 percent")
+	}
+	if peg.SustainedSamples < 1 {
+		return errors.New("sustained samples must be at least 1")
+	}
+	return nil
+}
+
+// PegDeviationPercent returns how far a price is from the peg, in percent of the peg.
+func PegDeviationPercent(peg *models.StablecoinPeg, price float64) float64 {
+	return math.Abs(price-peg.PegPrice) / peg.PegPrice * 100
+}
+
+// ObservePeg records one price sample on the peg's breach state. It returns PegDepegged when
+// the deviation has just lasted SustainedSamples samples in a row, PegRecovered when a de-pegged
+// coin returns within the threshold, and "" otherwise.
+func ObservePeg(peg *models.StablecoinPeg, price float64) string {
+	peg.LastPrice = price
+
+	if PegDeviationPercent(peg, price) <= peg.ThresholdPercent {
+		peg.BreachCount = 0
+		if peg.Depegged {
+			peg.Depegged = false
+			return PegRecovered
+		}
+		return ""
+	}
+
+	peg.BreachCount++
+	if !peg.Depegged && peg.BreachCount >= peg.SustainedSamples {
+		peg.Depegged = true
+		return PegDepegged
+	}
+	return ""
+}
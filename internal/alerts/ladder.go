@@ -0,0 +1,137 @@
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MaxLadderLevels caps how many child alerts a single ladder can create.
+const MaxLadderLevels = 25
+
+// LadderLevels expands a from-to range into levels spaced step apart, including both ends
+// when they fall on a step.
+func LadderLevels(from, to, step float64) ([]float64, error) {
+	if !isFinite(from) || !isFinite(to) || from <= 0 || to <= 0 {
+		return nil, errors.New("ladder prices must be positive numbers")
+	}
+	if !isFinite(step) || step <= 0 {
+		return nil, errors.New("ladder step must be a positive number")
+	}
+	if from > to {
+		from, to = to, from
+	}
+
+	// Count in float64 first: a tiny step over a wide range is too many levels for an int
+	spacings := math.Floor((to-from)/step + 1e-9)
+	if math.IsInf(spacings, 0) || spacings+1 > MaxLadderLevels {
+		return nil, fmt.Errorf("ladder would create more than %d levels", MaxLadderLevels)
+	}
+	count := int(spacings) + 1
+
+	levels := make([]float64, 0, count)
+	for i := 0; i < count; i++ {
+		levels = append(levels, roundLevel(from+float64(i)*step))
+	}
+	return levels, nil
+}
+
+// ParseLevels reads an explicit list of levels separated by commas or spaces.
+func ParseLevels(list string) ([]float64, error) {
+	fields := strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == ' ' })
+	if len(fields) == 0 {
+		return nil, errors.New("no ladder levels given")
+	}
+
+	levels := make([]float64, 0, len(fields))
+	for _, field := range fields {
+		level, err := strconv.ParseFloat(field, 64)
+		if err != nil || !isFinite(level) || level <= 0 {
+			return nil, fmt.Errorf("invalid ladder level %q", field)
+		}
+		levels = append(levels, level)
+	}
+
+	return NormalizeLevels(levels)
+}
+
+// NormalizeLevels checks an explicit list of levels, dropping duplicates and sorting it.
+func NormalizeLevels(levels []float64) ([]float64, error) {
+	if len(levels) == 0 {
+		return nil, errors.New("no ladder levels given")
+	}
+
+	seen := map[float64]bool{}
+	var normalized []float64
+	for _, level := range levels {
+		if !isFinite(level) || level <= 0 {
+			return nil, fmt.Errorf("invalid ladder level %v", level)
+		}
+		level = roundLevel(level)
+		if !seen[level] {
+			seen[level] = true
+			normalized = append(normalized, level)
+		}
+	}
+
+	if len(normalized) > MaxLadderLevels {
+		return nil, fmt.Errorf("ladder has %d levels; the maximum is %d", len(normalized), MaxLadderLevels)
+	}
+
+	sort.Float64s(normalized)
+	return normalized, nil
+}
+
+// LevelDirection returns the direction a ladder level fires in relative to the current price.
+func LevelDirection(level, currentPrice float64) string {
+	if level < currentPrice {
+		return "below"
+	}
+	return "above"
+}
+
+// roundLevel rounds to 8 decimal places. Levels too large to scale have no fraction to round.
+func roundLevel(level float64) float64 {
+	scaled := level * 1e8
+	if math.IsInf(scaled, 0) {
+		return level
+	}
+	return math.Round(scaled) / 1e8
+}
+
+func isFinite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
+
+// ParseLadderSpec reads either a range written as FROM-TO/STEP (for example 60000-70000/2500)
+// or an explicit list of levels.
+func ParseLadderSpec(spec string) ([]float64, error) {
+	spec = strings.TrimSpace(spec)
+	bounds, stepText, isRange := strings.Cut(spec, "/")
+	if !isRange {
+		return ParseLevels(spec)
+	}
+
+	fromText, toText, found := strings.Cut(bounds, "-")
+	if !found {
+		return nil, fmt.Errorf("invalid ladder range %q: use FROM-TO/STEP", spec)
+	}
+
+	from, err := strconv.ParseFloat(strings.TrimSpace(fromText), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ladder start %q", fromText)
+	}
+	to, err := strconv.ParseFloat(strings.TrimSpace(toText), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ladder end %q", toText)
+	}
+	step, err := strconv.ParseFloat(strings.TrimSpace(stepText), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ladder step %q", stepText)
+	}
+
+	return LadderLevels(from, to, step)
+}
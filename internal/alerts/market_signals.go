@@ -0,0 +1,87 @@
+package alerts
+
+import (
+	"cryptotracker/models"
+	"errors"
+	"time"
+)
+
+// AverageWindow is how far back stored snapshots are averaged.
+const AverageWindow = 7 * 24 * time.Hour
+
+// MinAverageSamples is the number of stored snapshots needed before an average is trusted.
+const MinAverageSamples = 3
+
+// ValidateVolumeMultiplier checks the multiple of trailing volume a spike alert waits for.
+func ValidateVolumeMultiplier(multiplier float64) error {
+	if multiplier <= 1 {
+		return errors.New("volume multiplier must be greater than 1")
+	}
+	return nil
+}
+
+// ValidateRankThreshold checks the market-cap rank a rank alert watches.
+func ValidateRankThreshold(threshold int) error {
+	if threshold < 1 || threshold > 5000 {
+		return errors.New("rank threshold must be between 1 and 5000")
+	}
+	return nil
+}
+
+// RankDirection returns "above" when a coin still has to climb into the top threshold, and
+// "below" when it is already inside and the alert should fire once it drops out.
+func RankDirection(threshold, currentRank int) string {
+	if currentRank > threshold {
+		return "above"
+	}
+	return "below"
+}
+
+// RankConditionHolds reports whether the rank is on the side of the threshold the alert waits for.
+func RankConditionHolds(notification *models.PriceNotification, rank int) bool {
+	if rank <= 0 {
+		return false
+	}
+	if notification.Direction == "below" {
+		return rank > notification.RankThreshold
+	}
+	return rank <= notification.RankThreshold
+}
+
+// ShouldTriggerRank reports whether an armed rank alert fires.
+func ShouldTriggerRank(notification *models.PriceNotification, rank int) bool {
+	return notification.Status == "Pending" && RankConditionHolds(notification, rank)
+}
+
+// VolumeSpikeHolds reports whether 24h volume exceeds the alert's multiple of its trailing average.
+func VolumeSpikeHolds(notification *models.PriceNotification, volume, average float64) bool {
+	return average > 0 && volume > notification.VolumeMultiplier*average
+}
+
+// ShouldTriggerVolumeSpike reports whether an armed volume spike alert fires.
+func ShouldTriggerVolumeSpike(notification *models.PriceNotification, volume, average float64) bool {
+	return notification.Status == "Pending" && VolumeSpikeHolds(notification, volume, average)
+}
+
+// AveragesFrom adapts stored snapshot averages for use as a Market's source of averages.
+// Symbols without enough samples report no average.
+func AveragesFrom(averages map[string]models.MarketAverage) func(symbol, metric string) (float64, bool) {
+	return func(symbol, metric string) (float64, bool) {
+		average, ok := averages[symbol]
+		if !ok || average.Samples < MinAverageSamples {
+			return 0, false
+		}
+
+		switch metric {
+		case "price":
+			return average.Price, true
+		case "24h_change":
+			return average.PercentChange24h, true
+		case "volume_24h":
+			return average.Volume24h, true
+		case "market_cap":
+			return average.MarketCap, true
+		}
+		return 0, false
+	}
+}
@@ -0,0 +1,41 @@
+package alerts
+
+import (
+	"cryptotracker/models"
+	"errors"
+	"time"
+)
+
+// ValidateOptions checks the optional settings supplied when an alert is created.
+func ValidateOptions(options *models.AlertOptions) error {
+	if options == nil {
+		return nil
+	}
+	if options.CooldownSeconds < 0 {
+		return errors.New("cooldown cannot be negative")
+	}
+	if options.HysteresisPercent < 0 || options.HysteresisPercent >= 100 {
+		return errors.New("hysteresis must be between 0 and 100 percent")
+	}
+	if !options.Recurring && (options.CooldownSeconds > 0 || options.HysteresisPercent > 0) {
+		return errors.New("cooldown and hysteresis only apply to recurring alerts")
+	}
+	if options.ExpiresAt != nil && !options.ExpiresAt.After(time.Now()) {
+		return errors.New("expiry must be in the future")
+	}
+	if (options.ActiveFrom == "") != (options.ActiveTo == "") {
+		return errors.New("active hours need both a start and an end")
+	}
+	if options.ActiveFrom != "" {
+		if _, err := ParseClock(options.ActiveFrom); err != nil {
+			return err
+		}
+		if _, err := ParseClock(options.ActiveTo); err != nil {
+			return err
+		}
+		if options.ActiveFrom == options.ActiveTo {
+			return errors.New("active hours cannot start and end at the same time")
+		}
+	}
+	return nil
+}
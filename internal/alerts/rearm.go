@@ -0,0 +1,88 @@
+package alerts
+
+import (
+	"cryptotracker/models"
+	"time"
+)
+
+// ShouldTrigger reports whether an armed alert fires at the given price. Alerts fire when the
+// price rises to the target, or falls to it for alerts whose direction is "below".
+func ShouldTrigger(notification *models.PriceNotification, currentPrice float64) bool {
+	if notification.Status != "Pending" {
+		return false
+	}
+	if notification.Direction == "below" {
+		return currentPrice <= notification.TargetPrice
+	}
+	return currentPrice >= notification.TargetPrice
+}
+
+// ShouldRearm reports whether a recurring alert that has already fired is ready to fire again.
+// A cooldown, when set, must have elapsed since the last trigger. A hysteresis band, when set,
+// requires the price to move back that many percent past the target. With neither set, the
+// alert re-arms as soon as the price is back on the far side of the target.
+func ShouldRearm(notification *models.PriceNotification, currentPrice float64, now time.Time) bool {
+	if !notification.Recurring || notification.Status != "Rearming" {
+		return false
+	}
+
+	if !cooldownElapsed(notification, now) {
+		return false
+	}
+
+	below := notification.Direction == "below"
+
+	if notification.HysteresisPercent > 0 {
+		band := notification.HysteresisPercent / 100
+		if below {
+			return currentPrice >= notification.TargetPrice*(1+band)
+		}
+		return currentPrice <= notification.TargetPrice*(1-band)
+	}
+
+	if notification.CooldownSeconds > 0 {
+		return true
+	}
+
+	if below {
+		return currentPrice > notification.TargetPrice
+	}
+	return currentPrice < notification.TargetPrice
+}
+
+// ShouldTriggerRule reports whether an armed rule alert fires against the market.
+func ShouldTriggerRule(notification *models.PriceNotification, rule Rule, market Market) bool {
+	return notification.Status == "Pending" && rule.Eval(market)
+}
+
+// ShouldRearmRule reports whether a recurring rule alert is ready to fire again.
+func ShouldRearmRule(notification *models.PriceNotification, rule Rule, market Market, now time.Time) bool {
+	return ShouldRearmCondition(notification, rule.Eval(market), now)
+}
+
+// ShouldRearmCondition reports whether a recurring alert on a condition other than a single
+// target price is ready to fire again. Once any cooldown has elapsed it re-arms; without a
+// cooldown it waits until the condition stops holding.
+func ShouldRearmCondition(notification *models.PriceNotification, holds bool, now time.Time) bool {
+	if !notification.Recurring || notification.Status != "Rearming" {
+		return false
+	}
+
+	if !cooldownElapsed(notification, now) {
+		return false
+	}
+
+	if notification.CooldownSeconds > 0 {
+		return true
+	}
+
+	return !holds
+}
+
+func cooldownElapsed(notification *models.PriceNotification, now time.Time) bool {
+	if notification.CooldownSeconds <= 0 || notification.LastTriggeredAt == nil {
+		return true
+	}
+	cooldown := time.Duration(notification.CooldownSeconds) * time.Second
+	return now.Sub(*notification.LastTriggeredAt) >= cooldown
+}
@@ -0,0 +1,433 @@
+package alerts
+
+import (
+	"cryptotracker/models"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Metrics that a rule condition can compare. Aliases are normalised by ParseRule.
+var ruleMetrics = map[string]string{
+	"price":      "price",
+	"24h_change": "24h_change",
+	"change_24h": "24h_change",
+	"volume_24h": "volume_24h",
+	"volume":     "volume_24h",
+	"market_cap": "market_cap",
+	"mcap":       "market_cap",
+}
+
+// Market supplies the data a rule is evaluated against.
+type Market interface {
+	Quote(symbol string) (*models.Quote, bool)
+	// Average returns the trailing average of a metric for a symbol, when history is available.
+	Average(symbol, metric string) (float64, bool)
+}
+
+// QuoteMarket is a Market backed by one batch of quotes and an optional source of averages.
+type QuoteMarket struct {
+	Quotes   map[string]*models.Quote
+	Averages func(symbol, metric string) (float64, bool)
+}
+
+// NewQuoteMarket wraps a batch of quotes keyed by upper-case symbol.
+func NewQuoteMarket(quotes map[string]*models.Quote) *QuoteMarket {
+	return &QuoteMarket{Quotes: quotes}
+}
+
+func (m *QuoteMarket) Quote(symbol string) (*models.Quote, bool) {
+	quote, ok := m.Quotes[strings.ToUpper(symbol)]
+	return quote, ok
+}
+
+func (m *QuoteMarket) Average(symbol, metric string) (float64, bool) {
+	if m.Averages == nil {
+		return 0, false
+	}
+	return m.Averages(strings.ToUpper(symbol), metric)
+}
+
+// Rule is a parsed alert condition.
+type Rule interface {
+	// Eval reports whether the rule holds. Conditions on assets the market has no data for are false.
+	Eval(market Market) bool
+	// Symbols lists every asset the rule refers to.
+	Symbols() []string
+	String() string
+}
+
+type logicalRule struct {
+	op          string // AND, OR
+	left, right Rule
+}
+
+func (r *logicalRule) Eval(market Market) bool {
+	if r.op == "AND" {
+		return r.left.Eval(market) && r.right.Eval(market)
+	}
+	return r.left.Eval(market) || r.right.Eval(market)
+}
+
+func (r *logicalRule) Symbols() []string {
+	seen := map[string]bool{}
+	var symbols []string
+	for _, symbol := range append(r.left.Symbols(), r.right.Symbols()...) {
+		if !seen[symbol] {
+			seen[symbol] = true
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols
+}
+
+func (r *logicalRule) String() string {
+	return fmt.Sprintf("(%s %s %s)", r.left, r.op, r.right)
+}
+
+type conditionRule struct {
+	base       string
+	quote      string // set for ratios such as ETH/BTC
+	metric     string
+	op         string
+	value      float64
+	multiplier float64 // set for "2x avg" comparisons
+}
+
+func (c *conditionRule) Eval(market Market) bool {
+	left, ok := metricValue(market, c.base, c.metric)
+	if !ok {
+		return false
+	}
+
+	if c.quote != "" {
+		denominator, ok := metricValue(market, c.quote, c.metric)
+		if !ok || denominator == 0 {
+			return false
+		}
+		left /= denominator
+	}
+
+	right := c.value
+	if c.multiplier > 0 {
+		avg, ok := market.Average(c.base, c.metric)
+		if !ok {
+			return false
+		}
+		right = c.multiplier * avg
+	}
+
+	switch c.op {
+	case "<":
+		return left < right
+	case "<=":
+		return left <= right
+	case ">":
+		return left > right
+	case ">=":
+		return left >= right
+	}
+	return false
+}
+
+func (c *conditionRule) Symbols() []string {
+	if c.quote != "" {
+		return []string{c.base, c.quote}
+	}
+	return []string{c.base}
+}
+
+func (c *conditionRule) String() string {
+	operand := c.base
+	if c.quote != "" {
+		operand += "/" + c.quote
+	}
+	if c.metric != "price" {
+		operand += " " + c.metric
+	}
+
+	value := strconv.FormatFloat(c.value, 'f', -1, 64)
+	if c.multiplier > 0 {
+		value = strconv.FormatFloat(c.multiplier, 'f', -1, 64) + "x avg"
+	}
+
+	return fmt.Sprintf("%s %s %s", operand, c.op, value)
+}
+
+func metricValue(market Market, symbol, metric string) (float64, bool) {
+	quote, ok := market.Quote(symbol)
+	if !ok {
+		return 0, false
+	}
+
+	switch metric {
+	case "price":
+		return quote.Price, true
+	case "24h_change":
+		return quote.PercentChange24h, true
+	case "volume_24h":
+		return quote.Volume24h, true
+	case "market_cap":
+		return quote.MarketCap, true
+	}
+	return 0, false
+}
+
+type ruleToken struct {
+	text string
+	pos  int
+}
+
+// ruleParser is a recursive-descent parser for:
+//
+//	rule      = and { "OR" and }
+//	and       = term { "AND" term }
+//	term      = "(" rule ")" | condition
+//	condition = SYMBOL [ "/" SYMBOL ] [ metric ] ( "<" | "<=" | ">" | ">=" ) value
+//	value     = NUMBER | NUMBER "x" "avg"     (NUMBER may carry a sign, as in -5)
+type ruleParser struct {
+	tokens []ruleToken
+	pos    int
+}
+
+// ParseRule parses an alert expression such as "BTC < 60000 AND ETH/BTC > 0.06".
+func ParseRule(input string) (Rule, error) {
+	tokens, err := tokenizeRule(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("rule is empty")
+	}
+
+	p := &ruleParser{tokens: tokens}
+	rule, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok, ok := p.peek(); ok {
+		return nil, fmt.Errorf("position %d: unexpected %q", tok.pos+1, tok.text)
+	}
+	return rule, nil
+}
+
+func tokenizeRule(input string) ([]ruleToken, error) {
+	var tokens []ruleToken
+	for i := 0; i < len(input); {
+		ch := rune(input[i])
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+		case strings.ContainsRune("()/", ch):
+			tokens = append(tokens, ruleToken{string(ch), i})
+			i++
+		case ch == '<' || ch == '>':
+			if i+1 < len(input) && input[i+1] == '=' {
+				tokens = append(tokens, ruleToken{input[i : i+2], i})
+				i += 2
+			} else {
+				tokens = append(tokens, ruleToken{string(ch), i})
+				i++
+			}
+		case unicode.IsLetter(ch) || unicode.IsDigit(ch) || ch == '_' || ch == '.' || isNumberSign(input, i):
+			start := i
+			if ch == '-' || ch == '+' {
+				i++
+			}
+			for i < len(input) && (unicode.IsLetter(rune(input[i])) || unicode.IsDigit(rune(input[i])) || input[i] == '_' || input[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, ruleToken{input[start:i], start})
+		default:
+			return nil, fmt.Errorf("position %d: unexpected character %q", i+1, ch)
+		}
+	}
+	return tokens, nil
+}
+
+// isNumberSign reports whether the character at i is a sign starting a number, such as the
+// minus in "BTC 24h_change < -5".
+func isNumberSign(input string, i int) bool {
+	if (input[i] != '-' && input[i] != '+') || i+1 >= len(input) {
+		return false
+	}
+	return unicode.IsDigit(rune(input[i+1])) || input[i+1] == '.'
+}
+
+func (p *ruleParser) peek() (ruleToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return ruleToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *ruleParser) next() (ruleToken, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return ruleToken{}, fmt.Errorf("unexpected end of rule")
+	}
+	p.pos++
+	return tok, nil
+}
+
+func (p *ruleParser) acceptKeyword(keyword string) bool {
+	if tok, ok := p.peek(); ok && strings.EqualFold(tok.text, keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *ruleParser) parseOr() (Rule, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalRule{op: "OR", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *ruleParser) parseAnd() (Rule, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("AND") {
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalRule{op: "AND", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *ruleParser) parseTerm() (Rule, error) {
+	if p.acceptKeyword("(") {
+		rule, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.acceptKeyword(")") {
+			return nil, p.errorf("expected \")\"")
+		}
+		return rule, nil
+	}
+	return p.parseCondition()
+}
+
+func (p *ruleParser) parseCondition() (Rule, error) {
+	tok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if !isRuleSymbol(tok.text) {
+		return nil, fmt.Errorf("position %d: expected an asset symbol, found %q", tok.pos+1, tok.text)
+	}
+	cond := &conditionRule{base: strings.ToUpper(tok.text), metric: "price"}
+
+	if p.acceptKeyword("/") {
+		tok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if !isRuleSymbol(tok.text) {
+			return nil, fmt.Errorf("position %d: expected an asset symbol after \"/\", found %q", tok.pos+1, tok.text)
+		}
+		cond.quote = strings.ToUpper(tok.text)
+	}
+
+	if tok, ok := p.peek(); ok {
+		if metric, known := ruleMetrics[strings.ToLower(tok.text)]; known {
+			cond.metric = metric
+			p.pos++
+		}
+	}
+
+	tok, err = p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch tok.text {
+	case "<", "<=", ">", ">=":
+		cond.op = tok.text
+	default:
+		return nil, fmt.Errorf("position %d: expected a comparison operator (<, <=, >, >=), found %q", tok.pos+1, tok.text)
+	}
+
+	tok, err = p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	if multiplier, ok := parseMultiplier(tok.text); ok {
+		cond.multiplier = multiplier
+	} else {
+		value, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil, fmt.Errorf("position %d: expected a number, found %q", tok.pos+1, tok.text)
+		}
+		cond.value = value
+		if p.acceptKeyword("x") {
+			if value <= 0 {
+				return nil, fmt.Errorf("position %d: the multiplier must be positive", tok.pos+1)
+			}
+			cond.multiplier = value
+			cond.value = 0
+		}
+	}
+
+	if cond.multiplier > 0 {
+		if !p.acceptKeyword("avg") {
+			return nil, p.errorf("expected \"avg\" after multiplier")
+		}
+		if cond.quote != "" {
+			return nil, fmt.Errorf("position %d: averages cannot be used with ratios", tok.pos+1)
+		}
+	}
+
+	return cond, nil
+}
+
+func (p *ruleParser) errorf(message string) error {
+	if tok, ok := p.peek(); ok {
+		return fmt.Errorf("position %d: %s, found %q", tok.pos+1, message, tok.text)
+	}
+	return fmt.Errorf("%s at end of rule", message)
+}
+
+// parseMultiplier recognises the "2x" shorthand.
+func parseMultiplier(text string) (float64, bool) {
+	lower := strings.ToLower(text)
+	if !strings.HasSuffix(lower, "x") {
+		return 0, false
+	}
+	multiplier, err := strconv.ParseFloat(strings.TrimSuffix(lower, "x"), 64)
+	if err != nil || multiplier <= 0 {
+		return 0, false
+	}
+	return multiplier, true
+}
+
+func isRuleSymbol(text string) bool {
+	if strings.EqualFold(text, "AND") || strings.EqualFold(text, "OR") {
+		return false
+	}
+	if _, err := strconv.ParseFloat(text, 64); err == nil {
+		return false
+	}
+	for _, ch := range text {
+		if !unicode.IsLetter(ch) && !unicode.IsDigit(ch) {
+			return false
+		}
+	}
+	return text != ""
+}
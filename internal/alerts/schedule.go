@@ -0,0 +1,53 @@
+package alerts
+
+import (
+	"fmt"
+	"time"
+)
+
+// ParseClock converts an "HH:MM" time of day into minutes after midnight.
+func ParseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q: use HH:MM", clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// LoadLocation resolves a user's timezone, falling back to UTC when it is unset or unknown.
+func LoadLocation(timezone string) *time.Location {
+	if timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// InActiveWindow reports whether now falls inside the from-to window in the given location.
+// An empty window is always active, and a window whose end is before its start wraps past
+// midnight (for example 22:00-06:00).
+func InActiveWindow(from, to string, loc *time.Location, now time.Time) bool {
+	if from == "" || to == "" {
+		return true
+	}
+
+	start, err := ParseClock(from)
+	if err != nil {
+		return true
+	}
+	end, err := ParseClock(to)
+	if err != nil {
+		return true
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
@@ -0,0 +1,47 @@
+package alerts
+
+import (
+	"cryptotracker/models"
+	"errors"
+	"time"
+)
+
+// ValidateTrailPercent checks the drop, in percent, that a trailing alert waits for.
+func ValidateTrailPercent(trailPercent float64) error {
+	if trailPercent <= 0 || trailPercent >= 100 {
+		return errors.New("trail must be between 0 and 100 percent")
+	}
+	return nil
+}
+
+// UpdateHighWaterMark raises the alert's high-water mark to the current price when it is higher,
+// and reports whether it changed.
+func UpdateHighWaterMark(notification *models.PriceNotification, currentPrice float64) bool {
+	if currentPrice <= notification.HighWaterMark {
+		return false
+	}
+	notification.HighWaterMark = currentPrice
+	return true
+}
+
+// TrailingStopPrice returns the price at which a trailing alert fires.
+func TrailingStopPrice(notification *models.PriceNotification) float64 {
+	return notification.HighWaterMark * (1 - notification.TrailPercent/100)
+}
+
+// ShouldTriggerTrailing reports whether an armed trailing alert fires at the given price.
+func ShouldTriggerTrailing(notification *models.PriceNotification, currentPrice float64) bool {
+	if notification.Status != "Pending" || notification.HighWaterMark <= 0 {
+		return false
+	}
+	return currentPrice <= TrailingStopPrice(notification)
+}
+
+// ShouldRearmTrailing reports whether a recurring trailing alert can start tracking again.
+// Its high-water mark restarts from the price at which it re-arms.
+func ShouldRearmTrailing(notification *models.PriceNotification, now time.Time) bool {
+	if !notification.Recurring || notification.Status != "Rearming" {
+		return false
+	}
+	return cooldownElapsed(notification, now)
+}
@@ -0,0 +1,85 @@
+package digest
+
+import (
+	"cryptotracker/internal/alerts"
+	"cryptotracker/models"
+	"errors"
+	"math"
+	"time"
+)
+
+// NearPercent is how close, in percent of the target, an armed price alert must be to show up
+// as "close to firing".
+const NearPercent = 5.0
+
+// Validate checks a user's digest settings.
+func Validate(settings *models.DigestSettings) error {
+	switch settings.Frequency {
+	case "off", "daily", "weekly":
+	default:
+		return errors.New("frequency must be off, daily or weekly")
+	}
+
+	if _, err := alerts.ParseClock(settings.SendAt); err != nil {
+		return err
+	}
+
+	if settings.Weekday < 0 || settings.Weekday > 6 {
+		return errors.New("weekday must be between 0 (Sunday) and 6 (Saturday)")
+	}
+
+	return nil
+}
+
+// Period returns the length of time a digest covers.
+func Period(frequency string) time.Duration {
+	if frequency == "weekly" {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// LastScheduled returns the most recent scheduled send time at or before now, in the given location.
+func LastScheduled(settings *models.DigestSettings, loc *time.Location, now time.Time) time.Time {
+	minutes, _ := alerts.ParseClock(settings.SendAt)
+	local := now.In(loc)
+	scheduled := time.Date(local.Year(), local.Month(), local.Day(), minutes/60, minutes%60, 0, 0, loc)
+
+	if settings.Frequency == "weekly" {
+		back := (int(local.Weekday()) - settings.Weekday + 7) % 7
+		scheduled = scheduled.AddDate(0, 0, -back)
+		if scheduled.After(local) {
+			scheduled = scheduled.AddDate(0, 0, -7)
+		}
+		return scheduled
+	}
+
+	if scheduled.After(local) {
+		scheduled = scheduled.AddDate(0, 0, -1)
+	}
+	return scheduled
+}
+
+// Due reports whether a digest should be sent now: its latest scheduled time has passed and
+// nothing has been sent since.
+func Due(settings *models.DigestSettings, loc *time.Location, now time.Time) bool {
+	if settings.Frequency != "daily" && settings.Frequency != "weekly" {
+		return false
+	}
+	scheduled := LastScheduled(settings, loc, now)
+	return settings.LastSentAt == nil || settings.LastSentAt.Before(scheduled)
+}
+
+// NearTarget reports how far an armed price alert is from its target, and whether that is
+// within NearPercent.
+func NearTarget(notification *models.PriceNotification, price float64) (float64, bool) {
+	if notification.Status != "Pending" || notification.TargetPrice <= 0 {
+		return 0, false
+	}
+	if notification.Kind != "" && notification.Kind != "price" {
+		return 0, false
+	}
+
+	distance := math.Abs(notification.TargetPrice-price) / notification.TargetPrice * 100
+	return distance, distance <= NearPercent
+}
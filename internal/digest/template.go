@@ -0,0 +1,98 @@
+package digest
+
+import (
+	"cryptotracker/models"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Template is the layout every digest is rendered with.
+const Template = `{{title .Frequency}} digest for {{.Username}}
+{{when .PeriodStart}} to {{when .PeriodEnd}}
+
+Watched coins
+{{- range .Coins}}
+  {{printf "%-8s" .Symbol}} {{money .Price}}  {{change .PercentChange24h}}
+{{- else}}
+  No watched coins. Set a watchlist or create an alert.
+{{- end}}
+
+Alerts fired
+{{- range .Fired}}
+  {{alert .}} on {{when .TriggeredAt}}
+{{- else}}
+  None.
+{{- end}}
+
+Close to firing
+{{- range .NearlyFired}}
+  {{.Crypto}} target {{money .TargetPrice}}, now {{money .CurrentPrice}} ({{printf "%.1f" .DistancePercent}}% away)
+{{- else}}
+  None.
+{{- end}}
+
+Cryptocurrency requests
+{{- range .Requests}}
+  {{upper .CryptoSymbol}}: {{.Status}}
+{{- else}}
+  None.
+{{- end}}
+`
+
+// Render produces the text of a digest.
+func Render(d *models.Digest) (string, error) {
+	loc := d.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	funcs := template.FuncMap{
+		"title": func(s string) string {
+			if s == "" {
+				return s
+			}
+			return strings.ToUpper(s[:1]) + s[1:]
+		},
+		"upper": strings.ToUpper,
+		"when": func(t time.Time) string {
+			return t.In(loc).Format("Jan 2 15:04 MST")
+		},
+		"money": func(v float64) string {
+			if v < 1 {
+				return fmt.Sprintf("$%.4f", v)
+			}
+			return fmt.Sprintf("$%.2f", v)
+		},
+		"change": func(v float64) string {
+			return fmt.Sprintf("%+.2f%%", v)
+		},
+		"alert": describeOccurrence,
+	}
+
+	tmpl, err := template.New("digest").Funcs(funcs).Parse(Template)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse digest template: %v", err)
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, d); err != nil {
+		return "", fmt.Errorf("failed to render digest: %v", err)
+	}
+	return out.String(), nil
+}
+
+func describeOccurrence(occurrence models.AlertOccurrence) string {
+	switch {
+	case occurrence.Rule != "":
+		return occurrence.Rule
+	case occurrence.Kind == "volume_spike":
+		return fmt.Sprintf("%s volume spike ($%.0f)", occurrence.Crypto, occurrence.TriggeredPrice)
+	case occurrence.Kind == "rank":
+		return fmt.Sprintf("%s reached rank #%.0f", occurrence.Crypto, occurrence.TriggeredPrice)
+	case strings.HasPrefix(occurrence.Kind, "depeg"):
+		return fmt.Sprintf("%s peg at $%.4f", occurrence.Crypto, occurrence.TriggeredPrice)
+	}
+	return fmt.Sprintf("%s target $%.2f hit at $%.2f", occurrence.Crypto, occurrence.TargetPrice, occurrence.TriggeredPrice)
+}
@@ -0,0 +1,126 @@
+// Package lockout decides when failed logins slow down or lock out further attempts.
+package lockout
+
+import (
+	"cryptotracker/models"
+	"cryptotracker/pkg/config"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultMaxFailures      = 5
+	DefaultMaxFailuresPerIP = 20
+	DefaultLockout          = 15 * time.Minute
+	DefaultFailureWindow    = 15 * time.Minute
+	DefaultBaseDelay        = time.Second
+	DefaultMaxDelay         = 30 * time.Second
+)
+
+// Policy holds the login throttling thresholds.
+type Policy struct {
+	MaxFailures      int
+	MaxFailuresPerIP int
+	Lockout          time.Duration
+	FailureWindow    time.Duration
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+}
+
+// PolicyFromConfig builds a policy from the configuration, using the defaults for any
+// setting left at zero.
+func PolicyFromConfig(cfg config.LoginThrottling) Policy {
+	policy := Policy{
+		MaxFailures:      DefaultMaxFailures,
+		MaxFailuresPerIP: DefaultMaxFailuresPerIP,
+		Lockout:          DefaultLockout,
+		FailureWindow:    DefaultFailureWindow,
+		BaseDelay:        DefaultBaseDelay,
+		MaxDelay:         DefaultMaxDelay,
+	}
+	if cfg.MaxFailures > 0 {
+		policy.MaxFailures = cfg.MaxFailures
+	}
+	if cfg.MaxFailuresPerIP > 0 {
+		policy.MaxFailuresPerIP = cfg.MaxFailuresPerIP
+	}
+	if cfg.LockoutMinutes > 0 {
+		policy.Lockout = time.Duration(cfg.LockoutMinutes) * time.Minute
+	}
+	if cfg.FailureWindowMinutes > 0 {
+		policy.FailureWindow = time.Duration(cfg.FailureWindowMinutes) * time.Minute
+	}
+	if cfg.BaseDelayMillis > 0 {
+		policy.BaseDelay = time.Duration(cfg.BaseDelayMillis) * time.Millisecond
+	}
+	if cfg.MaxDelaySeconds > 0 {
+		policy.MaxDelay = time.Duration(cfg.MaxDelaySeconds) * time.Second
+	}
+	return policy
+}
+
+// UserKey is the attempt key for a username. Usernames are matched case-insensitively so
+// changing case does not reset the count.
+func UserKey(username string) string {
+	return "user:" + strings.ToLower(username)
+}
+
+// IPKey is the attempt key for a client address.
+func IPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// IsUserKey reports whether a key counts failures for a username.
+func IsUserKey(key string) bool {
+	return strings.HasPrefix(key, "user:")
+}
+
+// IsIPKey reports whether a key counts failures for a client address.
+func IsIPKey(key string) bool {
+	return strings.HasPrefix(key, "ip:")
+}
+
+// Username returns the username a user key is for.
+func Username(key string) string {
+	return strings.TrimPrefix(key, "user:")
+}
+
+// Limit is how many failures the key may have before it is locked.
+func (p Policy) Limit(key string) int {
+	if IsUserKey(key) {
+		return p.MaxFailures
+	}
+	return p.MaxFailuresPerIP
+}
+
+// Delay is the wait required after the given number of consecutive failures: BaseDelay after
+// the first, doubling with each one, up to MaxDelay.
+func (p Policy) Delay(failures int) time.Duration {
+	if failures < 1 {
+		return 0
+	}
+	wait := p.BaseDelay
+	for i := 1; i < failures; i++ {
+		wait *= 2
+		if wait >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	return wait
+}
+
+// Wait returns how long the key must wait before its next login attempt, and whether that is
+// because it is locked rather than merely slowed down. Failures outside the window are
+// forgotten.
+func (p Policy) Wait(attempt *models.LoginAttempt, now time.Time) (time.Duration, bool) {
+	if attempt.LockedUntil != nil && now.Before(*attempt.LockedUntil) {
+		return attempt.LockedUntil.Sub(now), true
+	}
+	if now.Sub(attempt.LastFailureAt) > p.FailureWindow {
+		return 0, false
+	}
+	if ready := attempt.LastFailureAt.Add(p.Delay(attempt.Failures)); now.Before(ready) {
+		return ready.Sub(now), false
+	}
+	return 0, false
+}
@@ -0,0 +1,169 @@
+package notify
+
+import (
+	"bytes"
+	"cryptotracker/pkg/config"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// Recipient is where a user can be reached.
+type Recipient struct {
+	Username   string
+	Email      string
+	Mobile     string // E.164, e.g. +919876543210
+	WebhookURL string
+}
+
+// Message is one notification.
+type Message struct {
+	Event   string `json:"event"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Notifier sends messages on one outbound channel.
+type Notifier interface {
+	Channel() string
+	Send(recipient Recipient, message Message) error
+}
+
+// Mailer sends an email straight away, outside the notification queue. It is used for account
+// mail such as password resets, which must not wait for quiet hours or count towards caps.
+type Mailer interface {
+	SendMail(to, subject, body string) error
+}
+
+// EmailNotifier sends mail through an SMTP relay.
+type EmailNotifier struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+func (n *EmailNotifier) Channel() string { return ChannelEmail }
+
+func (n *EmailNotifier) Send(recipient Recipient, message Message) error {
+	if recipient.Email == "" {
+		return fmt.Errorf("no email address on file")
+	}
+	return n.SendMail(recipient.Email, message.Subject, message.Body)
+}
+
+func (n *EmailNotifier) SendMail(to, subject, body string) error {
+	var auth smtp.Auth
+	if n.Username != "" {
+		auth = smtp.PlainAuth("", n.Username, n.Password, n.Host)
+	}
+
+	mail := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n", n.From, to, subject, body)
+	if err := smtp.SendMail(n.Host+":"+strconv.Itoa(n.Port), auth, n.From, []string{to}, []byte(mail)); err != nil {
+		return fmt.Errorf("failed to send email: %v", err)
+	}
+	return nil
+}
+
+// WebhookNotifier posts the message as JSON to the user's webhook URL.
+type WebhookNotifier struct {
+	Client *http.Client
+}
+
+func (n *WebhookNotifier) Channel() string { return ChannelWebhook }
+
+func (n *WebhookNotifier) Send(recipient Recipient, message Message) error {
+	if recipient.WebhookURL == "" {
+		return fmt.Errorf("no webhook URL configured")
+	}
+	return postJSON(n.Client, recipient.WebhookURL, "", map[string]interface{}{
+		"username": recipient.Username,
+		"event":    message.Event,
+		"subject":  message.Subject,
+		"body":     message.Body,
+	})
+}
+
+// SMSNotifier sends text messages through an HTTP SMS gateway.
+type SMSNotifier struct {
+	Client     *http.Client
+	GatewayURL string
+	APIKey     string
+}
+
+func (n *SMSNotifier) Channel() string { return ChannelSMS }
+
+func (n *SMSNotifier) Send(recipient Recipient, message Message) error {
+	if recipient.Mobile == "" {
+		return fmt.Errorf("no mobile number on file")
+	}
+	return postJSON(n.Client, n.GatewayURL, n.APIKey, map[string]interface{}{
+		"to":   recipient.Mobile,
+		"text": message.Body,
+	})
+}
+
+func postJSON(client *http.Client, url, apiKey string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("request failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// NotifiersFromConfig returns the outbound notifiers the configuration enables. Webhooks need
+// no server-side setup; email needs an SMTP host and SMS needs a gateway URL.
+func NotifiersFromConfig(cfg config.Config) map[string]Notifier {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	notifiers := map[string]Notifier{
+		ChannelWebhook: &WebhookNotifier{Client: client},
+	}
+	if cfg.SMTPHost != "" {
+		notifiers[ChannelEmail] = emailNotifier(cfg)
+	}
+	if cfg.SMSGatewayURL != "" {
+		notifiers[ChannelSMS] = &SMSNotifier{Client: client, GatewayURL: cfg.SMSGatewayURL, APIKey: cfg.SMSAPIKey}
+	}
+	return notifiers
+}
+
+// MailerFromConfig returns a mailer for the configured SMTP relay, or nil when email is not
+// configured.
+func MailerFromConfig(cfg config.Config) Mailer {
+	if cfg.SMTPHost == "" {
+		return nil
+	}
+	return emailNotifier(cfg)
+}
+
+func emailNotifier(cfg config.Config) *EmailNotifier {
+	port := cfg.SMTPPort
+	if port == 0 {
+		port = 587
+	}
+	return &EmailNotifier{Host: cfg.SMTPHost, Port: port, Username: cfg.SMTPUsername, Password: cfg.SMTPPassword, From: cfg.SMTPFrom}
+}
@@ -0,0 +1,143 @@
+package notify
+
+import (
+	"cryptotracker/internal/alerts"
+	"cryptotracker/models"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Event types a user can route.
+const (
+	EventPriceAlert      = "price_alert"
+	EventRequestDecision = "request_decision"
+	EventAdminBroadcast  = "admin_broadcast"
+	EventDigest          = "digest"
+)
+
+// Channels a notification can be sent on.
+const (
+	ChannelInApp   = "in-app"
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelSMS     = "sms"
+)
+
+// Events lists every event type in display order.
+var Events = []string{EventPriceAlert, EventRequestDecision, EventAdminBroadcast, EventDigest}
+
+// AllChannels lists every channel in display order.
+var AllChannels = []string{ChannelInApp, ChannelEmail, ChannelWebhook, ChannelSMS}
+
+// DefaultPreferences sends every event in-app only, with no quiet hours and no rate cap.
+func DefaultPreferences() *models.NotificationPreferences {
+	channels := make(map[string][]string, len(Events))
+	for _, event := range Events {
+		channels[event] = []string{ChannelInApp}
+	}
+	return &models.NotificationPreferences{Channels: channels}
+}
+
+// Normalize fills in defaults for events the preferences do not mention, so stored preferences
+// saved before an event type existed keep working.
+func Normalize(prefs *models.NotificationPreferences) *models.NotificationPreferences {
+	if prefs == nil {
+		return DefaultPreferences()
+	}
+	if prefs.Channels == nil {
+		prefs.Channels = map[string][]string{}
+	}
+	for _, event := range Events {
+		if _, ok := prefs.Channels[event]; !ok {
+			prefs.Channels[event] = []string{ChannelInApp}
+		}
+	}
+	return prefs
+}
+
+// Validate checks preferences supplied by a user.
+func Validate(prefs *models.NotificationPreferences) error {
+	for event, channels := range prefs.Channels {
+		if !contains(Events, event) {
+			return fmt.Errorf("unknown event type %q", event)
+		}
+		for _, channel := range channels {
+			if !contains(AllChannels, channel) {
+				return fmt.Errorf("unknown channel %q for %s", channel, event)
+			}
+			if channel == ChannelWebhook && prefs.WebhookURL == "" {
+				return fmt.Errorf("a webhook URL is required to use the webhook channel")
+			}
+		}
+	}
+
+	if (prefs.QuietFrom == "") != (prefs.QuietTo == "") {
+		return fmt.Errorf("quiet hours need both a start and an end")
+	}
+	if prefs.QuietFrom != "" {
+		if _, err := alerts.ParseClock(prefs.QuietFrom); err != nil {
+			return err
+		}
+		if _, err := alerts.ParseClock(prefs.QuietTo); err != nil {
+			return err
+		}
+	}
+
+	if prefs.MaxPerHour < 0 {
+		return fmt.Errorf("max notifications per hour cannot be negative")
+	}
+
+	if prefs.WebhookURL != "" {
+		u, err := url.Parse(prefs.WebhookURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("webhook URL must be an http(s) URL")
+		}
+	}
+
+	return nil
+}
+
+// ChannelsFor returns the channels an event goes to.
+func ChannelsFor(prefs *models.NotificationPreferences, event string) []string {
+	return Normalize(prefs).Channels[event]
+}
+
+// WantsInApp reports whether an event should be shown in the app.
+func WantsInApp(prefs *models.NotificationPreferences, event string) bool {
+	return contains(ChannelsFor(prefs, event), ChannelInApp)
+}
+
+// InQuietHours reports whether now falls in the user's quiet hours.
+func InQuietHours(prefs *models.NotificationPreferences, loc *time.Location, now time.Time) bool {
+	if prefs == nil || prefs.QuietFrom == "" || prefs.QuietTo == "" {
+		return false
+	}
+	return alerts.InActiveWindow(prefs.QuietFrom, prefs.QuietTo, loc, now)
+}
+
+// Allow decides whether an outbound notification may be sent now. In-app notifications are
+// only shown while the user is looking, so quiet hours and the rate cap apply to the other
+// channels. sentLastHour is the number of outbound notifications sent in the past hour.
+// A non-empty reason explains a refusal.
+func Allow(prefs *models.NotificationPreferences, channel string, loc *time.Location, now time.Time, sentLastHour int) (bool, string) {
+	if channel == ChannelInApp {
+		return true, ""
+	}
+	if InQuietHours(prefs, loc, now) {
+		return false, "quiet hours"
+	}
+	if prefs != nil && prefs.MaxPerHour > 0 && sentLastHour >= prefs.MaxPerHour {
+		return false, "hourly limit reached"
+	}
+	return true, ""
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
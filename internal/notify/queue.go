@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"cryptotracker/internal/alerts"
+	"cryptotracker/models"
+	"time"
+)
+
+// Delivery statuses in the outbound queue.
+const (
+	DeliveryPending    = "pending"
+	DeliverySending    = "sending"
+	DeliverySent       = "sent"
+	DeliverySuppressed = "suppressed"
+	DeliveryDead       = "dead"
+)
+
+const (
+	// DefaultMaxAttempts is how many times a delivery is tried before it is dead-lettered.
+	DefaultMaxAttempts = 5
+	// ClaimLease is how long a worker owns a claimed delivery. A delivery still sending after
+	// the lease, because its worker stopped, is claimed again.
+	ClaimLease = 5 * time.Minute
+
+	baseBackoff = time.Minute
+	maxBackoff  = time.Hour
+)
+
+// Backoff is the wait before retrying a delivery that has failed the given number of times:
+// one minute, doubling with every failure, up to an hour.
+func Backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		return 0
+	}
+	wait := baseBackoff
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+		if wait >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return wait
+}
+
+// RecordFailure counts a failed attempt and either schedules the next one or, once the
+// delivery has used all its attempts, moves it to the dead-letter state.
+func RecordFailure(delivery *models.NotificationDelivery, err error, now time.Time) {
+	delivery.Attempts++
+	delivery.Error = err.Error()
+
+	maxAttempts := delivery.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	if delivery.Attempts >= maxAttempts {
+		delivery.Status = DeliveryDead
+		return
+	}
+	delivery.Status = DeliveryPending
+	delivery.NextAttemptAt = now.Add(Backoff(delivery.Attempts))
+}
+
+// QuietHoursEnd returns when the user's current quiet hours end. It is only meaningful while
+// InQuietHours reports true.
+func QuietHoursEnd(prefs *models.NotificationPreferences, loc *time.Location, now time.Time) time.Time {
+	end, err := alerts.ParseClock(prefs.QuietTo)
+	if err != nil {
+		return now
+	}
+
+	local := now.In(loc)
+	until := time.Date(local.Year(), local.Month(), local.Day(), end/60, end%60, 0, 0, loc)
+	if !until.After(local) {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until
+}
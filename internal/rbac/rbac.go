@@ -0,0 +1,129 @@
+// Package rbac maps user roles to the permissions they grant.
+package rbac
+
+// Roles, from least to most privileged.
+const (
+	Viewer  = "viewer"
+	User    = "user"
+	Analyst = "analyst"
+	Support = "support"
+	Admin   = "admin"
+	Owner   = "owner"
+)
+
+// Permission is an action a role may be allowed to take.
+type Permission string
+
+const (
+	// AlertsManage allows setting, pausing and deleting one's own alerts.
+	AlertsManage Permission = "alerts.manage"
+	// AlertsManageAny allows listing, pausing and deleting any user's alerts.
+	AlertsManageAny Permission = "alerts.manage_any"
+	// AdminPanel allows opening the CLI admin panel.
+	AdminPanel Permission = "admin.panel"
+	// UsersView allows listing user profiles.
+	UsersView Permission = "users.view"
+	// UsersDelete allows deleting users, and restoring them before they are purged.
+	UsersDelete Permission = "users.delete"
+	// UsersDeactivate allows deactivating users and reactivating them.
+	UsersDeactivate Permission = "users.deactivate"
+	// UsersChangeRole allows promoting and demoting users.
+	UsersChangeRole Permission = "users.change_role"
+	// UsersUnlock allows lifting login lockouts.
+	UsersUnlock Permission = "users.unlock"
+	// SessionsRevokeAny allows logging any user out of all of their sessions.
+	SessionsRevokeAny Permission = "sessions.revoke_any"
+	// RequestsView allows listing requests to add cryptocurrencies.
+	RequestsView Permission = "requests.view"
+	// RequestsApprove allows approving and rejecting those requests.
+	RequestsApprove Permission = "requests.approve"
+	// StablecoinsManage allows changing the monitored stablecoin pegs.
+	StablecoinsManage Permission = "stablecoins.manage"
+	// DepegAlerts sends every de-peg alert to the user without a subscription.
+	DepegAlerts Permission = "depeg.alerts"
+	// DeliveriesManage allows viewing and requeueing failed notification deliveries.
+	DeliveriesManage Permission = "deliveries.manage"
+	// SecurityManage allows changing the two-factor authentication policy.
+	SecurityManage Permission = "security.manage"
+	// AuditView allows searching, verifying and exporting the audit log.
+	AuditView Permission = "audit.view"
+)
+
+var roles = []string{Viewer, User, Analyst, Support, Admin, Owner}
+
+var permissions = map[string][]Permission{
+	Viewer:  {},
+	User:    {AlertsManage},
+	Analyst: {AlertsManage, AdminPanel, RequestsView, RequestsApprove, StablecoinsManage},
+	Support: {AlertsManage, AlertsManageAny, AdminPanel, UsersView, UsersDeactivate, UsersUnlock, SessionsRevokeAny,
+		RequestsView, DeliveriesManage},
+	Admin: {AlertsManage, AlertsManageAny, AdminPanel, UsersView, UsersDelete, UsersDeactivate, UsersChangeRole,
+		UsersUnlock, SessionsRevokeAny, RequestsView, RequestsApprove, StablecoinsManage, DepegAlerts, DeliveriesManage,
+		SecurityManage, AuditView},
+	Owner: {AlertsManage, AlertsManageAny, AdminPanel, UsersView, UsersDelete, UsersDeactivate, UsersChangeRole,
+		UsersUnlock, SessionsRevokeAny, RequestsView, RequestsApprove, StablecoinsManage, DepegAlerts, DeliveriesManage,
+		SecurityManage, AuditView},
+}
+
+// Roles returns every role, from least to most privileged.
+func Roles() []string {
+	return append([]string(nil), roles...)
+}
+
+// IsRole reports whether role is a known role.
+func IsRole(role string) bool {
+	return Rank(role) >= 0
+}
+
+// Rank orders the roles by privilege. Unknown roles rank -1, below every known role.
+func Rank(role string) int {
+	for i, r := range roles {
+		if r == role {
+			return i
+		}
+	}
+	return -1
+}
+
+// Can reports whether the role grants the permission. Unknown roles grant nothing.
+func Can(role string, permission Permission) bool {
+	for _, p := range permissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RolesWith returns every role that grants the permission, from least to most privileged.
+func RolesWith(permission Permission) []string {
+	var granted []string
+	for _, role := range roles {
+		if Can(role, permission) {
+			granted = append(granted, role)
+		}
+	}
+	return granted
+}
+
+// IsAdmin reports whether the role counts as an admin for the legacy isadmin flag.
+func IsAdmin(role string) bool {
+	return role == Admin || role == Owner
+}
+
+// CanManage reports whether a user with actorRole may act on the account of a user with
+// targetRole, for example to deactivate or delete it. Owners may act on anyone, others only
+// on users ranked below them.
+func CanManage(actorRole, targetRole string) bool {
+	return actorRole == Owner || Rank(targetRole) < Rank(actorRole)
+}
+
+// CanAssign reports whether a user with actorRole may change another user's role from one
+// role to another. Owners may change any role. Others with UsersChangeRole may only change
+// users ranked below them, and only to roles up to their own.
+func CanAssign(actorRole, from, to string) bool {
+	if !Can(actorRole, UsersChangeRole) || !IsRole(to) {
+		return false
+	}
+	return CanManage(actorRole, from) && Rank(to) <= Rank(actorRole)
+}
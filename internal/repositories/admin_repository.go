@@ -2,19 +2,34 @@ package repositories
 
 import (
 	"context"
+	"cryptotracker/internal/rbac"
 	"cryptotracker/models"
 	"cryptotracker/pkg/config"
 	"fmt"
 	"github.com/jackc/pgx/v4"
+	"strings"
+	"time"
 )
 
 type AdminRepository interface {
-	ChangeUserStatus(username string) error
-	DeleteUser(username string) error
+	GetUserRole(username string) (string, error)
+	SetUserRole(username, role string, events ...*models.AuditEvent) error
+	GetUserStatus(username string) (string, *time.Time, error)
+	SetUserStatus(username, status string, purgeAfter *time.Time, now time.Time, events ...*models.AuditEvent) error
+	GetUsersDueForPurge(now time.Time) ([]string, error)
+	PurgeUser(username string, now time.Time, events ...*models.AuditEvent) (bool, error)
 	ViewUserProfiles() ([]*models.User, error)
 	ManageUserRequests() ([]*models.UnavailableCryptoRequest, error)
 	ManageSpecificCryptoRequests(cryptoSymbol string) ([]*models.UnavailableCryptoRequest, error)
-	SaveUnavailableCryptoRequest(requests []*models.UnavailableCryptoRequest) error
+	UpdateRequestStatus(requests []*models.UnavailableCryptoRequest, status string, events []*models.AuditEvent) error
+	GetLockedLogins(now time.Time) ([]*models.LoginAttempt, error)
+	UnlockLogin(key string, events ...*models.AuditEvent) error
+	RecordAuditEvent(event *models.AuditEvent) error
+	GetAuditEvents(filter *models.AuditFilter) ([]*models.AuditEvent, error)
+	GetAuditChain() ([]*models.AuditEvent, error)
+	SetTwoFactorRequired(role string, required bool, events ...*models.AuditEvent) error
+	IsTwoFactorRequired(role string) (bool, error)
+	RevokeUserSessions(username string, now time.Time, event func(count int) *models.AuditEvent) (int, error)
 }
 
 type PostgresAdminRepository struct {
@@ -25,79 +40,199 @@ func NewPostgresAdminRepository(conn *pgx.Conn) *PostgresAdminRepository {
 	return &PostgresAdminRepository{conn: conn}
 }
 
-func (r *PostgresAdminRepository) ChangeUserStatus(username string) error {
-	query, err := config.BuildSelectQuery([]string{"username", "role"}, "users", "username=$1")
+// GetUserRole returns the current role of a user.
+func (r *PostgresAdminRepository) GetUserRole(username string) (string, error) {
+	query, err := config.BuildSelectQuery([]string{"role"}, "users", "username = $1")
 	if err != nil {
-		return fmt.Errorf("failed to build query: %v", err)
+		return "", fmt.Errorf("failed to build query: %v", err)
 	}
 
-	row := r.conn.QueryRow(context.Background(), query, username)
-
-	var user models.User
-	err = row.Scan(&user.Username, &user.Role)
-	if err != nil {
+	var role string
+	if err := r.conn.QueryRow(context.Background(), query, username).Scan(&role); err != nil {
 		if err == pgx.ErrNoRows {
-			return fmt.Errorf("user not found")
+			return "", fmt.Errorf("user not found")
 		}
-		return fmt.Errorf("failed to query user: %v", err)
+		return "", fmt.Errorf("failed to query user role: %v", err)
 	}
 
-	if user.Role == "admin" {
-		return fmt.Errorf("user is already an admin")
-	}
+	return role, nil
+}
 
-	updateQuery, err := config.BuildUpdateQuery("users", []string{"role", "isadmin"}, "username=$3")
+// SetUserRole changes a user's role, keeping the legacy isadmin flag in step with it, and
+// records the events in the audit log in the same transaction. A demoted user has every
+// session ended, so that tokens carrying the old role stop working.
+func (r *PostgresAdminRepository) SetUserRole(username, role string, events ...*models.AuditEvent) error {
+	selectQuery, err := config.BuildSelectQuery([]string{"role"}, "users", "username = $1 FOR UPDATE")
+	if err != nil {
+		return fmt.Errorf("failed to build query: %v", err)
+	}
+	query, err := config.BuildUpdateQuery("users", []string{"role", "isadmin"}, "username = $3")
 	if err != nil {
 		return fmt.Errorf("failed to build update query: %v", err)
 	}
 
-	_, err = r.conn.Exec(context.Background(), updateQuery, "admin", true, username)
+	return audited(r.conn, events, func(tx pgx.Tx) error {
+		var current string
+		if err := tx.QueryRow(context.Background(), selectQuery, username).Scan(&current); err != nil {
+			if err == pgx.ErrNoRows {
+				return fmt.Errorf("user not found")
+			}
+			return fmt.Errorf("failed to query user role: %v", err)
+		}
+
+		if _, err := tx.Exec(context.Background(), query, role, rbac.IsAdmin(role), username); err != nil {
+			return fmt.Errorf("failed to update user role: %v", err)
+		}
+
+		if rbac.Rank(role) < rbac.Rank(current) {
+			if _, err := revokeUserSessions(tx, username, time.Now()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetUserStatus returns whether a user is active, deactivated or deleted and, for a deleted
+// user, when they are due to be purged.
+func (r *PostgresAdminRepository) GetUserStatus(username string) (string, *time.Time, error) {
+	query, err := config.BuildSelectQuery([]string{"status", "purge_after"}, "users", "username = $1")
 	if err != nil {
-		return fmt.Errorf("failed to update user role: %v", err)
+		return "", nil, fmt.Errorf("failed to build query: %v", err)
 	}
 
-	return nil
+	var status string
+	var purgeAfter *time.Time
+	if err := r.conn.QueryRow(context.Background(), query, username).Scan(&status, &purgeAfter); err != nil {
+		if err == pgx.ErrNoRows {
+			return "", nil, fmt.Errorf("user not found")
+		}
+		return "", nil, fmt.Errorf("failed to query user status: %v", err)
+	}
+
+	return status, purgeAfter, nil
 }
 
-func (r *PostgresAdminRepository) DeleteUser(username string) error {
-	tx, err := r.conn.Begin(context.Background())
+// SetUserStatus activates, deactivates or deletes a user. purgeAfter is set only for deleted
+// users. A user who is no longer active has every session ended. The events are recorded in
+// the audit log in the same transaction.
+func (r *PostgresAdminRepository) SetUserStatus(username, status string, purgeAfter *time.Time, now time.Time, events ...*models.AuditEvent) error {
+	query, err := config.BuildUpdateQuery("users", []string{"status", "purge_after", "status_changed_at"}, "username = $4")
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %v", err)
+		return fmt.Errorf("failed to build update query: %v", err)
 	}
 
-	defer func() {
+	return audited(r.conn, events, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(context.Background(), query, status, purgeAfter, now, username)
 		if err != nil {
-			tx.Rollback(context.Background())
-		} else {
-			tx.Commit(context.Background())
+			return fmt.Errorf("failed to update user status: %v", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("user not found")
 		}
-	}()
 
-	deleteUserQuery, err := config.BuildDeleteQuery("users", "username=$1")
+		if status != models.AccountActive {
+			if _, err := revokeUserSessions(tx, username, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetUsersDueForPurge returns the deleted users whose grace period has ended.
+func (r *PostgresAdminRepository) GetUsersDueForPurge(now time.Time) ([]string, error) {
+	query, err := config.BuildSelectQuery([]string{"username"}, "users", "status = 'deleted' AND purge_after <= $1 ORDER BY purge_after")
 	if err != nil {
-		return fmt.Errorf("failed to build delete user query: %v", err)
+		return nil, fmt.Errorf("failed to build query: %v", err)
 	}
-	_, err = tx.Exec(context.Background(), deleteUserQuery, username)
+
+	rows, err := r.conn.Query(context.Background(), query, now)
 	if err != nil {
-		return fmt.Errorf("failed to delete user: %v", err)
+		return nil, fmt.Errorf("failed to query users due for purge: %v", err)
+	}
+	defer rows.Close()
+
+	var usernames []string
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, fmt.Errorf("failed to scan username: %v", err)
+		}
+		usernames = append(usernames, username)
 	}
 
-	// Delete related price notifications and unavailable crypto requests
-	_, err = tx.Exec(context.Background(), "DELETE FROM price_notifications WHERE username=$1", username)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %v", err)
+	}
+
+	return usernames, nil
+}
+
+// purgeStatements remove everything a purged user owns besides their users row. Each takes
+// the username as $1. The audit log keeps its entries, which are append-only.
+var purgeStatements = []struct {
+	query string
+	what  string
+}{
+	{"DELETE FROM price_alert_occurrences WHERE username=$1", "alert occurrences"},
+	{"DELETE FROM price_notifications WHERE username=$1", "price notifications"},
+	{"DELETE FROM alert_groups WHERE username=$1", "alert groups"},
+	{"DELETE FROM unavailable_cryptos WHERE username=$1", "unavailable crypto requests"},
+	{"DELETE FROM depeg_subscriptions WHERE username=$1", "de-peg subscription"},
+	{"DELETE FROM digest_settings WHERE username=$1", "digest settings"},
+	{"DELETE FROM digests WHERE username=$1", "digests"},
+	{"DELETE FROM notification_deliveries WHERE username=$1", "notification deliveries"},
+	{"DELETE FROM refresh_tokens WHERE username=$1", "refresh tokens"},
+	{"DELETE FROM api_keys WHERE username=$1", "API keys"},
+	{"DELETE FROM sessions WHERE username=$1", "sessions"},
+	{"DELETE FROM login_attempts WHERE key = 'user:' || LOWER($1)", "login attempts"},
+	{"DELETE FROM password_reset_tokens WHERE username=$1", "password reset tokens"},
+	{"DELETE FROM email_verification_tokens WHERE username=$1", "verification tokens"},
+	{"DELETE FROM recovery_codes WHERE username=$1", "recovery codes"},
+	{"DELETE FROM user_two_factor WHERE username=$1", "two-factor settings"},
+}
+
+// PurgeUser removes a deleted user whose grace period has ended, and everything they own, for
+// good, and records the events in the audit log in the same transaction. It reports false,
+// and removes and records nothing, when the user has been restored meanwhile.
+func (r *PostgresAdminRepository) PurgeUser(username string, now time.Time, events ...*models.AuditEvent) (bool, error) {
+	tx, err := r.conn.Begin(context.Background())
 	if err != nil {
-		return fmt.Errorf("failed to delete user price notifications: %v", err)
+		return false, fmt.Errorf("failed to begin transaction: %v", err)
 	}
+	defer tx.Rollback(context.Background())
 
-	_, err = tx.Exec(context.Background(), "DELETE FROM unavailable_cryptos WHERE username=$1", username)
+	deleteUserQuery, err := config.BuildDeleteQuery("users", "username=$1 AND status = 'deleted' AND purge_after <= $2")
+	if err != nil {
+		return false, fmt.Errorf("failed to build delete user query: %v", err)
+	}
+	tag, err := tx.Exec(context.Background(), deleteUserQuery, username, now)
 	if err != nil {
-		return fmt.Errorf("failed to delete user unavailable crypto requests: %v", err)
+		return false, fmt.Errorf("failed to delete user: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
 	}
 
-	return nil
+	for _, statement := range purgeStatements {
+		if _, err := tx.Exec(context.Background(), statement.query, username); err != nil {
+			return false, fmt.Errorf("failed to delete user %s: %v", statement.what, err)
+		}
+	}
+
+	if err := recordAuditEvents(tx, events...); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		return false, fmt.Errorf("failed to commit purge: %v", err)
+	}
+	return true, nil
 }
 
 func (r *PostgresAdminRepository) ViewUserProfiles() ([]*models.User, error) {
-	query, err := config.BuildSelectQuery([]string{"userid", "username", "email", "mobile", "isadmin", "role"}, "users", "")
+	query, err := config.BuildSelectQuery([]string{"userid", "username", "email", "mobile", "isadmin", "role", "status", "purge_after"}, "users", "TRUE ORDER BY userid")
 	if err != nil {
 		return nil, fmt.Errorf("failed to build query: %v", err)
 	}
@@ -111,7 +246,7 @@ func (r *PostgresAdminRepository) ViewUserProfiles() ([]*models.User, error) {
 	var users []*models.User
 	for rows.Next() {
 		var user models.User
-		if err := rows.Scan(&user.UserID, &user.Username, &user.Email, &user.Mobile, &user.IsAdmin, &user.Role); err != nil {
+		if err := rows.Scan(&user.UserID, &user.Username, &user.Email, &user.Mobile, &user.IsAdmin, &user.Role, &user.Status, &user.PurgeAfter); err != nil {
 			return nil, fmt.Errorf("failed to scan user: %v", err)
 		}
 		users = append(users, &user)
@@ -181,23 +316,159 @@ func (r *PostgresAdminRepository) ManageSpecificCryptoRequests(cryptoSymbol stri
 	return requests, nil
 }
 
-func (r *PostgresAdminRepository) SaveUnavailableCryptoRequest(requests []*models.UnavailableCryptoRequest) error {
+// UpdateRequestStatus sets the status of requests to add cryptocurrencies. A request is only
+// changed while it still has the status it was read with, and is then given the new one.
+// events[i] is recorded in the audit log, in the same transaction, only if requests[i] changed.
+func (r *PostgresAdminRepository) UpdateRequestStatus(requests []*models.UnavailableCryptoRequest, status string, events []*models.AuditEvent) error {
+	query, err := config.BuildUpdateQuery("unavailable_cryptos", []string{"status"}, "username = $2 AND crypto_symbol = $3 AND timestamp = $4 AND status = $5")
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %v", err)
+	}
 
-	var err error
-	var query string
+	tx, err := r.conn.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(context.Background())
 
-	for _, request := range requests {
-		query, err = config.BuildInsertQuery("unavailable_cryptos", []string{"crypto_symbol", "username", "request_message", "status", "timestamp"})
+	var changed []*models.UnavailableCryptoRequest
+	var changedEvents []*models.AuditEvent
+	for i, request := range requests {
+		tag, err := tx.Exec(context.Background(), query, status, request.UserName, request.CryptoSymbol, request.Timestamp, request.Status)
 		if err != nil {
-			return fmt.Errorf("failed to build insert query: %v", err)
+			return fmt.Errorf("failed to update request status: %v", err)
+		}
+		if tag.RowsAffected() != 1 {
+			continue
 		}
+		changed = append(changed, request)
+		if i < len(events) {
+			changedEvents = append(changedEvents, events[i])
+		}
+	}
+
+	if err := recordAuditEvents(tx, changedEvents...); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	for _, request := range changed {
+		request.Status = status
+	}
+	return nil
+}
+
+// GetLockedLogins returns the usernames and client addresses whose logins are locked.
+func (r *PostgresAdminRepository) GetLockedLogins(now time.Time) ([]*models.LoginAttempt, error) {
+	query, err := config.BuildSelectQuery(loginAttemptColumns, "login_attempts", "locked_until > $1 ORDER BY locked_until")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.Query(context.Background(), query, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query locked logins: %v", err)
+	}
 
-		_, err = r.conn.Exec(context.Background(), query, request.CryptoSymbol, request.UserName, request.RequestMessage, request.Status, request.Timestamp)
+	return scanLoginAttempts(rows)
+}
+
+// UnlockLogin removes the lock and failure count of a username or client address, and records
+// the events in the audit log in the same transaction.
+func (r *PostgresAdminRepository) UnlockLogin(key string, events ...*models.AuditEvent) error {
+	query, err := config.BuildDeleteQuery("login_attempts", "key = $1")
+	if err != nil {
+		return fmt.Errorf("failed to build delete query: %v", err)
+	}
+
+	return audited(r.conn, events, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(context.Background(), query, key)
 		if err != nil {
-			return fmt.Errorf("failed to insert request: %v", err)
+			return fmt.Errorf("failed to unlock login: %v", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("no failed logins found for %s", key)
 		}
+		return nil
+	})
+}
+
+// RecordAuditEvent appends an event that goes with no change of its own, such as an export,
+// to the audit log.
+func (r *PostgresAdminRepository) RecordAuditEvent(event *models.AuditEvent) error {
+	return recordAuditEvent(r.conn, event)
+}
+
+// GetAuditEvents returns the audit log entries matching the filter, newest first.
+func (r *PostgresAdminRepository) GetAuditEvents(filter *models.AuditFilter) ([]*models.AuditEvent, error) {
+	condition, args := auditCondition(filter)
+	condition += " ORDER BY id DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		condition += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	query, err := config.BuildSelectQuery(auditColumns, "audit_log", condition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %v", err)
+	}
+
+	return queryAuditEvents(r.conn, query, args...)
+}
+
+// GetAuditChain returns the whole audit log, oldest first, for checking its hash chain.
+func (r *PostgresAdminRepository) GetAuditChain() ([]*models.AuditEvent, error) {
+	query, err := config.BuildSelectQuery(auditColumns, "audit_log", "TRUE ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %v", err)
+	}
+
+	return queryAuditEvents(r.conn, query)
+}
+
+// SetTwoFactorRequired sets whether users with the role must use two-factor authentication,
+// and records the events in the audit log in the same transaction.
+func (r *PostgresAdminRepository) SetTwoFactorRequired(role string, required bool, events ...*models.AuditEvent) error {
+	query, err := config.BuildInsertQuery("role_settings", []string{"role", "require_two_factor"})
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %v", err)
+	}
+	query = strings.TrimSuffix(query, ";") + " ON CONFLICT (role) DO UPDATE SET require_two_factor = EXCLUDED.require_two_factor;"
 
+	return audited(r.conn, events, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(context.Background(), query, role, required); err != nil {
+			return fmt.Errorf("failed to save role settings: %v", err)
+		}
 		return nil
+	})
+}
+
+// IsTwoFactorRequired reports whether users with the role must use two-factor authentication.
+func (r *PostgresAdminRepository) IsTwoFactorRequired(role string) (bool, error) {
+	return isTwoFactorRequired(r.conn, role)
+}
+
+// RevokeUserSessions ends every session of a user and returns how many were live. The audit
+// log event, made from that count, is recorded in the same transaction.
+func (r *PostgresAdminRepository) RevokeUserSessions(username string, now time.Time, event func(count int) *models.AuditEvent) (int, error) {
+	tx, err := r.conn.Begin(context.Background())
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %v", err)
 	}
-	return nil
+	defer tx.Rollback(context.Background())
+
+	count, err := revokeUserSessions(tx, username, now)
+	if err != nil {
+		return 0, err
+	}
+	if err := recordAuditEvents(tx, event(count)); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return count, nil
 }
@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"context"
+	"cryptotracker/internal/audit"
+	"cryptotracker/models"
+	"cryptotracker/pkg/config"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"strings"
+)
+
+var auditColumns = []string{"id", "actor", "action", "target", "details", "before_value", "after_value", "source", "created_at", "prev_hash", "hash"}
+
+// recordAuditEvent appends an event that goes with no change of its own, such as an export,
+// to the audit log.
+func recordAuditEvent(conn *pgx.Conn, event *models.AuditEvent) error {
+	return audited(conn, []*models.AuditEvent{event}, func(tx pgx.Tx) error { return nil })
+}
+
+// audited runs a change in a transaction and appends its events to the audit log before the
+// transaction commits, so that the change is never kept without its entries or the entries
+// without the change.
+func audited(conn *pgx.Conn, events []*models.AuditEvent, change func(tx pgx.Tx) error) error {
+	tx, err := conn.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	if err := change(tx); err != nil {
+		return err
+	}
+	if err := recordAuditEvents(tx, events...); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// recordAuditEvents appends events to the audit log, chained to the newest entry, inside the
+// transaction of the change they record. It is shared by the repositories whose services
+// audit what they do.
+func recordAuditEvents(tx pgx.Tx, events ...*models.AuditEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	// Appends take turns, so that no two entries chain onto the same predecessor
+	if _, err := tx.Exec(context.Background(), "LOCK TABLE audit_log IN EXCLUSIVE MODE"); err != nil {
+		return fmt.Errorf("failed to lock audit log: %v", err)
+	}
+
+	var prevHash string
+	err := tx.QueryRow(context.Background(), "SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1").Scan(&prevHash)
+	if err != nil && err != pgx.ErrNoRows {
+		return fmt.Errorf("failed to query audit log: %v", err)
+	}
+
+	query, err := config.BuildInsertQuery("audit_log", auditColumns[1:])
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %v", err)
+	}
+	query = strings.TrimSuffix(query, ";") + " RETURNING id"
+
+	for _, event := range events {
+		audit.Chain(event, prevHash)
+		err = tx.QueryRow(context.Background(), query, event.Actor, event.Action, event.Target, event.Details, event.Before, event.After,
+			event.Source, event.CreatedAt, event.PrevHash, event.Hash).Scan(&event.ID)
+		if err != nil {
+			return fmt.Errorf("failed to record audit event: %v", err)
+		}
+		prevHash = event.Hash
+	}
+
+	return nil
+}
+
+// auditCondition turns a filter into a WHERE condition and its arguments.
+func auditCondition(filter *models.AuditFilter) (string, []interface{}) {
+	conditions := []string{"TRUE"}
+	var args []interface{}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Actor != "" {
+		add("LOWER(actor) = LOWER($%d)", filter.Actor)
+	}
+	if strings.HasSuffix(filter.Action, ".") {
+		add("starts_with(action, $%d)", filter.Action)
+	} else if filter.Action != "" {
+		add("action = $%d", filter.Action)
+	}
+	if filter.Target != "" {
+		add("LOWER(target) = LOWER($%d)", filter.Target)
+	}
+	if filter.Source != "" {
+		add("source = $%d", filter.Source)
+	}
+	if !filter.Since.IsZero() {
+		add("created_at >= $%d", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		add("created_at < $%d", filter.Until)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// queryAuditEvents runs a query for audit log entries.
+func queryAuditEvents(conn *pgx.Conn, query string, args ...interface{}) ([]*models.AuditEvent, error) {
+	rows, err := conn.Query(context.Background(), query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %v", err)
+	}
+	defer rows.Close()
+
+	var events []*models.AuditEvent
+	for rows.Next() {
+		var event models.AuditEvent
+		if err := rows.Scan(&event.ID, &event.Actor, &event.Action, &event.Target, &event.Details, &event.Before, &event.After,
+			&event.Source, &event.CreatedAt, &event.PrevHash, &event.Hash); err != nil {
+			return nil, fmt.Errorf("failed to scan audit event: %v", err)
+		}
+		events = append(events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %v", err)
+	}
+
+	return events, nil
+}
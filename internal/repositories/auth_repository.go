@@ -8,11 +8,32 @@ import (
 	"github.com/jackc/pgx/v4"
 	"github.com/pkg/errors"
 	"log"
+	"strings"
+	"time"
 )
 
 type AuthRepository interface {
 	LoginDBRepository(username string) (*models.User, error)
 	SignupDBRepository(user *models.User) error
+	UpdatePasswordHash(username, hash string) error
+	GetLoginAttempts(keys []string) ([]*models.LoginAttempt, error)
+	RecordLoginFailure(key string, now, windowStart time.Time) (int, error)
+	LockLogin(key string, until time.Time, events ...*models.AuditEvent) error
+	ClearLoginAttempts(key string) error
+	FindUsersForReset(identifier string) ([]*models.User, error)
+	CountPasswordResets(username string, since time.Time) (int, error)
+	SavePasswordResetToken(hash, username string, createdAt, expiresAt time.Time) error
+	ResetPassword(tokenHash, passwordHash string, now time.Time, events ...*models.AuditEvent) (string, error)
+	CountEmailVerifications(username string, since time.Time) (int, error)
+	SaveEmailVerificationToken(hash, username, email string, createdAt, expiresAt time.Time) error
+	VerifyEmail(tokenHash string, now time.Time, events ...*models.AuditEvent) (string, error)
+	GetTwoFactor(username string) (*models.TwoFactor, error)
+	SaveTwoFactorSecret(username, secret string, now time.Time) error
+	EnableTwoFactor(username string, step int64, recoveryCodeHashes []string, now time.Time, events ...*models.AuditEvent) error
+	DisableTwoFactor(username string, events ...*models.AuditEvent) error
+	UseTOTPStep(username string, step int64) (bool, error)
+	UseRecoveryCode(username, codeHash string, now time.Time, events ...*models.AuditEvent) (bool, error)
+	IsTwoFactorRequired(role string) (bool, error)
 }
 
 type PostgresAuthRepository struct {
@@ -29,7 +50,8 @@ func (r *PostgresAuthRepository) LoginDBRepository(username string) (*models.Use
 	var user models.User
 
 	// Define the columns and conditions for the query
-	columns := []string{"username", "password", "email", "mobile", "role", "isadmin"}
+	columns := []string{"username", "password", "email", "email_verified", "mobile", "role", "isadmin", "timezone",
+		"COALESCE((SELECT enabled FROM user_two_factor WHERE user_two_factor.username = users.username), FALSE)", "status", "purge_after"}
 	condition := "username = $1"
 
 	// Build the query to fetch user information
@@ -40,7 +62,8 @@ func (r *PostgresAuthRepository) LoginDBRepository(username string) (*models.Use
 
 	// Execute the query to fetch the user information
 	err = r.conn.QueryRow(context.Background(), query, username).
-		Scan(&user.Username, &user.Password, &user.Email, &user.Mobile, &user.Role, &user.IsAdmin)
+		Scan(&user.Username, &user.Password, &user.Email, &user.EmailVerified, &user.Mobile, &user.Role, &user.IsAdmin, &user.Timezone, &user.TwoFactorEnabled,
+			&user.Status, &user.PurgeAfter)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, errors.New("user not found")
@@ -73,7 +96,7 @@ func (r *PostgresAuthRepository) SignupDBRepository(user *models.User) error {
 	}
 
 	// Define columns for the INSERT query
-	insertColumns := []string{"username", "email", "mobile", "password", "role", "isadmin"}
+	insertColumns := []string{"username", "email", "email_verified", "mobile", "password", "role", "isadmin", "timezone"}
 
 	// Use BuildInsertQuery to create an INSERT query for user registration
 	insertQuery, err := config.BuildInsertQuery("users", insertColumns)
@@ -82,7 +105,11 @@ func (r *PostgresAuthRepository) SignupDBRepository(user *models.User) error {
 	}
 
 	// Insert the new user
-	_, err = r.conn.Exec(context.Background(), insertQuery, user.Username, user.Email, user.Mobile, user.Password, user.Role, user.IsAdmin)
+	if user.Timezone == "" {
+		user.Timezone = "UTC"
+	}
+
+	_, err = r.conn.Exec(context.Background(), insertQuery, user.Username, user.Email, user.EmailVerified, user.Mobile, user.Password, user.Role, user.IsAdmin, user.Timezone)
 	if err != nil {
 		log.Fatalf("failed to insert user: %v", err)
 		return err
@@ -90,3 +117,533 @@ func (r *PostgresAuthRepository) SignupDBRepository(user *models.User) error {
 
 	return nil
 }
+
+// UpdatePasswordHash replaces the stored password hash of a user.
+func (r *PostgresAuthRepository) UpdatePasswordHash(username, hash string) error {
+	query, err := config.BuildUpdateQuery("users", []string{"password"}, "username = $2")
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %v", err)
+	}
+
+	if _, err := r.conn.Exec(context.Background(), query, hash, username); err != nil {
+		return fmt.Errorf("failed to update password hash: %v", err)
+	}
+
+	return nil
+}
+
+var loginAttemptColumns = []string{"key", "failures", "last_failure_at", "locked_until"}
+
+func scanLoginAttempts(rows pgx.Rows) ([]*models.LoginAttempt, error) {
+	defer rows.Close()
+
+	var attempts []*models.LoginAttempt
+	for rows.Next() {
+		var attempt models.LoginAttempt
+		if err := rows.Scan(&attempt.Key, &attempt.Failures, &attempt.LastFailureAt, &attempt.LockedUntil); err != nil {
+			return nil, fmt.Errorf("failed to scan login attempt: %v", err)
+		}
+		attempts = append(attempts, &attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %v", err)
+	}
+
+	return attempts, nil
+}
+
+// GetLoginAttempts returns the failed login records for the given keys. Keys without
+// failures have no record.
+func (r *PostgresAuthRepository) GetLoginAttempts(keys []string) ([]*models.LoginAttempt, error) {
+	return getLoginAttempts(r.conn, keys)
+}
+
+// RecordLoginFailure counts a failed login for the key and returns its failures so far.
+// Failures before windowStart are forgotten.
+func (r *PostgresAuthRepository) RecordLoginFailure(key string, now, windowStart time.Time) (int, error) {
+	return recordLoginFailure(r.conn, key, now, windowStart)
+}
+
+// LockLogin blocks logins for the key until the given time, and records the events in the
+// audit log in the same transaction.
+func (r *PostgresAuthRepository) LockLogin(key string, until time.Time, events ...*models.AuditEvent) error {
+	return lockLogin(r.conn, key, until, events)
+}
+
+// ClearLoginAttempts forgets the failed logins of the key.
+func (r *PostgresAuthRepository) ClearLoginAttempts(key string) error {
+	return clearLoginAttempts(r.conn, key)
+}
+
+// getLoginAttempts, recordLoginFailure, lockLogin and clearLoginAttempts keep the failed login
+// counts. They are shared by every repository whose service checks a password.
+func getLoginAttempts(conn *pgx.Conn, keys []string) ([]*models.LoginAttempt, error) {
+	query, err := config.BuildSelectQuery(loginAttemptColumns, "login_attempts", "key = ANY($1)")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.Query(context.Background(), query, keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query login attempts: %v", err)
+	}
+
+	return scanLoginAttempts(rows)
+}
+
+func recordLoginFailure(conn *pgx.Conn, key string, now, windowStart time.Time) (int, error) {
+	query, err := config.BuildInsertQuery("login_attempts", []string{"key", "failures", "last_failure_at"})
+	if err != nil {
+		return 0, fmt.Errorf("failed to build insert query: %v", err)
+	}
+	query = strings.TrimSuffix(query, ";") + ` ON CONFLICT (key) DO UPDATE SET
+		failures = CASE WHEN login_attempts.last_failure_at < $4 THEN 1 ELSE login_attempts.failures + 1 END,
+		last_failure_at = EXCLUDED.last_failure_at
+		RETURNING failures;`
+
+	var failures int
+	if err := conn.QueryRow(context.Background(), query, key, 1, now, windowStart).Scan(&failures); err != nil {
+		return 0, fmt.Errorf("failed to record login failure: %v", err)
+	}
+
+	return failures, nil
+}
+
+func lockLogin(conn *pgx.Conn, key string, until time.Time, events []*models.AuditEvent) error {
+	query, err := config.BuildUpdateQuery("login_attempts", []string{"locked_until"}, "key = $2")
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %v", err)
+	}
+
+	return audited(conn, events, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(context.Background(), query, until, key); err != nil {
+			return fmt.Errorf("failed to lock login: %v", err)
+		}
+		return nil
+	})
+}
+
+func clearLoginAttempts(conn *pgx.Conn, key string) error {
+	query, err := config.BuildDeleteQuery("login_attempts", "key = $1")
+	if err != nil {
+		return fmt.Errorf("failed to build delete query: %v", err)
+	}
+
+	if _, err := conn.Exec(context.Background(), query, key); err != nil {
+		return fmt.Errorf("failed to clear login attempts: %v", err)
+	}
+
+	return nil
+}
+
+// FindUsersForReset returns the users with the given username or email address.
+func (r *PostgresAuthRepository) FindUsersForReset(identifier string) ([]*models.User, error) {
+	query, err := config.BuildSelectQuery([]string{"username", "email", "email_verified"}, "users", "username = $1 OR LOWER(email) = LOWER($1)")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.Query(context.Background(), query, identifier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %v", err)
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.Username, &user.Email, &user.EmailVerified); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %v", err)
+		}
+		users = append(users, &user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %v", err)
+	}
+
+	return users, nil
+}
+
+// CountPasswordResets counts the reset tokens issued to a user since the given time.
+func (r *PostgresAuthRepository) CountPasswordResets(username string, since time.Time) (int, error) {
+	query, err := config.BuildSelectQuery([]string{"COUNT(*)"}, "password_reset_tokens", "username = $1 AND created_at >= $2")
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := r.conn.QueryRow(context.Background(), query, username, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count password resets: %v", err)
+	}
+
+	return count, nil
+}
+
+// SavePasswordResetToken stores the hash of a new reset token and uses up the user's earlier
+// ones, so only the latest email works.
+func (r *PostgresAuthRepository) SavePasswordResetToken(hash, username string, createdAt, expiresAt time.Time) error {
+	tx, err := r.conn.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	query, err := config.BuildUpdateQuery("password_reset_tokens", []string{"used_at"}, "username = $2 AND used_at IS NULL")
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %v", err)
+	}
+	if _, err := tx.Exec(context.Background(), query, createdAt, username); err != nil {
+		return fmt.Errorf("failed to expire earlier reset tokens: %v", err)
+	}
+
+	query, err = config.BuildInsertQuery("password_reset_tokens", []string{"token_hash", "username", "created_at", "expires_at"})
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %v", err)
+	}
+	if _, err := tx.Exec(context.Background(), query, hash, username, createdAt, expiresAt); err != nil {
+		return fmt.Errorf("failed to save reset token: %v", err)
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// ResetPassword uses up a live reset token and sets its user's password. The user's sessions,
+// on the CLI and REST alike, and refresh tokens are revoked and any login lockout is lifted. It returns the username, or an
+// empty username when the token is unknown, expired or already used. The events are recorded
+// in the audit log in the same transaction, with the token's user as their actor and target.
+func (r *PostgresAuthRepository) ResetPassword(tokenHash, passwordHash string, now time.Time, events ...*models.AuditEvent) (string, error) {
+	tx, err := r.conn.Begin(context.Background())
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	query, err := config.BuildUpdateQuery("password_reset_tokens", []string{"used_at"}, "token_hash = $2 AND used_at IS NULL AND expires_at > $1")
+	if err != nil {
+		return "", fmt.Errorf("failed to build update query: %v", err)
+	}
+	query = strings.TrimSuffix(query, ";") + " RETURNING username;"
+
+	var username string
+	if err := tx.QueryRow(context.Background(), query, now, tokenHash).Scan(&username); err != nil {
+		if err == pgx.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to use reset token: %v", err)
+	}
+
+	query, err = config.BuildUpdateQuery("users", []string{"password"}, "username = $2")
+	if err != nil {
+		return "", fmt.Errorf("failed to build update query: %v", err)
+	}
+	if _, err := tx.Exec(context.Background(), query, passwordHash, username); err != nil {
+		return "", fmt.Errorf("failed to update password: %v", err)
+	}
+
+	if _, err := revokeUserSessions(tx, username, now); err != nil {
+		return "", err
+	}
+
+	if _, err := tx.Exec(context.Background(), "DELETE FROM login_attempts WHERE key = 'user:' || LOWER($1)", username); err != nil {
+		return "", fmt.Errorf("failed to clear login attempts: %v", err)
+	}
+
+	for _, event := range events {
+		event.Actor, event.Target = username, username
+	}
+	if err := recordAuditEvents(tx, events...); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return username, nil
+}
+
+// CountEmailVerifications counts the verification tokens issued to a user since the given time.
+func (r *PostgresAuthRepository) CountEmailVerifications(username string, since time.Time) (int, error) {
+	query, err := config.BuildSelectQuery([]string{"COUNT(*)"}, "email_verification_tokens", "username = $1 AND created_at >= $2")
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := r.conn.QueryRow(context.Background(), query, username, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count email verifications: %v", err)
+	}
+
+	return count, nil
+}
+
+// SaveEmailVerificationToken stores the hash of a new verification token for the address and
+// uses up the user's earlier ones, so only the latest email works.
+func (r *PostgresAuthRepository) SaveEmailVerificationToken(hash, username, email string, createdAt, expiresAt time.Time) error {
+	tx, err := r.conn.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	query, err := config.BuildUpdateQuery("email_verification_tokens", []string{"used_at"}, "username = $2 AND used_at IS NULL")
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %v", err)
+	}
+	if _, err := tx.Exec(context.Background(), query, createdAt, username); err != nil {
+		return fmt.Errorf("failed to expire earlier verification tokens: %v", err)
+	}
+
+	query, err = config.BuildInsertQuery("email_verification_tokens", []string{"token_hash", "username", "email", "created_at", "expires_at"})
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %v", err)
+	}
+	if _, err := tx.Exec(context.Background(), query, hash, username, email, createdAt, expiresAt); err != nil {
+		return fmt.Errorf("failed to save verification token: %v", err)
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// VerifyEmail uses up a live verification token and marks its user's email address verified,
+// as long as the address has not changed since the token was sent. It returns the username,
+// or an empty username when the token is unknown, expired or already used, or the address
+// has changed. The events are recorded in the audit log in the same transaction, with the
+// token's user as their actor and target.
+func (r *PostgresAuthRepository) VerifyEmail(tokenHash string, now time.Time, events ...*models.AuditEvent) (string, error) {
+	tx, err := r.conn.Begin(context.Background())
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	query, err := config.BuildUpdateQuery("email_verification_tokens", []string{"used_at"}, "token_hash = $2 AND used_at IS NULL AND expires_at > $1")
+	if err != nil {
+		return "", fmt.Errorf("failed to build update query: %v", err)
+	}
+	query = strings.TrimSuffix(query, ";") + " RETURNING username, email;"
+
+	var username, email string
+	if err := tx.QueryRow(context.Background(), query, now, tokenHash).Scan(&username, &email); err != nil {
+		if err == pgx.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to use verification token: %v", err)
+	}
+
+	query, err = config.BuildUpdateQuery("users", []string{"email_verified"}, "username = $2 AND email = $3")
+	if err != nil {
+		return "", fmt.Errorf("failed to build update query: %v", err)
+	}
+	tag, err := tx.Exec(context.Background(), query, true, username, email)
+	if err != nil {
+		return "", fmt.Errorf("failed to verify email address: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return "", nil
+	}
+
+	for _, event := range events {
+		event.Actor, event.Target = username, username
+	}
+	if err := recordAuditEvents(tx, events...); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return username, nil
+}
+
+// GetTwoFactor returns a user's TOTP enrollment, or nil if they have never started one.
+func (r *PostgresAuthRepository) GetTwoFactor(username string) (*models.TwoFactor, error) {
+	query, err := config.BuildSelectQuery([]string{"username", "secret", "enabled", "last_step"}, "user_two_factor", "username = $1")
+	if err != nil {
+		return nil, err
+	}
+
+	var twoFactor models.TwoFactor
+	err = r.conn.QueryRow(context.Background(), query, username).
+		Scan(&twoFactor.Username, &twoFactor.Secret, &twoFactor.Enabled, &twoFactor.LastStep)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to query two-factor settings: %v", err)
+	}
+
+	return &twoFactor, nil
+}
+
+// SaveTwoFactorSecret starts or restarts a pending enrollment with a new secret. An enabled
+// enrollment is left alone.
+func (r *PostgresAuthRepository) SaveTwoFactorSecret(username, secret string, now time.Time) error {
+	query, err := config.BuildInsertQuery("user_two_factor", []string{"username", "secret", "created_at"})
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %v", err)
+	}
+	query = strings.TrimSuffix(query, ";") + ` ON CONFLICT (username) DO UPDATE SET
+		secret = EXCLUDED.secret, last_step = 0, created_at = EXCLUDED.created_at
+		WHERE user_two_factor.enabled = FALSE;`
+
+	if _, err := r.conn.Exec(context.Background(), query, username, secret, now); err != nil {
+		return fmt.Errorf("failed to save two-factor secret: %v", err)
+	}
+
+	return nil
+}
+
+// EnableTwoFactor turns on a pending enrollment, recording the step of the code that
+// confirmed it, and replaces the user's recovery codes. The events are recorded in the audit
+// log in the same transaction.
+func (r *PostgresAuthRepository) EnableTwoFactor(username string, step int64, recoveryCodeHashes []string, now time.Time, events ...*models.AuditEvent) error {
+	tx, err := r.conn.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	query, err := config.BuildUpdateQuery("user_two_factor", []string{"enabled", "last_step", "enabled_at"}, "username = $4")
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %v", err)
+	}
+	if _, err := tx.Exec(context.Background(), query, true, step, now, username); err != nil {
+		return fmt.Errorf("failed to enable two-factor authentication: %v", err)
+	}
+
+	query, err = config.BuildDeleteQuery("recovery_codes", "username = $1")
+	if err != nil {
+		return fmt.Errorf("failed to build delete query: %v", err)
+	}
+	if _, err := tx.Exec(context.Background(), query, username); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %v", err)
+	}
+
+	query, err = config.BuildInsertQuery("recovery_codes", []string{"username", "code_hash"})
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %v", err)
+	}
+	for _, hash := range recoveryCodeHashes {
+		if _, err := tx.Exec(context.Background(), query, username, hash); err != nil {
+			return fmt.Errorf("failed to save recovery code: %v", err)
+		}
+	}
+
+	if err := recordAuditEvents(tx, events...); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// DisableTwoFactor removes a user's enrollment and recovery codes, and records the events in
+// the audit log in the same transaction.
+func (r *PostgresAuthRepository) DisableTwoFactor(username string, events ...*models.AuditEvent) error {
+	tx, err := r.conn.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	for _, table := range []string{"recovery_codes", "user_two_factor"} {
+		query, err := config.BuildDeleteQuery(table, "username = $1")
+		if err != nil {
+			return fmt.Errorf("failed to build delete query: %v", err)
+		}
+		if _, err := tx.Exec(context.Background(), query, username); err != nil {
+			return fmt.Errorf("failed to delete two-factor settings: %v", err)
+		}
+	}
+
+	if err := recordAuditEvents(tx, events...); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// UseTOTPStep records that a code from the given step has been used. It reports false if a
+// code from that step or a later one was already used, so each code works once.
+func (r *PostgresAuthRepository) UseTOTPStep(username string, step int64) (bool, error) {
+	query, err := config.BuildUpdateQuery("user_two_factor", []string{"last_step"}, "username = $2 AND enabled AND last_step < $1")
+	if err != nil {
+		return false, fmt.Errorf("failed to build update query: %v", err)
+	}
+
+	tag, err := r.conn.Exec(context.Background(), query, step, username)
+	if err != nil {
+		return false, fmt.Errorf("failed to record two-factor code: %v", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// UseRecoveryCode uses up one of a user's recovery codes. It reports false if the code is
+// unknown or already used. The events are recorded in the audit log, in the same
+// transaction, only when the code is used up.
+func (r *PostgresAuthRepository) UseRecoveryCode(username, codeHash string, now time.Time, events ...*models.AuditEvent) (bool, error) {
+	tx, err := r.conn.Begin(context.Background())
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	query, err := config.BuildUpdateQuery("recovery_codes", []string{"used_at"}, "username = $2 AND code_hash = $3 AND used_at IS NULL")
+	if err != nil {
+		return false, fmt.Errorf("failed to build update query: %v", err)
+	}
+
+	tag, err := tx.Exec(context.Background(), query, now, username, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %v", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return false, nil
+	}
+
+	if err := recordAuditEvents(tx, events...); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return true, nil
+}
+
+// IsTwoFactorRequired reports whether users with the role must use two-factor authentication.
+func (r *PostgresAuthRepository) IsTwoFactorRequired(role string) (bool, error) {
+	return isTwoFactorRequired(r.conn, role)
+}
+
+// isTwoFactorRequired reads the role setting. Roles without a setting do not require it.
+func isTwoFactorRequired(conn *pgx.Conn, role string) (bool, error) {
+	query, err := config.BuildSelectQuery([]string{"require_two_factor"}, "role_settings", "role = $1")
+	if err != nil {
+		return false, err
+	}
+
+	var required bool
+	if err := conn.QueryRow(context.Background(), query, role).Scan(&required); err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to query role settings: %v", err)
+	}
+
+	return required, nil
+}
@@ -234,6 +234,7 @@ package repositories
 
 import (
 	"context"
+	"cryptotracker/internal/alerts"
 	"cryptotracker/internal/api"
 	"cryptotracker/models"
 	"cryptotracker/pkg/config"
@@ -246,9 +247,18 @@ import (
 )
 
 type CryptoRepository interface {
+	GetUserRole(username string) (string, error)
 	DisplayTopCryptocurrencies(count int) ([]interface{}, error)
 	SearchCryptocurrency(user *models.User, cryptoSymbol string) (float64, string, string, error)
-	SetPriceAlert(user *models.User, symbol string, targetPrice float64) (float64, error)
+	SetPriceAlert(user *models.User, symbol string, targetPrice float64, options *models.AlertOptions) (float64, error)
+	SetRuleAlert(user *models.User, expression string, options *models.AlertOptions) (string, error)
+	SetTrailingAlert(user *models.User, symbol string, trailPercent float64, options *models.AlertOptions) (float64, error)
+	SetLadderAlert(user *models.User, symbol string, levels []float64, options *models.AlertOptions) (*models.AlertGroup, error)
+	SetVolumeSpikeAlert(user *models.User, symbol string, multiplier float64, options *models.AlertOptions) (float64, error)
+	SetRankAlert(user *models.User, symbol string, threshold int, options *models.AlertOptions) (int, error)
+	GetAlertGroups(username string) ([]*models.AlertGroup, error)
+	SetAlertGroupStatus(username string, groupID int, status string) error
+	DeleteAlertGroup(username string, groupID int) error
 }
 
 type PostgresCryptoRepository struct {
@@ -261,6 +271,24 @@ func NewPostgresCryptoRepository(conn *pgx.Conn) *PostgresCryptoRepository {
 	}
 }
 
+// GetUserRole returns the current role of a user. Deactivated and deleted users are not found.
+func (repo *PostgresCryptoRepository) GetUserRole(username string) (string, error) {
+	query, err := config.BuildSelectQuery([]string{"role"}, "users", "username = $1 AND status = 'active'")
+	if err != nil {
+		return "", err
+	}
+
+	var role string
+	if err := repo.conn.QueryRow(context.Background(), query, username).Scan(&role); err != nil {
+		if err == pgx.ErrNoRows {
+			return "", fmt.Errorf("user not found")
+		}
+		return "", fmt.Errorf("failed to query user role: %v", err)
+	}
+
+	return role, nil
+}
+
 func (repo *PostgresCryptoRepository) DisplayTopCryptocurrencies(count int) ([]interface{}, error) {
 	params := map[string]string{
 		"start":   "1",
@@ -282,6 +310,12 @@ func (repo *PostgresCryptoRepository) DisplayTopCryptocurrencies(count int) ([]i
 		return nil, fmt.Errorf("data not found in the response")
 	}
 
+	// Keep the listing as history for volume and rank alerts. This is best effort: a failed
+	// snapshot must not stop the listing from being shown.
+	if repo.conn != nil {
+		_ = saveMarketSnapshots(repo.conn, parseListingQuotes(data), time.Now())
+	}
+
 	return data, nil
 }
 
@@ -417,28 +451,99 @@ func CheckCryptocurrencyExists(symbol string) (bool, float64, error) {
 	return false, 0, nil
 }
 
-func (repo *PostgresCryptoRepository) SetPriceAlert(user *models.User, symbol string, targetPrice float64) (float64, error) {
-	exists, currentPrice, err := CheckCryptocurrencyExists(symbol)
-	if err != nil {
-		return 0, fmt.Errorf("error checking cryptocurrency: %v", err)
+// FetchLatestQuotes downloads the latest listings once and indexes them by upper-case symbol.
+func FetchLatestQuotes(limit int) (map[string]*models.Quote, error) {
+	params := map[string]string{
+		"start":   "1",
+		"limit":   strconv.Itoa(limit),
+		"convert": "USD",
+	}
+	apiResponse := api.CoinMarketCapClient{}
+	response := apiResponse.GetAPIResponse("/listings/latest", params)
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(response, &result); err != nil {
+		return nil, fmt.Errorf("error unmarshalling API response: %v", err)
+	}
+
+	data, ok := result["data"].([]interface{})
+	if !ok || len(data) == 0 {
+		return nil, fmt.Errorf("data not found or empty in the response")
+	}
+
+	return parseListingQuotes(data), nil
+}
+
+// parseListingQuotes indexes a listings payload by upper-case symbol, skipping malformed entries.
+func parseListingQuotes(data []interface{}) map[string]*models.Quote {
+	quotes := make(map[string]*models.Quote, len(data))
+	for _, item := range data {
+		crypto, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		symbol, ok := crypto["symbol"].(string)
+		if !ok {
+			continue
+		}
+
+		quote, ok := crypto["quote"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		usd, ok := quote["USD"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		q := &models.Quote{Symbol: strings.ToUpper(symbol)}
+		if id, ok := crypto["id"].(float64); ok {
+			q.ID = int(id)
+		}
+		if name, ok := crypto["name"].(string); ok {
+			q.Name = name
+		}
+		if rank, ok := crypto["cmc_rank"].(float64); ok {
+			q.CMCRank = int(rank)
+		}
+		q.Price, _ = usd["price"].(float64)
+		q.PercentChange24h, _ = usd["percent_change_24h"].(float64)
+		q.Volume24h, _ = usd["volume_24h"].(float64)
+		q.MarketCap, _ = usd["market_cap"].(float64)
+
+		quotes[q.Symbol] = q
+	}
+
+	return quotes
+}
+
+func (repo *PostgresCryptoRepository) SetPriceAlert(user *models.User, symbol string, targetPrice float64, options *models.AlertOptions) (float64, error) {
+	if err := alerts.ValidateOptions(options); err != nil {
+		return 0, fmt.Errorf("invalid alert options: %v", err)
 	}
 
-	if !exists {
-		return 0, fmt.Errorf("cryptocurrency with symbol %s does not exist", symbol)
+	quote, err := repo.cachedQuote(symbol)
+	if err != nil {
+		return 0, err
 	}
+	currentPrice := quote.Price
 
 	if currentPrice >= targetPrice {
 		return currentPrice, fmt.Errorf("alert: %s has reached your target price of $%.2f. Current price: $%.2f", symbol, targetPrice, currentPrice)
 	}
 
 	notification := &models.PriceNotification{
+		Kind:        "price",
 		CryptoID:    0, // We don't have the ID anymore, consider removing this field if not needed
 		Crypto:      symbol,
 		TargetPrice: targetPrice,
+		Direction:   "above",
 		Username:    user.Username,
 		AskedAt:     time.Now().Format(time.RFC3339),
 		Status:      "Pending",
 	}
+	applyAlertOptions(notification, options)
 
 	notificationRepository := NewPostgresNotificationRepository(repo.conn)
 	if err := notificationRepository.SavePriceNotification(repo.conn, notification); err != nil {
@@ -448,6 +553,382 @@ func (repo *PostgresCryptoRepository) SetPriceAlert(user *models.User, symbol st
 	return currentPrice, fmt.Errorf("%s is still below your target price. Current price: $%.2f. Notification created.", symbol, currentPrice)
 }
 
+// SetRuleAlert validates a compound alert expression against the current listings and stores it.
+// It returns the rule in canonical form.
+func (repo *PostgresCryptoRepository) SetRuleAlert(user *models.User, expression string, options *models.AlertOptions) (string, error) {
+	if err := alerts.ValidateOptions(options); err != nil {
+		return "", fmt.Errorf("invalid alert options: %v", err)
+	}
+	if options != nil && options.HysteresisPercent > 0 {
+		return "", fmt.Errorf("invalid alert options: hysteresis only applies to target price alerts")
+	}
+
+	rule, err := alerts.ParseRule(expression)
+	if err != nil {
+		return "", fmt.Errorf("invalid rule: %v", err)
+	}
+
+	quotes, err := cachedQuotes(repo.conn, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("error fetching quotes: %v", err)
+	}
+
+	for _, symbol := range rule.Symbols() {
+		if _, ok := quotes[symbol]; !ok {
+			return "", fmt.Errorf("invalid rule: cryptocurrency with symbol %s does not exist", symbol)
+		}
+	}
+
+	// Averages come from the same stored snapshots the evaluator uses, so "Nx avg" rules are
+	// checked here as they will be when they fire
+	market := alerts.NewQuoteMarket(quotes)
+	averages, err := trailingAverages(repo.conn, rule.Symbols(), time.Now().Add(-alerts.AverageWindow))
+	if err != nil {
+		return "", fmt.Errorf("error fetching averages: %v", err)
+	}
+	market.Averages = alerts.AveragesFrom(averages)
+
+	if rule.Eval(market) {
+		return rule.String(), fmt.Errorf("alert: %s already holds", rule)
+	}
+
+	notification := &models.PriceNotification{
+		Kind:     "rule",
+		Rule:     rule.String(),
+		Crypto:   strings.Join(rule.Symbols(), ","),
+		Username: user.Username,
+		AskedAt:  time.Now().Format(time.RFC3339),
+		Status:   "Pending",
+	}
+	applyAlertOptions(notification, options)
+
+	notificationRepository := NewPostgresNotificationRepository(repo.conn)
+	if err := notificationRepository.SavePriceNotification(repo.conn, notification); err != nil {
+		return "", fmt.Errorf("error saving notification: %v", err)
+	}
+
+	return rule.String(), nil
+}
+
+// SetTrailingAlert stores an alert that fires once the price drops trailPercent below the highest
+// price seen since it was set. It returns the current price, which becomes the first high-water mark.
+func (repo *PostgresCryptoRepository) SetTrailingAlert(user *models.User, symbol string, trailPercent float64, options *models.AlertOptions) (float64, error) {
+	if err := alerts.ValidateTrailPercent(trailPercent); err != nil {
+		return 0, fmt.Errorf("invalid trailing alert: %v", err)
+	}
+	if err := alerts.ValidateOptions(options); err != nil {
+		return 0, fmt.Errorf("invalid alert options: %v", err)
+	}
+	if options != nil && options.HysteresisPercent > 0 {
+		return 0, fmt.Errorf("invalid alert options: hysteresis only applies to target price alerts")
+	}
+
+	quote, err := repo.cachedQuote(symbol)
+	if err != nil {
+		return 0, err
+	}
+	currentPrice := quote.Price
+
+	notification := &models.PriceNotification{
+		Kind:          "trailing",
+		Crypto:        strings.ToUpper(symbol),
+		Username:      user.Username,
+		AskedAt:       time.Now().Format(time.RFC3339),
+		Status:        "Pending",
+		TrailPercent:  trailPercent,
+		HighWaterMark: currentPrice,
+	}
+	applyAlertOptions(notification, options)
+
+	notificationRepository := NewPostgresNotificationRepository(repo.conn)
+	if err := notificationRepository.SavePriceNotification(repo.conn, notification); err != nil {
+		return 0, fmt.Errorf("error saving notification: %v", err)
+	}
+
+	return currentPrice, nil
+}
+
+// SetVolumeSpikeAlert stores an alert that fires once 24h volume exceeds multiplier times its
+// trailing average over the stored snapshots. It returns the current 24h volume.
+func (repo *PostgresCryptoRepository) SetVolumeSpikeAlert(user *models.User, symbol string, multiplier float64, options *models.AlertOptions) (float64, error) {
+	if err := alerts.ValidateVolumeMultiplier(multiplier); err != nil {
+		return 0, fmt.Errorf("invalid volume spike alert: %v", err)
+	}
+	if err := alerts.ValidateOptions(options); err != nil {
+		return 0, fmt.Errorf("invalid alert options: %v", err)
+	}
+	if options != nil && options.HysteresisPercent > 0 {
+		return 0, fmt.Errorf("invalid alert options: hysteresis only applies to target price alerts")
+	}
+
+	quote, err := repo.cachedQuote(symbol)
+	if err != nil {
+		return 0, err
+	}
+
+	notification := &models.PriceNotification{
+		Kind:             "volume_spike",
+		Crypto:           quote.Symbol,
+		Username:         user.Username,
+		AskedAt:          time.Now().Format(time.RFC3339),
+		Status:           "Pending",
+		VolumeMultiplier: multiplier,
+	}
+	applyAlertOptions(notification, options)
+
+	notificationRepository := NewPostgresNotificationRepository(repo.conn)
+	if err := notificationRepository.SavePriceNotification(repo.conn, notification); err != nil {
+		return 0, fmt.Errorf("error saving notification: %v", err)
+	}
+
+	return quote.Volume24h, nil
+}
+
+// SetRankAlert stores an alert on a coin's market-cap rank crossing threshold. A coin currently
+// outside the top threshold fires when it enters it; a coin inside fires when it drops out.
+// It returns the current rank.
+func (repo *PostgresCryptoRepository) SetRankAlert(user *models.User, symbol string, threshold int, options *models.AlertOptions) (int, error) {
+	if err := alerts.ValidateRankThreshold(threshold); err != nil {
+		return 0, fmt.Errorf("invalid rank alert: %v", err)
+	}
+	if err := alerts.ValidateOptions(options); err != nil {
+		return 0, fmt.Errorf("invalid alert options: %v", err)
+	}
+	if options != nil && options.HysteresisPercent > 0 {
+		return 0, fmt.Errorf("invalid alert options: hysteresis only applies to target price alerts")
+	}
+
+	quote, err := repo.cachedQuote(symbol)
+	if err != nil {
+		return 0, err
+	}
+
+	notification := &models.PriceNotification{
+		Kind:          "rank",
+		Crypto:        quote.Symbol,
+		TargetPrice:   float64(threshold),
+		Direction:     alerts.RankDirection(threshold, quote.CMCRank),
+		Username:      user.Username,
+		AskedAt:       time.Now().Format(time.RFC3339),
+		Status:        "Pending",
+		RankThreshold: threshold,
+	}
+	applyAlertOptions(notification, options)
+
+	notificationRepository := NewPostgresNotificationRepository(repo.conn)
+	if err := notificationRepository.SavePriceNotification(repo.conn, notification); err != nil {
+		return 0, fmt.Errorf("error saving notification: %v", err)
+	}
+
+	return quote.CMCRank, nil
+}
+
+// SetLadderAlert creates a group of child price alerts, one per level, from a single price lookup.
+// Levels above the current price fire on the way up and levels below it fire on the way down.
+func (repo *PostgresCryptoRepository) SetLadderAlert(user *models.User, symbol string, levels []float64, options *models.AlertOptions) (*models.AlertGroup, error) {
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("invalid ladder: no levels given")
+	}
+	if len(levels) > alerts.MaxLadderLevels {
+		return nil, fmt.Errorf("invalid ladder: at most %d levels are allowed", alerts.MaxLadderLevels)
+	}
+	if err := alerts.ValidateOptions(options); err != nil {
+		return nil, fmt.Errorf("invalid alert options: %v", err)
+	}
+
+	quote, err := repo.cachedQuote(symbol)
+	if err != nil {
+		return nil, err
+	}
+	currentPrice := quote.Price
+
+	ctx := context.Background()
+	tx, err := repo.conn.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	group := &models.AlertGroup{
+		Username:  user.Username,
+		Crypto:    strings.ToUpper(symbol),
+		Kind:      "ladder",
+		Status:    "Active",
+		CreatedAt: time.Now(),
+	}
+
+	groupQuery, err := config.BuildInsertQuery("alert_groups", []string{"username", "crypto", "kind", "status", "created_at"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build insert query: %v", err)
+	}
+	groupQuery = strings.TrimSuffix(groupQuery, ";") + " RETURNING id;"
+
+	err = tx.QueryRow(ctx, groupQuery, group.Username, group.Crypto, group.Kind, group.Status, group.CreatedAt).Scan(&group.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save alert group: %v", err)
+	}
+
+	for _, level := range levels {
+		notification := models.PriceNotification{
+			Kind:        "price",
+			Crypto:      group.Crypto,
+			TargetPrice: level,
+			Direction:   alerts.LevelDirection(level, currentPrice),
+			Username:    user.Username,
+			AskedAt:     group.CreatedAt.Format(time.RFC3339),
+			Status:      "Pending",
+			GroupID:     &group.ID,
+		}
+		applyAlertOptions(&notification, options)
+
+		var query string
+		var args []interface{}
+		query, args, err = priceNotificationInsert(&notification)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err = tx.Exec(ctx, query, args...); err != nil {
+			return nil, fmt.Errorf("failed to save ladder level %.2f: %v", level, err)
+		}
+		group.Alerts = append(group.Alerts, notification)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit ladder alert: %v", err)
+	}
+	return group, nil
+}
+
+// GetAlertGroups returns the user's alert groups together with their child alerts.
+func (repo *PostgresCryptoRepository) GetAlertGroups(username string) ([]*models.AlertGroup, error) {
+	query, err := config.BuildSelectQuery([]string{"id", "username", "crypto", "kind", "status", "created_at"}, "alert_groups", "username = $1 ORDER BY created_at")
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %v", err)
+	}
+
+	rows, err := repo.conn.Query(context.Background(), query, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert groups: %v", err)
+	}
+	defer rows.Close()
+
+	var groups []*models.AlertGroup
+	byID := map[int]*models.AlertGroup{}
+	for rows.Next() {
+		var group models.AlertGroup
+		if err := rows.Scan(&group.ID, &group.Username, &group.Crypto, &group.Kind, &group.Status, &group.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan alert group: %v", err)
+		}
+		groups = append(groups, &group)
+		byID[group.ID] = &group
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %v", err)
+	}
+
+	if len(groups) == 0 {
+		return groups, nil
+	}
+
+	childQuery, err := config.BuildSelectQuery([]string{"id", "group_id", "crypto", "target_price", "direction", "status", "trigger_count"}, "price_notifications", "username = $1 AND group_id IS NOT NULL ORDER BY target_price")
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %v", err)
+	}
+
+	childRows, err := repo.conn.Query(context.Background(), childQuery, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ladder levels: %v", err)
+	}
+	defer childRows.Close()
+
+	for childRows.Next() {
+		notification := models.PriceNotification{Username: username, Kind: "price"}
+		if err := childRows.Scan(&notification.ID, &notification.GroupID, &notification.Crypto, &notification.TargetPrice, &notification.Direction, &notification.Status, &notification.TriggerCount); err != nil {
+			return nil, fmt.Errorf("failed to scan ladder level: %v", err)
+		}
+		if group, ok := byID[*notification.GroupID]; ok {
+			group.Alerts = append(group.Alerts, notification)
+		}
+	}
+
+	if err := childRows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %v", err)
+	}
+
+	return groups, nil
+}
+
+// SetAlertGroupStatus pauses or resumes every alert in one of the user's groups.
+func (repo *PostgresCryptoRepository) SetAlertGroupStatus(username string, groupID int, status string) error {
+	if status != "Active" && status != "Paused" {
+		return fmt.Errorf("invalid group status %q: must be Active or Paused", status)
+	}
+
+	query, err := config.BuildUpdateQuery("alert_groups", []string{"status"}, "id = $2 AND username = $3")
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %v", err)
+	}
+
+	tag, err := repo.conn.Exec(context.Background(), query, status, groupID, username)
+	if err != nil {
+		return fmt.Errorf("failed to update alert group: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("alert group not found")
+	}
+
+	return nil
+}
+
+// DeleteAlertGroup removes one of the user's groups; its child alerts are removed with it.
+func (repo *PostgresCryptoRepository) DeleteAlertGroup(username string, groupID int) error {
+	query, err := config.BuildDeleteQuery("alert_groups", "id = $1 AND username = $2")
+	if err != nil {
+		return fmt.Errorf("failed to build delete query: %v", err)
+	}
+
+	tag, err := repo.conn.Exec(context.Background(), query, groupID, username)
+	if err != nil {
+		return fmt.Errorf("failed to delete alert group: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("alert group not found")
+	}
+
+	return nil
+}
+
+// applyAlertOptions copies the optional settings of a new alert onto it.
+// cachedQuote looks the symbol up in the listings batch shared through quote_cache, so creating
+// alerts does not add API calls.
+func (repo *PostgresCryptoRepository) cachedQuote(symbol string) (*models.Quote, error) {
+	quotes, err := cachedQuotes(repo.conn, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("error fetching quotes: %v", err)
+	}
+
+	quote, ok := quotes[strings.ToUpper(symbol)]
+	if !ok {
+		return nil, fmt.Errorf("cryptocurrency with symbol %s does not exist", symbol)
+	}
+	return quote, nil
+}
+
+func applyAlertOptions(notification *models.PriceNotification, options *models.AlertOptions) {
+	if options == nil {
+		return
+	}
+	notification.Recurring = options.Recurring
+	notification.CooldownSeconds = options.CooldownSeconds
+	notification.HysteresisPercent = options.HysteresisPercent
+	notification.ExpiresAt = options.ExpiresAt
+	notification.ActiveFrom = options.ActiveFrom
+	notification.ActiveTo = options.ActiveTo
+}
+
 func (repo *PostgresCryptoRepository) SaveUnavailableCryptoRequest(conn *pgx.Conn, request *models.UnavailableCryptoRequest) error {
 	columns := []string{"crypto_symbol", "username", "request_message", "status", "timestamp"}
 	query, err := config.BuildInsertQuery("unavailable_cryptos", columns)
@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"context"
+	"cryptotracker/models"
+	"cryptotracker/pkg/config"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"strings"
+	"time"
+)
+
+type DeliveryRepository interface {
+	ClaimDueDeliveries(now, leaseUntil time.Time, limit int) ([]*models.NotificationDelivery, error)
+	UpdateDelivery(delivery *models.NotificationDelivery) error
+	CountSentDeliveries(username string, since time.Time) (int, error)
+	GetRecipient(username string) (*models.User, error)
+	GetFailedDeliveries(limit int) ([]*models.NotificationDelivery, error)
+	RequeueDelivery(id int, now time.Time) error
+}
+
+type PostgresDeliveryRepository struct {
+	conn *pgx.Conn
+}
+
+func NewPostgresDeliveryRepository(conn *pgx.Conn) *PostgresDeliveryRepository {
+	return &PostgresDeliveryRepository{conn: conn}
+}
+
+var deliveryColumns = []string{"id", "username", "event_type", "channel", "subject", "body", "status", "error", "attempts", "max_attempts", "next_attempt_at", "created_at", "sent_at"}
+
+func scanDeliveries(rows pgx.Rows) ([]*models.NotificationDelivery, error) {
+	defer rows.Close()
+
+	var deliveries []*models.NotificationDelivery
+	for rows.Next() {
+		var d models.NotificationDelivery
+		err := rows.Scan(
+			&d.ID,
+			&d.Username,
+			&d.EventType,
+			&d.Channel,
+			&d.Subject,
+			&d.Body,
+			&d.Status,
+			&d.Error,
+			&d.Attempts,
+			&d.MaxAttempts,
+			&d.NextAttemptAt,
+			&d.CreatedAt,
+			&d.SentAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification delivery: %v", err)
+		}
+		deliveries = append(deliveries, &d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %v", err)
+	}
+
+	return deliveries, nil
+}
+
+// ClaimDueDeliveries marks up to limit due deliveries as sending and returns them. Deliveries
+// left sending by a worker that stopped are due again once their lease has run out. Rows
+// another worker has locked are skipped, so several workers can share the queue.
+func (r *PostgresDeliveryRepository) ClaimDueDeliveries(now, leaseUntil time.Time, limit int) ([]*models.NotificationDelivery, error) {
+	condition := "id IN (SELECT id FROM notification_deliveries WHERE status IN ('pending', 'sending') AND next_attempt_at <= $3 ORDER BY next_attempt_at LIMIT $4 FOR UPDATE SKIP LOCKED)"
+	query, err := config.BuildUpdateQuery("notification_deliveries", []string{"status", "next_attempt_at"}, condition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build update query: %v", err)
+	}
+	query = strings.TrimSuffix(query, ";") + " RETURNING " + strings.Join(deliveryColumns, ", ") + ";"
+
+	rows, err := r.conn.Query(context.Background(), query, "sending", leaseUntil, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim notification deliveries: %v", err)
+	}
+
+	return scanDeliveries(rows)
+}
+
+// UpdateDelivery saves the outcome of a delivery attempt.
+func (r *PostgresDeliveryRepository) UpdateDelivery(delivery *models.NotificationDelivery) error {
+	query, err := config.BuildUpdateQuery("notification_deliveries", []string{"status", "error", "attempts", "next_attempt_at", "sent_at"}, "id = $6")
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %v", err)
+	}
+
+	_, err = r.conn.Exec(context.Background(), query,
+		delivery.Status,
+		delivery.Error,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.SentAt,
+		delivery.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update notification delivery: %v", err)
+	}
+
+	return nil
+}
+
+// CountSentDeliveries counts the outbound notifications sent to the user since the given time.
+func (r *PostgresDeliveryRepository) CountSentDeliveries(username string, since time.Time) (int, error) {
+	query, err := config.BuildSelectQuery([]string{"COUNT(*)"}, "notification_deliveries", "username = $1 AND status = 'sent' AND sent_at >= $2")
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := r.conn.QueryRow(context.Background(), query, username, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count notification deliveries: %v", err)
+	}
+
+	return count, nil
+}
+
+// GetRecipient returns the contact details, timezone and notification preferences of a user.
+func (r *PostgresDeliveryRepository) GetRecipient(username string) (*models.User, error) {
+	return notificationProfile(r.conn, username)
+}
+
+// failedDelivery matches dead deliveries and those waiting to retry after a failed attempt.
+const failedDelivery = "(status = 'dead' OR (status = 'pending' AND attempts > 0))"
+
+// GetFailedDeliveries returns the most recent failed deliveries.
+func (r *PostgresDeliveryRepository) GetFailedDeliveries(limit int) ([]*models.NotificationDelivery, error) {
+	query, err := config.BuildSelectQuery(deliveryColumns, "notification_deliveries", failedDelivery+" ORDER BY created_at DESC LIMIT $1")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.Query(context.Background(), query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notification deliveries: %v", err)
+	}
+
+	return scanDeliveries(rows)
+}
+
+// RequeueDelivery gives a dead delivery, or one waiting to retry, a fresh set of attempts
+// starting now.
+func (r *PostgresDeliveryRepository) RequeueDelivery(id int, now time.Time) error {
+	condition := "id = $5 AND " + failedDelivery
+	query, err := config.BuildUpdateQuery("notification_deliveries", []string{"status", "error", "attempts", "next_attempt_at"}, condition)
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %v", err)
+	}
+
+	tag, err := r.conn.Exec(context.Background(), query, "pending", "", 0, now, id)
+	if err != nil {
+		return fmt.Errorf("failed to requeue notification delivery: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("failed delivery %d not found", id)
+	}
+
+	return nil
+}
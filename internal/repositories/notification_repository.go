@@ -6,6 +6,8 @@ import (
 	"cryptotracker/pkg/config"
 	"fmt"
 	"github.com/jackc/pgx/v4"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -13,6 +15,25 @@ type NotificationRepository interface {
 	CheckUnavailableCryptoRequestsRepo(username string) (pgx.Rows, error)
 	CheckPriceAlertsRepo(username string) ([]models.PriceNotification, error)
 	UpdatePriceNotificationStatusRepo(notification *models.PriceNotification, username string, currentPrice float64) error
+	RearmPriceAlertRepo(notificationID int) error
+	GetAlertOccurrencesRepo(username string) ([]models.AlertOccurrence, error)
+	GetLatestQuotesRepo() (map[string]*models.Quote, error)
+	ExpirePriceAlertsRepo(username string, now time.Time) error
+	GetUserTimezoneRepo(username string) (string, error)
+	GetUndeliveredOccurrencesRepo(username string) ([]models.AlertOccurrence, error)
+	MarkOccurrencesDeliveredRepo(occurrenceIDs []int, deliveredAt time.Time) error
+	UpdateHighWaterMarkRepo(notificationID int, highWaterMark float64) error
+	SaveMarketSnapshotsRepo(quotes map[string]*models.Quote, now time.Time) error
+	GetTrailingAveragesRepo(symbols []string, since time.Time) (map[string]models.MarketAverage, error)
+	GetCryptoRequestsRepo(username string) ([]*models.UnavailableCryptoRequest, error)
+	GetDigestSettingsRepo(username string) (*models.DigestSettings, error)
+	SaveDigestSettingsRepo(settings *models.DigestSettings) error
+	GetScheduledDigestsRepo() ([]*models.DigestSettings, error)
+	SaveDigestRepo(username, body string, createdAt time.Time) error
+	MarkDigestSentRepo(username string, sentAt time.Time) error
+	TakeUndeliveredDigestsRepo(username string, deliveredAt time.Time) ([]string, error)
+	GetNotificationProfileRepo(username string) (*models.User, error)
+	EnqueueDeliveryRepo(delivery *models.NotificationDelivery) error
 }
 
 type PostgresNotificationRepository struct {
@@ -41,8 +62,11 @@ func (r *PostgresNotificationRepository) CheckUnavailableCryptoRequestsRepo(user
 }
 
 func (r *PostgresNotificationRepository) CheckPriceAlertsRepo(username string) ([]models.PriceNotification, error) {
-	columns := []string{"crypto_id", "crypto", "target_price"}
-	condition := "username = $1 AND status = 'Pending'"
+	columns := []string{"id", "kind", "rule", "crypto_id", "crypto", "target_price", "status", "recurring", "cooldown_seconds", "hysteresis_percent", "last_triggered_at", "trigger_count", "expires_at", "active_from", "active_to", "trail_percent", "high_water_mark", "direction", "group_id", "volume_multiplier", "rank_threshold"}
+	// Children of a paused group stay untouched until the group is resumed, and so do the
+	// alerts of deactivated and deleted users until they are restored
+	condition := "username = $1 AND status IN ('Pending', 'Rearming') AND (group_id IS NULL OR group_id NOT IN (SELECT id FROM alert_groups WHERE status = 'Paused'))" +
+		" AND username IN (SELECT username FROM users WHERE status = 'active')"
 
 	query, err := config.BuildSelectQuery(columns, "price_notifications", condition)
 	if err != nil {
@@ -57,8 +81,30 @@ func (r *PostgresNotificationRepository) CheckPriceAlertsRepo(username string) (
 
 	var notifications []models.PriceNotification
 	for rows.Next() {
-		var notification models.PriceNotification
-		err := rows.Scan(&notification.CryptoID, &notification.Crypto, &notification.TargetPrice)
+		notification := models.PriceNotification{Username: username}
+		err := rows.Scan(
+			&notification.ID,
+			&notification.Kind,
+			&notification.Rule,
+			&notification.CryptoID,
+			&notification.Crypto,
+			&notification.TargetPrice,
+			&notification.Status,
+			&notification.Recurring,
+			&notification.CooldownSeconds,
+			&notification.HysteresisPercent,
+			&notification.LastTriggeredAt,
+			&notification.TriggerCount,
+			&notification.ExpiresAt,
+			&notification.ActiveFrom,
+			&notification.ActiveTo,
+			&notification.TrailPercent,
+			&notification.HighWaterMark,
+			&notification.Direction,
+			&notification.GroupID,
+			&notification.VolumeMultiplier,
+			&notification.RankThreshold,
+		)
 		if err != nil {
 			return nil, err
 		}
@@ -72,33 +118,492 @@ func (r *PostgresNotificationRepository) CheckPriceAlertsRepo(username string) (
 	return notifications, nil
 }
 
+// UpdatePriceNotificationStatusRepo records that an alert fired. One-shot alerts move to Served,
+// recurring alerts move to Rearming, and every firing is stored as a separate occurrence. An
+// alert that is no longer Pending, because another check fired it first, records nothing.
 func (r *PostgresNotificationRepository) UpdatePriceNotificationStatusRepo(notification *models.PriceNotification, username string, cryptoPrice float64) error {
-	// Only update if the crypto price meets or exceeds the target price
-	if cryptoPrice >= notification.TargetPrice {
-		// Update the status and served time
-		notification.Status = "Served"
-		notification.ServedAt = time.Now().Format(time.RFC3339)
-
-		// Build the update query
-		updateColumns := []string{"status", "served_at"}
-		// Add condition to check for the correct notification using crypto_id, username, and price
-		updateCondition := "crypto_id = $3 AND username = $4 AND target_price <= $5 AND status = 'Pending'"
-
-		// Generate the query
-		updateQuery, err := config.BuildUpdateQuery("price_notifications", updateColumns, updateCondition)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	notification.Status = "Served"
+	if notification.Recurring {
+		notification.Status = "Rearming"
+	}
+	notification.ServedAt = now.Format(time.RFC3339)
+	notification.LastTriggeredAt = &now
+	notification.TriggerCount++
+
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	updateColumns := []string{"status", "served_at", "last_triggered_at", "trigger_count"}
+	updateCondition := "id = $5 AND username = $6 AND status = 'Pending'"
+
+	updateQuery, err := config.BuildUpdateQuery("price_notifications", updateColumns, updateCondition)
+	if err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, updateQuery, notification.Status, notification.ServedAt, notification.LastTriggeredAt, notification.TriggerCount, notification.ID, username)
+	if err != nil {
+		return fmt.Errorf("failed to update price notification: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil
+	}
+
+	insertColumns := []string{"notification_id", "username", "crypto", "target_price", "triggered_price", "triggered_at"}
+	insertQuery, err := config.BuildInsertQuery("price_alert_occurrences", insertColumns)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, insertQuery, notification.ID, username, notification.Crypto, notification.TargetPrice, cryptoPrice, now)
+	if err != nil {
+		return fmt.Errorf("failed to record alert occurrence: %v", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit alert occurrence: %v", err)
+	}
+	return nil
+}
+
+// RearmPriceAlertRepo puts a recurring alert back into the Pending state.
+func (r *PostgresNotificationRepository) RearmPriceAlertRepo(notificationID int) error {
+	query, err := config.BuildUpdateQuery("price_notifications", []string{"status"}, "id = $2 AND status = 'Rearming'")
+	if err != nil {
+		return err
+	}
+
+	_, err = r.conn.Exec(context.Background(), query, "Pending", notificationID)
+	if err != nil {
+		return fmt.Errorf("failed to re-arm price notification: %v", err)
+	}
+
+	return nil
+}
+
+// GetAlertOccurrencesRepo returns every recorded firing of the user's alerts, newest first.
+func (r *PostgresNotificationRepository) GetAlertOccurrencesRepo(username string) ([]models.AlertOccurrence, error) {
+	columns := []string{"o.id", "o.notification_id", "o.username", "o.crypto", "o.target_price", "o.triggered_price", "o.triggered_at", "o.delivered_at", "n.kind", "n.rule"}
+	table := "price_alert_occurrences o JOIN price_notifications n ON n.id = o.notification_id"
+	query, err := config.BuildSelectQuery(columns, table, "o.username = $1 ORDER BY o.triggered_at DESC")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.Query(context.Background(), query, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert occurrences: %v", err)
+	}
+	defer rows.Close()
+
+	var occurrences []models.AlertOccurrence
+	for rows.Next() {
+		var occurrence models.AlertOccurrence
+		err := rows.Scan(
+			&occurrence.ID,
+			&occurrence.NotificationID,
+			&occurrence.Username,
+			&occurrence.Crypto,
+			&occurrence.TargetPrice,
+			&occurrence.TriggeredPrice,
+			&occurrence.TriggeredAt,
+			&occurrence.DeliveredAt,
+			&occurrence.Kind,
+			&occurrence.Rule,
+		)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("failed to scan alert occurrence: %v", err)
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %v", err)
+	}
+
+	return occurrences, nil
+}
+
+// ExpirePriceAlertsRepo moves the user's active alerts whose expiry has passed to Expired.
+func (r *PostgresNotificationRepository) ExpirePriceAlertsRepo(username string, now time.Time) error {
+	condition := "username = $2 AND status IN ('Pending', 'Rearming') AND expires_at IS NOT NULL AND expires_at <= $3"
+	query, err := config.BuildUpdateQuery("price_notifications", []string{"status"}, condition)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.conn.Exec(context.Background(), query, "Expired", username, now)
+	if err != nil {
+		return fmt.Errorf("failed to expire price notifications: %v", err)
+	}
+
+	return nil
+}
+
+// GetUserTimezoneRepo returns the IANA timezone stored for the user.
+func (r *PostgresNotificationRepository) GetUserTimezoneRepo(username string) (string, error) {
+	query, err := config.BuildSelectQuery([]string{"timezone"}, "users", "username = $1")
+	if err != nil {
+		return "", err
+	}
+
+	var timezone string
+	err = r.conn.QueryRow(context.Background(), query, username).Scan(&timezone)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", fmt.Errorf("user not found")
+		}
+		return "", fmt.Errorf("failed to query user timezone: %v", err)
+	}
+
+	return timezone, nil
+}
+
+// GetUndeliveredOccurrencesRepo returns alert firings that have not been shown to the user yet,
+// together with the active window of the alert that produced them.
+func (r *PostgresNotificationRepository) GetUndeliveredOccurrencesRepo(username string) ([]models.AlertOccurrence, error) {
+	columns := []string{"o.id", "o.notification_id", "o.username", "o.crypto", "o.target_price", "o.triggered_price", "o.triggered_at", "n.kind", "n.rule", "n.active_from", "n.active_to"}
+	table := "price_alert_occurrences o JOIN price_notifications n ON n.id = o.notification_id"
+	query, err := config.BuildSelectQuery(columns, table, "o.username = $1 AND o.delivered_at IS NULL ORDER BY o.triggered_at")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.Query(context.Background(), query, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query held alert occurrences: %v", err)
+	}
+	defer rows.Close()
+
+	var occurrences []models.AlertOccurrence
+	for rows.Next() {
+		var occurrence models.AlertOccurrence
+		err := rows.Scan(
+			&occurrence.ID,
+			&occurrence.NotificationID,
+			&occurrence.Username,
+			&occurrence.Crypto,
+			&occurrence.TargetPrice,
+			&occurrence.TriggeredPrice,
+			&occurrence.TriggeredAt,
+			&occurrence.Kind,
+			&occurrence.Rule,
+			&occurrence.ActiveFrom,
+			&occurrence.ActiveTo,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan alert occurrence: %v", err)
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %v", err)
+	}
+
+	return occurrences, nil
+}
+
+// MarkOccurrencesDeliveredRepo stamps the given occurrences as shown to the user.
+func (r *PostgresNotificationRepository) MarkOccurrencesDeliveredRepo(occurrenceIDs []int, deliveredAt time.Time) error {
+	if len(occurrenceIDs) == 0 {
+		return nil
+	}
+
+	query, err := config.BuildUpdateQuery("price_alert_occurrences", []string{"delivered_at"}, "id = ANY($2)")
+	if err != nil {
+		return err
+	}
+
+	_, err = r.conn.Exec(context.Background(), query, deliveredAt, occurrenceIDs)
+	if err != nil {
+		return fmt.Errorf("failed to mark alert occurrences delivered: %v", err)
+	}
+
+	return nil
+}
+
+// UpdateHighWaterMarkRepo persists the highest price a trailing alert has seen.
+func (r *PostgresNotificationRepository) UpdateHighWaterMarkRepo(notificationID int, highWaterMark float64) error {
+	query, err := config.BuildUpdateQuery("price_notifications", []string{"high_water_mark"}, "id = $2")
+	if err != nil {
+		return err
+	}
+
+	_, err = r.conn.Exec(context.Background(), query, highWaterMark, notificationID)
+	if err != nil {
+		return fmt.Errorf("failed to update high-water mark: %v", err)
+	}
+
+	return nil
+}
+
+// GetLatestQuotesRepo returns one batch of listings for evaluating alerts. The batch is shared
+// by every session through quote_cache, so checking alerts often does not add API calls.
+func (r *PostgresNotificationRepository) GetLatestQuotesRepo() (map[string]*models.Quote, error) {
+	return cachedQuotes(r.conn, time.Now())
+}
+
+// SaveMarketSnapshotsRepo stores a batch of listings for computing trailing averages later.
+func (r *PostgresNotificationRepository) SaveMarketSnapshotsRepo(quotes map[string]*models.Quote, now time.Time) error {
+	return saveMarketSnapshots(r.conn, quotes, now)
+}
+
+// GetTrailingAveragesRepo returns the averages of the stored snapshots of each symbol since the given time.
+func (r *PostgresNotificationRepository) GetTrailingAveragesRepo(symbols []string, since time.Time) (map[string]models.MarketAverage, error) {
+	return trailingAverages(r.conn, symbols, since)
+}
+
+// GetCryptoRequestsRepo returns all of the user's requests to add a cryptocurrency, newest first.
+func (r *PostgresNotificationRepository) GetCryptoRequestsRepo(username string) ([]*models.UnavailableCryptoRequest, error) {
+	columns := []string{"crypto_symbol", "username", "request_message", "status", "timestamp"}
+	query, err := config.BuildSelectQuery(columns, "unavailable_cryptos", "username = $1 ORDER BY timestamp DESC")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.Query(context.Background(), query, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query crypto requests: %v", err)
+	}
+	defer rows.Close()
+
+	var requests []*models.UnavailableCryptoRequest
+	for rows.Next() {
+		var request models.UnavailableCryptoRequest
+		if err := rows.Scan(&request.CryptoSymbol, &request.UserName, &request.RequestMessage, &request.Status, &request.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan crypto request: %v", err)
+		}
+		requests = append(requests, &request)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %v", err)
+	}
+
+	return requests, nil
+}
+
+var digestSettingsColumns = []string{"username", "frequency", "send_at", "weekday", "watchlist", "last_sent_at"}
+
+func scanDigestSettings(row pgx.Row) (*models.DigestSettings, error) {
+	var settings models.DigestSettings
+	var watchlist string
+	err := row.Scan(
+		&settings.Username,
+		&settings.Frequency,
+		&settings.SendAt,
+		&settings.Weekday,
+		&watchlist,
+		&settings.LastSentAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if watchlist != "" {
+		settings.Watchlist = strings.Split(watchlist, ",")
+	}
+	return &settings, nil
+}
+
+// GetDigestSettingsRepo returns the user's digest schedule, or nil when none has been saved.
+func (r *PostgresNotificationRepository) GetDigestSettingsRepo(username string) (*models.DigestSettings, error) {
+	query, err := config.BuildSelectQuery(digestSettingsColumns, "digest_settings", "username = $1")
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := scanDigestSettings(r.conn.QueryRow(context.Background(), query, username))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
 		}
+		return nil, fmt.Errorf("failed to query digest settings: %v", err)
+	}
+
+	return settings, nil
+}
+
+// SaveDigestSettingsRepo creates or replaces the user's digest schedule.
+func (r *PostgresNotificationRepository) SaveDigestSettingsRepo(settings *models.DigestSettings) error {
+	query, err := config.BuildInsertQuery("digest_settings", digestSettingsColumns)
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %v", err)
+	}
+	query = strings.TrimSuffix(query, ";") + " ON CONFLICT (username) DO UPDATE SET frequency = EXCLUDED.frequency, send_at = EXCLUDED.send_at, weekday = EXCLUDED.weekday, watchlist = EXCLUDED.watchlist, last_sent_at = EXCLUDED.last_sent_at;"
+
+	_, err = r.conn.Exec(context.Background(), query,
+		settings.Username,
+		settings.Frequency,
+		settings.SendAt,
+		settings.Weekday,
+		strings.Join(settings.Watchlist, ","),
+		settings.LastSentAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save digest settings: %v", err)
+	}
+
+	return nil
+}
+
+// GetScheduledDigestsRepo returns every digest schedule that is switched on.
+func (r *PostgresNotificationRepository) GetScheduledDigestsRepo() ([]*models.DigestSettings, error) {
+	query, err := config.BuildSelectQuery(digestSettingsColumns, "digest_settings", "frequency <> 'off' AND username IN (SELECT username FROM users WHERE status = 'active')")
+	if err != nil {
+		return nil, err
+	}
 
-		// Set the context for the query
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
+	rows, err := r.conn.Query(context.Background(), query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query digest settings: %v", err)
+	}
+	defer rows.Close()
 
-		// Execute the query, passing the current values and crypto price
-		_, err = r.conn.Exec(ctx, updateQuery, notification.Status, notification.ServedAt, notification.CryptoID, username, notification.TargetPrice)
+	var schedules []*models.DigestSettings
+	for rows.Next() {
+		settings, err := scanDigestSettings(rows)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("failed to scan digest settings: %v", err)
 		}
+		schedules = append(schedules, settings)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %v", err)
+	}
+
+	return schedules, nil
+}
+
+// SaveDigestRepo queues a rendered digest for in-app delivery.
+func (r *PostgresNotificationRepository) SaveDigestRepo(username, body string, createdAt time.Time) error {
+	query, err := config.BuildInsertQuery("digests", []string{"username", "body", "created_at"})
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %v", err)
+	}
+
+	if _, err := r.conn.Exec(context.Background(), query, username, body, createdAt); err != nil {
+		return fmt.Errorf("failed to save digest: %v", err)
+	}
+
+	return nil
+}
+
+// MarkDigestSentRepo records when the user's last digest went out.
+func (r *PostgresNotificationRepository) MarkDigestSentRepo(username string, sentAt time.Time) error {
+	query, err := config.BuildUpdateQuery("digest_settings", []string{"last_sent_at"}, "username = $2")
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.conn.Exec(context.Background(), query, sentAt, username); err != nil {
+		return fmt.Errorf("failed to update digest settings: %v", err)
+	}
+
+	return nil
+}
+
+// TakeUndeliveredDigestsRepo marks the user's queued digests delivered and returns their text, oldest first.
+func (r *PostgresNotificationRepository) TakeUndeliveredDigestsRepo(username string, deliveredAt time.Time) ([]string, error) {
+	query, err := config.BuildUpdateQuery("digests", []string{"delivered_at"}, "username = $2 AND delivered_at IS NULL")
+	if err != nil {
+		return nil, err
+	}
+	query = strings.TrimSuffix(query, ";") + " RETURNING created_at, body;"
+
+	rows, err := r.conn.Query(context.Background(), query, deliveredAt, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deliver digests: %v", err)
+	}
+	defer rows.Close()
+
+	type queued struct {
+		createdAt time.Time
+		body      string
+	}
+	var digests []queued
+	for rows.Next() {
+		var d queued
+		if err := rows.Scan(&d.createdAt, &d.body); err != nil {
+			return nil, fmt.Errorf("failed to scan digest: %v", err)
+		}
+		digests = append(digests, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %v", err)
+	}
+
+	// RETURNING has no ORDER BY
+	sort.Slice(digests, func(i, j int) bool { return digests[i].createdAt.Before(digests[j].createdAt) })
+
+	bodies := make([]string, len(digests))
+	for i, d := range digests {
+		bodies[i] = d.body
+	}
+	return bodies, nil
+}
+
+// GetNotificationProfileRepo returns the contact details, timezone and notification preferences of a user.
+func (r *PostgresNotificationRepository) GetNotificationProfileRepo(username string) (*models.User, error) {
+	return notificationProfile(r.conn, username)
+}
+
+// notificationProfile loads what is needed to reach a user, for the notification service and
+// the delivery workers.
+func notificationProfile(conn *pgx.Conn, username string) (*models.User, error) {
+	columns := []string{"username", "email", "email_verified", "mobile", "timezone", "notification_preferences"}
+	query, err := config.BuildSelectQuery(columns, "users", "username = $1")
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	var preferences []byte
+	err = conn.QueryRow(context.Background(), query, username).Scan(&user.Username, &user.Email, &user.EmailVerified, &user.Mobile, &user.Timezone, &preferences)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to query notification profile: %v", err)
+	}
+
+	if user.NotificationPreferences, err = decodePreferences(preferences); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// EnqueueDeliveryRepo adds an outbound notification to the delivery queue.
+func (r *PostgresNotificationRepository) EnqueueDeliveryRepo(delivery *models.NotificationDelivery) error {
+	columns := []string{"username", "event_type", "channel", "subject", "body", "status", "max_attempts", "next_attempt_at", "created_at"}
+	query, err := config.BuildInsertQuery("notification_deliveries", columns)
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %v", err)
+	}
+
+	_, err = r.conn.Exec(context.Background(), query,
+		delivery.Username,
+		delivery.EventType,
+		delivery.Channel,
+		delivery.Subject,
+		delivery.Body,
+		delivery.Status,
+		delivery.MaxAttempts,
+		delivery.NextAttemptAt,
+		delivery.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue notification delivery: %v", err)
 	}
 
 	return nil
@@ -106,10 +611,26 @@ func (r *PostgresNotificationRepository) UpdatePriceNotificationStatusRepo(notif
 
 // SavePriceNotification saves a single notification to PostgreSQL
 func (r *PostgresNotificationRepository) SavePriceNotification(conn *pgx.Conn, notification *models.PriceNotification) error {
-	columns := []string{"crypto_id", "crypto", "target_price", "username", "asked_at", "status", "served_at"}
+	query, args, err := priceNotificationInsert(notification)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.Exec(context.Background(), query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to save price notification: %v", err)
+	}
+
+	return nil
+}
+
+// priceNotificationInsert builds the INSERT statement and arguments for a price notification,
+// so it can be executed on a connection or inside a transaction.
+func priceNotificationInsert(notification *models.PriceNotification) (string, []interface{}, error) {
+	columns := []string{"kind", "rule", "crypto_id", "crypto", "target_price", "direction", "username", "asked_at", "status", "served_at", "recurring", "cooldown_seconds", "hysteresis_percent", "expires_at", "active_from", "active_to", "trail_percent", "high_water_mark", "group_id", "volume_multiplier", "rank_threshold"}
 	query, err := config.BuildInsertQuery("price_notifications", columns)
 	if err != nil {
-		return fmt.Errorf("failed to build insert query: %v", err)
+		return "", nil, fmt.Errorf("failed to build insert query: %v", err)
 	}
 
 	// Handle the case where ServedAt is not set (i.e., it's pending)
@@ -120,18 +641,39 @@ func (r *PostgresNotificationRepository) SavePriceNotification(conn *pgx.Conn, n
 		servedAt = notification.ServedAt
 	}
 
-	_, err = conn.Exec(context.Background(), query,
+	kind := notification.Kind
+	if kind == "" {
+		kind = "price"
+	}
+
+	direction := notification.Direction
+	if direction == "" {
+		direction = "above"
+	}
+
+	args := []interface{}{
+		kind,
+		notification.Rule,
 		notification.CryptoID,
 		notification.Crypto,
 		notification.TargetPrice,
+		direction,
 		notification.Username,
 		notification.AskedAt,
 		notification.Status,
 		servedAt,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to save price notification: %v", err)
+		notification.Recurring,
+		notification.CooldownSeconds,
+		notification.HysteresisPercent,
+		notification.ExpiresAt,
+		notification.ActiveFrom,
+		notification.ActiveTo,
+		notification.TrailPercent,
+		notification.HighWaterMark,
+		notification.GroupID,
+		notification.VolumeMultiplier,
+		notification.RankThreshold,
 	}
 
-	return nil
+	return query, args, nil
 }
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+	"cryptotracker/models"
+	"cryptotracker/pkg/config"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"time"
+)
+
+// quoteCacheTTL is how long a listings batch in quote_cache is used before it is fetched again.
+// Listings update about once a minute.
+const quoteCacheTTL = time.Minute
+
+var quoteCacheColumns = []string{"symbol", "cmc_id", "name", "price", "percent_change_24h", "volume_24h", "market_cap", "cmc_rank"}
+
+// cachedQuotes returns the latest listings batch, shared through quote_cache by every session.
+// The first session to find the batch stale refreshes it while holding a lock on the table, so
+// the others wait for that fetch instead of making their own.
+func cachedQuotes(conn *pgx.Conn, now time.Time) (map[string]*models.Quote, error) {
+	ctx := context.Background()
+
+	if fresh, err := quoteCacheFresh(conn, now); err != nil {
+		return nil, err
+	} else if fresh {
+		return loadCachedQuotes(conn)
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "LOCK TABLE quote_cache IN EXCLUSIVE MODE"); err != nil {
+		return nil, fmt.Errorf("failed to lock quote cache: %v", err)
+	}
+
+	// Another session may have refreshed the batch while this one waited for the lock
+	if fresh, err := quoteCacheFresh(tx, now); err != nil {
+		return nil, err
+	} else if fresh {
+		return loadCachedQuotes(tx)
+	}
+
+	quotes, err := FetchLatestQuotes(5000)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM quote_cache"); err != nil {
+		return nil, fmt.Errorf("failed to clear quote cache: %v", err)
+	}
+
+	rows := make([][]interface{}, 0, len(quotes))
+	for _, quote := range quotes {
+		rows = append(rows, []interface{}{quote.Symbol, quote.ID, quote.Name, quote.Price, quote.PercentChange24h, quote.Volume24h, quote.MarketCap, quote.CMCRank, now})
+	}
+	columns := append(append([]string(nil), quoteCacheColumns...), "fetched_at")
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"quote_cache"}, columns, pgx.CopyFromRows(rows)); err != nil {
+		return nil, fmt.Errorf("failed to save quote cache: %v", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit quote cache: %v", err)
+	}
+	return quotes, nil
+}
+
+// quoteQuerier is satisfied by both a connection and a transaction.
+type quoteQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}
+
+func quoteCacheFresh(db quoteQuerier, now time.Time) (bool, error) {
+	query, err := config.BuildSelectQuery([]string{"COUNT(*)"}, "quote_cache", "fetched_at > $1")
+	if err != nil {
+		return false, err
+	}
+
+	var count int
+	if err := db.QueryRow(context.Background(), query, now.Add(-quoteCacheTTL)).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check quote cache: %v", err)
+	}
+	return count > 0, nil
+}
+
+func loadCachedQuotes(db quoteQuerier) (map[string]*models.Quote, error) {
+	query, err := config.BuildSelectQuery(quoteCacheColumns, "quote_cache", "TRUE")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(context.Background(), query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quote cache: %v", err)
+	}
+	defer rows.Close()
+
+	quotes := make(map[string]*models.Quote)
+	for rows.Next() {
+		quote := &models.Quote{}
+		if err := rows.Scan(&quote.Symbol, &quote.ID, &quote.Name, &quote.Price, &quote.PercentChange24h, &quote.Volume24h, &quote.MarketCap, &quote.CMCRank); err != nil {
+			return nil, fmt.Errorf("failed to scan cached quote: %v", err)
+		}
+		quotes[quote.Symbol] = quote
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %v", err)
+	}
+
+	return quotes, nil
+}
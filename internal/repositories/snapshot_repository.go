@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"cryptotracker/models"
+	"cryptotracker/pkg/config"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"time"
+)
+
+// snapshotInterval is the minimum gap between two stored snapshots of the same symbol, so
+// frequent polling does not flood market_snapshots.
+const snapshotInterval = 5 * time.Minute
+
+// saveMarketSnapshots stores the given quotes, skipping symbols snapshotted within snapshotInterval.
+func saveMarketSnapshots(conn *pgx.Conn, quotes map[string]*models.Quote, now time.Time) error {
+	if len(quotes) == 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+
+	symbols := make([]string, 0, len(quotes))
+	for symbol := range quotes {
+		symbols = append(symbols, symbol)
+	}
+
+	query, err := config.BuildSelectQuery([]string{"DISTINCT symbol"}, "market_snapshots", "symbol = ANY($1) AND captured_at > $2")
+	if err != nil {
+		return err
+	}
+
+	rows, err := conn.Query(ctx, query, symbols, now.Add(-snapshotInterval))
+	if err != nil {
+		return fmt.Errorf("failed to query recent market snapshots: %v", err)
+	}
+
+	recent := make(map[string]bool)
+	for rows.Next() {
+		var symbol string
+		if err := rows.Scan(&symbol); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan market snapshot: %v", err)
+		}
+		recent[symbol] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows error: %v", err)
+	}
+
+	var snapshots [][]interface{}
+	for _, quote := range quotes {
+		if recent[quote.Symbol] {
+			continue
+		}
+		snapshots = append(snapshots, []interface{}{quote.Symbol, quote.Price, quote.PercentChange24h, quote.Volume24h, quote.MarketCap, quote.CMCRank, now})
+	}
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	columns := []string{"symbol", "price", "percent_change_24h", "volume_24h", "market_cap", "cmc_rank", "captured_at"}
+	_, err = conn.CopyFrom(ctx, pgx.Identifier{"market_snapshots"}, columns, pgx.CopyFromRows(snapshots))
+	if err != nil {
+		return fmt.Errorf("failed to save market snapshots: %v", err)
+	}
+
+	return nil
+}
+
+// trailingAverages averages the stored snapshots of each symbol captured since the given time.
+func trailingAverages(conn *pgx.Conn, symbols []string, since time.Time) (map[string]models.MarketAverage, error) {
+	averages := make(map[string]models.MarketAverage)
+	if len(symbols) == 0 {
+		return averages, nil
+	}
+
+	columns := []string{"symbol", "AVG(price)", "AVG(percent_change_24h)", "AVG(volume_24h)", "AVG(market_cap)", "COUNT(*)"}
+	query, err := config.BuildSelectQuery(columns, "market_snapshots", "symbol = ANY($1) AND captured_at >= $2 GROUP BY symbol")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.Query(context.Background(), query, symbols, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query market snapshots: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var average models.MarketAverage
+		err := rows.Scan(
+			&average.Symbol,
+			&average.Price,
+			&average.PercentChange24h,
+			&average.Volume24h,
+			&average.MarketCap,
+			&average.Samples,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan market average: %v", err)
+		}
+		averages[average.Symbol] = average
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %v", err)
+	}
+
+	return averages, nil
+}
@@ -0,0 +1,261 @@
+package repositories
+
+import (
+	"context"
+	"cryptotracker/internal/rbac"
+	"cryptotracker/models"
+	"cryptotracker/pkg/config"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"strings"
+	"time"
+)
+
+type StablecoinRepository interface {
+	GetPegs() ([]*models.StablecoinPeg, error)
+	SavePeg(peg *models.StablecoinPeg) error
+	DeletePeg(symbol string) error
+	UpdatePegState(peg *models.StablecoinPeg) error
+	GetLatestQuotes() (map[string]*models.Quote, error)
+	GetDepegRecipients() ([]string, error)
+	RecordPegEvent(peg *models.StablecoinPeg, kind string, recipients []string, now time.Time) error
+	IsSubscribed(username string) (bool, error)
+	Subscribe(username string) error
+	Unsubscribe(username string) error
+}
+
+type PostgresStablecoinRepository struct {
+	conn *pgx.Conn
+}
+
+func NewPostgresStablecoinRepository(conn *pgx.Conn) *PostgresStablecoinRepository {
+	return &PostgresStablecoinRepository{conn: conn}
+}
+
+// GetPegs returns every monitored stablecoin.
+func (r *PostgresStablecoinRepository) GetPegs() ([]*models.StablecoinPeg, error) {
+	columns := []string{"symbol", "peg_price", "threshold_percent", "sustained_samples", "breach_count", "depegged", "last_price", "updated_at"}
+	query, err := config.BuildSelectQuery(columns, "stablecoin_pegs", "TRUE ORDER BY symbol")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.Query(context.Background(), query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stablecoin pegs: %v", err)
+	}
+	defer rows.Close()
+
+	var pegs []*models.StablecoinPeg
+	for rows.Next() {
+		var peg models.StablecoinPeg
+		err := rows.Scan(
+			&peg.Symbol,
+			&peg.PegPrice,
+			&peg.ThresholdPercent,
+			&peg.SustainedSamples,
+			&peg.BreachCount,
+			&peg.Depegged,
+			&peg.LastPrice,
+			&peg.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan stablecoin peg: %v", err)
+		}
+		pegs = append(pegs, &peg)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %v", err)
+	}
+
+	return pegs, nil
+}
+
+// SavePeg adds a stablecoin to the monitor or changes its peg settings. Changing the settings
+// restarts the breach count.
+func (r *PostgresStablecoinRepository) SavePeg(peg *models.StablecoinPeg) error {
+	query, err := config.BuildInsertQuery("stablecoin_pegs", []string{"symbol", "peg_price", "threshold_percent", "sustained_samples"})
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %v", err)
+	}
+	query = strings.TrimSuffix(query, ";") + " ON CONFLICT (symbol) DO UPDATE SET peg_price = EXCLUDED.peg_price, threshold_percent = EXCLUDED.threshold_percent, sustained_samples = EXCLUDED.sustained_samples, breach_count = 0;"
+
+	_, err = r.conn.Exec(context.Background(), query, strings.ToUpper(peg.Symbol), peg.PegPrice, peg.ThresholdPercent, peg.SustainedSamples)
+	if err != nil {
+		return fmt.Errorf("failed to save stablecoin peg: %v", err)
+	}
+
+	return nil
+}
+
+// DeletePeg stops monitoring a stablecoin.
+func (r *PostgresStablecoinRepository) DeletePeg(symbol string) error {
+	query, err := config.BuildDeleteQuery("stablecoin_pegs", "symbol = $1")
+	if err != nil {
+		return fmt.Errorf("failed to build delete query: %v", err)
+	}
+
+	tag, err := r.conn.Exec(context.Background(), query, strings.ToUpper(symbol))
+	if err != nil {
+		return fmt.Errorf("failed to delete stablecoin peg: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("stablecoin %s is not monitored", strings.ToUpper(symbol))
+	}
+
+	return nil
+}
+
+// UpdatePegState persists the breach state after a sample.
+func (r *PostgresStablecoinRepository) UpdatePegState(peg *models.StablecoinPeg) error {
+	query, err := config.BuildUpdateQuery("stablecoin_pegs", []string{"breach_count", "depegged", "last_price", "updated_at"}, "symbol = $5")
+	if err != nil {
+		return err
+	}
+
+	_, err = r.conn.Exec(context.Background(), query, peg.BreachCount, peg.Depegged, peg.LastPrice, peg.UpdatedAt, peg.Symbol)
+	if err != nil {
+		return fmt.Errorf("failed to update stablecoin peg state: %v", err)
+	}
+
+	return nil
+}
+
+// GetLatestQuotes fetches one batch of listings to sample the pegs from.
+func (r *PostgresStablecoinRepository) GetLatestQuotes() (map[string]*models.Quote, error) {
+	return FetchLatestQuotes(5000)
+}
+
+// GetDepegRecipients returns every active subscribed user together with every active user
+// whose role grants rbac.DepegAlerts.
+func (r *PostgresStablecoinRepository) GetDepegRecipients() ([]string, error) {
+	table := "users"
+	condition := "(role = ANY($1) OR username IN (SELECT username FROM depeg_subscriptions)) AND status = 'active'"
+	query, err := config.BuildSelectQuery([]string{"username"}, table, condition)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.Query(context.Background(), query, rbac.RolesWith(rbac.DepegAlerts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query de-peg recipients: %v", err)
+	}
+	defer rows.Close()
+
+	var recipients []string
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, fmt.Errorf("failed to scan de-peg recipient: %v", err)
+		}
+		recipients = append(recipients, username)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %v", err)
+	}
+
+	return recipients, nil
+}
+
+// RecordPegEvent hands a de-peg or recovery to every recipient through the price alert path:
+// each recipient gets a fired alert of the event's kind and an undelivered occurrence, which the
+// next notification check delivers like any other alert.
+func (r *PostgresStablecoinRepository) RecordPegEvent(peg *models.StablecoinPeg, kind string, recipients []string, now time.Time) error {
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	occurrenceQuery, err := config.BuildInsertQuery("price_alert_occurrences", []string{"notification_id", "username", "crypto", "target_price", "triggered_price", "triggered_at"})
+	if err != nil {
+		return err
+	}
+
+	for _, username := range recipients {
+		notification := &models.PriceNotification{
+			Kind:            kind,
+			Crypto:          peg.Symbol,
+			TargetPrice:     peg.PegPrice,
+			Username:        username,
+			AskedAt:         now.Format(time.RFC3339),
+			Status:          "Served",
+			ServedAt:        now.Format(time.RFC3339),
+			LastTriggeredAt: &now,
+			TriggerCount:    1,
+		}
+
+		var query string
+		var args []interface{}
+		query, args, err = priceNotificationInsert(notification)
+		if err != nil {
+			return err
+		}
+		query = strings.TrimSuffix(query, ";") + " RETURNING id;"
+
+		if err = tx.QueryRow(ctx, query, args...).Scan(&notification.ID); err != nil {
+			return fmt.Errorf("failed to save de-peg notification for %s: %v", username, err)
+		}
+
+		if _, err = tx.Exec(ctx, occurrenceQuery, notification.ID, username, peg.Symbol, peg.PegPrice, peg.LastPrice, now); err != nil {
+			return fmt.Errorf("failed to record de-peg occurrence for %s: %v", username, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit de-peg event: %v", err)
+	}
+	return nil
+}
+
+// IsSubscribed reports whether the user has asked for de-peg notifications.
+func (r *PostgresStablecoinRepository) IsSubscribed(username string) (bool, error) {
+	query, err := config.BuildSelectQuery([]string{"COUNT(*)"}, "depeg_subscriptions", "username = $1")
+	if err != nil {
+		return false, err
+	}
+
+	var count int
+	if err := r.conn.QueryRow(context.Background(), query, username).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to query de-peg subscription: %v", err)
+	}
+
+	return count > 0, nil
+}
+
+// Subscribe signs the user up for de-peg notifications.
+func (r *PostgresStablecoinRepository) Subscribe(username string) error {
+	query, err := config.BuildInsertQuery("depeg_subscriptions", []string{"username", "subscribed_at"})
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %v", err)
+	}
+	query = strings.TrimSuffix(query, ";") + " ON CONFLICT (username) DO NOTHING;"
+
+	if _, err := r.conn.Exec(context.Background(), query, username, time.Now()); err != nil {
+		return fmt.Errorf("failed to save de-peg subscription: %v", err)
+	}
+
+	return nil
+}
+
+// Unsubscribe stops de-peg notifications for the user.
+func (r *PostgresStablecoinRepository) Unsubscribe(username string) error {
+	query, err := config.BuildDeleteQuery("depeg_subscriptions", "username = $1")
+	if err != nil {
+		return fmt.Errorf("failed to build delete query: %v", err)
+	}
+
+	if _, err := r.conn.Exec(context.Background(), query, username); err != nil {
+		return fmt.Errorf("failed to delete de-peg subscription: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,383 @@
+package repositories
+
+import (
+	"context"
+	"cryptotracker/models"
+	"cryptotracker/pkg/config"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"strings"
+	"time"
+)
+
+type TokenRepository interface {
+	GetUserRole(username string) (string, error)
+	SaveRefreshToken(hash, username, sessionID string, issuedAt, expiresAt time.Time) error
+	ConsumeRefreshToken(hash string, now time.Time) (string, string, error)
+	RevokeRefreshToken(hash string, now time.Time) error
+	RevokeUserRefreshTokens(username string, now time.Time) error
+	RevokeAccessToken(jti string, expiresAt time.Time) error
+	IsAccessTokenRevoked(jti string) (bool, error)
+	DeleteExpiredTokens(now time.Time) error
+	CreateSession(session *models.Session, expiresAt time.Time) error
+	TouchSession(id string, now, expiresAt time.Time) (bool, error)
+	GetActiveSessions(username string, now time.Time) ([]*models.Session, error)
+	RevokeSession(username, id string, now time.Time) error
+	RevokeUserSessions(username string, now time.Time) (int, error)
+	SaveAPIKey(key *models.APIKey, hash string, events ...*models.AuditEvent) error
+	UseAPIKey(hash string, now time.Time) (*models.APIKey, error)
+	GetAPIKeys(username string) ([]*models.APIKey, error)
+	RevokeAPIKey(username string, id int, now time.Time, events ...*models.AuditEvent) error
+}
+
+type PostgresTokenRepository struct {
+	conn *pgx.Conn
+}
+
+func NewPostgresTokenRepository(conn *pgx.Conn) *PostgresTokenRepository {
+	return &PostgresTokenRepository{conn: conn}
+}
+
+// GetUserRole returns the current role of a user. Deactivated and deleted users are not
+// found, so their refresh tokens and API keys stop working.
+func (r *PostgresTokenRepository) GetUserRole(username string) (string, error) {
+	query, err := config.BuildSelectQuery([]string{"role"}, "users", "username = $1 AND status = 'active'")
+	if err != nil {
+		return "", err
+	}
+
+	var role string
+	if err := r.conn.QueryRow(context.Background(), query, username).Scan(&role); err != nil {
+		if err == pgx.ErrNoRows {
+			return "", fmt.Errorf("user not found")
+		}
+		return "", fmt.Errorf("failed to query user role: %v", err)
+	}
+
+	return role, nil
+}
+
+// SaveRefreshToken stores the hash of a newly issued refresh token for a session.
+func (r *PostgresTokenRepository) SaveRefreshToken(hash, username, sessionID string, issuedAt, expiresAt time.Time) error {
+	query, err := config.BuildInsertQuery("refresh_tokens", []string{"token_hash", "username", "session_id", "issued_at", "expires_at"})
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %v", err)
+	}
+
+	if _, err := r.conn.Exec(context.Background(), query, hash, username, sessionID, issuedAt, expiresAt); err != nil {
+		return fmt.Errorf("failed to save refresh token: %v", err)
+	}
+
+	return nil
+}
+
+// ConsumeRefreshToken revokes a live refresh token and returns its owner and session, so each
+// refresh token can be used once. It returns an empty username when the token is unknown,
+// expired or already revoked.
+func (r *PostgresTokenRepository) ConsumeRefreshToken(hash string, now time.Time) (string, string, error) {
+	query, err := config.BuildUpdateQuery("refresh_tokens", []string{"revoked_at"}, "token_hash = $2 AND revoked_at IS NULL AND expires_at > $1")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to build update query: %v", err)
+	}
+	query = strings.TrimSuffix(query, ";") + " RETURNING username, COALESCE(session_id, '');"
+
+	var username, sessionID string
+	if err := r.conn.QueryRow(context.Background(), query, now, hash).Scan(&username, &sessionID); err != nil {
+		if err == pgx.ErrNoRows {
+			return "", "", nil
+		}
+		return "", "", fmt.Errorf("failed to use refresh token: %v", err)
+	}
+
+	return username, sessionID, nil
+}
+
+// RevokeRefreshToken revokes a single refresh token.
+func (r *PostgresTokenRepository) RevokeRefreshToken(hash string, now time.Time) error {
+	query, err := config.BuildUpdateQuery("refresh_tokens", []string{"revoked_at"}, "token_hash = $2 AND revoked_at IS NULL")
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %v", err)
+	}
+
+	if _, err := r.conn.Exec(context.Background(), query, now, hash); err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %v", err)
+	}
+
+	return nil
+}
+
+// RevokeUserRefreshTokens revokes every live refresh token of a user.
+func (r *PostgresTokenRepository) RevokeUserRefreshTokens(username string, now time.Time) error {
+	query, err := config.BuildUpdateQuery("refresh_tokens", []string{"revoked_at"}, "username = $2 AND revoked_at IS NULL")
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %v", err)
+	}
+
+	if _, err := r.conn.Exec(context.Background(), query, now, username); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %v", err)
+	}
+
+	return nil
+}
+
+// RevokeAccessToken records that an access token may no longer be used.
+func (r *PostgresTokenRepository) RevokeAccessToken(jti string, expiresAt time.Time) error {
+	query, err := config.BuildInsertQuery("revoked_tokens", []string{"jti", "expires_at"})
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %v", err)
+	}
+	query = strings.TrimSuffix(query, ";") + " ON CONFLICT (jti) DO NOTHING;"
+
+	if _, err := r.conn.Exec(context.Background(), query, jti, expiresAt); err != nil {
+		return fmt.Errorf("failed to revoke access token: %v", err)
+	}
+
+	return nil
+}
+
+// IsAccessTokenRevoked reports whether an access token has been revoked.
+func (r *PostgresTokenRepository) IsAccessTokenRevoked(jti string) (bool, error) {
+	query, err := config.BuildSelectQuery([]string{"COUNT(*)"}, "revoked_tokens", "jti = $1")
+	if err != nil {
+		return false, err
+	}
+
+	var count int
+	if err := r.conn.QueryRow(context.Background(), query, jti).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to query revoked tokens: %v", err)
+	}
+
+	return count > 0, nil
+}
+
+// DeleteExpiredTokens removes token records that can no longer be used anyway.
+func (r *PostgresTokenRepository) DeleteExpiredTokens(now time.Time) error {
+	for _, table := range []string{"refresh_tokens", "revoked_tokens", "password_reset_tokens", "email_verification_tokens", "sessions", "api_keys"} {
+		query, err := config.BuildDeleteQuery(table, "expires_at <= $1")
+		if err != nil {
+			return fmt.Errorf("failed to build delete query: %v", err)
+		}
+
+		if _, err := r.conn.Exec(context.Background(), query, now); err != nil {
+			return fmt.Errorf("failed to delete expired tokens: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// CreateSession stores a new session.
+func (r *PostgresTokenRepository) CreateSession(session *models.Session, expiresAt time.Time) error {
+	query, err := config.BuildInsertQuery("sessions", []string{"id", "username", "client", "ip", "created_at", "last_seen_at", "expires_at"})
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %v", err)
+	}
+
+	if _, err := r.conn.Exec(context.Background(), query, session.ID, session.Username, session.Client, session.IP, session.CreatedAt, session.LastSeenAt, expiresAt); err != nil {
+		return fmt.Errorf("failed to save session: %v", err)
+	}
+
+	return nil
+}
+
+// TouchSession records that a session was just used and extends its expiry. It reports false
+// when the session is unknown, expired or revoked.
+func (r *PostgresTokenRepository) TouchSession(id string, now, expiresAt time.Time) (bool, error) {
+	query, err := config.BuildUpdateQuery("sessions", []string{"last_seen_at", "expires_at"}, "id = $3 AND revoked_at IS NULL AND expires_at > $1")
+	if err != nil {
+		return false, fmt.Errorf("failed to build update query: %v", err)
+	}
+
+	tag, err := r.conn.Exec(context.Background(), query, now, expiresAt, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to update session: %v", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetActiveSessions returns a user's live sessions, most recently used first.
+func (r *PostgresTokenRepository) GetActiveSessions(username string, now time.Time) ([]*models.Session, error) {
+	query, err := config.BuildSelectQuery([]string{"id", "username", "client", "ip", "created_at", "last_seen_at"}, "sessions", "username = $1 AND revoked_at IS NULL AND expires_at > $2 ORDER BY last_seen_at DESC")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.Query(context.Background(), query, username, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %v", err)
+	}
+	defer rows.Close()
+
+	var sessions []*models.Session
+	for rows.Next() {
+		var session models.Session
+		if err := rows.Scan(&session.ID, &session.Username, &session.Client, &session.IP, &session.CreatedAt, &session.LastSeenAt); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %v", err)
+		}
+		sessions = append(sessions, &session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %v", err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession ends one of a user's sessions along with its refresh tokens.
+func (r *PostgresTokenRepository) RevokeSession(username, id string, now time.Time) error {
+	tx, err := r.conn.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	query, err := config.BuildUpdateQuery("sessions", []string{"revoked_at"}, "id = $2 AND username = $3 AND revoked_at IS NULL")
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %v", err)
+	}
+	tag, err := tx.Exec(context.Background(), query, now, id, username)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("session not found")
+	}
+
+	query, err = config.BuildUpdateQuery("refresh_tokens", []string{"revoked_at"}, "session_id = $2 AND revoked_at IS NULL")
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %v", err)
+	}
+	if _, err := tx.Exec(context.Background(), query, now, id); err != nil {
+		return fmt.Errorf("failed to revoke session refresh tokens: %v", err)
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return nil
+}
+
+// RevokeUserSessions ends every session of a user and returns how many were live.
+func (r *PostgresTokenRepository) RevokeUserSessions(username string, now time.Time) (int, error) {
+	var count int
+	err := audited(r.conn, nil, func(tx pgx.Tx) error {
+		var err error
+		count, err = revokeUserSessions(tx, username, now)
+		return err
+	})
+	return count, err
+}
+
+// revokeUserSessions ends every session of a user along with all of their refresh tokens, as
+// part of the caller's transaction, and returns how many sessions were live.
+func revokeUserSessions(tx pgx.Tx, username string, now time.Time) (int, error) {
+	query, err := config.BuildUpdateQuery("sessions", []string{"revoked_at"}, "username = $2 AND revoked_at IS NULL AND expires_at > $1")
+	if err != nil {
+		return 0, fmt.Errorf("failed to build update query: %v", err)
+	}
+	tag, err := tx.Exec(context.Background(), query, now, username)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %v", err)
+	}
+
+	query, err = config.BuildUpdateQuery("refresh_tokens", []string{"revoked_at"}, "username = $2 AND revoked_at IS NULL")
+	if err != nil {
+		return 0, fmt.Errorf("failed to build update query: %v", err)
+	}
+	if _, err := tx.Exec(context.Background(), query, now, username); err != nil {
+		return 0, fmt.Errorf("failed to revoke refresh tokens: %v", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// SaveAPIKey stores a new API key by its hash and sets its ID. The events are recorded in the
+// audit log in the same transaction.
+func (r *PostgresTokenRepository) SaveAPIKey(key *models.APIKey, hash string, events ...*models.AuditEvent) error {
+	query, err := config.BuildInsertQuery("api_keys", []string{"username", "name", "prefix", "key_hash", "scopes", "created_at", "expires_at"})
+	if err != nil {
+		return fmt.Errorf("failed to build insert query: %v", err)
+	}
+	query = strings.TrimSuffix(query, ";") + " RETURNING id;"
+
+	return audited(r.conn, events, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(context.Background(), query, key.Username, key.Name, key.Prefix, hash, key.Scopes, key.CreatedAt, key.ExpiresAt).Scan(&key.ID); err != nil {
+			return fmt.Errorf("failed to save API key: %v", err)
+		}
+		return nil
+	})
+}
+
+// UseAPIKey records that a live API key was used and returns it. It returns nil when the key
+// is unknown, expired or revoked.
+func (r *PostgresTokenRepository) UseAPIKey(hash string, now time.Time) (*models.APIKey, error) {
+	query, err := config.BuildUpdateQuery("api_keys", []string{"last_used_at"}, "key_hash = $2 AND revoked_at IS NULL AND expires_at > $1")
+	if err != nil {
+		return nil, fmt.Errorf("failed to build update query: %v", err)
+	}
+	query = strings.TrimSuffix(query, ";") + " RETURNING id, username, name, prefix, scopes, created_at, expires_at, last_used_at;"
+
+	var key models.APIKey
+	err = r.conn.QueryRow(context.Background(), query, now, hash).
+		Scan(&key.ID, &key.Username, &key.Name, &key.Prefix, &key.Scopes, &key.CreatedAt, &key.ExpiresAt, &key.LastUsedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to use API key: %v", err)
+	}
+
+	return &key, nil
+}
+
+// GetAPIKeys returns a user's API keys that have not been revoked, newest first.
+func (r *PostgresTokenRepository) GetAPIKeys(username string) ([]*models.APIKey, error) {
+	query, err := config.BuildSelectQuery([]string{"id", "username", "name", "prefix", "scopes", "created_at", "expires_at", "last_used_at"}, "api_keys", "username = $1 AND revoked_at IS NULL ORDER BY created_at DESC")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn.Query(context.Background(), query, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query API keys: %v", err)
+	}
+	defer rows.Close()
+
+	var keys []*models.APIKey
+	for rows.Next() {
+		var key models.APIKey
+		if err := rows.Scan(&key.ID, &key.Username, &key.Name, &key.Prefix, &key.Scopes, &key.CreatedAt, &key.ExpiresAt, &key.LastUsedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %v", err)
+		}
+		keys = append(keys, &key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %v", err)
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey revokes one of a user's API keys, and records the events in the audit log in
+// the same transaction.
+func (r *PostgresTokenRepository) RevokeAPIKey(username string, id int, now time.Time, events ...*models.AuditEvent) error {
+	query, err := config.BuildUpdateQuery("api_keys", []string{"revoked_at"}, "id = $2 AND username = $3 AND revoked_at IS NULL")
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %v", err)
+	}
+
+	return audited(r.conn, events, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(context.Background(), query, now, id, username)
+		if err != nil {
+			return fmt.Errorf("failed to revoke API key: %v", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("API key not found")
+		}
+		return nil
+	})
+}
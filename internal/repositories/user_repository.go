@@ -4,13 +4,23 @@ import (
 	"context"
 	"cryptotracker/models"
 	"cryptotracker/pkg/config"
+	"encoding/json"
 	"fmt"
 	"github.com/jackc/pgx/v4"
+	"time"
 	//"github.com/jackc/pgx/v5"
 )
 
 type UserRepository interface {
 	GetUserProfile(username string) (*models.User, error)
+	GetNotificationPreferences(username string) (*models.NotificationPreferences, error)
+	UpdateNotificationPreferences(username string, preferences *models.NotificationPreferences) error
+	GetPasswordHash(username string) (string, error)
+	UpdateProfile(username string, changes *models.ProfileChanges, now time.Time, events ...*models.AuditEvent) error
+	GetLoginAttempts(keys []string) ([]*models.LoginAttempt, error)
+	RecordLoginFailure(key string, now, windowStart time.Time) (int, error)
+	LockLogin(key string, until time.Time, events ...*models.AuditEvent) error
+	ClearLoginAttempts(key string) error
 }
 
 type PostgresUserRepository struct {
@@ -23,7 +33,7 @@ func NewPostgresUserRepository(conn *pgx.Conn) UserRepository {
 
 func (repo *PostgresUserRepository) GetUserProfile(username string) (*models.User, error) {
 	// Define the columns to select
-	columns := []string{"username", "email", "mobile", "role"}
+	columns := []string{"username", "email", "email_verified", "mobile", "role", "timezone", "notification_preferences"}
 	// Define the condition
 	condition := "username = $1"
 
@@ -34,11 +44,15 @@ func (repo *PostgresUserRepository) GetUserProfile(username string) (*models.Use
 	}
 
 	var user models.User
+	var preferences []byte
 	err = repo.conn.QueryRow(context.Background(), query, username).Scan(
 		&user.Username,
 		&user.Email,
+		&user.EmailVerified,
 		&user.Mobile,
 		&user.Role,
+		&user.Timezone,
+		&preferences,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -46,5 +60,170 @@ func (repo *PostgresUserRepository) GetUserProfile(username string) (*models.Use
 		}
 		return nil, fmt.Errorf("failed to get user profile: %v", err)
 	}
+
+	user.NotificationPreferences, err = decodePreferences(preferences)
+	if err != nil {
+		return nil, err
+	}
 	return &user, nil
 }
+
+// GetNotificationPreferences returns the user's stored notification preferences. Users who never
+// saved any get an empty set, which callers fill with defaults.
+func (repo *PostgresUserRepository) GetNotificationPreferences(username string) (*models.NotificationPreferences, error) {
+	query, err := config.BuildSelectQuery([]string{"notification_preferences"}, "users", "username = $1")
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %v", err)
+	}
+
+	var preferences []byte
+	err = repo.conn.QueryRow(context.Background(), query, username).Scan(&preferences)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to get notification preferences: %v", err)
+	}
+
+	return decodePreferences(preferences)
+}
+
+// UpdateNotificationPreferences replaces the user's notification preferences.
+func (repo *PostgresUserRepository) UpdateNotificationPreferences(username string, preferences *models.NotificationPreferences) error {
+	data, err := json.Marshal(preferences)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification preferences: %v", err)
+	}
+
+	query, err := config.BuildUpdateQuery("users", []string{"notification_preferences"}, "username = $2")
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %v", err)
+	}
+
+	tag, err := repo.conn.Exec(context.Background(), query, string(data), username)
+	if err != nil {
+		return fmt.Errorf("failed to update notification preferences: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// GetPasswordHash returns the stored password hash of a user.
+func (repo *PostgresUserRepository) GetPasswordHash(username string) (string, error) {
+	query, err := config.BuildSelectQuery([]string{"password"}, "users", "username = $1")
+	if err != nil {
+		return "", fmt.Errorf("failed to build query: %v", err)
+	}
+
+	var hash string
+	if err := repo.conn.QueryRow(context.Background(), query, username).Scan(&hash); err != nil {
+		if err == pgx.ErrNoRows {
+			return "", fmt.Errorf("user not found")
+		}
+		return "", fmt.Errorf("failed to get password hash: %v", err)
+	}
+
+	return hash, nil
+}
+
+// UpdateProfile changes the given profile fields together. A new email address is stored
+// unverified, and a new password ends every session of the user and revokes their refresh
+// tokens. The events are
+// recorded in the audit log in the same transaction.
+func (repo *PostgresUserRepository) UpdateProfile(username string, changes *models.ProfileChanges, now time.Time, events ...*models.AuditEvent) error {
+	var columns []string
+	var values []interface{}
+	if changes.Email != nil {
+		columns = append(columns, "email", "email_verified")
+		values = append(values, *changes.Email, false)
+	}
+	if changes.Mobile != nil {
+		columns = append(columns, "mobile")
+		values = append(values, *changes.Mobile)
+	}
+	if changes.PasswordHash != nil {
+		columns = append(columns, "password")
+		values = append(values, *changes.PasswordHash)
+	}
+	if changes.NotificationPreferences != nil {
+		data, err := json.Marshal(changes.NotificationPreferences)
+		if err != nil {
+			return fmt.Errorf("failed to encode notification preferences: %v", err)
+		}
+		columns = append(columns, "notification_preferences")
+		values = append(values, string(data))
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+
+	tx, err := repo.conn.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	query, err := config.BuildUpdateQuery("users", columns, fmt.Sprintf("username = $%d", len(columns)+1))
+	if err != nil {
+		return fmt.Errorf("failed to build update query: %v", err)
+	}
+	tag, err := tx.Exec(context.Background(), query, append(values, username)...)
+	if err != nil {
+		return fmt.Errorf("failed to update profile: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	if changes.PasswordHash != nil {
+		if _, err := revokeUserSessions(tx, username, now); err != nil {
+			return err
+		}
+	}
+
+	if err := recordAuditEvents(tx, events...); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// decodePreferences parses the notification_preferences column.
+func decodePreferences(data []byte) (*models.NotificationPreferences, error) {
+	var preferences models.NotificationPreferences
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &preferences); err != nil {
+			return nil, fmt.Errorf("failed to decode notification preferences: %v", err)
+		}
+	}
+	return &preferences, nil
+}
+
+// GetLoginAttempts returns the failed login records for the given keys, so wrong current
+// passwords count towards the same lockout as failed logins.
+func (repo *PostgresUserRepository) GetLoginAttempts(keys []string) ([]*models.LoginAttempt, error) {
+	return getLoginAttempts(repo.conn, keys)
+}
+
+// RecordLoginFailure counts a wrong current password for the key and returns its failures
+// so far. Failures before windowStart are forgotten.
+func (repo *PostgresUserRepository) RecordLoginFailure(key string, now, windowStart time.Time) (int, error) {
+	return recordLoginFailure(repo.conn, key, now, windowStart)
+}
+
+// LockLogin blocks logins for the key until the given time, and records the events in the
+// audit log in the same transaction.
+func (repo *PostgresUserRepository) LockLogin(key string, until time.Time, events ...*models.AuditEvent) error {
+	return lockLogin(repo.conn, key, until, events)
+}
+
+// ClearLoginAttempts forgets the failed logins of the key.
+func (repo *PostgresUserRepository) ClearLoginAttempts(key string) error {
+	return clearLoginAttempts(repo.conn, key)
+}
@@ -1,52 +1,493 @@
 package services
 
 import (
+	"cryptotracker/internal/audit"
+	"cryptotracker/internal/lockout"
+	"cryptotracker/internal/rbac"
 	"cryptotracker/internal/repositories"
 	"cryptotracker/models"
+	"cryptotracker/pkg/config"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
 )
 
+// ErrPermissionDenied is returned when the acting user's role does not grant the permission
+// an action needs.
+var ErrPermissionDenied = errors.New("permission denied")
+
+// DefaultAuditLimit and MaxAuditLimit bound how many audit log entries a search returns.
+const (
+	DefaultAuditLimit = 50
+	MaxAuditLimit     = 500
+)
+
+// DefaultDeletionGracePeriod is how long a deleted account can be restored before it is
+// purged, unless the configuration says otherwise.
+const DefaultDeletionGracePeriod = 30 * 24 * time.Hour
+
+// AdminService holds the actions of the admin panel. Each takes the username of the acting
+// user and checks their current role, so a role change applies at once rather than when the
+// user next logs in.
 type AdminService interface {
-	ChangeUserStatus(username string) error
-	DeleteUser(username string) error
-	ViewUserProfiles() ([]*models.User, error)
-	ManageUserRequests() ([]*models.UnavailableCryptoRequest, error)
-	ManageSpecificCryptoRequests(cryptoSymbol string) ([]*models.UnavailableCryptoRequest, error)
-	UpdateRequestStatus(requests []*models.UnavailableCryptoRequest, status string) error
+	ChangeUserRole(username, role, actor, source string) error
+	DeactivateUser(username, actor, source string) error
+	DeleteUser(username, actor, source string) (time.Time, error)
+	RestoreUser(username, actor, source string) error
+	PurgeDeletedUsers(now time.Time) (int, error)
+	ViewUserProfiles(actor string) ([]*models.User, error)
+	ManageUserRequests(actor string) ([]*models.UnavailableCryptoRequest, error)
+	ManageSpecificCryptoRequests(cryptoSymbol, actor string) ([]*models.UnavailableCryptoRequest, error)
+	UpdateRequestStatus(requests []*models.UnavailableCryptoRequest, status, actor, source string) error
+	GetLockedLogins(actor string) ([]*models.LoginAttempt, error)
+	UnlockLogin(key, actor, source string) error
+	SetTwoFactorRequired(role string, required bool, actor, source string) error
+	IsTwoFactorRequired(role string) (bool, error)
+	ForceLogout(username, actor, source string) (int, error)
+	GetAuditLog(filter *models.AuditFilter, actor string) ([]*models.AuditEvent, error)
+	VerifyAuditLog(actor string) (*models.AuditVerification, error)
+	ExportAuditLog(w io.Writer, filter *models.AuditFilter, format, actor, source string) (int, error)
 }
 
 type AdminServiceImpl struct {
-	repo repositories.AdminRepository
+	repo        repositories.AdminRepository
+	gracePeriod time.Duration
 }
 
 func NewAdminService(repo repositories.AdminRepository) AdminService {
-	return &AdminServiceImpl{repo: repo}
+	gracePeriod := DefaultDeletionGracePeriod
+	if days := config.AppConfig.AccountDeletion.GracePeriodDays; days > 0 {
+		gracePeriod = time.Duration(days) * 24 * time.Hour
+	}
+	return &AdminServiceImpl{repo: repo, gracePeriod: gracePeriod}
+}
+
+// authorize returns the actor's current role, or ErrPermissionDenied when it does not grant
+// the permission.
+func (s *AdminServiceImpl) authorize(actor string, permission rbac.Permission) (string, error) {
+	role, err := s.repo.GetUserRole(actor)
+	if err != nil {
+		return "", err
+	}
+	if !rbac.Can(role, permission) {
+		return "", ErrPermissionDenied
+	}
+	return role, nil
 }
 
-func (s *AdminServiceImpl) ChangeUserStatus(username string) error {
-	return s.repo.ChangeUserStatus(username)
+// authorizeOver is authorize for an action on another user's account, which the actor's role
+// must also outrank unless the actor is an owner. It returns the user's role.
+func (s *AdminServiceImpl) authorizeOver(username, actor string, permission rbac.Permission) (string, error) {
+	actorRole, err := s.authorize(actor, permission)
+	if err != nil {
+		return "", err
+	}
+
+	role, err := s.repo.GetUserRole(username)
+	if err != nil {
+		return "", err
+	}
+	if !rbac.CanManage(actorRole, role) {
+		return "", ErrPermissionDenied
+	}
+	return role, nil
 }
 
-func (s *AdminServiceImpl) DeleteUser(username string) error {
-	return s.repo.DeleteUser(username)
+// ChangeUserRole promotes or demotes a user and records who did it. Users cannot change their
+// own role, and only owners may change the role of users ranked as high as themselves. A
+// demoted user is signed out everywhere.
+func (s *AdminServiceImpl) ChangeUserRole(username, role, actor, source string) error {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if !rbac.IsRole(role) {
+		return fmt.Errorf("unknown role %q: must be one of %s", role, strings.Join(rbac.Roles(), ", "))
+	}
+
+	actorRole, err := s.authorize(actor, rbac.UsersChangeRole)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(username, actor) {
+		return fmt.Errorf("you cannot change your own role")
+	}
+
+	current, err := s.repo.GetUserRole(username)
+	if err != nil {
+		return err
+	}
+	if current == role {
+		return fmt.Errorf("user is already %s %s", article(role), role)
+	}
+	if !rbac.CanAssign(actorRole, current, role) {
+		return ErrPermissionDenied
+	}
+
+	action := "role.promoted"
+	if rbac.Rank(role) < rbac.Rank(current) {
+		action = "role.demoted"
+	}
+	return s.repo.SetUserRole(username, role, &models.AuditEvent{
+		Actor:     actor,
+		Action:    action,
+		Target:    username,
+		Details:   fmt.Sprintf("role changed from %s to %s", current, role),
+		Before:    current,
+		After:     role,
+		Source:    source,
+		CreatedAt: time.Now(),
+	})
+}
+
+// DeactivateUser blocks a user from logging in and pauses their alerts until they are
+// reactivated, ending their sessions, and records who did it.
+func (s *AdminServiceImpl) DeactivateUser(username, actor, source string) error {
+	if strings.EqualFold(username, actor) {
+		return fmt.Errorf("you cannot deactivate your own account")
+	}
+	if _, err := s.authorizeOver(username, actor, rbac.UsersDeactivate); err != nil {
+		return err
+	}
+
+	status, _, err := s.repo.GetUserStatus(username)
+	if err != nil {
+		return err
+	}
+	if status != models.AccountActive {
+		return fmt.Errorf("user is already %s", status)
+	}
+
+	now := time.Now()
+	return s.repo.SetUserStatus(username, models.AccountDeactivated, nil, now, &models.AuditEvent{
+		Actor:     actor,
+		Action:    "user.deactivated",
+		Target:    username,
+		Before:    status,
+		After:     models.AccountDeactivated,
+		Source:    source,
+		CreatedAt: now,
+	})
+}
+
+// DeleteUser deletes a user, ending their sessions, and records who did it. The account and
+// its data are kept, and can be restored, until the grace period ends and the purge job
+// removes them. It returns when that will be.
+func (s *AdminServiceImpl) DeleteUser(username, actor, source string) (time.Time, error) {
+	if strings.EqualFold(username, actor) {
+		return time.Time{}, fmt.Errorf("you cannot delete your own account")
+	}
+	role, err := s.authorizeOver(username, actor, rbac.UsersDelete)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	status, _, err := s.repo.GetUserStatus(username)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if status == models.AccountDeleted {
+		return time.Time{}, fmt.Errorf("user is already deleted")
+	}
+
+	now := time.Now()
+	purgeAfter := now.Add(s.gracePeriod)
+	err = s.repo.SetUserStatus(username, models.AccountDeleted, &purgeAfter, now, &models.AuditEvent{
+		Actor:     actor,
+		Action:    "user.deleted",
+		Target:    username,
+		Details:   fmt.Sprintf("%s account; data kept until %s", role, purgeAfter.UTC().Format(time.RFC3339)),
+		Before:    status,
+		After:     models.AccountDeleted,
+		Source:    source,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return purgeAfter, nil
+}
+
+// RestoreUser reactivates a deactivated user, or brings back a deleted user whose grace period
+// has not ended, and records who did it. Restoring a deleted user needs UsersDelete.
+func (s *AdminServiceImpl) RestoreUser(username, actor, source string) error {
+	if strings.EqualFold(username, actor) {
+		return fmt.Errorf("you cannot restore your own account")
+	}
+	if _, err := s.authorizeOver(username, actor, rbac.UsersDeactivate); err != nil {
+		return err
+	}
+
+	status, purgeAfter, err := s.repo.GetUserStatus(username)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	switch status {
+	case models.AccountActive:
+		return fmt.Errorf("user is already active")
+	case models.AccountDeleted:
+		if _, err := s.authorize(actor, rbac.UsersDelete); err != nil {
+			return err
+		}
+		if purgeAfter != nil && !purgeAfter.After(now) {
+			return fmt.Errorf("the grace period for restoring %s has ended", username)
+		}
+	}
+
+	return s.repo.SetUserStatus(username, models.AccountActive, nil, now, &models.AuditEvent{
+		Actor:     actor,
+		Action:    "user.restored",
+		Target:    username,
+		Before:    status,
+		After:     models.AccountActive,
+		Source:    source,
+		CreatedAt: now,
+	})
+}
+
+// PurgeDeletedUsers removes, for good, the deleted users whose grace period has ended and
+// everything they own. It is run by the background jobs and returns how many were purged.
+func (s *AdminServiceImpl) PurgeDeletedUsers(now time.Time) (int, error) {
+	usernames, err := s.repo.GetUsersDueForPurge(now)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, username := range usernames {
+		ok, err := s.repo.PurgeUser(username, now, &models.AuditEvent{
+			Actor:     AuditActorSystem,
+			Action:    "user.purged",
+			Target:    username,
+			Details:   "grace period ended; account and its data removed",
+			Before:    models.AccountDeleted,
+			Source:    AuditSourceSystem,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return purged, err
+		}
+		// Restored since it was listed
+		if !ok {
+			continue
+		}
+		purged++
+	}
+	return purged, nil
 }
 
-func (s *AdminServiceImpl) ViewUserProfiles() ([]*models.User, error) {
+func (s *AdminServiceImpl) ViewUserProfiles(actor string) ([]*models.User, error) {
+	if _, err := s.authorize(actor, rbac.UsersView); err != nil {
+		return nil, err
+	}
 	return s.repo.ViewUserProfiles()
 }
 
-func (s *AdminServiceImpl) ManageUserRequests() ([]*models.UnavailableCryptoRequest, error) {
+func (s *AdminServiceImpl) ManageUserRequests(actor string) ([]*models.UnavailableCryptoRequest, error) {
+	if _, err := s.authorize(actor, rbac.RequestsView); err != nil {
+		return nil, err
+	}
 	return s.repo.ManageUserRequests()
 }
 
-func (s *AdminServiceImpl) ManageSpecificCryptoRequests(cryptoSymbol string) ([]*models.UnavailableCryptoRequest, error) {
+func (s *AdminServiceImpl) ManageSpecificCryptoRequests(cryptoSymbol, actor string) ([]*models.UnavailableCryptoRequest, error) {
+	if _, err := s.authorize(actor, rbac.RequestsView); err != nil {
+		return nil, err
+	}
 	return s.repo.ManageSpecificCryptoRequests(cryptoSymbol)
 }
 
-func (s *AdminServiceImpl) UpdateRequestStatus(requests []*models.UnavailableCryptoRequest, status string) error {
+// UpdateRequestStatus approves or rejects requests to add cryptocurrencies, and records who
+// changed each request's status. Requests that already have the status, or were changed by
+// someone else since they were read, are left alone.
+func (s *AdminServiceImpl) UpdateRequestStatus(requests []*models.UnavailableCryptoRequest, status, actor, source string) error {
+	if _, err := s.authorize(actor, rbac.RequestsApprove); err != nil {
+		return err
+	}
 
+	now := time.Now()
+	var pending []*models.UnavailableCryptoRequest
+	var events []*models.AuditEvent
 	for _, request := range requests {
-		request.Status = status
+		if request.Status == status {
+			continue
+		}
+		pending = append(pending, request)
+		events = append(events, &models.AuditEvent{
+			Actor:     actor,
+			Action:    "request.status_changed",
+			Target:    request.UserName,
+			Details:   "request to add " + request.CryptoSymbol,
+			Before:    request.Status,
+			After:     status,
+			Source:    source,
+			CreatedAt: now,
+		})
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	return s.repo.UpdateRequestStatus(pending, status, events)
+}
+
+// GetLockedLogins returns the usernames and client addresses currently locked out.
+func (s *AdminServiceImpl) GetLockedLogins(actor string) ([]*models.LoginAttempt, error) {
+	if _, err := s.authorize(actor, rbac.UsersUnlock); err != nil {
+		return nil, err
+	}
+	return s.repo.GetLockedLogins(time.Now())
+}
+
+// UnlockLogin lifts a lockout early and records who lifted it. A bare username is taken to
+// mean that user's account.
+func (s *AdminServiceImpl) UnlockLogin(key, actor, source string) error {
+	if _, err := s.authorize(actor, rbac.UsersUnlock); err != nil {
+		return err
+	}
+	if !lockout.IsUserKey(key) && !lockout.IsIPKey(key) {
+		key = lockout.UserKey(key)
+	}
+
+	return s.repo.UnlockLogin(key, &models.AuditEvent{
+		Actor:     actor,
+		Action:    "login.unlocked",
+		Target:    lockout.Username(key),
+		Source:    source,
+		CreatedAt: time.Now(),
+	})
+}
+
+// SetTwoFactorRequired makes two-factor authentication mandatory, or optional again, for
+// every user with the role, and records who changed it.
+func (s *AdminServiceImpl) SetTwoFactorRequired(role string, required bool, actor, source string) error {
+	if !rbac.IsRole(role) {
+		return fmt.Errorf("unknown role %q: must be one of %s", role, strings.Join(rbac.Roles(), ", "))
+	}
+	if _, err := s.authorize(actor, rbac.SecurityManage); err != nil {
+		return err
+	}
+
+	wasRequired, err := s.repo.IsTwoFactorRequired(role)
+	if err != nil {
+		return err
+	}
+	details := "two-factor authentication made optional"
+	if required {
+		details = "two-factor authentication made mandatory"
+	}
+	return s.repo.SetTwoFactorRequired(role, required, &models.AuditEvent{
+		Actor:     actor,
+		Action:    "2fa.policy_changed",
+		Target:    role,
+		Details:   details,
+		Before:    twoFactorPolicy(wasRequired),
+		After:     twoFactorPolicy(required),
+		Source:    source,
+		CreatedAt: time.Now(),
+	})
+}
+
+// IsTwoFactorRequired reports whether users with the role must use two-factor authentication.
+func (s *AdminServiceImpl) IsTwoFactorRequired(role string) (bool, error) {
+	return s.repo.IsTwoFactorRequired(role)
+}
+
+// ForceLogout ends every session of a user, on the CLI and REST alike, and records who did
+// it. The actor must outrank the user unless they are an owner. It returns how many sessions
+// were ended.
+func (s *AdminServiceImpl) ForceLogout(username, actor, source string) (int, error) {
+	if _, err := s.authorizeOver(username, actor, rbac.SessionsRevokeAny); err != nil {
+		return 0, err
 	}
 
-	return s.repo.SaveUnavailableCryptoRequest(requests)
+	now := time.Now()
+	return s.repo.RevokeUserSessions(username, now, func(count int) *models.AuditEvent {
+		return &models.AuditEvent{
+			Actor:     actor,
+			Action:    "sessions.revoked",
+			Target:    username,
+			Details:   fmt.Sprintf("%d session(s) ended", count),
+			Source:    source,
+			CreatedAt: now,
+		}
+	})
+}
+
+// GetAuditLog searches the audit log, newest first. A filter without a limit returns
+// DefaultAuditLimit entries, and none returns more than MaxAuditLimit.
+func (s *AdminServiceImpl) GetAuditLog(filter *models.AuditFilter, actor string) ([]*models.AuditEvent, error) {
+	if _, err := s.authorize(actor, rbac.AuditView); err != nil {
+		return nil, err
+	}
+
+	search := *filter
+	if search.Limit <= 0 {
+		search.Limit = DefaultAuditLimit
+	} else if search.Limit > MaxAuditLimit {
+		search.Limit = MaxAuditLimit
+	}
+	return s.repo.GetAuditEvents(&search)
+}
+
+// VerifyAuditLog checks the audit log's hash chain from its first entry to its last.
+func (s *AdminServiceImpl) VerifyAuditLog(actor string) (*models.AuditVerification, error) {
+	if _, err := s.authorize(actor, rbac.AuditView); err != nil {
+		return nil, err
+	}
+
+	events, err := s.repo.GetAuditChain()
+	if err != nil {
+		return nil, err
+	}
+	return audit.Verify(events), nil
+}
+
+// ExportAuditLog writes every audit log entry matching the filter, newest first, as CSV or
+// JSON, and records the export. It returns how many entries were written.
+func (s *AdminServiceImpl) ExportAuditLog(w io.Writer, filter *models.AuditFilter, format, actor, source string) (int, error) {
+	if format != audit.FormatCSV && format != audit.FormatJSON {
+		return 0, fmt.Errorf("unknown export format %q: must be %s or %s", format, audit.FormatCSV, audit.FormatJSON)
+	}
+	if _, err := s.authorize(actor, rbac.AuditView); err != nil {
+		return 0, err
+	}
+
+	events, err := s.repo.GetAuditEvents(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	// Recorded first, so that a failed write still leaves a trace of the attempt
+	if err := s.repo.RecordAuditEvent(&models.AuditEvent{
+		Actor:     actor,
+		Action:    "audit.exported",
+		Details:   fmt.Sprintf("%d entries exported as %s", len(events), format),
+		Source:    source,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return 0, err
+	}
+
+	if err := audit.Export(w, events, format); err != nil {
+		return 0, fmt.Errorf("failed to export audit log: %v", err)
+	}
+	return len(events), nil
+}
+
+// twoFactorPolicy describes whether two-factor authentication is required, for the audit log.
+func twoFactorPolicy(required bool) string {
+	if required {
+		return "mandatory"
+	}
+	return "optional"
+}
+
+// article is the indefinite article for a role name.
+func article(role string) string {
+	if strings.ContainsRune("aeiou", rune(role[0])) {
+		return "an"
+	}
+	return "a"
 }
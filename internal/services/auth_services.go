@@ -2,42 +2,190 @@ package services
 
 import (
 	//"cryptotracker/internal/notification"
+	"cryptotracker/internal/lockout"
+	"cryptotracker/internal/notify"
 	"cryptotracker/internal/repositories"
+	"cryptotracker/internal/totp"
 	"cryptotracker/models"
+	"cryptotracker/pkg/config"
 	"cryptotracker/pkg/utils"
+	"cryptotracker/pkg/validation"
+	"fmt"
 	"github.com/pkg/errors"
+	"strings"
+	"time"
 	//"github.com/jackc/pgx/v4"
 )
 
+// Where an audited action came from. Background jobs act as AuditActorSystem.
+const (
+	AuditSourceCLI    = "cli"
+	AuditSourceREST   = "rest"
+	AuditSourceSystem = "system"
+	AuditActorSystem  = "system"
+)
+
+// LoginThrottledError is returned when a login is refused without checking the password,
+// because of recent failed attempts for the username or the client address.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *LoginThrottledError) Error() string {
+	wait := e.RetryAfter.Round(time.Second)
+	if wait < time.Second {
+		wait = time.Second
+	}
+	if e.Locked {
+		return fmt.Sprintf("too many failed login attempts; login is locked for %v", wait)
+	}
+	return fmt.Sprintf("too many failed login attempts; try again in %v", wait)
+}
+
+const (
+	// PasswordResetTTL is how long an emailed password reset code can be used.
+	PasswordResetTTL = 30 * time.Minute
+
+	// maxPasswordResets caps the reset emails sent to one user within PasswordResetTTL.
+	maxPasswordResets = 3
+
+	// EmailVerificationTTL is how long an emailed verification code can be used.
+	EmailVerificationTTL = 24 * time.Hour
+
+	// maxVerificationEmails caps the verification emails sent to one user within an hour.
+	maxVerificationEmails = 3
+)
+
+var (
+	// ErrInvalidResetToken is returned for reset codes that are unknown, expired or used.
+	ErrInvalidResetToken = errors.New("invalid or expired reset code")
+	// ErrResetUnavailable is returned when no mail server is configured to send reset codes.
+	ErrResetUnavailable = errors.New("password reset by email is not available on this server")
+	// ErrInvalidVerificationToken is returned for verification codes that are unknown,
+	// expired or used, or were sent to an address the user has since changed.
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification code")
+	// ErrVerificationUnavailable is returned when no mail server is configured to send
+	// verification codes.
+	ErrVerificationUnavailable = errors.New("email verification is not available on this server")
+	// ErrTooManyVerificationEmails is returned when a user asks for verification emails too often.
+	ErrTooManyVerificationEmails = errors.New("too many verification emails have been sent; try again later")
+	// ErrInvalidTwoFactorCode is returned for authenticator and recovery codes that do not
+	// match or have already been used.
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	// ErrTwoFactorNotEnabled is returned when a second factor is checked for a user who has
+	// not enabled two-factor authentication.
+	ErrTwoFactorNotEnabled = errors.New("two-factor authentication is not enabled")
+	// ErrTwoFactorNotStarted is returned when enrollment is confirmed before it was begun.
+	ErrTwoFactorNotStarted = errors.New("two-factor enrollment has not been started")
+	// ErrTwoFactorMandatory is returned when a user tries to turn off two-factor
+	// authentication that their role requires.
+	ErrTwoFactorMandatory = errors.New("two-factor authentication is mandatory for your role")
+	// ErrAccountDeactivated is returned when a deactivated user logs in.
+	ErrAccountDeactivated = errors.New("this account has been deactivated; ask an admin to reactivate it")
+	// ErrAccountDeleted is returned when a deleted user logs in before their account is purged.
+	ErrAccountDeleted = errors.New("this account has been deleted; ask an admin to restore it before it is removed for good")
+)
+
+const (
+	// twoFactorIssuer names the account in authenticator apps.
+	twoFactorIssuer = "CryptoTracker"
+	// recoveryCodeCount is how many recovery codes are issued when 2FA is enabled.
+	recoveryCodeCount = 10
+)
+
+// TwoFactorEnrollment is what a user needs to add their account to an authenticator app.
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
 type AuthService interface {
-	Login(username, password string) (*models.User, string, error)
+	Login(username, password, clientIP string) (*models.User, string, error)
 	Signup(user *models.User) error
+	RequestPasswordReset(identifier string) error
+	ResetPassword(code, newPassword, source string) error
+	SendEmailVerification(username string) error
+	VerifyEmail(code, source string) error
+	TwoFactorRequired(user *models.User) (bool, error)
+	VerifySecondFactor(username, code, clientIP string) error
+	BeginTwoFactorEnrollment(username string) (*TwoFactorEnrollment, error)
+	ConfirmTwoFactorEnrollment(username, code, source string) ([]string, error)
+	DisableTwoFactor(username, code, clientIP string) error
 }
 
 type AuthServiceImpl struct {
 	repo                repositories.AuthRepository
 	NotificationService NotificationService
+	throttle            loginThrottle
+	mailer              notify.Mailer
 }
 
 func NewAuthService(repo repositories.AuthRepository, notificationService NotificationService) AuthService {
 	return &AuthServiceImpl{repo: repo,
-		NotificationService: notificationService}
+		NotificationService: notificationService,
+		throttle:            newLoginThrottle(repo),
+		mailer:              notify.MailerFromConfig(config.AppConfig)}
 }
 
-func (s *AuthServiceImpl) Login(username, password string) (*models.User, string, error) {
+// Login checks a username and password. clientIP is the address of a REST client and is
+// empty for the CLI. Failed attempts are counted per username and per address; each one makes
+// the next attempt wait longer, and too many lock further attempts out for a while.
+func (s *AuthServiceImpl) Login(username, password, clientIP string) (*models.User, string, error) {
+	now := time.Now()
+	keys := []string{lockout.UserKey(username)}
+	if clientIP != "" {
+		keys = append(keys, lockout.IPKey(clientIP))
+	}
+
+	if err := s.throttle.check(keys, now); err != nil {
+		return nil, "", err
+	}
 
 	// Call the repository function to fetch the user from the database
 	user, err := s.repo.LoginDBRepository(username)
 	if err != nil {
+		// Unknown usernames count as failures too, so they cannot be probed any faster
+		if err.Error() == "user not found" {
+			if err := s.recordFailure(keys, clientIP, now); err != nil {
+				return nil, "", err
+			}
+		}
 		return nil, "", err // Return the error if user is not found or any other DB issue
 	}
 
 	// Compare the provided password with the stored hashed password
-	hashedPassword := utils.HashPassword(password)
-	if user.Password != hashedPassword {
+	match, needsRehash, err := utils.VerifyPassword(password, user.Password)
+	if err != nil || !match {
+		if err := s.recordFailure(keys, clientIP, now); err != nil {
+			return nil, "", err
+		}
 		return nil, "", errors.New("invalid username or password")
 	}
 
+	// The address keeps its count, so one valid account does not reset it for guessing others
+	if err := s.repo.ClearLoginAttempts(lockout.UserKey(username)); err != nil {
+		return nil, "", err
+	}
+
+	// Only told apart from a wrong password once the password is right
+	switch user.Status {
+	case models.AccountDeactivated:
+		return nil, "", ErrAccountDeactivated
+	case models.AccountDeleted:
+		return nil, "", ErrAccountDeleted
+	}
+
+	// Upgrade legacy SHA-256 hashes and hashes made under an older cost policy. This is best
+	// effort: if it fails the user still logs in and the upgrade is tried again next time.
+	if needsRehash {
+		if hash, err := utils.HashPassword(password); err == nil {
+			if err := s.repo.UpdatePasswordHash(username, hash); err == nil {
+				user.Password = hash
+			}
+		}
+	}
+
 	// Check and display any notifications for the user
 	//s.NotificationService.CheckNotification(username)
 	//notification.CheckNotification(s.repo.conn, username)
@@ -46,7 +194,361 @@ func (s *AuthServiceImpl) Login(username, password string) (*models.User, string
 	return user, user.Role, nil
 }
 
+// recordFailure counts a failed attempt against each key and locks the keys that reach their
+// limit. clientIP is empty for the CLI.
+func (s *AuthServiceImpl) recordFailure(keys []string, clientIP string, now time.Time) error {
+	if clientIP != "" {
+		return s.throttle.recordFailure(keys, AuditSourceREST, clientIP, now)
+	}
+	return s.throttle.recordFailure(keys, AuditSourceCLI, "the CLI", now)
+}
+
+// Signup creates the account with its email address unverified. Callers then send the
+// verification code with SendEmailVerification.
 func (s *AuthServiceImpl) Signup(user *models.User) error {
+	user.EmailVerified = false
 	err := s.repo.SignupDBRepository(user)
 	return err
 }
+
+// SendEmailVerification emails a single-use code that proves the user owns their email
+// address. Email notifications and password resets are not sent to the address until it is
+// verified.
+func (s *AuthServiceImpl) SendEmailVerification(username string) error {
+	if s.mailer == nil {
+		return ErrVerificationUnavailable
+	}
+
+	user, err := s.repo.LoginDBRepository(username)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return errors.New("email address is already verified")
+	}
+
+	now := time.Now()
+	count, err := s.repo.CountEmailVerifications(username, now.Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	if count >= maxVerificationEmails {
+		return ErrTooManyVerificationEmails
+	}
+
+	code, err := randomToken(32)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.SaveEmailVerificationToken(hashToken(code), username, user.Email, now, now.Add(EmailVerificationTTL)); err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Hello %s,\n\nUse this code to verify your CryptoTracker email address:\n\n    %s\n\n"+
+		"Enter it under \"Verify Email\" in the CLI, or send it to POST /verify-email. "+
+		"It can be used once and expires in %v.\n\nIf you did not sign up for CryptoTracker, you can ignore this email.\n",
+		username, code, EmailVerificationTTL)
+	return s.mailer.SendMail(user.Email, "Verify your CryptoTracker email address", body)
+}
+
+// VerifyEmail marks the user's email address verified using an emailed code.
+func (s *AuthServiceImpl) VerifyEmail(code, source string) error {
+	now := time.Now()
+	username, err := s.repo.VerifyEmail(hashToken(strings.TrimSpace(code)), now, &models.AuditEvent{
+		Action:    "email.verified",
+		Source:    source,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+	if username == "" {
+		return ErrInvalidVerificationToken
+	}
+	return nil
+}
+
+// RequestPasswordReset emails a single-use reset code to the account with the given username
+// or email address. It succeeds whether or not an account matches, so it cannot be used to
+// find out which accounts exist.
+func (s *AuthServiceImpl) RequestPasswordReset(identifier string) error {
+	if s.mailer == nil {
+		return ErrResetUnavailable
+	}
+
+	users, err := s.repo.FindUsersForReset(identifier)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, user := range users {
+		// Only verified addresses, so an account cannot be taken over through an address
+		// that was never proven to belong to its owner
+		if user.Email == "" || !user.EmailVerified {
+			continue
+		}
+
+		// Quietly skip users who have been sent enough codes recently, so the address
+		// cannot be flooded
+		count, err := s.repo.CountPasswordResets(user.Username, now.Add(-PasswordResetTTL))
+		if err != nil {
+			return err
+		}
+		if count >= maxPasswordResets {
+			continue
+		}
+
+		code, err := randomToken(32)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.SavePasswordResetToken(hashToken(code), user.Username, now, now.Add(PasswordResetTTL)); err != nil {
+			return err
+		}
+
+		body := fmt.Sprintf("Hello %s,\n\nUse this code to reset your CryptoTracker password:\n\n    %s\n\n"+
+			"Enter it under \"Forgot Password\" in the CLI, or send it to POST /password-reset/confirm. "+
+			"It can be used once and expires in %v.\n\nIf you did not ask to reset your password, you can ignore this email.\n",
+			user.Username, code, PasswordResetTTL)
+		if err := s.mailer.SendMail(user.Email, "Reset your CryptoTracker password", body); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password using an emailed reset code. The code is used up, the
+// user's sessions are ended, on the CLI and REST alike, and any login lockout is lifted.
+func (s *AuthServiceImpl) ResetPassword(code, newPassword, source string) error {
+	if !validation.IsValidPassword(newPassword) {
+		return errors.New("invalid password: must be at least 8 characters, include an uppercase letter, a number, and a special character")
+	}
+
+	hash, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	username, err := s.repo.ResetPassword(hashToken(strings.TrimSpace(code)), hash, now, &models.AuditEvent{
+		Action:    "password.reset",
+		Details:   "password reset with an emailed code",
+		Source:    source,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+	if username == "" {
+		return ErrInvalidResetToken
+	}
+	return nil
+}
+
+// TwoFactorRequired reports whether a user who has given the right password must also give a
+// second factor: because they enabled it, or because it is mandatory for their role. Callers
+// check user.TwoFactorEnabled to tell whether they still have to enroll.
+func (s *AuthServiceImpl) TwoFactorRequired(user *models.User) (bool, error) {
+	if user.TwoFactorEnabled {
+		return true, nil
+	}
+	return s.repo.IsTwoFactorRequired(user.Role)
+}
+
+// VerifySecondFactor checks an authenticator or recovery code after the password has been
+// accepted. Wrong codes count as failed logins, so they are throttled and locked out the same
+// way as wrong passwords.
+func (s *AuthServiceImpl) VerifySecondFactor(username, code, clientIP string) error {
+	now := time.Now()
+	keys := []string{lockout.UserKey(username)}
+	if clientIP != "" {
+		keys = append(keys, lockout.IPKey(clientIP))
+	}
+
+	if err := s.throttle.check(keys, now); err != nil {
+		return err
+	}
+
+	twoFactor, err := s.enabledTwoFactor(username)
+	if err != nil {
+		return err
+	}
+
+	source := AuditSourceCLI
+	if clientIP != "" {
+		source = AuditSourceREST
+	}
+	ok, err := s.checkCode(twoFactor, code, now, &models.AuditEvent{
+		Actor:     username,
+		Action:    "2fa.recovery_code_used",
+		Target:    username,
+		Details:   "logged in with a recovery code",
+		Source:    source,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if err := s.recordFailure(keys, clientIP, now); err != nil {
+			return err
+		}
+		return ErrInvalidTwoFactorCode
+	}
+
+	return s.repo.ClearLoginAttempts(lockout.UserKey(username))
+}
+
+// BeginTwoFactorEnrollment creates a new secret for the user. It has no effect on logins
+// until ConfirmTwoFactorEnrollment is called with a code from it.
+func (s *AuthServiceImpl) BeginTwoFactorEnrollment(username string) (*TwoFactorEnrollment, error) {
+	twoFactor, err := s.repo.GetTwoFactor(username)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor != nil && twoFactor.Enabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveTwoFactorSecret(username, secret, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorEnrollment{
+		Secret: secret,
+		URI:    totp.ProvisioningURI(twoFactorIssuer, username, secret),
+	}, nil
+}
+
+// ConfirmTwoFactorEnrollment enables two-factor authentication once the user shows a code
+// from their new secret, and returns their recovery codes. Only hashes of the codes are kept,
+// so this is the only time they can be shown.
+func (s *AuthServiceImpl) ConfirmTwoFactorEnrollment(username, code, source string) ([]string, error) {
+	twoFactor, err := s.repo.GetTwoFactor(username)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil {
+		return nil, ErrTwoFactorNotStarted
+	}
+	if twoFactor.Enabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	now := time.Now()
+	step, ok := totp.Validate(twoFactor.Secret, code, now)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, err := totp.RecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, recoveryCode := range codes {
+		hashes[i] = hashToken(recoveryCode)
+	}
+
+	err = s.repo.EnableTwoFactor(username, step, hashes, now, &models.AuditEvent{
+		Actor:     username,
+		Action:    "2fa.enabled",
+		Target:    username,
+		Source:    source,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTwoFactor turns two-factor authentication off after checking a current code. It is
+// refused when two-factor authentication is mandatory for the user's role. clientIP is empty
+// for the CLI. Wrong codes are throttled like those given at login.
+func (s *AuthServiceImpl) DisableTwoFactor(username, code, clientIP string) error {
+	now := time.Now()
+	keys := []string{lockout.UserKey(username)}
+	if clientIP != "" {
+		keys = append(keys, lockout.IPKey(clientIP))
+	}
+
+	if err := s.throttle.check(keys, now); err != nil {
+		return err
+	}
+
+	user, err := s.repo.LoginDBRepository(username)
+	if err != nil {
+		return err
+	}
+	required, err := s.repo.IsTwoFactorRequired(user.Role)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrTwoFactorMandatory
+	}
+
+	twoFactor, err := s.enabledTwoFactor(username)
+	if err != nil {
+		return err
+	}
+
+	ok, err := s.checkCode(twoFactor, code, now)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if err := s.recordFailure(keys, clientIP, now); err != nil {
+			return err
+		}
+		return ErrInvalidTwoFactorCode
+	}
+	if err := s.repo.ClearLoginAttempts(lockout.UserKey(username)); err != nil {
+		return err
+	}
+
+	source := AuditSourceCLI
+	if clientIP != "" {
+		source = AuditSourceREST
+	}
+	return s.repo.DisableTwoFactor(username, &models.AuditEvent{
+		Actor:     username,
+		Action:    "2fa.disabled",
+		Target:    username,
+		Source:    source,
+		CreatedAt: now,
+	})
+}
+
+// enabledTwoFactor returns the user's enrollment, or an error if it is not enabled.
+func (s *AuthServiceImpl) enabledTwoFactor(username string) (*models.TwoFactor, error) {
+	twoFactor, err := s.repo.GetTwoFactor(username)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil || !twoFactor.Enabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	return twoFactor, nil
+}
+
+// checkCode accepts an unused authenticator code or recovery code, using it up. The
+// recoveryEvents are recorded in the audit log along with using up a recovery code.
+func (s *AuthServiceImpl) checkCode(twoFactor *models.TwoFactor, code string, now time.Time, recoveryEvents ...*models.AuditEvent) (bool, error) {
+	if totp.IsRecoveryCode(code) {
+		return s.repo.UseRecoveryCode(twoFactor.Username, hashToken(totp.NormalizeRecoveryCode(code)), now, recoveryEvents...)
+	}
+
+	step, ok := totp.Validate(twoFactor.Secret, code, now)
+	if !ok {
+		return false, nil
+	}
+	return s.repo.UseTOTPStep(twoFactor.Username, step)
+}
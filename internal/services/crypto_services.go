@@ -1,6 +1,7 @@
 package services
 
 import (
+	"cryptotracker/internal/rbac"
 	"cryptotracker/internal/repositories"
 	"cryptotracker/models"
 	//"github.com/jackc/pgx/v5"
@@ -9,7 +10,15 @@ import (
 type CryptoService interface {
 	DisplayTopCryptocurrencies(count int) ([]interface{}, error)
 	SearchCryptocurrency(user *models.User, cryptoSymbol string) (float64, string, string, error)
-	SetPriceAlert(user *models.User, symbol string, targetPrice float64) (float64, error)
+	SetPriceAlert(user *models.User, symbol string, targetPrice float64, options *models.AlertOptions) (float64, error)
+	SetRuleAlert(user *models.User, expression string, options *models.AlertOptions) (string, error)
+	SetTrailingAlert(user *models.User, symbol string, trailPercent float64, options *models.AlertOptions) (float64, error)
+	SetLadderAlert(user *models.User, symbol string, levels []float64, options *models.AlertOptions) (*models.AlertGroup, error)
+	SetVolumeSpikeAlert(user *models.User, symbol string, multiplier float64, options *models.AlertOptions) (float64, error)
+	SetRankAlert(user *models.User, symbol string, threshold int, options *models.AlertOptions) (int, error)
+	GetAlertGroups(username, actor string) ([]*models.AlertGroup, error)
+	SetAlertGroupStatus(username string, groupID int, status, actor string) error
+	DeleteAlertGroup(username string, groupID int, actor string) error
 }
 
 type CryptoServiceImpl struct {
@@ -22,6 +31,29 @@ func NewCryptoService(cryptoRepo repositories.CryptoRepository) *CryptoServiceIm
 	}
 }
 
+// authorize returns ErrPermissionDenied unless the actor's current role grants the
+// permission. The role is read afresh rather than taken from a token, so a demotion takes
+// effect at once.
+func (s *CryptoServiceImpl) authorize(actor string, permission rbac.Permission) error {
+	role, err := s.cryptoRepo.GetUserRole(actor)
+	if err != nil {
+		return err
+	}
+	if !rbac.Can(role, permission) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// authorizeFor checks that the actor may manage the alerts of username: their own with
+// AlertsManage, anyone else's with AlertsManageAny.
+func (s *CryptoServiceImpl) authorizeFor(username, actor string) error {
+	if username == actor {
+		return s.authorize(actor, rbac.AlertsManage)
+	}
+	return s.authorize(actor, rbac.AlertsManageAny)
+}
+
 func (s *CryptoServiceImpl) DisplayTopCryptocurrencies(count int) ([]interface{}, error) {
 	return s.cryptoRepo.DisplayTopCryptocurrencies(count)
 }
@@ -30,6 +62,68 @@ func (s *CryptoServiceImpl) SearchCryptocurrency(user *models.User, cryptoSymbol
 	return s.cryptoRepo.SearchCryptocurrency(user, cryptoSymbol)
 }
 
-func (s *CryptoServiceImpl) SetPriceAlert(user *models.User, symbol string, targetPrice float64) (float64, error) {
-	return s.cryptoRepo.SetPriceAlert(user, symbol, targetPrice)
+func (s *CryptoServiceImpl) SetPriceAlert(user *models.User, symbol string, targetPrice float64, options *models.AlertOptions) (float64, error) {
+	if err := s.authorize(user.Username, rbac.AlertsManage); err != nil {
+		return 0, err
+	}
+	return s.cryptoRepo.SetPriceAlert(user, symbol, targetPrice, options)
+}
+
+func (s *CryptoServiceImpl) SetRuleAlert(user *models.User, expression string, options *models.AlertOptions) (string, error) {
+	if err := s.authorize(user.Username, rbac.AlertsManage); err != nil {
+		return "", err
+	}
+	return s.cryptoRepo.SetRuleAlert(user, expression, options)
+}
+
+func (s *CryptoServiceImpl) SetTrailingAlert(user *models.User, symbol string, trailPercent float64, options *models.AlertOptions) (float64, error) {
+	if err := s.authorize(user.Username, rbac.AlertsManage); err != nil {
+		return 0, err
+	}
+	return s.cryptoRepo.SetTrailingAlert(user, symbol, trailPercent, options)
+}
+
+func (s *CryptoServiceImpl) SetLadderAlert(user *models.User, symbol string, levels []float64, options *models.AlertOptions) (*models.AlertGroup, error) {
+	if err := s.authorize(user.Username, rbac.AlertsManage); err != nil {
+		return nil, err
+	}
+	return s.cryptoRepo.SetLadderAlert(user, symbol, levels, options)
+}
+
+func (s *CryptoServiceImpl) SetVolumeSpikeAlert(user *models.User, symbol string, multiplier float64, options *models.AlertOptions) (float64, error) {
+	if err := s.authorize(user.Username, rbac.AlertsManage); err != nil {
+		return 0, err
+	}
+	return s.cryptoRepo.SetVolumeSpikeAlert(user, symbol, multiplier, options)
+}
+
+func (s *CryptoServiceImpl) SetRankAlert(user *models.User, symbol string, threshold int, options *models.AlertOptions) (int, error) {
+	if err := s.authorize(user.Username, rbac.AlertsManage); err != nil {
+		return 0, err
+	}
+	return s.cryptoRepo.SetRankAlert(user, symbol, threshold, options)
+}
+
+// GetAlertGroups returns the alert groups of username, on behalf of the actor.
+func (s *CryptoServiceImpl) GetAlertGroups(username, actor string) ([]*models.AlertGroup, error) {
+	if err := s.authorizeFor(username, actor); err != nil {
+		return nil, err
+	}
+	return s.cryptoRepo.GetAlertGroups(username)
+}
+
+// SetAlertGroupStatus pauses or resumes an alert group of username, on behalf of the actor.
+func (s *CryptoServiceImpl) SetAlertGroupStatus(username string, groupID int, status, actor string) error {
+	if err := s.authorizeFor(username, actor); err != nil {
+		return err
+	}
+	return s.cryptoRepo.SetAlertGroupStatus(username, groupID, status)
+}
+
+// DeleteAlertGroup deletes an alert group of username, on behalf of the actor.
+func (s *CryptoServiceImpl) DeleteAlertGroup(username string, groupID int, actor string) error {
+	if err := s.authorizeFor(username, actor); err != nil {
+		return err
+	}
+	return s.cryptoRepo.DeleteAlertGroup(username, groupID)
 }
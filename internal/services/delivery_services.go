@@ -0,0 +1,133 @@
+package services
+
+import (
+	"cryptotracker/internal/alerts"
+	"cryptotracker/internal/notify"
+	"cryptotracker/internal/repositories"
+	"cryptotracker/models"
+	"cryptotracker/pkg/config"
+	"fmt"
+	"time"
+)
+
+const (
+	// DeliveryCheckInterval is how often the workers look for due deliveries.
+	DeliveryCheckInterval = time.Minute
+
+	deliveryBatchSize = 50
+	deliveryListLimit = 100
+)
+
+type DeliveryService interface {
+	ProcessDueDeliveries(now time.Time) (int, error)
+	GetFailedDeliveries() ([]*models.NotificationDelivery, error)
+	RequeueDelivery(id int) error
+}
+
+type DeliveryServiceImpl struct {
+	repo      repositories.DeliveryRepository
+	notifiers map[string]notify.Notifier
+}
+
+func NewDeliveryService(repo repositories.DeliveryRepository) DeliveryService {
+	return &DeliveryServiceImpl{repo: repo, notifiers: notify.NotifiersFromConfig(config.AppConfig)}
+}
+
+// ProcessDueDeliveries claims the deliveries that are due and tries to send each one, and
+// returns how many were sent. Deliveries in the user's quiet hours wait until they end without
+// using an attempt; failures are retried with backoff and dead-lettered after the last attempt.
+func (s *DeliveryServiceImpl) ProcessDueDeliveries(now time.Time) (int, error) {
+	deliveries, err := s.repo.ClaimDueDeliveries(now, now.Add(notify.ClaimLease), deliveryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	users := make(map[string]*models.User)
+	for _, delivery := range deliveries {
+		user, ok := users[delivery.Username]
+		if !ok {
+			user, err = s.repo.GetRecipient(delivery.Username)
+			if err != nil {
+				// The user may have been removed; the attempt fails like any other
+				notify.RecordFailure(delivery, err, now)
+				if err := s.repo.UpdateDelivery(delivery); err != nil {
+					return sent, err
+				}
+				continue
+			}
+			users[delivery.Username] = user
+		}
+
+		if s.deliver(user, delivery, now) {
+			sent++
+		}
+		if err := s.repo.UpdateDelivery(delivery); err != nil {
+			return sent, err
+		}
+	}
+
+	return sent, nil
+}
+
+// deliver makes one attempt at a claimed delivery and records the outcome on it. It reports
+// whether the message was sent.
+func (s *DeliveryServiceImpl) deliver(user *models.User, delivery *models.NotificationDelivery, now time.Time) bool {
+	prefs := notify.Normalize(user.NotificationPreferences)
+	loc := alerts.LoadLocation(user.Timezone)
+
+	if notify.InQuietHours(prefs, loc, now) {
+		delivery.Status = notify.DeliveryPending
+		delivery.NextAttemptAt = notify.QuietHoursEnd(prefs, loc, now)
+		return false
+	}
+
+	if prefs.MaxPerHour > 0 {
+		count, err := s.repo.CountSentDeliveries(user.Username, now.Add(-time.Hour))
+		if err != nil {
+			notify.RecordFailure(delivery, err, now)
+			return false
+		}
+		if allowed, reason := notify.Allow(prefs, delivery.Channel, loc, now, count); !allowed {
+			delivery.Status = notify.DeliverySuppressed
+			delivery.Error = reason
+			return false
+		}
+	}
+
+	// The address may have changed since the delivery was queued
+	if delivery.Channel == notify.ChannelEmail && !user.EmailVerified {
+		delivery.Status = notify.DeliverySuppressed
+		delivery.Error = "email address not verified"
+		return false
+	}
+
+	notifier, configured := s.notifiers[delivery.Channel]
+	if !configured {
+		notify.RecordFailure(delivery, fmt.Errorf("channel %s is not configured on this server", delivery.Channel), now)
+		return false
+	}
+
+	recipient := notify.Recipient{Username: user.Username, Email: user.Email, Mobile: user.Mobile, WebhookURL: prefs.WebhookURL}
+	message := notify.Message{Event: delivery.EventType, Subject: delivery.Subject, Body: delivery.Body}
+	if err := notifier.Send(recipient, message); err != nil {
+		notify.RecordFailure(delivery, err, now)
+		return false
+	}
+
+	delivery.Attempts++
+	delivery.Status = notify.DeliverySent
+	delivery.Error = ""
+	delivery.SentAt = &now
+	return true
+}
+
+// GetFailedDeliveries returns dead deliveries and those waiting to retry, newest first.
+func (s *DeliveryServiceImpl) GetFailedDeliveries() ([]*models.NotificationDelivery, error) {
+	return s.repo.GetFailedDeliveries(deliveryListLimit)
+}
+
+// RequeueDelivery schedules a failed delivery to be sent again with a fresh set of attempts.
+func (s *DeliveryServiceImpl) RequeueDelivery(id int) error {
+	return s.repo.RequeueDelivery(id, time.Now())
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"cryptotracker/internal/lockout"
+	"cryptotracker/models"
+	"cryptotracker/pkg/config"
+	"fmt"
+	"time"
+)
+
+// loginAttempts stores the failed password and code checks counted by a loginThrottle.
+type loginAttempts interface {
+	GetLoginAttempts(keys []string) ([]*models.LoginAttempt, error)
+	RecordLoginFailure(key string, now, windowStart time.Time) (int, error)
+	LockLogin(key string, until time.Time, events ...*models.AuditEvent) error
+	ClearLoginAttempts(key string) error
+}
+
+// loginThrottle applies the login lockout policy. Every check of a password or second factor
+// goes through one, so they all share a user's failure count.
+type loginThrottle struct {
+	attempts loginAttempts
+	policy   lockout.Policy
+}
+
+func newLoginThrottle(attempts loginAttempts) loginThrottle {
+	return loginThrottle{attempts: attempts, policy: lockout.PolicyFromConfig(config.AppConfig.LoginThrottling)}
+}
+
+// check refuses the attempt if any of the keys is locked or still has to wait.
+func (t loginThrottle) check(keys []string, now time.Time) error {
+	attempts, err := t.attempts.GetLoginAttempts(keys)
+	if err != nil {
+		return err
+	}
+
+	var throttled *LoginThrottledError
+	for _, attempt := range attempts {
+		wait, locked := t.policy.Wait(attempt, now)
+		if wait > 0 && (throttled == nil || wait > throttled.RetryAfter) {
+			throttled = &LoginThrottledError{RetryAfter: wait, Locked: locked}
+		}
+	}
+	if throttled != nil {
+		return throttled
+	}
+	return nil
+}
+
+// recordFailure counts a failed attempt against each key and locks the keys that reach their
+// limit, recording the lockout in the audit log. from says where the last attempt came from.
+func (t loginThrottle) recordFailure(keys []string, source, from string, now time.Time) error {
+	for _, key := range keys {
+		failures, err := t.attempts.RecordLoginFailure(key, now, now.Add(-t.policy.FailureWindow))
+		if err != nil {
+			return err
+		}
+		if failures < t.policy.Limit(key) {
+			continue
+		}
+
+		until := now.Add(t.policy.Lockout)
+		event := &models.AuditEvent{
+			Actor:     "system",
+			Action:    "login.locked",
+			Target:    lockout.Username(key),
+			Details:   fmt.Sprintf("locked until %s after %d failed attempts, the last from %s", until.Format(time.RFC3339), failures, from),
+			Source:    source,
+			CreatedAt: now,
+		}
+		if !lockout.IsUserKey(key) {
+			event.Action = "login.address_locked"
+		}
+		if err := t.attempts.LockLogin(key, until, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
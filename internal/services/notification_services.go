@@ -1,12 +1,14 @@
 package services
 
 import (
-	"cryptotracker/internal/api"
+	"cryptotracker/internal/alerts"
+	"cryptotracker/internal/digest"
+	"cryptotracker/internal/notify"
 	"cryptotracker/internal/repositories"
-	//"cryptotracker/models"
-	"encoding/json"
+	"cryptotracker/models"
 	"fmt"
-	//"strings"
+	"strings"
+	"time"
 )
 
 type Notification struct {
@@ -16,8 +18,15 @@ type Notification struct {
 
 type NotificationService interface {
 	CheckNotification(username string) ([]Notification, error)
+	CheckSessionNotifications(username string) ([]Notification, error)
 	CheckUnavailableCryptoRequestsService(username string) ([]Notification, error)
 	CheckPriceAlertService(username string) ([]Notification, error)
+	GetAlertOccurrences(username string) ([]models.AlertOccurrence, error)
+	GetDigestSettings(username string) (*models.DigestSettings, error)
+	SaveDigestSettings(settings *models.DigestSettings) error
+	PreviewDigest(username string) (string, error)
+	SendDueDigests(now time.Time) error
+	NotifyRequestDecisions(requests []*models.UnavailableCryptoRequest) error
 }
 
 type NotificationServiceImpl struct {
@@ -37,16 +46,44 @@ func (s *NotificationServiceImpl) CheckNotification(username string) ([]Notifica
 	}
 	notifications = append(notifications, unavailableCryptoMsgs...)
 
-	priceAlertMsgs, err := s.CheckPriceAlertService(username)
+	sessionMsgs, err := s.CheckSessionNotifications(username)
 	if err != nil {
 		return nil, err
 	}
-	notifications = append(notifications, priceAlertMsgs...)
+	notifications = append(notifications, sessionMsgs...)
+
+	return notifications, nil
+}
+
+// CheckSessionNotifications returns the notifications that are only shown once: price alerts
+// that fired and digests not yet delivered. Request decisions are left out, as they are
+// reported on every login.
+func (s *NotificationServiceImpl) CheckSessionNotifications(username string) ([]Notification, error) {
+	notifications, err := s.CheckPriceAlertService(username)
+	if err != nil {
+		return nil, err
+	}
+
+	digests, err := s.repo.TakeUndeliveredDigestsRepo(username, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for i, body := range digests {
+		notifications = append(notifications, Notification{Index: i + 1, Message: body})
+	}
 
 	return notifications, nil
 }
 
 func (s *NotificationServiceImpl) CheckUnavailableCryptoRequestsService(username string) ([]Notification, error) {
+	user, err := s.repo.GetNotificationProfileRepo(username)
+	if err != nil {
+		return nil, err
+	}
+	if !notify.WantsInApp(user.NotificationPreferences, notify.EventRequestDecision) {
+		return nil, nil
+	}
+
 	rows, err := s.repo.CheckUnavailableCryptoRequestsRepo(username)
 	if err != nil {
 		return nil, err
@@ -76,48 +113,456 @@ func (s *NotificationServiceImpl) CheckUnavailableCryptoRequestsService(username
 }
 
 func (s *NotificationServiceImpl) CheckPriceAlertService(username string) ([]Notification, error) {
+	now := time.Now()
+	if err := s.repo.ExpirePriceAlertsRepo(username, now); err != nil {
+		return nil, err
+	}
+
 	notifications, err := s.repo.CheckPriceAlertsRepo(username)
 	if err != nil {
 		return nil, err
 	}
 
-	var fulfilledNotifications []Notification
-	index := 1
-	for _, notification := range notifications {
-		params := map[string]string{
-			"id":      fmt.Sprintf("%d", notification.CryptoID),
-			"convert": "USD",
+	if len(notifications) > 0 {
+		quotes, err := s.repo.GetLatestQuotesRepo()
+		if err != nil {
+			return nil, err
 		}
-		apiResponse := api.CoinMarketCapClient{}
-
-		response := apiResponse.GetAPIResponse("/listings/latest", params)
+		market := alerts.NewQuoteMarket(quotes)
 
-		var result map[string]interface{}
-		err := json.Unmarshal(response, &result)
+		// Averages come from earlier snapshots, so they are read before this batch is stored
+		averages, err := s.repo.GetTrailingAveragesRepo(alertSymbols(notifications), now.Add(-alerts.AverageWindow))
 		if err != nil {
 			return nil, err
 		}
+		market.Averages = alerts.AveragesFrom(averages)
+
+		for i := range notifications {
+			if err := s.evaluatePriceAlert(&notifications[i], username, market, now); err != nil {
+				return nil, err
+			}
+		}
 
-		data, dataOk := result["data"].(map[string]interface{})
-		if !dataOk || data[fmt.Sprintf("%d", notification.CryptoID)] == nil {
-			return nil, fmt.Errorf("crypto data not found for ID: %d", notification.CryptoID)
+		if err := s.repo.SaveMarketSnapshotsRepo(quotes, now); err != nil {
+			return nil, err
 		}
+	}
+
+	return s.deliverHeldAlerts(username, now)
+}
 
-		cryptoData := data[fmt.Sprintf("%d", notification.CryptoID)].(map[string]interface{})
-		priceData := cryptoData["quote"].(map[string]interface{})
-		currentPrice := priceData["USD"].(map[string]interface{})["price"].(float64)
+// evaluatePriceAlert fires or re-arms a single alert against the current market.
+func (s *NotificationServiceImpl) evaluatePriceAlert(notification *models.PriceNotification, username string, market alerts.Market, now time.Time) error {
+	var trigger, rearm bool
+	var currentPrice float64
 
-		if currentPrice >= notification.TargetPrice {
-			err := s.repo.UpdatePriceNotificationStatusRepo(&notification, username, currentPrice)
-			if err != nil {
-				return nil, err
+	switch notification.Kind {
+	case "rule":
+		// Rules are validated when created; skip anything that no longer parses
+		rule, err := alerts.ParseRule(notification.Rule)
+		if err != nil {
+			return nil
+		}
+		if quote, ok := market.Quote(rule.Symbols()[0]); ok {
+			currentPrice = quote.Price
+		}
+		trigger = alerts.ShouldTriggerRule(notification, rule, market)
+		rearm = alerts.ShouldRearmRule(notification, rule, market, now)
+	case "trailing":
+		quote, ok := market.Quote(notification.Crypto)
+		if !ok {
+			return nil
+		}
+		currentPrice = quote.Price
+
+		if notification.Status == "Pending" && alerts.UpdateHighWaterMark(notification, currentPrice) {
+			if err := s.repo.UpdateHighWaterMarkRepo(notification.ID, notification.HighWaterMark); err != nil {
+				return err
+			}
+		}
+
+		trigger = alerts.ShouldTriggerTrailing(notification, currentPrice)
+		if trigger {
+			// Record the stop level that was crossed as the occurrence's target
+			notification.TargetPrice = alerts.TrailingStopPrice(notification)
+		}
+		rearm = alerts.ShouldRearmTrailing(notification, now)
+	case "volume_spike":
+		quote, ok := market.Quote(notification.Crypto)
+		if !ok {
+			return nil
+		}
+		average, ok := market.Average(notification.Crypto, "volume_24h")
+		if !ok {
+			// Not enough history yet to tell what a spike is
+			return nil
+		}
+		currentPrice = quote.Volume24h
+
+		trigger = alerts.ShouldTriggerVolumeSpike(notification, quote.Volume24h, average)
+		if trigger {
+			// Record the volume threshold that was crossed as the occurrence's target
+			notification.TargetPrice = notification.VolumeMultiplier * average
+		}
+		rearm = alerts.ShouldRearmCondition(notification, alerts.VolumeSpikeHolds(notification, quote.Volume24h, average), now)
+	case "rank":
+		quote, ok := market.Quote(notification.Crypto)
+		if !ok {
+			return nil
+		}
+		currentPrice = float64(quote.CMCRank)
+		notification.TargetPrice = float64(notification.RankThreshold)
+
+		trigger = alerts.ShouldTriggerRank(notification, quote.CMCRank)
+		rearm = alerts.ShouldRearmCondition(notification, alerts.RankConditionHolds(notification, quote.CMCRank), now)
+	default:
+		quote, ok := market.Quote(strings.ToUpper(notification.Crypto))
+		if !ok {
+			return nil
+		}
+		currentPrice = quote.Price
+		trigger = alerts.ShouldTrigger(notification, currentPrice)
+		rearm = alerts.ShouldRearm(notification, currentPrice, now)
+	}
+
+	switch {
+	case trigger:
+		return s.repo.UpdatePriceNotificationStatusRepo(notification, username, currentPrice)
+	case rearm:
+		if notification.Kind == "trailing" {
+			if err := s.repo.UpdateHighWaterMarkRepo(notification.ID, currentPrice); err != nil {
+				return err
+			}
+		}
+		return s.repo.RearmPriceAlertRepo(notification.ID)
+	}
+	return nil
+}
+
+// alertSymbols lists the assets the given alerts watch.
+func alertSymbols(notifications []models.PriceNotification) []string {
+	seen := map[string]bool{}
+	var symbols []string
+	for _, notification := range notifications {
+		candidates := []string{strings.ToUpper(notification.Crypto)}
+		if notification.Kind == "rule" {
+			candidates = strings.Split(notification.Crypto, ",")
+		}
+		for _, symbol := range candidates {
+			if symbol != "" && !seen[symbol] {
+				seen[symbol] = true
+				symbols = append(symbols, symbol)
 			}
+		}
+	}
+	return symbols
+}
+
+// deliverHeldAlerts turns every undelivered alert firing whose active window is open into a
+// notification. Firings outside their window stay held until a later check.
+func (s *NotificationServiceImpl) deliverHeldAlerts(username string, now time.Time) ([]Notification, error) {
+	occurrences, err := s.repo.GetUndeliveredOccurrencesRepo(username)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(occurrences) == 0 {
+		return nil, nil
+	}
 
-			message := fmt.Sprintf("Your price alert for %s (ID: %d) at target price %d has been fulfilled at current price $%.2f.", notification.Crypto, notification.TargetPrice, notification.CryptoID, currentPrice)
-			fulfilledNotifications = append(fulfilledNotifications, Notification{Index: index, Message: message})
+	user, err := s.repo.GetNotificationProfileRepo(username)
+	if err != nil {
+		return nil, err
+	}
+	loc := alerts.LoadLocation(user.Timezone)
+
+	var fulfilledNotifications []Notification
+	var delivered []int
+	index := 1
+	for _, occurrence := range occurrences {
+		if !alerts.InActiveWindow(occurrence.ActiveFrom, occurrence.ActiveTo, loc, now) {
+			continue
+		}
+
+		message := notify.Message{
+			Event:   notify.EventPriceAlert,
+			Subject: "CryptoTracker alert: " + occurrence.Crypto,
+			Body:    occurrenceMessage(&occurrence, loc),
+		}
+		showInApp, err := s.dispatch(user, message, now)
+		if err != nil {
+			return nil, err
+		}
+
+		if showInApp {
+			fulfilledNotifications = append(fulfilledNotifications, Notification{Index: index, Message: message.Body})
 			index++
 		}
+		delivered = append(delivered, occurrence.ID)
+	}
+
+	if err := s.repo.MarkOccurrencesDeliveredRepo(delivered, now); err != nil {
+		return nil, err
 	}
 
 	return fulfilledNotifications, nil
 }
+
+func occurrenceMessage(occurrence *models.AlertOccurrence, loc *time.Location) string {
+	triggeredAt := occurrence.TriggeredAt.In(loc).Format("Jan 2 15:04 MST")
+	switch {
+	case occurrence.Rule != "":
+		return fmt.Sprintf("Your alert %s was triggered on %s.", occurrence.Rule, triggeredAt)
+	case occurrence.Kind == "volume_spike":
+		return fmt.Sprintf("%s 24h volume spiked to $%.0f, above your threshold of $%.0f, on %s.",
+			occurrence.Crypto, occurrence.TriggeredPrice, occurrence.TargetPrice, triggeredAt)
+	case occurrence.Kind == alerts.PegDepegged:
+		return fmt.Sprintf("%s has lost its $%.2f peg: trading at $%.4f since %s.",
+			occurrence.Crypto, occurrence.TargetPrice, occurrence.TriggeredPrice, triggeredAt)
+	case occurrence.Kind == alerts.PegRecovered:
+		return fmt.Sprintf("%s is back at its $%.2f peg: trading at $%.4f on %s.",
+			occurrence.Crypto, occurrence.TargetPrice, occurrence.TriggeredPrice, triggeredAt)
+	case occurrence.Kind == "rank":
+		return fmt.Sprintf("%s moved to market-cap rank #%.0f, crossing your rank %.0f alert, on %s.",
+			occurrence.Crypto, occurrence.TriggeredPrice, occurrence.TargetPrice, triggeredAt)
+	}
+	return fmt.Sprintf("Your price alert for %s at target price $%.2f was fulfilled at $%.2f on %s.",
+		occurrence.Crypto, occurrence.TargetPrice, occurrence.TriggeredPrice, triggeredAt)
+}
+
+func (s *NotificationServiceImpl) GetAlertOccurrences(username string) ([]models.AlertOccurrence, error) {
+	return s.repo.GetAlertOccurrencesRepo(username)
+}
+
+// GetDigestSettings returns the user's digest schedule, or the defaults (digests off) when none is saved.
+func (s *NotificationServiceImpl) GetDigestSettings(username string) (*models.DigestSettings, error) {
+	settings, err := s.repo.GetDigestSettingsRepo(username)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = &models.DigestSettings{Username: username, Frequency: "off", SendAt: "08:00", Weekday: 1}
+	}
+	return settings, nil
+}
+
+// SaveDigestSettings validates and stores a digest schedule. The first digest goes out at the
+// next scheduled time rather than immediately.
+func (s *NotificationServiceImpl) SaveDigestSettings(settings *models.DigestSettings) error {
+	if err := digest.Validate(settings); err != nil {
+		return fmt.Errorf("invalid digest settings: %v", err)
+	}
+
+	var watchlist []string
+	for _, symbol := range settings.Watchlist {
+		if symbol = strings.ToUpper(strings.TrimSpace(symbol)); symbol != "" {
+			watchlist = append(watchlist, symbol)
+		}
+	}
+	settings.Watchlist = watchlist
+
+	now := time.Now()
+	settings.LastSentAt = &now
+	return s.repo.SaveDigestSettingsRepo(settings)
+}
+
+// PreviewDigest renders the digest the user would receive now, without sending it.
+func (s *NotificationServiceImpl) PreviewDigest(username string) (string, error) {
+	settings, err := s.GetDigestSettings(username)
+	if err != nil {
+		return "", err
+	}
+	if settings.Frequency == "off" {
+		settings.Frequency = "daily"
+	}
+
+	quotes, err := s.repo.GetLatestQuotesRepo()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	d, err := s.buildDigest(settings, quotes, now.Add(-digest.Period(settings.Frequency)), now)
+	if err != nil {
+		return "", err
+	}
+	return digest.Render(d)
+}
+
+// SendDueDigests renders and delivers every digest whose scheduled local time has passed.
+func (s *NotificationServiceImpl) SendDueDigests(now time.Time) error {
+	schedules, err := s.repo.GetScheduledDigestsRepo()
+	if err != nil {
+		return err
+	}
+
+	var quotes map[string]*models.Quote
+	for _, settings := range schedules {
+		user, err := s.repo.GetNotificationProfileRepo(settings.Username)
+		if err != nil {
+			return err
+		}
+		if !digest.Due(settings, alerts.LoadLocation(user.Timezone), now) {
+			continue
+		}
+
+		// One listing fetch serves every digest sent in this pass
+		if quotes == nil {
+			if quotes, err = s.repo.GetLatestQuotesRepo(); err != nil {
+				return err
+			}
+		}
+
+		since := now.Add(-digest.Period(settings.Frequency))
+		if settings.LastSentAt != nil && settings.LastSentAt.After(since) {
+			since = *settings.LastSentAt
+		}
+
+		d, err := s.buildDigest(settings, quotes, since, now)
+		if err != nil {
+			return err
+		}
+		body, err := digest.Render(d)
+		if err != nil {
+			return err
+		}
+
+		message := notify.Message{Event: notify.EventDigest, Subject: "Your CryptoTracker " + settings.Frequency + " digest", Body: body}
+		showInApp, err := s.dispatch(user, message, now)
+		if err != nil {
+			return err
+		}
+
+		// In-app digests wait for the user's next notification check
+		if showInApp {
+			if err := s.repo.SaveDigestRepo(settings.Username, body, now); err != nil {
+				return err
+			}
+		}
+		if err := s.repo.MarkDigestSentRepo(settings.Username, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// buildDigest gathers the data for one user's digest covering since to now.
+func (s *NotificationServiceImpl) buildDigest(settings *models.DigestSettings, quotes map[string]*models.Quote, since, now time.Time) (*models.Digest, error) {
+	username := settings.Username
+
+	timezone, err := s.repo.GetUserTimezoneRepo(username)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &models.Digest{
+		Username:    username,
+		Frequency:   settings.Frequency,
+		PeriodStart: since,
+		PeriodEnd:   now,
+		Location:    alerts.LoadLocation(timezone),
+	}
+
+	active, err := s.repo.CheckPriceAlertsRepo(username)
+	if err != nil {
+		return nil, err
+	}
+
+	watchlist := settings.Watchlist
+	if len(watchlist) == 0 {
+		watchlist = alertSymbols(active)
+	}
+	for _, symbol := range watchlist {
+		if quote, ok := quotes[symbol]; ok {
+			d.Coins = append(d.Coins, quote)
+		}
+	}
+
+	occurrences, err := s.repo.GetAlertOccurrencesRepo(username)
+	if err != nil {
+		return nil, err
+	}
+	for _, occurrence := range occurrences {
+		if !occurrence.TriggeredAt.Before(since) {
+			d.Fired = append(d.Fired, occurrence)
+		}
+	}
+
+	for i := range active {
+		quote, ok := quotes[strings.ToUpper(active[i].Crypto)]
+		if !ok {
+			continue
+		}
+		if distance, near := digest.NearTarget(&active[i], quote.Price); near {
+			d.NearlyFired = append(d.NearlyFired, models.DigestNearAlert{
+				Crypto:          quote.Symbol,
+				TargetPrice:     active[i].TargetPrice,
+				CurrentPrice:    quote.Price,
+				DistancePercent: distance,
+			})
+		}
+	}
+
+	if d.Requests, err = s.repo.GetCryptoRequestsRepo(username); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// NotifyRequestDecisions tells each requester, on their outbound channels, that an admin has
+// decided on their request. The in-app message is shown by CheckUnavailableCryptoRequestsService.
+func (s *NotificationServiceImpl) NotifyRequestDecisions(requests []*models.UnavailableCryptoRequest) error {
+	now := time.Now()
+	for _, request := range requests {
+		user, err := s.repo.GetNotificationProfileRepo(request.UserName)
+		if err != nil {
+			return err
+		}
+
+		message := notify.Message{
+			Event:   notify.EventRequestDecision,
+			Subject: "Your CryptoTracker request for " + strings.ToUpper(request.CryptoSymbol),
+			Body:    fmt.Sprintf("Your request for %s has been %s.", request.CryptoSymbol, request.Status),
+		}
+		if _, err := s.dispatch(user, message, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dispatch queues a message for every outbound channel the user routes its event to and
+// reports whether it should also be shown in the app. Email is skipped until the user has
+// verified their address. The delivery workers send queued messages, applying quiet hours
+// and the hourly cap at send time.
+func (s *NotificationServiceImpl) dispatch(user *models.User, message notify.Message, now time.Time) (bool, error) {
+	showInApp := false
+	for _, channel := range notify.ChannelsFor(user.NotificationPreferences, message.Event) {
+		if channel == notify.ChannelInApp {
+			showInApp = true
+			continue
+		}
+		if channel == notify.ChannelEmail && !user.EmailVerified {
+			continue
+		}
+
+		delivery := &models.NotificationDelivery{
+			Username:      user.Username,
+			EventType:     message.Event,
+			Channel:       channel,
+			Subject:       message.Subject,
+			Body:          message.Body,
+			Status:        notify.DeliveryPending,
+			MaxAttempts:   notify.DefaultMaxAttempts,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		}
+		if err := s.repo.EnqueueDeliveryRepo(delivery); err != nil {
+			return false, err
+		}
+	}
+
+	return showInApp, nil
+}
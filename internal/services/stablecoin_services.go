@@ -0,0 +1,108 @@
+package services
+
+import (
+	"cryptotracker/internal/alerts"
+	"cryptotracker/internal/repositories"
+	"cryptotracker/models"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DepegCheckInterval is how often the monitor samples stablecoin prices.
+const DepegCheckInterval = 5 * time.Minute
+
+type StablecoinService interface {
+	GetPegs() ([]*models.StablecoinPeg, error)
+	SavePeg(peg *models.StablecoinPeg) error
+	DeletePeg(symbol string) error
+	CheckPegs(now time.Time) error
+	IsSubscribed(username string) (bool, error)
+	Subscribe(username string) error
+	Unsubscribe(username string) error
+}
+
+type StablecoinServiceImpl struct {
+	repo repositories.StablecoinRepository
+}
+
+func NewStablecoinService(repo repositories.StablecoinRepository) StablecoinService {
+	return &StablecoinServiceImpl{repo: repo}
+}
+
+func (s *StablecoinServiceImpl) GetPegs() ([]*models.StablecoinPeg, error) {
+	return s.repo.GetPegs()
+}
+
+func (s *StablecoinServiceImpl) SavePeg(peg *models.StablecoinPeg) error {
+	peg.Symbol = strings.ToUpper(strings.TrimSpace(peg.Symbol))
+	if err := alerts.ValidatePeg(peg); err != nil {
+		return fmt.Errorf("invalid peg: %v", err)
+	}
+	return s.repo.SavePeg(peg)
+}
+
+func (s *StablecoinServiceImpl) DeletePeg(symbol string) error {
+	return s.repo.DeletePeg(symbol)
+}
+
+// CheckPegs takes one price sample of every monitored stablecoin. A de-peg that has lasted the
+// configured number of samples, and the later recovery, are sent to subscribers and admins.
+func (s *StablecoinServiceImpl) CheckPegs(now time.Time) error {
+	pegs, err := s.repo.GetPegs()
+	if err != nil {
+		return err
+	}
+
+	if len(pegs) == 0 {
+		return nil
+	}
+
+	quotes, err := s.repo.GetLatestQuotes()
+	if err != nil {
+		return err
+	}
+
+	var recipients []string
+	for _, peg := range pegs {
+		quote, ok := quotes[peg.Symbol]
+		if !ok {
+			continue
+		}
+
+		event := alerts.ObservePeg(peg, quote.Price)
+		peg.UpdatedAt = &now
+		if err := s.repo.UpdatePegState(peg); err != nil {
+			return err
+		}
+
+		if event == "" {
+			continue
+		}
+
+		if recipients == nil {
+			recipients, err = s.repo.GetDepegRecipients()
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := s.repo.RecordPegEvent(peg, event, recipients, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *StablecoinServiceImpl) IsSubscribed(username string) (bool, error) {
+	return s.repo.IsSubscribed(username)
+}
+
+func (s *StablecoinServiceImpl) Subscribe(username string) error {
+	return s.repo.Subscribe(username)
+}
+
+func (s *StablecoinServiceImpl) Unsubscribe(username string) error {
+	return s.repo.Unsubscribe(username)
+}
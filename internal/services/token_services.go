@@ -0,0 +1,471 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"cryptotracker/internal/repositories"
+	"cryptotracker/models"
+	"cryptotracker/pkg/config"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+	"strings"
+	"time"
+)
+
+const (
+	// AccessTokenTTL is how long a REST access token is valid.
+	AccessTokenTTL = 15 * time.Minute
+	// RefreshTokenTTL is how long a refresh token can be exchanged for new tokens.
+	RefreshTokenTTL = 7 * 24 * time.Hour
+	// TwoFactorChallengeTTL is how long a client has to send the second factor after the
+	// password was accepted.
+	TwoFactorChallengeTTL = 5 * time.Minute
+	// SessionIdleTimeout is how long a session lasts without being used. A REST session is
+	// used whenever one of its tokens is, so it lives as long as its refresh tokens.
+	SessionIdleTimeout = RefreshTokenTTL
+
+	// SessionClientCLI and SessionClientREST say where a session was started from.
+	SessionClientCLI  = "cli"
+	SessionClientREST = "rest"
+
+	// APIKeyPrefix starts every API key, so they can be told apart from access tokens.
+	APIKeyPrefix = "ctk_"
+	// ScopeMarketRead lets an API key read market data.
+	ScopeMarketRead = "market:read"
+	// ScopeAlertsManage lets an API key list, create, pause and delete its owner's alerts.
+	ScopeAlertsManage = "alerts:manage"
+	// DefaultAPIKeyDays is how long an API key is valid for when no expiry is given.
+	DefaultAPIKeyDays = 90
+	// MaxAPIKeyDays is the longest an API key can be valid for.
+	MaxAPIKeyDays = 365
+
+	maxAPIKeyNameLength = 50
+	apiKeyPrefixLength  = len(APIKeyPrefix) + 6
+
+	tokenIssuer = "cryptotracker"
+	// twoFactorAudience marks challenge tokens so they are never accepted as access tokens.
+	twoFactorAudience = "2fa"
+)
+
+var (
+	// ErrInvalidToken is returned for tokens that are malformed, expired or revoked.
+	ErrInvalidToken = errors.New("invalid or expired token")
+	// ErrSessionEnded is returned when a session has expired or been revoked.
+	ErrSessionEnded = errors.New("your session has ended, please log in again")
+)
+
+// TokenPair is returned to REST clients when they log in or refresh.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// APIKeyScopes are the scopes an API key can be given.
+var APIKeyScopes = []string{ScopeMarketRead, ScopeAlertsManage}
+
+// TokenClaims are carried in an access token. The subject is the username, the ID is used to
+// revoke the token and SessionID ties it to the login it came from. Claims built from an API
+// key carry its scopes instead of a session.
+type TokenClaims struct {
+	Role      string   `json:"role"`
+	SessionID string   `json:"sid,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`
+	jwt.StandardClaims
+}
+
+// IsAPIKey reports whether the claims were built from an API key rather than an access token.
+func (c *TokenClaims) IsAPIKey() bool {
+	return c.Scopes != nil
+}
+
+// HasScope reports whether an API key was given the scope.
+func (c *TokenClaims) HasScope(scope string) bool {
+	return hasString(c.Scopes, scope)
+}
+
+type TokenService interface {
+	IssueTokens(username, role, clientIP string) (*TokenPair, error)
+	RefreshTokens(refreshToken string) (*TokenPair, error)
+	ValidateAccessToken(accessToken string) (*TokenClaims, error)
+	IssueTwoFactorChallenge(username, role string) (string, error)
+	ValidateTwoFactorChallenge(challengeToken string) (*TokenClaims, error)
+	RevokeTokens(claims *TokenClaims, refreshToken string) error
+	PurgeExpiredTokens(now time.Time) error
+	StartSession(username, client, clientIP string) (string, error)
+	CheckSession(sessionID string) error
+	ListSessions(username string) ([]*models.Session, error)
+	RevokeSession(username, sessionID string) error
+	RevokeUserSessions(username string) (int, error)
+	CreateAPIKey(username, name string, scopes []string, expiresInDays int, source string) (*models.APIKey, string, error)
+	ListAPIKeys(username string) ([]*models.APIKey, error)
+	RevokeAPIKey(username string, id int, source string) error
+	ValidateAPIKey(apiKey string) (*TokenClaims, error)
+}
+
+type TokenServiceImpl struct {
+	repo   repositories.TokenRepository
+	secret []byte
+}
+
+func NewTokenService(repo repositories.TokenRepository) TokenService {
+	secret := []byte(config.AppConfig.JWTSecret)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			panic(fmt.Sprintf("failed to generate JWT secret: %v", err))
+		}
+	}
+	return &TokenServiceImpl{repo: repo, secret: secret}
+}
+
+// IssueTokens starts a REST session for the user and issues its first token pair.
+func (s *TokenServiceImpl) IssueTokens(username, role, clientIP string) (*TokenPair, error) {
+	sessionID, err := s.StartSession(username, SessionClientREST, clientIP)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueTokens(username, role, sessionID)
+}
+
+// issueTokens signs an access token for the session and stores a new refresh token.
+func (s *TokenServiceImpl) issueTokens(username, role, sessionID string) (*TokenPair, error) {
+	now := time.Now()
+
+	jti, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	claims := &TokenClaims{
+		Role:      role,
+		SessionID: sessionID,
+		StandardClaims: jwt.StandardClaims{
+			Id:        jti,
+			Subject:   username,
+			Issuer:    tokenIssuer,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(AccessTokenTTL).Unix(),
+		},
+	}
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign access token: %v", err)
+	}
+
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveRefreshToken(hashToken(refreshToken), username, sessionID, now, now.Add(RefreshTokenTTL)); err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// RefreshTokens exchanges a refresh token for a new pair in the same session. The refresh
+// token is used up, and the new access token carries the user's current role.
+func (s *TokenServiceImpl) RefreshTokens(refreshToken string) (*TokenPair, error) {
+	username, sessionID, err := s.repo.ConsumeRefreshToken(hashToken(refreshToken), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if username == "" || sessionID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	if err := s.CheckSession(sessionID); err != nil {
+		if err == ErrSessionEnded {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	role, err := s.repo.GetUserRole(username)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return s.issueTokens(username, role, sessionID)
+}
+
+// ValidateAccessToken checks the signature, expiry and revocation of an access token and
+// that its session is still live.
+func (s *TokenServiceImpl) ValidateAccessToken(accessToken string) (*TokenClaims, error) {
+	claims, err := s.parseToken(accessToken)
+	if err != nil || claims.Id == "" || claims.SessionID == "" || claims.Audience != "" {
+		return nil, ErrInvalidToken
+	}
+
+	revoked, err := s.repo.IsAccessTokenRevoked(claims.Id)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrInvalidToken
+	}
+
+	if err := s.CheckSession(claims.SessionID); err != nil {
+		if err == ErrSessionEnded {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// IssueTwoFactorChallenge signs a short-lived token showing that the user's password was
+// accepted. It is exchanged for a token pair once the second factor is checked.
+func (s *TokenServiceImpl) IssueTwoFactorChallenge(username, role string) (string, error) {
+	now := time.Now()
+	claims := &TokenClaims{
+		Role: role,
+		StandardClaims: jwt.StandardClaims{
+			Subject:   username,
+			Audience:  twoFactorAudience,
+			Issuer:    tokenIssuer,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(TwoFactorChallengeTTL).Unix(),
+		},
+	}
+
+	challenge, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign challenge token: %v", err)
+	}
+	return challenge, nil
+}
+
+// ValidateTwoFactorChallenge checks the signature and expiry of a challenge token.
+func (s *TokenServiceImpl) ValidateTwoFactorChallenge(challengeToken string) (*TokenClaims, error) {
+	claims, err := s.parseToken(challengeToken)
+	if err != nil || !claims.VerifyAudience(twoFactorAudience, true) {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// parseToken checks the signature and expiry of a token signed by this service.
+func (s *TokenServiceImpl) parseToken(signed string) (*TokenClaims, error) {
+	claims := &TokenClaims{}
+	token, err := jwt.ParseWithClaims(signed, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return s.secret, nil
+	})
+	if err != nil || !token.Valid || claims.Subject == "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// RevokeTokens ends a REST session: the access token and its session are revoked along with
+// the given refresh token, or with every refresh token of the user when none is given.
+func (s *TokenServiceImpl) RevokeTokens(claims *TokenClaims, refreshToken string) error {
+	now := time.Now()
+
+	if err := s.repo.RevokeAccessToken(claims.Id, time.Unix(claims.ExpiresAt, 0)); err != nil {
+		return err
+	}
+	if err := s.repo.RevokeSession(claims.Subject, claims.SessionID, now); err != nil {
+		return err
+	}
+
+	if refreshToken == "" {
+		return s.repo.RevokeUserRefreshTokens(claims.Subject, now)
+	}
+	return s.repo.RevokeRefreshToken(hashToken(refreshToken), now)
+}
+
+// PurgeExpiredTokens deletes token records past their expiry.
+func (s *TokenServiceImpl) PurgeExpiredTokens(now time.Time) error {
+	return s.repo.DeleteExpiredTokens(now)
+}
+
+// StartSession records a new login of the user and returns the session ID.
+func (s *TokenServiceImpl) StartSession(username, client, clientIP string) (string, error) {
+	id, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	session := &models.Session{
+		ID:         id,
+		Username:   username,
+		Client:     client,
+		IP:         clientIP,
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+	if err := s.repo.CreateSession(session, now.Add(SessionIdleTimeout)); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// CheckSession records that the session is in use, or returns ErrSessionEnded when it has
+// expired or been revoked.
+func (s *TokenServiceImpl) CheckSession(sessionID string) error {
+	now := time.Now()
+	active, err := s.repo.TouchSession(sessionID, now, now.Add(SessionIdleTimeout))
+	if err != nil {
+		return err
+	}
+	if !active {
+		return ErrSessionEnded
+	}
+	return nil
+}
+
+// ListSessions returns the user's live sessions, most recently used first.
+func (s *TokenServiceImpl) ListSessions(username string) ([]*models.Session, error) {
+	return s.repo.GetActiveSessions(username, time.Now())
+}
+
+// RevokeSession ends one of the user's sessions.
+func (s *TokenServiceImpl) RevokeSession(username, sessionID string) error {
+	return s.repo.RevokeSession(username, sessionID, time.Now())
+}
+
+// RevokeUserSessions ends every session of the user and returns how many there were.
+func (s *TokenServiceImpl) RevokeUserSessions(username string) (int, error) {
+	return s.repo.RevokeUserSessions(username, time.Now())
+}
+
+// CreateAPIKey mints a named API key limited to the scopes and valid for the given number of
+// days. The key is returned once and only its hash is stored.
+func (s *TokenServiceImpl) CreateAPIKey(username, name string, scopes []string, expiresInDays int, source string) (*models.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxAPIKeyNameLength {
+		return nil, "", fmt.Errorf("API key name must be 1 to %d characters", maxAPIKeyNameLength)
+	}
+	if expiresInDays < 1 || expiresInDays > MaxAPIKeyDays {
+		return nil, "", fmt.Errorf("API key must expire in 1 to %d days", MaxAPIKeyDays)
+	}
+
+	var granted []string
+	for _, scope := range scopes {
+		if !hasString(APIKeyScopes, scope) {
+			return nil, "", fmt.Errorf("unknown scope %q: must be one of %s", scope, strings.Join(APIKeyScopes, ", "))
+		}
+		if !hasString(granted, scope) {
+			granted = append(granted, scope)
+		}
+	}
+	if len(granted) == 0 {
+		return nil, "", fmt.Errorf("an API key needs at least one scope")
+	}
+
+	secret, err := randomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	apiKey := APIKeyPrefix + secret
+
+	now := time.Now()
+	key := &models.APIKey{
+		Username:  username,
+		Name:      name,
+		Prefix:    apiKey[:apiKeyPrefixLength],
+		Scopes:    granted,
+		CreatedAt: now,
+		ExpiresAt: now.AddDate(0, 0, expiresInDays),
+	}
+	err = s.repo.SaveAPIKey(key, hashToken(apiKey), &models.AuditEvent{
+		Actor:     username,
+		Action:    "apikey.created",
+		Target:    username,
+		Details:   fmt.Sprintf("%s (%s) with scopes %s", key.Name, key.Prefix, strings.Join(key.Scopes, ", ")),
+		Source:    source,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return key, apiKey, nil
+}
+
+// ListAPIKeys returns the user's API keys that have not been revoked, newest first.
+func (s *TokenServiceImpl) ListAPIKeys(username string) ([]*models.APIKey, error) {
+	return s.repo.GetAPIKeys(username)
+}
+
+// RevokeAPIKey revokes one of the user's API keys.
+func (s *TokenServiceImpl) RevokeAPIKey(username string, id int, source string) error {
+	now := time.Now()
+	return s.repo.RevokeAPIKey(username, id, now, &models.AuditEvent{
+		Actor:     username,
+		Action:    "apikey.revoked",
+		Target:    username,
+		Details:   fmt.Sprintf("API key %d", id),
+		Source:    source,
+		CreatedAt: now,
+	})
+}
+
+// ValidateAPIKey checks that an API key is live and records its use. The claims carry the
+// owner's current role and the key's scopes.
+func (s *TokenServiceImpl) ValidateAPIKey(apiKey string) (*TokenClaims, error) {
+	if !strings.HasPrefix(apiKey, APIKeyPrefix) {
+		return nil, ErrInvalidToken
+	}
+
+	key, err := s.repo.UseAPIKey(hashToken(apiKey), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, ErrInvalidToken
+	}
+
+	role, err := s.repo.GetUserRole(key.Username)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return &TokenClaims{
+		Role:   role,
+		Scopes: key.Scopes,
+		StandardClaims: jwt.StandardClaims{
+			Id:        fmt.Sprintf("apikey:%d", key.ID),
+			Subject:   key.Username,
+			ExpiresAt: key.ExpiresAt.Unix(),
+		},
+	}, nil
+}
+
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func hasString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
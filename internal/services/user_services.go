@@ -1,22 +1,163 @@
 package services
 
 import (
+	"cryptotracker/internal/lockout"
+	"cryptotracker/internal/notify"
 	"cryptotracker/internal/repositories"
 	"cryptotracker/models"
+	"cryptotracker/pkg/utils"
+	"cryptotracker/pkg/validation"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 )
 
+// ErrWrongPassword is returned when the current password given to change it does not match.
+var ErrWrongPassword = errors.New("current password is incorrect")
+
+// ProfileUpdate holds the changes a user asks for. Fields left nil or empty are not changed;
+// changing the password needs the current one.
+type ProfileUpdate struct {
+	Email                   *string                         `json:"email"`
+	Mobile                  *string                         `json:"mobile"`
+	CurrentPassword         string                          `json:"current_password"`
+	NewPassword             string                          `json:"new_password"`
+	NotificationPreferences *models.NotificationPreferences `json:"notificationPreferences"`
+}
+
 type UserServices interface {
 	GetUserProfile(username string) (*models.User, error)
+	GetNotificationPreferences(username string) (*models.NotificationPreferences, error)
+	UpdateNotificationPreferences(username string, preferences *models.NotificationPreferences) error
+	UpdateProfile(username string, update *ProfileUpdate, source string) (bool, error)
 }
 
 type UserServiceImpl struct {
-	repo repositories.UserRepository
+	repo     repositories.UserRepository
+	throttle loginThrottle
 }
 
 func NewUserService(repo repositories.UserRepository) *UserServiceImpl {
-	return &UserServiceImpl{repo: repo}
+	return &UserServiceImpl{repo: repo, throttle: newLoginThrottle(repo)}
 }
 
 func (s *UserServiceImpl) GetUserProfile(username string) (*models.User, error) {
 	return s.repo.GetUserProfile(username)
 }
+
+// GetNotificationPreferences returns the user's preferences with defaults filled in.
+func (s *UserServiceImpl) GetNotificationPreferences(username string) (*models.NotificationPreferences, error) {
+	preferences, err := s.repo.GetNotificationPreferences(username)
+	if err != nil {
+		return nil, err
+	}
+	return notify.Normalize(preferences), nil
+}
+
+func (s *UserServiceImpl) UpdateNotificationPreferences(username string, preferences *models.NotificationPreferences) error {
+	if err := notify.Validate(preferences); err != nil {
+		return fmt.Errorf("invalid notification preferences: %v", err)
+	}
+	return s.repo.UpdateNotificationPreferences(username, notify.Normalize(preferences))
+}
+
+// UpdateProfile validates every requested change and then applies them together. It reports
+// whether the email address changed, in which case it is unverified until the user confirms
+// it with the code from AuthService.SendEmailVerification. Email and password changes are
+// recorded in the audit log. Wrong current passwords count as failed logins for the user, so
+// they are throttled and can lock the account out like a login.
+func (s *UserServiceImpl) UpdateProfile(username string, update *ProfileUpdate, source string) (bool, error) {
+	current, err := s.repo.GetUserProfile(username)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	changes := &models.ProfileChanges{}
+	var events []*models.AuditEvent
+
+	if update.Email != nil {
+		email := strings.TrimSpace(*update.Email)
+		if email != current.Email {
+			if !validation.IsValidEmail(email) {
+				return false, errors.New("invalid email: must be a valid email address")
+			}
+			changes.Email = &email
+			events = append(events, &models.AuditEvent{
+				Actor:   username,
+				Action:  "email.changed",
+				Target:  username,
+				Details: fmt.Sprintf("changed from %s to %s", current.Email, email),
+			})
+		}
+	}
+
+	if update.Mobile != nil {
+		mobile, err := validation.NormalizeMobile(*update.Mobile)
+		if err != nil {
+			return false, fmt.Errorf("invalid mobile number: %v", err)
+		}
+		if mobile != current.Mobile {
+			changes.Mobile = &mobile
+		}
+	}
+
+	if update.NewPassword != "" {
+		if update.CurrentPassword == "" {
+			return false, errors.New("the current password is required to change the password")
+		}
+		keys := []string{lockout.UserKey(username)}
+		if err := s.throttle.check(keys, now); err != nil {
+			return false, err
+		}
+		hash, err := s.repo.GetPasswordHash(username)
+		if err != nil {
+			return false, err
+		}
+		if match, _, err := utils.VerifyPassword(update.CurrentPassword, hash); err != nil || !match {
+			from := "the CLI"
+			if source == AuditSourceREST {
+				from = "the REST API"
+			}
+			if err := s.throttle.recordFailure(keys, source, from, now); err != nil {
+				return false, err
+			}
+			return false, ErrWrongPassword
+		}
+		if err := s.repo.ClearLoginAttempts(keys[0]); err != nil {
+			return false, err
+		}
+		if !validation.IsValidPassword(update.NewPassword) {
+			return false, errors.New("invalid password: must be at least 8 characters, include an uppercase letter, a number, and a special character")
+		}
+
+		newHash, err := utils.HashPassword(update.NewPassword)
+		if err != nil {
+			return false, err
+		}
+		changes.PasswordHash = &newHash
+		events = append(events, &models.AuditEvent{
+			Actor:  username,
+			Action: "password.changed",
+			Target: username,
+		})
+	}
+
+	if update.NotificationPreferences != nil {
+		if err := notify.Validate(update.NotificationPreferences); err != nil {
+			return false, fmt.Errorf("invalid notification preferences: %v", err)
+		}
+		changes.NotificationPreferences = notify.Normalize(update.NotificationPreferences)
+	}
+
+	for _, event := range events {
+		event.Source = source
+		event.CreatedAt = now
+	}
+	if err := s.repo.UpdateProfile(username, changes, now, events...); err != nil {
+		return false, err
+	}
+
+	return changes.Email != nil, nil
+}
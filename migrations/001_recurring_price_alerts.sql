@@ -0,0 +1,24 @@
+-- Recurring price alerts: alerts can re-arm after a cooldown or once the
+-- price has moved back out of a hysteresis band, and every firing is kept.
+
+ALTER TABLE price_notifications
+    ADD COLUMN IF NOT EXISTS id BIGSERIAL,
+    ADD COLUMN IF NOT EXISTS recurring BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS cooldown_seconds INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS hysteresis_percent DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS last_triggered_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS trigger_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE UNIQUE INDEX IF NOT EXISTS price_notifications_id_key ON price_notifications (id);
+
+CREATE TABLE IF NOT EXISTS price_alert_occurrences (
+    id              BIGSERIAL PRIMARY KEY,
+    notification_id BIGINT NOT NULL REFERENCES price_notifications (id) ON DELETE CASCADE,
+    username        TEXT NOT NULL,
+    crypto          TEXT NOT NULL,
+    target_price    DOUBLE PRECISION NOT NULL,
+    triggered_price DOUBLE PRECISION NOT NULL,
+    triggered_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS price_alert_occurrences_username_idx ON price_alert_occurrences (username, triggered_at DESC);
@@ -0,0 +1,16 @@
+-- Alert expiry and active-hour windows, evaluated in each user's own timezone.
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT 'UTC';
+
+ALTER TABLE price_notifications
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS active_from TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS active_to TEXT NOT NULL DEFAULT '';
+
+-- Firings outside the active window are held until it opens; anything already
+-- recorded has been shown to the user.
+ALTER TABLE price_alert_occurrences
+    ADD COLUMN IF NOT EXISTS delivered_at TIMESTAMPTZ;
+
+UPDATE price_alert_occurrences SET delivered_at = triggered_at WHERE delivered_at IS NULL;
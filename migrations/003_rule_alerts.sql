@@ -0,0 +1,6 @@
+-- Alert kinds: plain target-price alerts and compound rule expressions share
+-- price_notifications; rule alerts keep their canonical expression in "rule".
+
+ALTER TABLE price_notifications
+    ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'price',
+    ADD COLUMN IF NOT EXISTS rule TEXT NOT NULL DEFAULT '';
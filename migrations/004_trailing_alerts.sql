@@ -0,0 +1,7 @@
+-- Trailing-stop alerts fire when the price falls trail_percent below the highest
+-- price seen since the alert was set. The high-water mark is persisted so it
+-- survives restarts.
+
+ALTER TABLE price_notifications
+    ADD COLUMN IF NOT EXISTS trail_percent DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS high_water_mark DOUBLE PRECISION NOT NULL DEFAULT 0;
@@ -0,0 +1,19 @@
+-- Alert groups: a ladder alert is a set of child price alerts at staggered
+-- levels that fire independently but are paused or deleted as one unit.
+-- Alerts now also carry a direction so levels below the current price fire
+-- when the price falls to them.
+
+CREATE TABLE IF NOT EXISTS alert_groups (
+    id         BIGSERIAL PRIMARY KEY,
+    username   TEXT NOT NULL,
+    crypto     TEXT NOT NULL,
+    kind       TEXT NOT NULL DEFAULT 'ladder',
+    status     TEXT NOT NULL DEFAULT 'Active',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS alert_groups_username_idx ON alert_groups (username);
+
+ALTER TABLE price_notifications
+    ADD COLUMN IF NOT EXISTS group_id BIGINT REFERENCES alert_groups (id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS direction TEXT NOT NULL DEFAULT 'above';
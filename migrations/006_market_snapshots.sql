@@ -0,0 +1,20 @@
+-- Locally persisted listing snapshots, used for trailing averages (volume spike
+-- alerts and "avg" rule conditions), plus the settings for volume spike and
+-- market-cap rank alerts.
+
+CREATE TABLE IF NOT EXISTS market_snapshots (
+    id                 BIGSERIAL PRIMARY KEY,
+    symbol             TEXT NOT NULL,
+    price              DOUBLE PRECISION NOT NULL,
+    percent_change_24h DOUBLE PRECISION NOT NULL DEFAULT 0,
+    volume_24h         DOUBLE PRECISION NOT NULL DEFAULT 0,
+    market_cap         DOUBLE PRECISION NOT NULL DEFAULT 0,
+    cmc_rank           INTEGER NOT NULL DEFAULT 0,
+    captured_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS market_snapshots_symbol_idx ON market_snapshots (symbol, captured_at DESC);
+
+ALTER TABLE price_notifications
+    ADD COLUMN IF NOT EXISTS volume_multiplier DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rank_threshold INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,22 @@
+-- Stablecoin de-peg monitoring: the admin-configured peg list with the running
+-- breach state of each coin, and the users who want to hear about de-pegs.
+-- Admins are always notified and need no subscription.
+
+CREATE TABLE IF NOT EXISTS stablecoin_pegs (
+    symbol            TEXT PRIMARY KEY,
+    peg_price         DOUBLE PRECISION NOT NULL DEFAULT 1,
+    threshold_percent DOUBLE PRECISION NOT NULL DEFAULT 0.5,
+    sustained_samples INTEGER NOT NULL DEFAULT 3,
+    breach_count      INTEGER NOT NULL DEFAULT 0,
+    depegged          BOOLEAN NOT NULL DEFAULT FALSE,
+    last_price        DOUBLE PRECISION NOT NULL DEFAULT 0,
+    updated_at        TIMESTAMPTZ
+);
+
+INSERT INTO stablecoin_pegs (symbol) VALUES ('USDT'), ('USDC'), ('DAI')
+    ON CONFLICT (symbol) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS depeg_subscriptions (
+    username      TEXT PRIMARY KEY,
+    subscribed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,23 @@
+-- Scheduled daily/weekly digests. digest_settings holds each user's schedule
+-- (local send time, evaluated in users.timezone); digests holds rendered
+-- digests waiting to be shown in the app.
+
+CREATE TABLE IF NOT EXISTS digest_settings (
+    username     TEXT PRIMARY KEY,
+    frequency    TEXT NOT NULL DEFAULT 'off',
+    send_at      TEXT NOT NULL DEFAULT '08:00',
+    weekday      INTEGER NOT NULL DEFAULT 1,
+    channel      TEXT NOT NULL DEFAULT 'in-app',
+    watchlist    TEXT NOT NULL DEFAULT '',
+    last_sent_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS digests (
+    id           BIGSERIAL PRIMARY KEY,
+    username     TEXT NOT NULL,
+    body         TEXT NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS digests_undelivered_idx ON digests (username) WHERE delivered_at IS NULL;
@@ -0,0 +1,23 @@
+-- Structured notification preferences replace the unused free-text preference.
+-- Each outbound notification attempt is logged in notification_deliveries,
+-- which also backs the per-hour rate cap.
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS notification_preferences JSONB NOT NULL DEFAULT '{}';
+
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+    id         BIGSERIAL PRIMARY KEY,
+    username   TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    channel    TEXT NOT NULL,
+    subject    TEXT NOT NULL DEFAULT '',
+    body       TEXT NOT NULL,
+    status     TEXT NOT NULL, -- sent, failed, suppressed
+    error      TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS notification_deliveries_username_idx ON notification_deliveries (username, created_at DESC);
+
+-- Digests follow the digest entry of the notification preferences now
+ALTER TABLE digest_settings DROP COLUMN IF EXISTS channel;
@@ -0,0 +1,16 @@
+-- notification_deliveries becomes a durable outbox: the notification service
+-- enqueues a job per outbound channel and background workers send it,
+-- retrying with backoff until it succeeds or is moved to the dead-letter
+-- state. Statuses: pending, sending, sent, suppressed, dead.
+
+ALTER TABLE notification_deliveries
+    ADD COLUMN IF NOT EXISTS attempts        INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS max_attempts    INTEGER NOT NULL DEFAULT 5,
+    ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD COLUMN IF NOT EXISTS sent_at         TIMESTAMPTZ;
+
+UPDATE notification_deliveries SET sent_at = created_at WHERE status = 'sent' AND sent_at IS NULL;
+UPDATE notification_deliveries SET status = 'dead' WHERE status = 'failed';
+
+CREATE INDEX IF NOT EXISTS notification_deliveries_due_idx ON notification_deliveries (next_attempt_at)
+    WHERE status IN ('pending', 'sending');
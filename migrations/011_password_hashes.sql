@@ -0,0 +1,5 @@
+-- Passwords are hashed with argon2id in an encoded form that is longer than the
+-- 64-character SHA-256 digests stored so far. Existing digests stay valid and are
+-- rehashed with argon2id the next time their owner logs in.
+
+ALTER TABLE users ALTER COLUMN password TYPE TEXT;
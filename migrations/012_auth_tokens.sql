@@ -0,0 +1,18 @@
+-- Server-side state for REST authentication. Refresh tokens are stored as SHA-256
+-- hashes and rotated on every use; access tokens revoked before they expire (on
+-- logout) are remembered by their JWT ID until their natural expiry.
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    token_hash TEXT PRIMARY KEY,
+    username   TEXT NOT NULL,
+    issued_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_username_idx ON refresh_tokens (username);
+
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti        TEXT PRIMARY KEY,
+    expires_at TIMESTAMPTZ NOT NULL
+);
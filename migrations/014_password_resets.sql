@@ -0,0 +1,12 @@
+-- Single-use password reset tokens, stored as SHA-256 hashes. Requesting a new
+-- token uses up any earlier ones; rows are kept to cap how often a user can ask.
+
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    token_hash TEXT PRIMARY KEY,
+    username   TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS password_reset_tokens_username_idx ON password_reset_tokens (username, created_at);
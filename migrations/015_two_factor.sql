@@ -0,0 +1,26 @@
+-- Optional TOTP two-factor authentication. A secret is stored as soon as enrollment
+-- starts but only takes effect once a code from it has been confirmed. last_step is
+-- the most recent code step accepted, so a code cannot be used twice.
+
+CREATE TABLE IF NOT EXISTS user_two_factor (
+    username   TEXT PRIMARY KEY,
+    secret     TEXT NOT NULL,
+    enabled    BOOLEAN NOT NULL DEFAULT FALSE,
+    last_step  BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    enabled_at TIMESTAMPTZ
+);
+
+-- One-time recovery codes, stored as SHA-256 hashes.
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    username  TEXT NOT NULL,
+    code_hash TEXT NOT NULL,
+    used_at   TIMESTAMPTZ,
+    PRIMARY KEY (username, code_hash)
+);
+
+-- Per-role security settings set by admins.
+CREATE TABLE IF NOT EXISTS role_settings (
+    role               TEXT PRIMARY KEY,
+    require_two_factor BOOLEAN NOT NULL DEFAULT FALSE
+);
@@ -0,0 +1,18 @@
+-- Email addresses must be verified before email notifications or password resets are
+-- sent to them. Accounts that already exist are treated as verified.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE users ALTER COLUMN email_verified SET DEFAULT FALSE;
+
+-- Single-use verification tokens, stored as SHA-256 hashes. The address is kept so a
+-- token only verifies the address it was sent to.
+CREATE TABLE IF NOT EXISTS email_verification_tokens (
+    token_hash TEXT PRIMARY KEY,
+    username   TEXT NOT NULL,
+    email      TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS email_verification_tokens_username_idx ON email_verification_tokens (username, created_at);
@@ -0,0 +1,15 @@
+-- Roles now grant permissions (see internal/rbac) instead of admin being all or nothing.
+-- Existing admins had full control, including making other admins, so they become owners;
+-- any other role was treated as a plain user.
+
+UPDATE users SET role = 'owner' WHERE role = 'admin';
+UPDATE users SET role = 'user' WHERE role IS NULL OR role NOT IN ('owner', 'user');
+UPDATE users SET isadmin = (role = 'owner');
+
+ALTER TABLE users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('viewer', 'user', 'analyst', 'support', 'admin', 'owner'));
+
+-- Owners keep the two-factor policy they had as admins
+INSERT INTO role_settings (role, require_two_factor)
+SELECT 'owner', require_two_factor FROM role_settings WHERE role = 'admin'
+ON CONFLICT (role) DO NOTHING;
@@ -0,0 +1,22 @@
+-- A session is one login, from the CLI or a REST client. REST tokens carry their session
+-- ID, so revoking a session ends it everywhere: its access tokens stop validating and its
+-- refresh tokens are revoked. expires_at slides forward while the session is in use.
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id           TEXT PRIMARY KEY,
+    username     TEXT NOT NULL,
+    client       TEXT NOT NULL,
+    ip           TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at   TIMESTAMPTZ NOT NULL,
+    revoked_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS sessions_username_idx ON sessions (username);
+
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS session_id TEXT;
+CREATE INDEX IF NOT EXISTS refresh_tokens_session_idx ON refresh_tokens (session_id);
+
+-- Refresh tokens issued before sessions existed belong to none, so REST clients log in again
+UPDATE refresh_tokens SET revoked_at = NOW() WHERE session_id IS NULL AND revoked_at IS NULL;
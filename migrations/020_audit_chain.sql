@@ -0,0 +1,50 @@
+-- The audit log becomes tamper-evident. Entries keep the value an action changed from and to,
+-- and each is hash-chained to the one before it: hash covers prev_hash and the entry's own
+-- fields (see internal/audit), so editing or removing an entry breaks every hash after it.
+
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS before_value TEXT NOT NULL DEFAULT '';
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS after_value TEXT NOT NULL DEFAULT '';
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS prev_hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS hash TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS audit_log_actor_idx ON audit_log (actor);
+CREATE INDEX IF NOT EXISTS audit_log_target_idx ON audit_log (target);
+
+-- Chain the entries written so far, in the same format as audit.Hash: each field as
+-- "<length in bytes>:<value>;", with the time in Unix microseconds.
+DO $$
+DECLARE
+    entry    RECORD;
+    previous TEXT := '';
+BEGIN
+    FOR entry IN SELECT * FROM audit_log ORDER BY id LOOP
+        UPDATE audit_log
+        SET prev_hash = previous,
+            hash = encode(sha256(convert_to(
+                octet_length(previous) || ':' || previous || ';' ||
+                octet_length(entry.actor) || ':' || entry.actor || ';' ||
+                octet_length(entry.action) || ':' || entry.action || ';' ||
+                octet_length(entry.target) || ':' || entry.target || ';' ||
+                octet_length(entry.details) || ':' || entry.details || ';' ||
+                octet_length(entry.before_value) || ':' || entry.before_value || ';' ||
+                octet_length(entry.after_value) || ':' || entry.after_value || ';' ||
+                octet_length(entry.source) || ':' || entry.source || ';' ||
+                octet_length(micros) || ':' || micros || ';', 'UTF8')), 'hex')
+        FROM (SELECT (EXTRACT(EPOCH FROM date_trunc('second', entry.created_at))::BIGINT * 1000000
+                      + EXTRACT(MICROSECONDS FROM entry.created_at)::BIGINT % 1000000)::TEXT AS micros) AS t
+        WHERE id = entry.id
+        RETURNING hash INTO previous;
+    END LOOP;
+END $$;
+
+-- Entries can only be added
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
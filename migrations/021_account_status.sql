@@ -0,0 +1,14 @@
+-- Accounts can be deactivated (logins blocked, alerts paused) or deleted. A deleted account
+-- keeps its data until purge_after, so an admin can restore it until then; after that the
+-- purge job removes it for good.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS purge_after TIMESTAMPTZ;
+
+ALTER TABLE users ADD CONSTRAINT users_status_check
+    CHECK (status IN ('active', 'deactivated', 'deleted'));
+ALTER TABLE users ADD CONSTRAINT users_purge_after_check
+    CHECK ((status = 'deleted') = (purge_after IS NOT NULL));
+
+CREATE INDEX IF NOT EXISTS users_purge_after_idx ON users (purge_after) WHERE status = 'deleted';
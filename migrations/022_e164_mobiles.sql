@@ -0,0 +1,20 @@
+-- Mobile numbers are stored as E.164 strings ("+919876543210") instead of integers, which
+-- dropped leading zeros and could not hold a country code. Existing numbers were validated
+-- as 10 national digits without a country code, so they are given the code in the
+-- cryptotracker.mobile_country_code setting, or 91 if it is not set:
+--
+--     SET cryptotracker.mobile_country_code = '1';
+--
+-- Users without a number get an empty string.
+
+ALTER TABLE users ALTER COLUMN mobile DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN mobile TYPE TEXT USING
+    CASE
+        WHEN mobile IS NULL OR mobile <= 0 THEN ''
+        ELSE '+' || COALESCE(NULLIF(current_setting('cryptotracker.mobile_country_code', true), ''), '91') || mobile::TEXT
+    END;
+ALTER TABLE users ALTER COLUMN mobile SET DEFAULT '';
+ALTER TABLE users ALTER COLUMN mobile SET NOT NULL;
+
+ALTER TABLE users ADD CONSTRAINT users_mobile_e164_check
+    CHECK (mobile = '' OR mobile ~ '^\+[1-9][0-9]{4,14}$');
@@ -0,0 +1,15 @@
+-- The latest listings batch, shared by every session that evaluates alerts. Whichever session
+-- finds it stale refreshes it from the listings API; the rest read it, so the API is called at
+-- most once a minute however many users are logged in.
+
+CREATE TABLE IF NOT EXISTS quote_cache (
+    symbol             TEXT PRIMARY KEY,
+    cmc_id             INTEGER NOT NULL DEFAULT 0,
+    name               TEXT NOT NULL DEFAULT '',
+    price              DOUBLE PRECISION NOT NULL,
+    percent_change_24h DOUBLE PRECISION NOT NULL DEFAULT 0,
+    volume_24h         DOUBLE PRECISION NOT NULL DEFAULT 0,
+    market_cap         DOUBLE PRECISION NOT NULL DEFAULT 0,
+    cmc_rank           INTEGER NOT NULL DEFAULT 0,
+    fetched_at         TIMESTAMPTZ NOT NULL
+);
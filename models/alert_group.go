@@ -0,0 +1,17 @@
+//go:build !test
+// +build !test
+
+package models
+
+import "time"
+
+// AlertGroup bundles child price alerts that are managed as one unit, such as a ladder.
+type AlertGroup struct {
+	ID        int                 `json:"id"`
+	Username  string              `json:"username"`
+	Crypto    string              `json:"crypto"`
+	Kind      string              `json:"kind"`   // ladder
+	Status    string              `json:"status"` // Active, Paused
+	CreatedAt time.Time           `json:"created_at"`
+	Alerts    []PriceNotification `json:"alerts,omitempty"`
+}
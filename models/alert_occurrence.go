@@ -0,0 +1,24 @@
+//go:build !test
+// +build !test
+
+package models
+
+import "time"
+
+// AlertOccurrence records a single firing of a price alert.
+type AlertOccurrence struct {
+	ID             int        `json:"id"`
+	NotificationID int        `json:"notification_id"`
+	Username       string     `json:"username"`
+	Crypto         string     `json:"crypto"`
+	TargetPrice    float64    `json:"target_price"`
+	TriggeredPrice float64    `json:"triggered_price"`
+	TriggeredAt    time.Time  `json:"triggered_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+
+	// Kind, Rule, ActiveFrom and ActiveTo are copied from the parent alert when loading occurrences.
+	Kind       string `json:"kind"`
+	Rule       string `json:"rule,omitempty"`
+	ActiveFrom string `json:"-"`
+	ActiveTo   string `json:"-"`
+}
@@ -0,0 +1,19 @@
+//go:build !test
+// +build !test
+
+package models
+
+import "time"
+
+// APIKey is a named key a user's scripts authenticate to the REST API with, limited to its
+// scopes. Prefix is the start of the key; the key itself is only shown when it is created.
+type APIKey struct {
+	ID         int        `json:"id"`
+	Username   string     `json:"username"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
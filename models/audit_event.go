@@ -0,0 +1,43 @@
+//go:build !test
+// +build !test
+
+package models
+
+import "time"
+
+// AuditEvent records a security-relevant action: who did it, what it was, what it was done to
+// and, where the action changed a value, what it changed from and to. Source is "cli" or
+// "rest". Hash chains the entry to the one before it, whose hash is PrevHash.
+type AuditEvent struct {
+	ID        int       `json:"id"`
+	Actor     string    `json:"actor"`
+	Action    string    `json:"action"`
+	Target    string    `json:"target"`
+	Details   string    `json:"details"`
+	Before    string    `json:"before"`
+	After     string    `json:"after"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"created_at"`
+	PrevHash  string    `json:"prev_hash"`
+	Hash      string    `json:"hash"`
+}
+
+// AuditFilter narrows a search of the audit log. Empty fields match everything; Action
+// matches a whole action ("role.promoted") or a prefix ending in a dot ("role.").
+type AuditFilter struct {
+	Actor  string
+	Action string
+	Target string
+	Source string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+}
+
+// AuditVerification is the result of checking the audit log's hash chain. BrokenAt is the ID
+// of the first entry that does not match, or 0 when the chain is intact.
+type AuditVerification struct {
+	Entries  int  `json:"entries"`
+	Intact   bool `json:"intact"`
+	BrokenAt int  `json:"broken_at,omitempty"`
+}
@@ -0,0 +1,38 @@
+//go:build !test
+// +build !test
+
+package models
+
+import "time"
+
+// DigestSettings is a user's digest schedule. SendAt is a local "HH:MM" time in the user's
+// timezone and Weekday (0 = Sunday) is only used by weekly digests.
+type DigestSettings struct {
+	Username   string     `json:"username"`
+	Frequency  string     `json:"frequency"` // off, daily, weekly
+	SendAt     string     `json:"send_at"`
+	Weekday    int        `json:"weekday"`
+	Watchlist  []string   `json:"watchlist"`
+	LastSentAt *time.Time `json:"last_sent_at"`
+}
+
+// Digest is the data a digest is rendered from.
+type Digest struct {
+	Username    string
+	Frequency   string
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Location    *time.Location
+	Coins       []*Quote
+	Fired       []AlertOccurrence
+	NearlyFired []DigestNearAlert
+	Requests    []*UnavailableCryptoRequest
+}
+
+// DigestNearAlert is an armed price alert whose target is close to the current price.
+type DigestNearAlert struct {
+	Crypto          string
+	TargetPrice     float64
+	CurrentPrice    float64
+	DistancePercent float64
+}
@@ -0,0 +1,15 @@
+//go:build !test
+// +build !test
+
+package models
+
+import "time"
+
+// LoginAttempt tracks recent failed logins for a username or a client address. Key is
+// "user:<username>" or "ip:<address>".
+type LoginAttempt struct {
+	Key           string     `json:"key"`
+	Failures      int        `json:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+}
@@ -0,0 +1,14 @@
+//go:build !test
+// +build !test
+
+package models
+
+// MarketAverage holds the trailing averages of a symbol's stored snapshots.
+type MarketAverage struct {
+	Symbol           string  `json:"symbol"`
+	Price            float64 `json:"price"`
+	PercentChange24h float64 `json:"percent_change_24h"`
+	Volume24h        float64 `json:"volume_24h"`
+	MarketCap        float64 `json:"market_cap"`
+	Samples          int     `json:"samples"`
+}
@@ -0,0 +1,23 @@
+//go:build !test
+// +build !test
+
+package models
+
+import "time"
+
+// NotificationDelivery is a queued notification for one outbound channel.
+type NotificationDelivery struct {
+	ID            int        `json:"id"`
+	Username      string     `json:"username"`
+	EventType     string     `json:"event_type"`
+	Channel       string     `json:"channel"`
+	Subject       string     `json:"subject"`
+	Body          string     `json:"body"`
+	Status        string     `json:"status"` // pending, sending, sent, suppressed, dead
+	Error         string     `json:"error"`
+	Attempts      int        `json:"attempts"`
+	MaxAttempts   int        `json:"max_attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	SentAt        *time.Time `json:"sent_at"`
+}
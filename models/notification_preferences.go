@@ -0,0 +1,12 @@
+package models
+
+// NotificationPreferences controls where and when a user is notified. Channels maps an event
+// type (price_alert, request_decision, admin_broadcast, digest) to the channels it is sent on
+// (in-app, email, webhook, sms). Quiet hours are local "HH:MM" times in the user's timezone.
+type NotificationPreferences struct {
+	Channels   map[string][]string `json:"channels"`
+	QuietFrom  string              `json:"quiet_from"`
+	QuietTo    string              `json:"quiet_to"`
+	MaxPerHour int                 `json:"max_per_hour"` // 0 means no cap
+	WebhookURL string              `json:"webhook_url"`
+}
@@ -3,15 +3,41 @@
 
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import "time"
 
 type PriceNotification struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty"`
-	CryptoID    int                `bson:"crypto_id" json:"crypto_id"`
-	Crypto      string             `bson:"crypto" json:"crypto"`
-	TargetPrice float64            `bson:"target_price" json:"target_price"`
-	Username    string             `bson:"username" json:"username"`
-	AskedAt     string             `bson:"asked_at" json:"asked_at"`
-	Status      string             `bson:"status" json:"status"`
-	ServedAt    string             `bson:"served_at,omitempty" json:"served_at,omitempty"`
+	ID                int        `bson:"_id,omitempty" json:"id"`
+	Kind              string     `bson:"kind" json:"kind"` // price, rule, trailing, volume_spike, rank
+	Rule              string     `bson:"rule,omitempty" json:"rule,omitempty"`
+	CryptoID          int        `bson:"crypto_id" json:"crypto_id"`
+	Crypto            string     `bson:"crypto" json:"crypto"`
+	TargetPrice       float64    `bson:"target_price" json:"target_price"`
+	Direction         string     `bson:"direction" json:"direction"` // above, below
+	Username          string     `bson:"username" json:"username"`
+	AskedAt           string     `bson:"asked_at" json:"asked_at"`
+	Status            string     `bson:"status" json:"status"` // Pending, Rearming, Served, Expired
+	ServedAt          string     `bson:"served_at,omitempty" json:"served_at,omitempty"`
+	Recurring         bool       `bson:"recurring" json:"recurring"`
+	CooldownSeconds   int        `bson:"cooldown_seconds" json:"cooldown_seconds"`
+	HysteresisPercent float64    `bson:"hysteresis_percent" json:"hysteresis_percent"`
+	LastTriggeredAt   *time.Time `bson:"last_triggered_at,omitempty" json:"last_triggered_at,omitempty"`
+	TriggerCount      int        `bson:"trigger_count" json:"trigger_count"`
+	ExpiresAt         *time.Time `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+	ActiveFrom        string     `bson:"active_from,omitempty" json:"active_from,omitempty"` // HH:MM in the user's timezone
+	ActiveTo          string     `bson:"active_to,omitempty" json:"active_to,omitempty"`
+	TrailPercent      float64    `bson:"trail_percent,omitempty" json:"trail_percent,omitempty"`
+	HighWaterMark     float64    `bson:"high_water_mark,omitempty" json:"high_water_mark,omitempty"`
+	GroupID           *int       `bson:"group_id,omitempty" json:"group_id,omitempty"`
+	VolumeMultiplier  float64    `bson:"volume_multiplier,omitempty" json:"volume_multiplier,omitempty"`
+	RankThreshold     int        `bson:"rank_threshold,omitempty" json:"rank_threshold,omitempty"`
+}
+
+// AlertOptions carries the optional settings a user can attach to a new price alert.
+type AlertOptions struct {
+	Recurring         bool       `json:"recurring"`
+	CooldownSeconds   int        `json:"cooldown_seconds"`
+	HysteresisPercent float64    `json:"hysteresis_percent"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+	ActiveFrom        string     `json:"active_from,omitempty"`
+	ActiveTo          string     `json:"active_to,omitempty"`
 }
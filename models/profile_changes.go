@@ -0,0 +1,13 @@
+//go:build !test
+// +build !test
+
+package models
+
+// ProfileChanges holds the profile fields to change; nil fields are left as they are. The
+// password is already hashed.
+type ProfileChanges struct {
+	Email                   *string
+	Mobile                  *string
+	PasswordHash            *string
+	NotificationPreferences *NotificationPreferences
+}
@@ -0,0 +1,16 @@
+//go:build !test
+// +build !test
+
+package models
+
+// Quote is the latest USD market data for a single listed cryptocurrency.
+type Quote struct {
+	ID               int     `json:"id"`
+	Symbol           string  `json:"symbol"`
+	Name             string  `json:"name"`
+	Price            float64 `json:"price"`
+	PercentChange24h float64 `json:"percent_change_24h"`
+	Volume24h        float64 `json:"volume_24h"`
+	MarketCap        float64 `json:"market_cap"`
+	CMCRank          int     `json:"cmc_rank"`
+}
@@ -0,0 +1,18 @@
+//go:build !test
+// +build !test
+
+package models
+
+import "time"
+
+// Session is one login of a user. Client is "cli" or "rest"; Current marks the session the
+// listing was requested from.
+type Session struct {
+	ID         string    `json:"id"`
+	Username   string    `json:"username"`
+	Client     string    `json:"client"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+}
@@ -0,0 +1,18 @@
+//go:build !test
+// +build !test
+
+package models
+
+import "time"
+
+// StablecoinPeg is a monitored stablecoin with its configured peg and current breach state.
+type StablecoinPeg struct {
+	Symbol           string     `json:"symbol"`
+	PegPrice         float64    `json:"peg_price"`
+	ThresholdPercent float64    `json:"threshold_percent"`
+	SustainedSamples int        `json:"sustained_samples"`
+	BreachCount      int        `json:"breach_count"`
+	Depegged         bool       `json:"depegged"`
+	LastPrice        float64    `json:"last_price"`
+	UpdatedAt        *time.Time `json:"updated_at"`
+}
@@ -0,0 +1,13 @@
+//go:build !test
+// +build !test
+
+package models
+
+// TwoFactor is a user's TOTP enrollment. It is pending until Enabled is set by confirming a
+// code. LastStep is the step of the last code accepted.
+type TwoFactor struct {
+	Username string `json:"username"`
+	Secret   string `json:"-"`
+	Enabled  bool   `json:"enabled"`
+	LastStep int64  `json:"last_step"`
+}
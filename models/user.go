@@ -1,13 +1,30 @@
 package models
 
+import "time"
+
+// Account statuses. Deactivated users cannot log in and their alerts are paused. Deleted
+// users are purged once PurgeAfter has passed, unless an admin restores them first.
+const (
+	AccountActive      = "active"
+	AccountDeactivated = "deactivated"
+	AccountDeleted     = "deleted"
+)
+
 // User represents a user in the system
 type User struct {
-	UserID                 int    `json:"userId"`
-	Username               string `json:"username"`
-	Password               string `json:"password"`
-	Email                  string `json:"email"`
-	Mobile                 int    `json:"mobile"`
-	NotificationPreference string `json:"notificationPreference"`
-	IsAdmin                bool   `json:"isAdmin"`
-	Role                   string `json:"Role"`
+	UserID                  int                      `json:"userId"`
+	Username                string                   `json:"username"`
+	Password                string                   `json:"password"`
+	Email                   string                   `json:"email"`
+	EmailVerified           bool                     `json:"emailVerified"`
+	Mobile                  string                   `json:"mobile"`
+	NotificationPreferences *NotificationPreferences `json:"notificationPreferences,omitempty"`
+	IsAdmin                 bool                     `json:"isAdmin"`
+	Role                    string                   `json:"Role"`
+	Timezone                string                   `json:"timezone"`
+	TwoFactorEnabled        bool                     `json:"twoFactorEnabled"`
+	Status                  string                   `json:"status"`
+	PurgeAfter              *time.Time               `json:"purgeAfter,omitempty"`
+	// SessionID is the session the CLI user logged in with
+	SessionID string `json:"-"`
 }
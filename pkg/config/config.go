@@ -10,6 +10,51 @@ import (
 
 type Config struct {
 	APIKey string `json:"api_key"`
+
+	// Outbound notification channels; a channel is disabled when its settings are empty
+	SMTPHost      string `json:"smtp_host"`
+	SMTPPort      int    `json:"smtp_port"`
+	SMTPUsername  string `json:"smtp_username"`
+	SMTPPassword  string `json:"smtp_password"`
+	SMTPFrom      string `json:"smtp_from"`
+	SMSGatewayURL string `json:"sms_gateway_url"`
+	SMSAPIKey     string `json:"sms_api_key"`
+
+	PasswordHashing PasswordHashing `json:"password_hashing"`
+
+	// JWTSecret signs REST access tokens. When it is empty a random secret is generated at
+	// startup, so tokens stop working when the server restarts.
+	JWTSecret string `json:"jwt_secret"`
+
+	LoginThrottling LoginThrottling `json:"login_throttling"`
+
+	AccountDeletion AccountDeletion `json:"account_deletion"`
+}
+
+// PasswordHashing holds the argon2id cost parameters for new password hashes. Zero values
+// fall back to the defaults in utils. Raising them upgrades each stored hash on its owner's
+// next login.
+type PasswordHashing struct {
+	MemoryKiB   uint32 `json:"memory_kib"`
+	Iterations  uint32 `json:"iterations"`
+	Parallelism uint8  `json:"parallelism"`
+}
+
+// LoginThrottling sets how failed logins are slowed down and locked out. Zero values fall
+// back to the defaults in the lockout package.
+type LoginThrottling struct {
+	MaxFailures          int `json:"max_failures"`           // per username before the account locks
+	MaxFailuresPerIP     int `json:"max_failures_per_ip"`    // per client address before it is blocked
+	LockoutMinutes       int `json:"lockout_minutes"`        // how long a lock lasts
+	FailureWindowMinutes int `json:"failure_window_minutes"` // failures older than this are forgotten
+	BaseDelayMillis      int `json:"base_delay_millis"`      // wait after the first failure, doubling after each
+	MaxDelaySeconds      int `json:"max_delay_seconds"`      // cap on the wait between attempts
+}
+
+// AccountDeletion sets how long a deleted account can be restored before the purge job
+// removes it. Zero falls back to the default in services.
+type AccountDeletion struct {
+	GracePeriodDays int `json:"grace_period_days"`
 }
 
 var AppConfig Config
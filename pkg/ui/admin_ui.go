@@ -4,16 +4,23 @@
 package ui
 
 import (
+	"cryptotracker/internal/rbac"
+	"cryptotracker/internal/repositories"
 	"cryptotracker/internal/services"
 	"cryptotracker/models"
+	"cryptotracker/pkg/validation"
 	"fmt"
 	"github.com/fatih/color"
 	"github.com/jackc/pgx/v4"
 	"strings"
 )
 
-func ShowAdminPanel(conn *pgx.Conn, adminService services.AdminService) {
+func ShowAdminPanel(conn *pgx.Conn, admin *models.User, adminService services.AdminService, stablecoinService services.StablecoinService, notificationService services.NotificationService, deliveryService services.DeliveryService, tokenService services.TokenService) {
 	for {
+		if sessionEnded(tokenService, admin) {
+			return
+		}
+
 		fmt.Println()
 		fmt.Println(colorBlue("=================================="))
 		fmt.Println(colorYellow("            Admin Menu            "))
@@ -22,7 +29,12 @@ func ShowAdminPanel(conn *pgx.Conn, adminService services.AdminService) {
 		fmt.Println("1. Manage Users")
 		fmt.Println("2. View User Profiles")
 		fmt.Println("3. Manage User Requests")
-		fmt.Println("4. Logout")
+		fmt.Println("4. Manage Stablecoin Pegs")
+		fmt.Println("5. Failed Deliveries")
+		fmt.Println("6. Locked Accounts")
+		fmt.Println("7. Security Settings")
+		fmt.Println("8. Audit Log")
+		fmt.Println("9. Logout")
 
 		var choice int
 		color.New(color.FgYellow).Print("Enter your choice: ")
@@ -30,12 +42,37 @@ func ShowAdminPanel(conn *pgx.Conn, adminService services.AdminService) {
 
 		switch choice {
 		case 1:
-			ManageUsers(conn, adminService)
+			if allowed(admin, rbac.UsersChangeRole, rbac.UsersDeactivate, rbac.UsersDelete) {
+				ManageUsers(conn, adminService, admin)
+			}
 		case 2:
-			ViewUserProfiles(conn, adminService)
+			if allowed(admin, rbac.UsersView) {
+				ViewUserProfiles(conn, adminService, admin)
+			}
 		case 3:
-			ManageUserRequests(conn, adminService)
+			if allowed(admin, rbac.RequestsView) {
+				ManageUserRequests(conn, adminService, notificationService, admin)
+			}
 		case 4:
+			if allowed(admin, rbac.StablecoinsManage) {
+				ManageStablecoinPegs(stablecoinService)
+			}
+		case 5:
+			if allowed(admin, rbac.DeliveriesManage) {
+				ManageFailedDeliveries(deliveryService)
+			}
+		case 6:
+			if allowed(admin, rbac.UsersUnlock) {
+				ManageLockedLogins(adminService, admin.Username)
+			}
+		case 7:
+			ManageSecuritySettings(services.NewAuthService(repositories.NewPostgresAuthRepository(conn), notificationService), adminService, tokenService, admin)
+		case 8:
+			if allowed(admin, rbac.AuditView) {
+				ManageAuditLog(adminService, admin)
+			}
+		case 9:
+			endSession(tokenService, admin)
 			color.New(color.FgCyan).Println("Logging out...")
 			return
 		default:
@@ -44,11 +81,156 @@ func ShowAdminPanel(conn *pgx.Conn, adminService services.AdminService) {
 	}
 }
 
-func ManageUsers(conn *pgx.Conn, adminService services.AdminService) {
+// allowed reports whether the user's role grants any of the permissions, telling them when
+// it does not
+func allowed(user *models.User, permissions ...rbac.Permission) bool {
+	for _, permission := range permissions {
+		if rbac.Can(user.Role, permission) {
+			return true
+		}
+	}
+	color.New(color.FgRed).Printf("Your role (%s) does not allow this.\n", user.Role)
+	return false
+}
+
+// ManageStablecoinPegs lets an admin view, add, change or remove monitored stablecoins
+func ManageStablecoinPegs(stablecoinService services.StablecoinService) {
+	pegs, err := stablecoinService.GetPegs()
+	if err != nil {
+		color.New(color.FgRed).Printf("Error fetching stablecoin pegs: %v\n", err)
+		return
+	}
+
 	fmt.Println()
-	color.New(color.FgGreen).Println("Managing users")
-	fmt.Println("1. Change a user status to admin")
-	fmt.Println("2. Delete a user")
+	color.New(color.FgGreen).Println("Monitored stablecoins")
+	fmt.Printf("%-8s %-10s %-12s %-10s %-10s %-10s\n", "Symbol", "Peg", "Threshold", "Samples", "Last", "State")
+	for _, peg := range pegs {
+		state := "on peg"
+		if peg.Depegged {
+			state = "DE-PEGGED"
+		} else if peg.BreachCount > 0 {
+			state = fmt.Sprintf("%d off", peg.BreachCount)
+		}
+		fmt.Printf("%-8s $%-9.2f %-12s %-10d $%-9.4f %-10s\n", peg.Symbol, peg.PegPrice, fmt.Sprintf("%.2f%%", peg.ThresholdPercent), peg.SustainedSamples, peg.LastPrice, state)
+	}
+
+	fmt.Println()
+	fmt.Println("1. Add or change a stablecoin")
+	fmt.Println("2. Remove a stablecoin")
+	fmt.Println("3. Back")
+
+	var choice int
+	color.New(color.FgYellow).Print("Enter your choice: ")
+	fmt.Scan(&choice)
+
+	switch choice {
+	case 1:
+		peg := &models.StablecoinPeg{}
+		color.New(color.FgYellow).Print("Enter the stablecoin symbol: ")
+		fmt.Scan(&peg.Symbol)
+		color.New(color.FgYellow).Print("Enter the peg price (e.g. 1): ")
+		fmt.Scan(&peg.PegPrice)
+		color.New(color.FgYellow).Print("Enter the allowed deviation in percent (e.g. 0.5): ")
+		fmt.Scan(&peg.ThresholdPercent)
+		color.New(color.FgYellow).Print("Enter how many samples in a row must deviate: ")
+		fmt.Scan(&peg.SustainedSamples)
+
+		if err := stablecoinService.SavePeg(peg); err != nil {
+			color.New(color.FgRed).Printf("Error saving stablecoin peg: %v\n", err)
+			return
+		}
+		color.New(color.FgGreen).Printf("%s is now monitored.\n", peg.Symbol)
+	case 2:
+		var symbol string
+		color.New(color.FgYellow).Print("Enter the stablecoin symbol to remove: ")
+		fmt.Scan(&symbol)
+
+		if err := stablecoinService.DeletePeg(symbol); err != nil {
+			color.New(color.FgRed).Printf("Error removing stablecoin peg: %v\n", err)
+			return
+		}
+		color.New(color.FgGreen).Printf("%s is no longer monitored.\n", strings.ToUpper(symbol))
+	case 3:
+		return
+	default:
+		color.New(color.FgRed).Println("Invalid choice, please try again.")
+	}
+}
+
+// ManageFailedDeliveries lists outbound notifications that failed and lets an admin requeue one
+func ManageFailedDeliveries(deliveryService services.DeliveryService) {
+	deliveries, err := deliveryService.GetFailedDeliveries()
+	if err != nil {
+		color.New(color.FgRed).Printf("Error fetching failed deliveries: %v\n", err)
+		return
+	}
+
+	fmt.Println()
+	color.New(color.FgGreen).Println("Failed deliveries")
+	if len(deliveries) == 0 {
+		fmt.Println("No failed deliveries.")
+		return
+	}
+
+	fmt.Printf("%-6s %-15s %-17s %-8s %-8s %-9s %-17s %s\n", "ID", "Username", "Event", "Channel", "Status", "Attempts", "Next attempt", "Last error")
+	for _, d := range deliveries {
+		next := "-"
+		if d.Status == "pending" {
+			next = d.NextAttemptAt.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Printf("%-6d %-15s %-17s %-8s %-8s %-9s %-17s %s\n", d.ID, d.Username, d.EventType, d.Channel, d.Status, fmt.Sprintf("%d/%d", d.Attempts, d.MaxAttempts), next, d.Error)
+	}
+
+	fmt.Println()
+	fmt.Println("1. Requeue a delivery")
+	fmt.Println("2. Back")
+
+	var choice int
+	color.New(color.FgYellow).Print("Enter your choice: ")
+	fmt.Scan(&choice)
+
+	switch choice {
+	case 1:
+		var id int
+		color.New(color.FgYellow).Print("Enter the delivery ID to requeue: ")
+		fmt.Scan(&id)
+
+		if err := deliveryService.RequeueDelivery(id); err != nil {
+			color.New(color.FgRed).Printf("Error requeueing delivery: %v\n", err)
+			return
+		}
+		color.New(color.FgGreen).Printf("Delivery %d will be sent again shortly.\n", id)
+	case 2:
+		return
+	default:
+		color.New(color.FgRed).Println("Invalid choice, please try again.")
+	}
+}
+
+// ManageLockedLogins lists the accounts and client addresses locked out after failed logins
+// and lets the admin unlock one
+func ManageLockedLogins(adminService services.AdminService, adminUsername string) {
+	locked, err := adminService.GetLockedLogins(adminUsername)
+	if err != nil {
+		color.New(color.FgRed).Printf("Error fetching locked accounts: %v\n", err)
+		return
+	}
+
+	fmt.Println()
+	color.New(color.FgGreen).Println("Locked accounts")
+	if len(locked) == 0 {
+		fmt.Println("No accounts are locked.")
+		return
+	}
+
+	fmt.Printf("%-30s %-9s %-17s %s\n", "Account or address", "Failures", "Last failure", "Locked until")
+	for _, attempt := range locked {
+		fmt.Printf("%-30s %-9d %-17s %s\n", attempt.Key, attempt.Failures, attempt.LastFailureAt.Local().Format("2006-01-02 15:04"), attempt.LockedUntil.Local().Format("2006-01-02 15:04"))
+	}
+
+	fmt.Println()
+	fmt.Println("1. Unlock an account or address")
+	fmt.Println("2. Back")
 
 	var choice int
 	color.New(color.FgYellow).Print("Enter your choice: ")
@@ -56,21 +238,173 @@ func ManageUsers(conn *pgx.Conn, adminService services.AdminService) {
 
 	switch choice {
 	case 1:
+		var key string
+		color.New(color.FgYellow).Print("Enter the username or the key shown above: ")
+		fmt.Scan(&key)
+
+		if err := adminService.UnlockLogin(key, adminUsername, services.AuditSourceCLI); err != nil {
+			color.New(color.FgRed).Printf("Error unlocking: %v\n", err)
+			return
+		}
+		color.New(color.FgGreen).Printf("%s has been unlocked.\n", key)
+	case 2:
+		return
+	default:
+		color.New(color.FgRed).Println("Invalid choice, please try again.")
+	}
+}
+
+// ManageSecuritySettings lets an admin choose the roles for which two-factor authentication
+// is mandatory, manage their own two-factor authentication and sessions, verify their email
+// address and log other users out
+func ManageSecuritySettings(authService services.AuthService, adminService services.AdminService, tokenService services.TokenService, admin *models.User) {
+	fmt.Println()
+	color.New(color.FgGreen).Println("Security settings")
+	if rbac.Can(admin.Role, rbac.SecurityManage) {
+		fmt.Printf("%-10s %s\n", "Role", "Two-factor authentication")
+		for _, role := range rbac.Roles() {
+			required, err := adminService.IsTwoFactorRequired(role)
+			if err != nil {
+				color.New(color.FgRed).Printf("Error fetching security settings: %v\n", err)
+				return
+			}
+			policy := "optional"
+			if required {
+				policy = "mandatory"
+			}
+			fmt.Printf("%-10s %s\n", role, policy)
+		}
+		fmt.Println()
+	}
+	fmt.Println("1. Change the two-factor policy for a role")
+	fmt.Println("2. My two-factor authentication")
+	fmt.Println("3. Verify my email address")
+	fmt.Println("4. My sessions")
+	fmt.Println("5. Log a user out everywhere")
+	fmt.Println("6. My API keys")
+	fmt.Println("7. Back")
+
+	var choice int
+	color.New(color.FgYellow).Print("Enter your choice: ")
+	fmt.Scan(&choice)
+
+	switch choice {
+	case 1:
+		if !allowed(admin, rbac.SecurityManage) {
+			return
+		}
+		var role string
+		color.New(color.FgYellow).Printf("Enter the role (%s): ", strings.Join(rbac.Roles(), ", "))
+		fmt.Scan(&role)
+
+		required, err := adminService.IsTwoFactorRequired(role)
+		if err != nil {
+			color.New(color.FgRed).Printf("Error fetching security settings: %v\n", err)
+			return
+		}
+		if err := adminService.SetTwoFactorRequired(role, !required, admin.Username, services.AuditSourceCLI); err != nil {
+			color.New(color.FgRed).Printf("Error saving security settings: %v\n", err)
+			return
+		}
+		if required {
+			color.New(color.FgGreen).Printf("Two-factor authentication is now optional for the %s role.\n", role)
+			return
+		}
+		color.New(color.FgGreen).Printf("Two-factor authentication is now mandatory for the %s role. Users who have not set it up will be asked to at their next login.\n", role)
+	case 2:
+		ManageTwoFactor(authService, admin)
+	case 3:
+		VerifyEmailUI(authService, admin)
+	case 4:
+		ManageSessions(tokenService, admin)
+	case 5:
+		if !allowed(admin, rbac.SessionsRevokeAny) {
+			return
+		}
 		var username string
+		color.New(color.FgYellow).Print("Enter the username to log out: ")
+		fmt.Scan(&username)
+
+		count, err := adminService.ForceLogout(username, admin.Username, services.AuditSourceCLI)
+		if err != nil {
+			color.New(color.FgRed).Printf("Error logging user out: %v\n", err)
+			return
+		}
+		color.New(color.FgGreen).Printf("%d session(s) of %s ended.\n", count, username)
+	case 6:
+		ManageAPIKeys(tokenService, admin)
+	case 7:
+		return
+	default:
+		color.New(color.FgRed).Println("Invalid choice, please try again.")
+	}
+}
+
+func ManageUsers(conn *pgx.Conn, adminService services.AdminService, admin *models.User) {
+	fmt.Println()
+	color.New(color.FgGreen).Println("Managing users")
+	fmt.Println("1. Change a user's role")
+	fmt.Println("2. Deactivate a user")
+	fmt.Println("3. Delete a user")
+	fmt.Println("4. Restore a user")
+
+	var choice int
+	color.New(color.FgYellow).Print("Enter your choice: ")
+	fmt.Scan(&choice)
+
+	switch choice {
+	case 1:
+		if !allowed(admin, rbac.UsersChangeRole) {
+			return
+		}
+		var username, role string
 		color.New(color.FgYellow).Print("Enter the username to change role: ")
 		fmt.Scan(&username)
-		err := adminService.ChangeUserStatus(username)
+		color.New(color.FgYellow).Printf("Enter the new role (%s): ", strings.Join(rbac.Roles(), ", "))
+		fmt.Scan(&role)
+		err := adminService.ChangeUserRole(username, role, admin.Username, services.AuditSourceCLI)
 		if err != nil {
-			color.Red("Error changing user status: %v", err)
+			color.Red("Error changing user role: %v", err)
+			return
 		}
+		color.Green("%s is now %s.", username, strings.ToLower(role))
 	case 2:
+		if !allowed(admin, rbac.UsersDeactivate) {
+			return
+		}
+		var username string
+		color.New(color.FgYellow).Print("Enter the username to deactivate: ")
+		fmt.Scan(&username)
+		if err := adminService.DeactivateUser(username, admin.Username, services.AuditSourceCLI); err != nil {
+			color.Red("Error deactivating user: %v", err)
+			return
+		}
+		color.Green("%s is deactivated and has been signed out everywhere.", username)
+	case 3:
+		if !allowed(admin, rbac.UsersDelete) {
+			return
+		}
 		var username string
 		color.New(color.FgYellow).Print("Enter the username to delete: ")
 		fmt.Scan(&username)
-		err := adminService.DeleteUser(username)
+		purgeAfter, err := adminService.DeleteUser(username, admin.Username, services.AuditSourceCLI)
 		if err != nil {
 			color.Red("Error deleting user: %v", err)
+			return
+		}
+		color.Green("%s is deleted and can be restored until %s.", username, purgeAfter.Local().Format("2006-01-02 15:04"))
+	case 4:
+		if !allowed(admin, rbac.UsersDeactivate) {
+			return
+		}
+		var username string
+		color.New(color.FgYellow).Print("Enter the username to restore: ")
+		fmt.Scan(&username)
+		if err := adminService.RestoreUser(username, admin.Username, services.AuditSourceCLI); err != nil {
+			color.Red("Error restoring user: %v", err)
+			return
 		}
+		color.Green("%s is active again.", username)
 	default:
 		color.Red("Invalid choice")
 	}
@@ -78,9 +412,9 @@ func ManageUsers(conn *pgx.Conn, adminService services.AdminService) {
 }
 
 // ViewUserProfiles displays a list of user profiles in a tabular format
-func ViewUserProfiles(conn *pgx.Conn, adminService services.AdminService) {
+func ViewUserProfiles(conn *pgx.Conn, adminService services.AdminService, admin *models.User) {
 	fmt.Println()
-	users, err := adminService.ViewUserProfiles()
+	users, err := adminService.ViewUserProfiles(admin.Username)
 	if err != nil {
 		color.New(color.FgRed).Println("Error fetching user profiles:", err)
 		return
@@ -94,13 +428,9 @@ func ViewUserProfiles(conn *pgx.Conn, adminService services.AdminService) {
 	// Print table header
 	printTableHeader()
 
-	// Print user profiles in table format with correct serial numbers
-	displayedNumber := 1
-	for _, user := range users {
-		if user.Role == "user" {
-			printUserProfile(displayedNumber, user)
-			displayedNumber++
-		}
+	// Print every user, whatever their role
+	for i, user := range users {
+		printUserProfile(i+1, user)
 	}
 
 	fmt.Println()
@@ -108,17 +438,21 @@ func ViewUserProfiles(conn *pgx.Conn, adminService services.AdminService) {
 
 // printTableHeader prints the table header with bold formatting
 func printTableHeader() {
-	color.New(color.FgGreen, color.Bold).Printf("%-5s %-20s %-30s %-15s %-10s\n", "S.No", "Username", "Email", "Mobile", "Role")
-	color.New(color.FgWhite).Println(strings.Repeat("-", 80))
+	color.New(color.FgGreen, color.Bold).Printf("%-5s %-20s %-30s %-18s %-10s %-12s %s\n", "S.No", "Username", "Email", "Mobile", "Role", "Status", "Purge After")
+	color.New(color.FgWhite).Println(strings.Repeat("-", 113))
 }
 
 // printUserProfile prints a formatted user profile
 func printUserProfile(index int, user *models.User) {
-	color.New(color.FgYellow).Printf("%-5d %-20s %-30s %-15d %-10s\n", index, user.Username, user.Email, user.Mobile, user.Role)
+	purgeAfter := "-"
+	if user.PurgeAfter != nil {
+		purgeAfter = user.PurgeAfter.Local().Format("2006-01-02")
+	}
+	color.New(color.FgYellow).Printf("%-5d %-20s %-30s %-18s %-10s %-12s %s\n", index, user.Username, user.Email, validation.FormatMobile(user.Mobile), user.Role, user.Status, purgeAfter)
 }
 
-func ManageUserRequests(conn *pgx.Conn, adminService services.AdminService) {
-	unavailableRequests, err := adminService.ManageUserRequests()
+func ManageUserRequests(conn *pgx.Conn, adminService services.AdminService, notificationService services.NotificationService, admin *models.User) {
+	unavailableRequests, err := adminService.ManageUserRequests(admin.Username)
 	if err != nil {
 		color.New(color.FgRed).Println("Error fetching unavailable crypto requests:", err)
 		return
@@ -137,6 +471,10 @@ func ManageUserRequests(conn *pgx.Conn, adminService services.AdminService) {
 		printRequestTableRow(i+1, request)
 	}
 
+	if !rbac.Can(admin.Role, rbac.RequestsApprove) {
+		return
+	}
+
 	// Get the crypto symbol from the admin
 	var cryptoSymbol string
 	color.New(color.FgYellow).Print("Enter the crypto symbol to manage: ")
@@ -178,13 +516,17 @@ func ManageUserRequests(conn *pgx.Conn, adminService services.AdminService) {
 	}
 
 	// Call the update function with the slice of matching requests
-	err = adminService.UpdateRequestStatus(matchingRequests, newStatus)
+	err = adminService.UpdateRequestStatus(matchingRequests, newStatus, admin.Username, services.AuditSourceCLI)
 	if err != nil {
 		color.New(color.FgRed).Println("Error updating request status:", err)
 		return
 	}
 
 	color.New(color.FgGreen).Printf("Status of all requests for '%s' updated to '%s'.\n", cryptoSymbol, newStatus)
+
+	if err := notificationService.NotifyRequestDecisions(matchingRequests); err != nil {
+		color.New(color.FgRed).Println("Error notifying requesters:", err)
+	}
 }
 
 // printRequestTableHeader prints the table header with borders
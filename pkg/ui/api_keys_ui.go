@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"cryptotracker/internal/services"
+	"cryptotracker/models"
+	"cryptotracker/pkg/utils"
+	"fmt"
+	"github.com/fatih/color"
+	"strings"
+)
+
+// ManageAPIKeys lists the user's API keys and lets them create and revoke keys
+func ManageAPIKeys(tokenService services.TokenService, user *models.User) {
+	keys, err := tokenService.ListAPIKeys(user.Username)
+	if err != nil {
+		color.New(color.FgRed).Printf("Error fetching API keys: %v\n", err)
+		return
+	}
+
+	fmt.Println()
+	color.New(color.FgGreen).Println("API keys")
+	if len(keys) == 0 {
+		fmt.Println("You have no API keys.")
+	} else {
+		fmt.Printf("%-5s %-20s %-11s %-27s %-11s %s\n", "ID", "Name", "Key", "Scopes", "Expires", "Last used")
+		for _, key := range keys {
+			lastUsed := "never"
+			if key.LastUsedAt != nil {
+				lastUsed = key.LastUsedAt.Local().Format("2006-01-02 15:04")
+			}
+			fmt.Printf("%-5d %-20s %-11s %-27s %-11s %s\n", key.ID, key.Name, key.Prefix+"…", strings.Join(key.Scopes, ","),
+				key.ExpiresAt.Local().Format("2006-01-02"), lastUsed)
+		}
+	}
+
+	fmt.Println()
+	fmt.Println("1. Create an API key")
+	fmt.Println("2. Revoke an API key")
+	fmt.Println("3. Back")
+
+	var choice int
+	color.New(color.FgYellow).Print("Enter your choice: ")
+	fmt.Scan(&choice)
+
+	switch choice {
+	case 1:
+		CreateAPIKeyUI(tokenService, user)
+	case 2:
+		var id int
+		color.New(color.FgYellow).Print("Enter the API key ID to revoke: ")
+		fmt.Scan(&id)
+
+		if err := tokenService.RevokeAPIKey(user.Username, id, services.AuditSourceCLI); err != nil {
+			color.New(color.FgRed).Printf("Error revoking API key: %v\n", err)
+			return
+		}
+		color.New(color.FgGreen).Printf("API key %d has been revoked.\n", id)
+	case 3:
+		return
+	default:
+		color.New(color.FgRed).Println("Invalid choice, please try again.")
+	}
+}
+
+// CreateAPIKeyUI asks for a name, scopes and expiry, then shows the new key once
+func CreateAPIKeyUI(tokenService services.TokenService, user *models.User) {
+	name := utils.GetLineInput(colorCyan("Enter a name for the key (e.g. price-script): "))
+
+	fmt.Println("Scopes:")
+	for i, scope := range services.APIKeyScopes {
+		fmt.Printf("%d. %s\n", i+1, scope)
+	}
+	color.New(color.FgYellow).Print("Enter the scope numbers separated by commas (e.g. 1,2): ")
+	var selection string
+	fmt.Scan(&selection)
+
+	var scopes []string
+	for _, field := range strings.Split(selection, ",") {
+		var number int
+		if _, err := fmt.Sscan(strings.TrimSpace(field), &number); err != nil || number < 1 || number > len(services.APIKeyScopes) {
+			color.New(color.FgRed).Printf("Invalid scope number: %s\n", field)
+			return
+		}
+		scopes = append(scopes, services.APIKeyScopes[number-1])
+	}
+
+	days := services.DefaultAPIKeyDays
+	color.New(color.FgYellow).Printf("Expire in how many days (1-%d): ", services.MaxAPIKeyDays)
+	fmt.Scan(&days)
+
+	key, apiKey, err := tokenService.CreateAPIKey(user.Username, name, scopes, days, services.AuditSourceCLI)
+	if err != nil {
+		color.New(color.FgRed).Printf("Error creating API key: %v\n", err)
+		return
+	}
+
+	fmt.Println()
+	color.New(color.FgGreen).Printf("API key %q created. Copy it now; it will not be shown again:\n", key.Name)
+	color.New(color.FgCyan, color.Bold).Println(apiKey)
+	fmt.Printf("Send it as \"Authorization: Bearer <key>\". It expires on %s.\n", key.ExpiresAt.Local().Format("2006-01-02"))
+}
@@ -0,0 +1,143 @@
+//go:build !test
+// +build !test
+
+package ui
+
+import (
+	"cryptotracker/internal/audit"
+	"cryptotracker/internal/services"
+	"cryptotracker/models"
+	"cryptotracker/pkg/utils"
+	"fmt"
+	"github.com/fatih/color"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ManageAuditLog checks the audit log's hash chain, then lets an admin search and export it
+func ManageAuditLog(adminService services.AdminService, admin *models.User) {
+	verification, err := adminService.VerifyAuditLog(admin.Username)
+	if err != nil {
+		color.New(color.FgRed).Printf("Error checking the audit log: %v\n", err)
+		return
+	}
+
+	fmt.Println()
+	color.New(color.FgGreen).Println("Audit log")
+	if verification.Intact {
+		color.New(color.FgGreen).Printf("Hash chain intact across %d entries.\n", verification.Entries)
+	} else {
+		color.New(color.FgRed, color.Bold).Printf("Hash chain BROKEN at entry %d: the log has been altered from there on.\n", verification.BrokenAt)
+	}
+
+	for {
+		fmt.Println()
+		fmt.Println("1. Search entries")
+		fmt.Println("2. Export entries to a file")
+		fmt.Println("3. Back")
+
+		var choice int
+		color.New(color.FgYellow).Print("Enter your choice: ")
+		fmt.Scan(&choice)
+
+		switch choice {
+		case 1:
+			filter, ok := readAuditFilter()
+			if !ok {
+				continue
+			}
+			events, err := adminService.GetAuditLog(filter, admin.Username)
+			if err != nil {
+				color.New(color.FgRed).Printf("Error searching the audit log: %v\n", err)
+				continue
+			}
+			printAuditEvents(events)
+		case 2:
+			filter, ok := readAuditFilter()
+			if !ok {
+				continue
+			}
+			ExportAuditLog(adminService, admin, filter)
+		case 3:
+			return
+		default:
+			color.New(color.FgRed).Println("Invalid choice, please try again.")
+		}
+	}
+}
+
+// readAuditFilter asks for search terms such as "actor=alice action=role. since=2026-01-01"
+func readAuditFilter() (*models.AuditFilter, bool) {
+	fmt.Printf("Search terms: %s (action may end in a dot to match a group, e.g. role.)\n", strings.Join(audit.FilterKeys, ", "))
+	input := utils.GetLineInput(colorCyan("Enter terms as key=value separated by spaces, or \"all\": "))
+
+	values := url.Values{}
+	if !strings.EqualFold(input, "all") {
+		for _, term := range strings.Fields(input) {
+			key, value, found := strings.Cut(term, "=")
+			if !found {
+				color.New(color.FgRed).Printf("Invalid search term %q: expected key=value\n", term)
+				return nil, false
+			}
+			values.Set(strings.ToLower(key), value)
+		}
+	}
+
+	filter, err := audit.ParseFilter(values)
+	if err != nil {
+		color.New(color.FgRed).Println(err)
+		return nil, false
+	}
+	return filter, true
+}
+
+func printAuditEvents(events []*models.AuditEvent) {
+	if len(events) == 0 {
+		fmt.Println("No entries match.")
+		return
+	}
+
+	fmt.Printf("%-6s %-17s %-15s %-22s %-15s %-25s %-5s %s\n", "ID", "Time", "Actor", "Action", "Target", "Change", "From", "Details")
+	for _, event := range events {
+		change := ""
+		if event.Before != "" || event.After != "" {
+			change = fmt.Sprintf("%s -> %s", valueOrDash(event.Before), valueOrDash(event.After))
+		}
+		fmt.Printf("%-6d %-17s %-15s %-22s %-15s %-25s %-5s %s\n", event.ID, event.CreatedAt.Local().Format("2006-01-02 15:04"),
+			event.Actor, event.Action, event.Target, change, event.Source, event.Details)
+	}
+	fmt.Printf("%d entries shown, newest first.\n", len(events))
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// ExportAuditLog writes the entries matching the filter to a CSV or JSON file
+func ExportAuditLog(adminService services.AdminService, admin *models.User, filter *models.AuditFilter) {
+	var format string
+	color.New(color.FgYellow).Printf("Enter the format (%s or %s): ", audit.FormatCSV, audit.FormatJSON)
+	fmt.Scan(&format)
+	format = strings.ToLower(format)
+
+	path := utils.GetLineInput(colorCyan(fmt.Sprintf("Enter the file to write (e.g. audit-log.%s): ", format)))
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		color.New(color.FgRed).Printf("Error creating the file: %v\n", err)
+		return
+	}
+	defer file.Close()
+
+	count, err := adminService.ExportAuditLog(file, filter, format, admin.Username, services.AuditSourceCLI)
+	if err != nil {
+		os.Remove(path)
+		color.New(color.FgRed).Printf("Error exporting the audit log: %v\n", err)
+		return
+	}
+	color.New(color.FgGreen).Printf("%d entries written to %s.\n", count, path)
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"github.com/fatih/color"
 	"github.com/jackc/pgx/v4"
+	"time"
 )
 
 // AuthenticateUser handles the login/signup process
@@ -29,17 +30,34 @@ func (ui *UI) AuthenticateUser(conn *pgx.Conn) (*models.User, string) {
 		switch choice {
 		case 1:
 			if user, Role, err := ui.LoginUI(authService); err == nil {
-				return user, Role
+				user.SessionID, err = ui.tokenService.StartSession(user.Username, services.SessionClientCLI, "")
+				if err == nil {
+					return user, Role
+				}
+				color.New(color.FgRed).Println("Login failed:", err)
 			} else {
 				color.New(color.FgRed).Println("Login failed:", err)
+
+				// Wait out the delay here rather than refusing the next attempt
+				var throttled *services.LoginThrottledError
+				if errors.As(err, &throttled) && !throttled.Locked {
+					time.Sleep(throttled.RetryAfter)
+				}
 			}
 		case 2:
-			if _, err := ui.SignupUI(conn, authService); err != nil {
+			if user, err := ui.SignupUI(conn, authService); err != nil {
 				color.New(color.FgRed).Println("Signup failed:", err)
 			} else {
 				color.New(color.FgGreen).Println("Signup successful. Please login.")
+				sendVerification(authService, user)
 			}
 		case 3:
+			if err := ui.ForgotPasswordUI(authService); err != nil {
+				color.New(color.FgRed).Println("Password reset failed:", err)
+			} else {
+				color.New(color.FgGreen).Println("Your password has been reset. Please login.")
+			}
+		case 4:
 			color.New(color.FgYellow).Println("Exiting...")
 			return nil, ""
 		default:
@@ -50,8 +68,7 @@ func (ui *UI) AuthenticateUser(conn *pgx.Conn) (*models.User, string) {
 
 // SignupUI handles user input and validation for PostgreSQL
 func (ui *UI) SignupUI(conn *pgx.Conn, authService services.AuthService) (*models.User, error) {
-	var username, password, email string
-	var mobile int
+	var username, password, email, timezone string
 
 	// Get user input for username
 	color.New(color.FgCyan).Print("Enter username: ")
@@ -74,14 +91,23 @@ func (ui *UI) SignupUI(conn *pgx.Conn, authService services.AuthService) (*model
 	}
 
 	// Get mobile number input and validate
-	color.New(color.FgCyan).Print("Enter mobile (10 digits): ")
-	fmt.Scan(&mobile)
-	if !validation.IsValidMobile(mobile) {
-		return nil, errors.New("invalid mobile number: must be 10 digits")
+	mobile, err := validation.NormalizeMobile(utils.GetLineInput(colorCyan("Enter mobile with country code (e.g. +91 98765 43210): ")))
+	if err != nil {
+		return nil, fmt.Errorf("invalid mobile number: %v", err)
+	}
+
+	// Get timezone input and validate
+	color.New(color.FgCyan).Print("Enter your timezone (e.g. Asia/Kolkata, UTC): ")
+	fmt.Scan(&timezone)
+	if !validation.IsValidTimezone(timezone) {
+		return nil, errors.New("invalid timezone: must be an IANA name such as Europe/London")
 	}
 
 	// Hash the password before creating the user object
-	hashedPassword := utils.HashPassword(password)
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create a new user object
 	user := &models.User{
@@ -91,14 +117,41 @@ func (ui *UI) SignupUI(conn *pgx.Conn, authService services.AuthService) (*model
 		Mobile:   mobile,
 		IsAdmin:  false,
 		Role:     "user",
+		Timezone: timezone,
 	}
 
 	// Insert user into PostgreSQL database using the auth package
-	err := authService.Signup(user)
+	err = authService.Signup(user)
 
 	return user, err
 }
 
+// ForgotPasswordUI emails a reset code to the user and sets the new password they choose
+func (ui *UI) ForgotPasswordUI(authService services.AuthService) error {
+	var identifier string
+	color.New(color.FgCyan).Print("Enter your username or email: ")
+	fmt.Scan(&identifier)
+
+	if err := authService.RequestPasswordReset(identifier); err != nil {
+		return err
+	}
+	color.New(color.FgGreen).Printf("If an account matches, a reset code has been emailed to it. It expires in %v.\n", services.PasswordResetTTL)
+
+	var code string
+	color.New(color.FgCyan).Print("Enter the reset code: ")
+	fmt.Scan(&code)
+
+	password := utils.GetHiddenInput("Enter new password: ")
+	if !validation.IsValidPassword(password) {
+		return errors.New("invalid password: must be at least 8 characters, include an uppercase letter, a number, and a special character")
+	}
+	if utils.GetHiddenInput("Confirm new password: ") != password {
+		return errors.New("passwords do not match")
+	}
+
+	return authService.ResetPassword(code, password, services.AuditSourceCLI)
+}
+
 // LoginUI handles user input and validation for PostgreSQL
 func (ui *UI) LoginUI(authService services.AuthService) (*models.User, string, error) {
 	var username, password string
@@ -117,7 +170,18 @@ func (ui *UI) LoginUI(authService services.AuthService) (*models.User, string, e
 	}
 
 	// Authenticate user with PostgreSQL using the auth package
-	user, role, err := authService.Login(username, password)
+	user, role, err := authService.Login(username, password, "")
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := SecondFactorUI(authService, user); err != nil {
+		return nil, "", err
+	}
+
+	if !user.EmailVerified {
+		color.New(color.FgYellow).Println("Your email address is not verified yet. Email alerts and password reset stay off until you verify it under User Profile.")
+	}
 
 	notifications, err := ui.notificationService.CheckNotification(username)
 	if err != nil {
@@ -131,5 +195,57 @@ func (ui *UI) LoginUI(authService services.AuthService) (*models.User, string, e
 		color.New(color.FgYellow).Println("No new notifications.")
 	}
 
-	return user, role, err
+	return user, role, nil
+}
+
+// sendVerification emails a verification code and tells the user what happened
+func sendVerification(authService services.AuthService, user *models.User) {
+	err := authService.SendEmailVerification(user.Username)
+	switch {
+	case err == nil:
+		color.New(color.FgGreen).Printf("A verification code has been emailed to %s. It expires in %v.\n", user.Email, services.EmailVerificationTTL)
+	case err == services.ErrVerificationUnavailable:
+		color.New(color.FgYellow).Println("Email verification is not available on this server, so email alerts and password reset stay off.")
+	default:
+		color.New(color.FgRed).Println("Could not send the verification email:", err)
+	}
+}
+
+// VerifyEmailUI lets a user with an unverified email address enter their code or ask for a
+// new one
+func VerifyEmailUI(authService services.AuthService, user *models.User) {
+	if user.EmailVerified {
+		color.New(color.FgGreen).Printf("Your email address %s is verified.\n", user.Email)
+		return
+	}
+
+	fmt.Println()
+	color.New(color.FgYellow).Printf("Your email address %s is not verified. Email alerts and password reset are off until it is.\n", user.Email)
+	fmt.Println("1. Enter verification code")
+	fmt.Println("2. Resend verification email")
+	fmt.Println("3. Back")
+
+	var choice int
+	color.New(color.FgYellow).Print("Enter your choice: ")
+	fmt.Scan(&choice)
+
+	switch choice {
+	case 1:
+		var code string
+		color.New(color.FgCyan).Print("Enter the verification code: ")
+		fmt.Scan(&code)
+
+		if err := authService.VerifyEmail(code, services.AuditSourceCLI); err != nil {
+			color.New(color.FgRed).Println("Verification failed:", err)
+			return
+		}
+		user.EmailVerified = true
+		color.New(color.FgGreen).Println("Your email address is verified.")
+	case 2:
+		sendVerification(authService, user)
+	case 3:
+		return
+	default:
+		color.New(color.FgRed).Println("Invalid choice, please try again.")
+	}
 }
@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"cryptotracker/internal/services"
+	"fmt"
+	"github.com/fatih/color"
+	"golang.org/x/term"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LiveNotificationInterval is how often the CLI checks for new notifications during a session.
+// Each check evaluates the user's alerts against the listings batch every session shares, which
+// is fetched again at most once a minute however many sessions are polling.
+const LiveNotificationInterval = time.Minute
+
+// NotificationWatcher polls for notifications while a user is logged in to the CLI. New
+// notifications are listed in a banner above the main menu the next time it is drawn. While
+// the menu is waiting for input, the first banner line is redrawn in place instead, keeping
+// the cursor where it was so the prompt and whatever has been typed are left alone.
+//
+// The watcher polls from its own goroutine, so its service must use its own connection.
+type NotificationWatcher struct {
+	service  services.NotificationService
+	username string
+	interval time.Duration
+	out      io.Writer
+	inPlace  bool
+
+	mu           sync.Mutex
+	pending      []string
+	seenRequests map[string]bool
+	atPrompt     bool
+	stop         chan struct{}
+}
+
+// NewNotificationWatcher creates a watcher for the user's session. Request decisions the user
+// has already been told about at login are not repeated.
+func NewNotificationWatcher(service services.NotificationService, username string) *NotificationWatcher {
+	return &NotificationWatcher{
+		service:      service,
+		username:     username,
+		interval:     LiveNotificationInterval,
+		out:          os.Stdout,
+		inPlace:      term.IsTerminal(int(os.Stdout.Fd())),
+		seenRequests: make(map[string]bool),
+		stop:         make(chan struct{}),
+	}
+}
+
+// Start begins polling in the background.
+func (w *NotificationWatcher) Start() {
+	if w == nil {
+		return
+	}
+
+	if requests, err := w.service.CheckUnavailableCryptoRequestsService(w.username); err == nil {
+		for _, request := range requests {
+			w.seenRequests[request.Message] = true
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				w.poll()
+			}
+		}
+	}()
+}
+
+// Stop ends polling.
+func (w *NotificationWatcher) Stop() {
+	if w == nil {
+		return
+	}
+	close(w.stop)
+}
+
+func (w *NotificationWatcher) poll() {
+	var messages []string
+
+	requests, err := w.service.CheckUnavailableCryptoRequestsService(w.username)
+	if err != nil {
+		return
+	}
+	for _, request := range requests {
+		if !w.seenRequests[request.Message] {
+			w.seenRequests[request.Message] = true
+			messages = append(messages, request.Message)
+		}
+	}
+
+	notifications, err := w.service.CheckSessionNotifications(w.username)
+	if err != nil {
+		return
+	}
+	for _, notification := range notifications {
+		messages = append(messages, notification.Message)
+	}
+
+	if len(messages) > 0 {
+		w.add(messages)
+	}
+}
+
+func (w *NotificationWatcher) add(messages []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pending = append(w.pending, messages...)
+	if w.atPrompt && w.inPlace {
+		// Save the cursor, rewrite the top line, then restore the cursor to the prompt
+		fmt.Fprintf(w.out, "\0337\033[1;1H\033[2K%s\0338", bannerHeadline(w.pending))
+	}
+}
+
+// DisplayBanner lists the notifications received since the menu was last drawn. It always
+// writes at least one line, so the top line of the screen is free for in-place updates.
+func (w *NotificationWatcher) DisplayBanner() {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.pending) == 0 {
+		fmt.Fprintln(w.out)
+		return
+	}
+
+	fmt.Fprintln(w.out, bannerHeadline(w.pending))
+	for i, message := range w.pending {
+		fmt.Fprintln(w.out, color.New(color.FgGreen).Sprintf("  %d. %s", i+1, message))
+	}
+	w.pending = nil
+}
+
+// WaitForInput marks whether the menu is waiting at its prompt. Call it with true just
+// before reading a choice and with false as soon as the read returns.
+func (w *NotificationWatcher) WaitForInput(waiting bool) {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.atPrompt = waiting
+}
+
+// bannerHeadline fits on one line: the count and the first line of the latest message.
+func bannerHeadline(pending []string) string {
+	latest, _, _ := strings.Cut(pending[len(pending)-1], "\n")
+	if len(pending) == 1 {
+		return color.New(color.FgBlack, color.BgYellow).Sprint(" 🔔 1 new notification ") + " " + latest
+	}
+	return color.New(color.FgBlack, color.BgYellow).Sprintf(" 🔔 %d new notifications ", len(pending)) + " " + latest
+}
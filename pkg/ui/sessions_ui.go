@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"cryptotracker/internal/services"
+	"cryptotracker/models"
+	"fmt"
+	"github.com/fatih/color"
+)
+
+// ManageSessions lists the user's live sessions on the CLI and REST clients and lets them end
+// any of them
+func ManageSessions(tokenService services.TokenService, user *models.User) {
+	sessions, err := tokenService.ListSessions(user.Username)
+	if err != nil {
+		color.New(color.FgRed).Printf("Error fetching sessions: %v\n", err)
+		return
+	}
+
+	fmt.Println()
+	color.New(color.FgGreen).Println("Active sessions")
+	fmt.Printf("%-4s %-7s %-18s %-17s %-17s\n", "No.", "Client", "Address", "Started", "Last seen")
+	for i, session := range sessions {
+		address := session.IP
+		if address == "" {
+			address = "local"
+		}
+		current := ""
+		if session.ID == user.SessionID {
+			current = "(this session)"
+		}
+		fmt.Printf("%-4d %-7s %-18s %-17s %-17s %s\n", i+1, session.Client, address,
+			session.CreatedAt.Local().Format("2006-01-02 15:04"), session.LastSeenAt.Local().Format("2006-01-02 15:04"), current)
+	}
+
+	fmt.Println()
+	fmt.Println("1. End a session")
+	fmt.Println("2. End all other sessions")
+	fmt.Println("3. Back")
+
+	var choice int
+	color.New(color.FgYellow).Print("Enter your choice: ")
+	fmt.Scan(&choice)
+
+	switch choice {
+	case 1:
+		var number int
+		color.New(color.FgYellow).Print("Enter the session number: ")
+		fmt.Scan(&number)
+		if number < 1 || number > len(sessions) {
+			color.New(color.FgRed).Println("Invalid session number.")
+			return
+		}
+		if sessions[number-1].ID == user.SessionID {
+			color.New(color.FgRed).Println("Use Logout to end this session.")
+			return
+		}
+
+		if err := tokenService.RevokeSession(user.Username, sessions[number-1].ID); err != nil {
+			color.New(color.FgRed).Printf("Error ending session: %v\n", err)
+			return
+		}
+		color.New(color.FgGreen).Println("Session ended.")
+	case 2:
+		ended := 0
+		for _, session := range sessions {
+			if session.ID == user.SessionID {
+				continue
+			}
+			if err := tokenService.RevokeSession(user.Username, session.ID); err != nil {
+				color.New(color.FgRed).Printf("Error ending session: %v\n", err)
+				return
+			}
+			ended++
+		}
+		color.New(color.FgGreen).Printf("%d other session(s) ended.\n", ended)
+	case 3:
+		return
+	default:
+		color.New(color.FgRed).Println("Invalid choice, please try again.")
+	}
+}
+
+// sessionEnded reports whether the CLI session was revoked or expired, telling the user
+func sessionEnded(tokenService services.TokenService, user *models.User) bool {
+	err := tokenService.CheckSession(user.SessionID)
+	if err == nil {
+		return false
+	}
+	if err == services.ErrSessionEnded {
+		color.New(color.FgRed).Println("Your session has been ended. Please log in again.")
+	} else {
+		color.New(color.FgRed).Println("Error checking your session:", err)
+	}
+	return true
+}
+
+// endSession revokes the CLI session when the user logs out
+func endSession(tokenService services.TokenService, user *models.User) {
+	if err := tokenService.RevokeSession(user.Username, user.SessionID); err != nil {
+		color.New(color.FgRed).Println("Error ending your session:", err)
+	}
+}
@@ -0,0 +1,128 @@
+package ui
+
+import (
+	"cryptotracker/internal/services"
+	"cryptotracker/models"
+	"errors"
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/skip2/go-qrcode"
+)
+
+// maxCodeAttempts is how many codes may be typed at the login prompt before going back to
+// the menu. Each wrong code also counts as a failed login.
+const maxCodeAttempts = 3
+
+// SecondFactorUI asks for an authenticator or recovery code after the password was accepted.
+// Users whose role requires two-factor authentication but who have not enrolled yet are
+// taken through enrollment instead.
+func SecondFactorUI(authService services.AuthService, user *models.User) error {
+	required, err := authService.TwoFactorRequired(user)
+	if err != nil {
+		return err
+	}
+	if !required {
+		return nil
+	}
+
+	if !user.TwoFactorEnabled {
+		color.New(color.FgYellow).Printf("Two-factor authentication is required for %s accounts. Let's set it up now.\n", user.Role)
+		if err := EnrollTwoFactorUI(authService, user.Username); err != nil {
+			return err
+		}
+		user.TwoFactorEnabled = true
+		return nil
+	}
+
+	for attempt := 1; ; attempt++ {
+		var code string
+		color.New(color.FgCyan).Print("Enter the code from your authenticator app, or a recovery code: ")
+		fmt.Scan(&code)
+
+		err := authService.VerifySecondFactor(user.Username, code, "")
+		if err == nil || !errors.Is(err, services.ErrInvalidTwoFactorCode) || attempt == maxCodeAttempts {
+			return err
+		}
+		color.New(color.FgRed).Println("That code is not valid, please try again.")
+	}
+}
+
+// EnrollTwoFactorUI shows a new secret as a QR code and turns two-factor authentication on
+// once the user types a code from it.
+func EnrollTwoFactorUI(authService services.AuthService, username string) error {
+	enrollment, err := authService.BeginTwoFactorEnrollment(username)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	color.New(color.FgGreen).Println("Scan this QR code with your authenticator app:")
+	if qr, err := qrcode.New(enrollment.URI, qrcode.Medium); err == nil {
+		fmt.Println(qr.ToSmallString(false))
+	}
+	fmt.Println("If you cannot scan it, enter this key instead:", enrollment.Secret)
+	fmt.Println("or open this link on a device with the app:", enrollment.URI)
+	fmt.Println()
+
+	var code string
+	color.New(color.FgCyan).Print("Enter the 6-digit code the app shows: ")
+	fmt.Scan(&code)
+
+	codes, err := authService.ConfirmTwoFactorEnrollment(username, code, services.AuditSourceCLI)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	color.New(color.FgGreen).Println("Two-factor authentication is on.")
+	color.New(color.FgYellow).Println("Keep these recovery codes somewhere safe. Each one can be used once instead of a code from the app, and they will not be shown again:")
+	for _, recoveryCode := range codes {
+		fmt.Println("   ", recoveryCode)
+	}
+	fmt.Println()
+	return nil
+}
+
+// ManageTwoFactor lets a logged-in user turn two-factor authentication on or off
+func ManageTwoFactor(authService services.AuthService, user *models.User) {
+	fmt.Println()
+	if !user.TwoFactorEnabled {
+		color.New(color.FgGreen).Println("Two-factor authentication is off.")
+		fmt.Println("1. Turn on two-factor authentication")
+		fmt.Println("2. Back")
+	} else {
+		color.New(color.FgGreen).Println("Two-factor authentication is on.")
+		fmt.Println("1. Turn off two-factor authentication")
+		fmt.Println("2. Back")
+	}
+
+	var choice int
+	color.New(color.FgYellow).Print("Enter your choice: ")
+	fmt.Scan(&choice)
+
+	switch choice {
+	case 1:
+		if !user.TwoFactorEnabled {
+			if err := EnrollTwoFactorUI(authService, user.Username); err != nil {
+				color.New(color.FgRed).Printf("Error turning on two-factor authentication: %v\n", err)
+				return
+			}
+			user.TwoFactorEnabled = true
+			return
+		}
+
+		var code string
+		color.New(color.FgCyan).Print("Enter a code from your authenticator app, or a recovery code: ")
+		fmt.Scan(&code)
+		if err := authService.DisableTwoFactor(user.Username, code, ""); err != nil {
+			color.New(color.FgRed).Printf("Error turning off two-factor authentication: %v\n", err)
+			return
+		}
+		user.TwoFactorEnabled = false
+		color.New(color.FgGreen).Println("Two-factor authentication is off.")
+	case 2:
+		return
+	default:
+		color.New(color.FgRed).Println("Invalid choice, please try again.")
+	}
+}
@@ -46,7 +46,8 @@ func DisplayAuthMenu() {
 	//fmt.Println(colorYellow("Authentication Menu:"))
 	fmt.Println(colorBlue("1. Login"))
 	fmt.Println(colorBlue("2. SignUp"))
-	fmt.Println(colorBlue("3. Exit"))
+	fmt.Println(colorBlue("3. Forgot Password"))
+	fmt.Println(colorBlue("4. Exit"))
 	fmt.Println()
 }
 
@@ -61,7 +62,13 @@ func DisplayMainMenu() {
 	fmt.Println(colorGreen("2. Search for a Cryptocurrency"))
 	fmt.Println(colorGreen("3. Set Price Alert"))
 	fmt.Println(colorGreen("4. User Profile"))
-	fmt.Println(colorRed("5. Logout"))
+	fmt.Println(colorGreen("5. Alert History"))
+	fmt.Println(colorGreen("6. Manage Alert Groups"))
+	fmt.Println(colorGreen("7. Digest Settings"))
+	fmt.Println(colorGreen("8. Two-Factor Authentication"))
+	fmt.Println(colorGreen("9. Active Sessions"))
+	fmt.Println(colorGreen("10. API Keys"))
+	fmt.Println(colorRed("11. Logout"))
 	fmt.Println()
 }
 
@@ -75,6 +82,7 @@ type UI struct {
 	adminService        services.AdminService
 	cryptoService       services.CryptoService
 	notificationService services.NotificationService
+	tokenService        services.TokenService
 }
 
 // NewUI initializes the UI with the provided services
@@ -83,11 +91,13 @@ func NewUI(
 	adminService services.AdminService,
 	cryptoService services.CryptoService,
 	notificationService services.NotificationService,
+	tokenService services.TokenService,
 ) *UI {
 	return &UI{
 		userService:         userService,
 		adminService:        adminService,
 		cryptoService:       cryptoService,
 		notificationService: notificationService,
+		tokenService:        tokenService,
 	}
 }
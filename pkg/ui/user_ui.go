@@ -1,8 +1,14 @@
 package ui
 
 import (
+	"cryptotracker/internal/alerts"
+	"cryptotracker/internal/notify"
+	"cryptotracker/internal/rbac"
+	"cryptotracker/internal/repositories"
 	"cryptotracker/internal/services"
 	"cryptotracker/models"
+	"cryptotracker/pkg/utils"
+	"cryptotracker/pkg/validation"
 	"fmt"
 	"github.com/fatih/color"
 	"github.com/jackc/pgx/v4"
@@ -10,18 +16,29 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
 	//"github.com/jackc/pgx/v5"
 )
 
 // MainMenu displays the main menu for a regular user
-func MainMenu(conn *pgx.Conn, user *models.User, userService *services.UserServiceImpl, cryptoService *services.CryptoServiceImpl) {
+func MainMenu(conn *pgx.Conn, user *models.User, userService *services.UserServiceImpl, cryptoService *services.CryptoServiceImpl, notificationService services.NotificationService, stablecoinService services.StablecoinService, tokenService services.TokenService, watcher *NotificationWatcher) {
+	watcher.Start()
+	defer watcher.Stop()
+
 	for {
+		if sessionEnded(tokenService, user) {
+			return
+		}
+
 		ClearScreen()
+		watcher.DisplayBanner()
 		DisplayMainMenu()
 
 		var choice int
 		color.New(color.FgYellow).Print("Enter your choice: ")
+		watcher.WaitForInput(true)
 		fmt.Scan(&choice)
+		watcher.WaitForInput(false)
 
 		switch choice {
 		case 1:
@@ -29,10 +46,27 @@ func MainMenu(conn *pgx.Conn, user *models.User, userService *services.UserServi
 		case 2:
 			SearchCryptocurrency(user, cryptoService)
 		case 3:
-			SetPriceAlert(user, cryptoService)
+			if allowed(user, rbac.AlertsManage) {
+				SetPriceAlert(user, cryptoService, stablecoinService)
+			}
 		case 4:
-			DisplayUserProfile(userService, user.Username)
+			DisplayUserProfile(userService, services.NewAuthService(repositories.NewPostgresAuthRepository(conn), notificationService), user)
 		case 5:
+			DisplayAlertHistory(user, notificationService)
+		case 6:
+			if allowed(user, rbac.AlertsManage) {
+				ManageAlertGroups(user, cryptoService)
+			}
+		case 7:
+			ManageDigestSettings(user, notificationService)
+		case 8:
+			ManageTwoFactor(services.NewAuthService(repositories.NewPostgresAuthRepository(conn), notificationService), user)
+		case 9:
+			ManageSessions(tokenService, user)
+		case 10:
+			ManageAPIKeys(tokenService, user)
+		case 11:
+			endSession(tokenService, user)
 			color.New(color.FgCyan).Println("Logging out...")
 			log.Println("Logging out...")
 			os.Exit(0)
@@ -42,8 +76,9 @@ func MainMenu(conn *pgx.Conn, user *models.User, userService *services.UserServi
 	}
 }
 
-// DisplayUserProfile handles the display of the user profile
-func DisplayUserProfile(userService *services.UserServiceImpl, username string) {
+// DisplayUserProfile handles the display of the user profile and lets the user change it
+func DisplayUserProfile(userService *services.UserServiceImpl, authService services.AuthService, sessionUser *models.User) {
+	username := sessionUser.Username
 	user, err := userService.GetUserProfile(username)
 	if err != nil {
 		color.New(color.FgRed).Println("Error fetching user profile:", err)
@@ -62,11 +97,192 @@ func DisplayUserProfile(userService *services.UserServiceImpl, username string)
 
 	// Print user profile details
 	printDetail("Username", user.Username, width)
-	printDetail("Email", user.Email, width)
-	printDetail("Mobile", fmt.Sprintf("%d", user.Mobile), width)
+	email := user.Email
+	if !user.EmailVerified {
+		email += " (unverified)"
+	}
+	printDetail("Email", email, width)
+	printDetail("Mobile", validation.FormatMobile(user.Mobile), width)
 	printDetail("Role", user.Role, width)
+	printDetail("Timezone", user.Timezone, width)
 
 	fmt.Println()
+	color.New(color.FgGreen, color.Bold).Println("==== Notifications ====")
+	fmt.Println()
+	printPreferences(notify.Normalize(user.NotificationPreferences), width)
+	fmt.Println()
+
+	fmt.Println("1. Edit notification preferences")
+	fmt.Println("2. Change email address")
+	fmt.Println("3. Change mobile number")
+	fmt.Println("4. Change password")
+	fmt.Println("5. Back")
+	if !user.EmailVerified {
+		fmt.Println("6. Verify email address")
+	}
+
+	var choice int
+	color.New(color.FgYellow).Print("Enter your choice: ")
+	fmt.Scan(&choice)
+
+	switch choice {
+	case 1:
+		EditNotificationPreferences(userService, username)
+	case 2:
+		ChangeEmail(userService, authService, sessionUser)
+	case 3:
+		ChangeMobile(userService, username)
+	case 4:
+		ChangePassword(userService, username)
+	case 6:
+		if !user.EmailVerified {
+			sessionUser.EmailVerified = false
+			VerifyEmailUI(authService, sessionUser)
+		}
+	}
+}
+
+// ChangeEmail sets a new email address and emails it a verification code
+func ChangeEmail(userService *services.UserServiceImpl, authService services.AuthService, user *models.User) {
+	var email string
+	color.New(color.FgCyan).Print("Enter new email: ")
+	fmt.Scan(&email)
+	if !validation.IsValidEmail(email) {
+		color.New(color.FgRed).Println("Invalid email: must be a valid email address")
+		return
+	}
+
+	changed, err := userService.UpdateProfile(user.Username, &services.ProfileUpdate{Email: &email}, services.AuditSourceCLI)
+	if err != nil {
+		color.New(color.FgRed).Println("Error changing email address:", err)
+		return
+	}
+	if !changed {
+		color.New(color.FgYellow).Println("That is already your email address.")
+		return
+	}
+
+	user.Email = email
+	user.EmailVerified = false
+	color.New(color.FgGreen).Println("Email address changed. Email alerts and password reset stay off until you verify it.")
+	sendVerification(authService, user)
+}
+
+// ChangeMobile sets a new mobile number
+func ChangeMobile(userService *services.UserServiceImpl, username string) {
+	mobile, err := validation.NormalizeMobile(utils.GetLineInput(colorCyan("Enter new mobile with country code (e.g. +91 98765 43210): ")))
+	if err != nil {
+		color.New(color.FgRed).Println("Invalid mobile number:", err)
+		return
+	}
+
+	if _, err := userService.UpdateProfile(username, &services.ProfileUpdate{Mobile: &mobile}, services.AuditSourceCLI); err != nil {
+		color.New(color.FgRed).Println("Error changing mobile number:", err)
+		return
+	}
+	color.New(color.FgGreen).Println("Mobile number changed.")
+}
+
+// ChangePassword sets a new password after checking the current one
+func ChangePassword(userService *services.UserServiceImpl, username string) {
+	update := &services.ProfileUpdate{CurrentPassword: utils.GetHiddenInput("Enter current password: ")}
+
+	update.NewPassword = utils.GetHiddenInput("Enter new password: ")
+	if !validation.IsValidPassword(update.NewPassword) {
+		color.New(color.FgRed).Println("Invalid password: must be at least 8 characters, include an uppercase letter, a number, and a special character")
+		return
+	}
+	if utils.GetHiddenInput("Confirm new password: ") != update.NewPassword {
+		color.New(color.FgRed).Println("Passwords do not match")
+		return
+	}
+
+	if _, err := userService.UpdateProfile(username, update, services.AuditSourceCLI); err != nil {
+		color.New(color.FgRed).Println("Error changing password:", err)
+		return
+	}
+	color.New(color.FgGreen).Println("Password changed. Every session has been ended, so please log in again.")
+}
+
+// printPreferences prints where each event type is sent, plus quiet hours and the hourly cap
+func printPreferences(preferences *models.NotificationPreferences, width int) {
+	for _, event := range notify.Events {
+		channels := strings.Join(preferences.Channels[event], ", ")
+		if channels == "" {
+			channels = "off"
+		}
+		printDetail(event, channels, width)
+	}
+
+	quietHours := "none"
+	if preferences.QuietFrom != "" {
+		quietHours = preferences.QuietFrom + " - " + preferences.QuietTo
+	}
+	printDetail("Quiet hours", quietHours, width)
+
+	maxPerHour := "no limit"
+	if preferences.MaxPerHour > 0 {
+		maxPerHour = fmt.Sprintf("%d", preferences.MaxPerHour)
+	}
+	printDetail("Max per hour", maxPerHour, width)
+
+	if preferences.WebhookURL != "" {
+		printDetail("Webhook URL", preferences.WebhookURL, width)
+	}
+}
+
+// EditNotificationPreferences walks the user through their notification preferences
+func EditNotificationPreferences(userService *services.UserServiceImpl, username string) {
+	preferences, err := userService.GetNotificationPreferences(username)
+	if err != nil {
+		color.New(color.FgRed).Println("Error fetching notification preferences:", err)
+		return
+	}
+
+	color.New(color.FgCyan).Printf("Channels: %s. Separate several with commas, enter \"off\" for none, leave empty to keep.\n", strings.Join(notify.AllChannels, ", "))
+	for _, event := range notify.Events {
+		input := utils.GetLineInput(colorCyan(fmt.Sprintf("%s [%s]: ", event, strings.Join(preferences.Channels[event], ","))))
+		input = strings.TrimSpace(input)
+		switch {
+		case input == "":
+			continue
+		case strings.EqualFold(input, "off"):
+			preferences.Channels[event] = []string{}
+		default:
+			var channels []string
+			for _, channel := range strings.Split(input, ",") {
+				if channel = strings.ToLower(strings.TrimSpace(channel)); channel != "" {
+					channels = append(channels, channel)
+				}
+			}
+			preferences.Channels[event] = channels
+		}
+	}
+
+	if webhook := strings.TrimSpace(utils.GetLineInput(colorCyan(fmt.Sprintf("Webhook URL [%s]: ", preferences.WebhookURL)))); webhook != "" {
+		preferences.WebhookURL = webhook
+	}
+
+	quiet := strings.TrimSpace(utils.GetLineInput(colorCyan("Quiet hours as HH:MM-HH:MM (\"none\" to clear, empty to keep): ")))
+	if strings.EqualFold(quiet, "none") {
+		preferences.QuietFrom, preferences.QuietTo = "", ""
+	} else if quiet != "" {
+		parts := strings.SplitN(quiet, "-", 2)
+		preferences.QuietFrom = strings.TrimSpace(parts[0])
+		preferences.QuietTo = ""
+		if len(parts) == 2 {
+			preferences.QuietTo = strings.TrimSpace(parts[1])
+		}
+	}
+
+	color.New(color.FgCyan).Printf("Maximum outbound notifications per hour, 0 for no limit [%d]: ", preferences.MaxPerHour)
+	fmt.Scan(&preferences.MaxPerHour)
+
+	if err := userService.UpdateNotificationPreferences(username, preferences); err != nil {
+		color.New(color.FgRed).Println("Error saving notification preferences:", err)
+		return
+	}
+	color.New(color.FgGreen).Println("Notification preferences saved.")
 }
 
 // printDetail prints a formatted profile detail
@@ -151,8 +367,44 @@ func SearchCryptocurrency(user *models.User, cryptoService *services.CryptoServi
 	}
 }
 
-// SetPriceAlert prompts user to set a price alert
-func SetPriceAlert(user *models.User, cryptoService *services.CryptoServiceImpl) {
+// SetPriceAlert prompts user to choose the kind of alert to set
+func SetPriceAlert(user *models.User, cryptoService *services.CryptoServiceImpl, stablecoinService services.StablecoinService) {
+	fmt.Println()
+	color.New(color.FgGreen).Println("Alert type")
+	fmt.Println("1. Target price")
+	fmt.Println("2. Compound rule (e.g. BTC < 60000 AND ETH/BTC > 0.06)")
+	fmt.Println("3. Trailing stop (e.g. ADA drops 7% from its high)")
+	fmt.Println("4. Ladder (several levels at once)")
+	fmt.Println("5. Volume spike (e.g. 24h volume above 3x its 7-day average)")
+	fmt.Println("6. Market-cap rank (e.g. enters or leaves the top 20)")
+	fmt.Println("7. Stablecoin de-peg notifications")
+
+	var choice int
+	color.New(color.FgYellow).Print("Enter your choice: ")
+	fmt.Scan(&choice)
+
+	switch choice {
+	case 1:
+		SetTargetPriceAlert(user, cryptoService)
+	case 2:
+		SetRuleAlert(user, cryptoService)
+	case 3:
+		SetTrailingAlert(user, cryptoService)
+	case 4:
+		SetLadderAlert(user, cryptoService)
+	case 5:
+		SetVolumeSpikeAlert(user, cryptoService)
+	case 6:
+		SetRankAlert(user, cryptoService)
+	case 7:
+		ToggleDepegSubscription(user, stablecoinService)
+	default:
+		color.New(color.FgRed).Println("Invalid choice, please try again.")
+	}
+}
+
+// SetTargetPriceAlert prompts user to set an alert on a single target price
+func SetTargetPriceAlert(user *models.User, cryptoService *services.CryptoServiceImpl) {
 	var symbol string
 	var targetPrice float64
 
@@ -161,7 +413,13 @@ func SetPriceAlert(user *models.User, cryptoService *services.CryptoServiceImpl)
 	color.New(color.FgCyan).Print("Enter the target price: ")
 	fmt.Scan(&targetPrice)
 
-	currentPrice, err := cryptoService.SetPriceAlert(user, symbol, targetPrice)
+	options, err := promptAlertOptions(user, true)
+	if err != nil {
+		color.New(color.FgRed).Printf("Error setting price alert: %v\n", err)
+		return
+	}
+
+	currentPrice, err := cryptoService.SetPriceAlert(user, symbol, targetPrice, options)
 	if err.Error() == fmt.Sprintf("%s is still below your target price. Current price: $%.2f. Notification created.\n", symbol, currentPrice) {
 		color.New(color.FgGreen).Printf("%s is still below your target price. Current price: $%.2f. Notification created.\n", symbol, currentPrice)
 	} else if err.Error() == fmt.Sprintf("Alert: %s has reached your target price of $%.2f. Current price: $%.2f\n", symbol, targetPrice, currentPrice) {
@@ -170,3 +428,432 @@ func SetPriceAlert(user *models.User, cryptoService *services.CryptoServiceImpl)
 		color.New(color.FgRed).Printf("Error setting price alert: %v\n", err)
 	}
 }
+
+// SetRuleAlert prompts user for a compound alert expression
+func SetRuleAlert(user *models.User, cryptoService *services.CryptoServiceImpl) {
+	color.New(color.FgCyan).Println("Conditions compare price, 24h_change, volume_24h or market_cap, and can be joined with AND/OR.")
+	color.New(color.FgCyan).Println("Examples: BTC < 60000 AND ETH/BTC > 0.06, SOL 24h_change > 8 OR SOL volume_24h > 2x avg")
+	expression := utils.GetLineInput(colorCyan("Enter the rule: "))
+
+	options, err := promptAlertOptions(user, false)
+	if err != nil {
+		color.New(color.FgRed).Printf("Error setting alert: %v\n", err)
+		return
+	}
+
+	rule, err := cryptoService.SetRuleAlert(user, expression, options)
+	if err != nil {
+		color.New(color.FgRed).Printf("Error setting alert: %v\n", err)
+		return
+	}
+
+	color.New(color.FgGreen).Printf("Alert created: %s\n", rule)
+}
+
+// SetTrailingAlert prompts user for a trailing-stop alert
+func SetTrailingAlert(user *models.User, cryptoService *services.CryptoServiceImpl) {
+	var symbol string
+	var trailPercent float64
+
+	color.New(color.FgCyan).Print("Enter the cryptocurrency symbol: ")
+	fmt.Scan(&symbol)
+	color.New(color.FgCyan).Print("Notify when the price drops this percent from its high: ")
+	fmt.Scan(&trailPercent)
+
+	options, err := promptAlertOptions(user, false)
+	if err != nil {
+		color.New(color.FgRed).Printf("Error setting alert: %v\n", err)
+		return
+	}
+
+	currentPrice, err := cryptoService.SetTrailingAlert(user, symbol, trailPercent, options)
+	if err != nil {
+		color.New(color.FgRed).Printf("Error setting alert: %v\n", err)
+		return
+	}
+
+	color.New(color.FgGreen).Printf("Trailing alert created for %s. Tracking from $%.2f; it fires %.2f%% below the highest price seen.\n", strings.ToUpper(symbol), currentPrice, trailPercent)
+}
+
+// SetVolumeSpikeAlert prompts user to set an alert on unusual 24h volume
+func SetVolumeSpikeAlert(user *models.User, cryptoService *services.CryptoServiceImpl) {
+	var symbol string
+	var multiplier float64
+
+	color.New(color.FgCyan).Print("Enter the cryptocurrency symbol: ")
+	fmt.Scan(&symbol)
+	color.New(color.FgCyan).Print("Notify when 24h volume exceeds this multiple of its 7-day average: ")
+	fmt.Scan(&multiplier)
+
+	options, err := promptAlertOptions(user, false)
+	if err != nil {
+		color.New(color.FgRed).Printf("Error setting alert: %v\n", err)
+		return
+	}
+
+	volume, err := cryptoService.SetVolumeSpikeAlert(user, symbol, multiplier, options)
+	if err != nil {
+		color.New(color.FgRed).Printf("Error setting alert: %v\n", err)
+		return
+	}
+
+	color.New(color.FgGreen).Printf("Volume spike alert created for %s. Current 24h volume: $%.0f.\n", strings.ToUpper(symbol), volume)
+	color.New(color.FgYellow).Println("Averages are built from stored snapshots, so the alert starts firing once enough history exists.")
+}
+
+// SetRankAlert prompts user to set an alert on a market-cap rank threshold
+func SetRankAlert(user *models.User, cryptoService *services.CryptoServiceImpl) {
+	var symbol string
+	var threshold int
+
+	color.New(color.FgCyan).Print("Enter the cryptocurrency symbol: ")
+	fmt.Scan(&symbol)
+	color.New(color.FgCyan).Print("Enter the rank threshold (e.g. 20 for the top 20): ")
+	fmt.Scan(&threshold)
+
+	options, err := promptAlertOptions(user, false)
+	if err != nil {
+		color.New(color.FgRed).Printf("Error setting alert: %v\n", err)
+		return
+	}
+
+	rank, err := cryptoService.SetRankAlert(user, symbol, threshold, options)
+	if err != nil {
+		color.New(color.FgRed).Printf("Error setting alert: %v\n", err)
+		return
+	}
+
+	if rank > threshold {
+		color.New(color.FgGreen).Printf("Rank alert created. %s is ranked #%d; you will be notified when it enters the top %d.\n", strings.ToUpper(symbol), rank, threshold)
+	} else {
+		color.New(color.FgGreen).Printf("Rank alert created. %s is ranked #%d; you will be notified when it drops out of the top %d.\n", strings.ToUpper(symbol), rank, threshold)
+	}
+}
+
+// ToggleDepegSubscription shows the monitored stablecoins and subscribes or unsubscribes the user
+func ToggleDepegSubscription(user *models.User, stablecoinService services.StablecoinService) {
+	pegs, err := stablecoinService.GetPegs()
+	if err != nil {
+		color.New(color.FgRed).Printf("Error fetching stablecoins: %v\n", err)
+		return
+	}
+
+	subscribed, err := stablecoinService.IsSubscribed(user.Username)
+	if err != nil {
+		color.New(color.FgRed).Printf("Error fetching subscription: %v\n", err)
+		return
+	}
+
+	fmt.Println()
+	color.New(color.FgGreen).Println("Monitored stablecoins:")
+	for _, peg := range pegs {
+		fmt.Printf("  %s: $%.2f peg, flagged after %d samples more than %.2f%% away\n", peg.Symbol, peg.PegPrice, peg.SustainedSamples, peg.ThresholdPercent)
+	}
+	fmt.Println()
+
+	var answer string
+	if subscribed {
+		color.New(color.FgCyan).Print("You are subscribed to de-peg notifications. Unsubscribe? (y/n): ")
+	} else {
+		color.New(color.FgCyan).Print("Subscribe to de-peg notifications? (y/n): ")
+	}
+	fmt.Scan(&answer)
+	if !strings.EqualFold(answer, "y") {
+		return
+	}
+
+	if subscribed {
+		err = stablecoinService.Unsubscribe(user.Username)
+	} else {
+		err = stablecoinService.Subscribe(user.Username)
+	}
+	if err != nil {
+		color.New(color.FgRed).Printf("Error updating subscription: %v\n", err)
+		return
+	}
+
+	if subscribed {
+		color.New(color.FgGreen).Println("Unsubscribed from de-peg notifications.")
+	} else {
+		color.New(color.FgGreen).Println("Subscribed to de-peg notifications.")
+	}
+}
+
+// SetLadderAlert prompts user for a symbol and a set of levels to alert on
+func SetLadderAlert(user *models.User, cryptoService *services.CryptoServiceImpl) {
+	var symbol, spec string
+
+	color.New(color.FgCyan).Print("Enter the cryptocurrency symbol: ")
+	fmt.Scan(&symbol)
+	color.New(color.FgCyan).Print("Enter a range as FROM-TO/STEP (e.g. 60000-70000/2500) or levels (e.g. 61000,63500,66000): ")
+	fmt.Scan(&spec)
+
+	levels, err := alerts.ParseLadderSpec(spec)
+	if err != nil {
+		color.New(color.FgRed).Printf("Error setting alert: %v\n", err)
+		return
+	}
+
+	options, err := promptAlertOptions(user, true)
+	if err != nil {
+		color.New(color.FgRed).Printf("Error setting alert: %v\n", err)
+		return
+	}
+
+	group, err := cryptoService.SetLadderAlert(user, symbol, levels, options)
+	if err != nil {
+		color.New(color.FgRed).Printf("Error setting alert: %v\n", err)
+		return
+	}
+
+	color.New(color.FgGreen).Printf("Ladder %d created for %s with %d levels.\n", group.ID, group.Crypto, len(group.Alerts))
+	for _, alert := range group.Alerts {
+		color.New(color.FgCyan).Printf("  $%.2f (%s)\n", alert.TargetPrice, alert.Direction)
+	}
+}
+
+// ManageAlertGroups lists the user's alert groups and pauses, resumes or deletes one of them
+func ManageAlertGroups(user *models.User, cryptoService *services.CryptoServiceImpl) {
+	groups, err := cryptoService.GetAlertGroups(user.Username, user.Username)
+	if err != nil {
+		color.New(color.FgRed).Println("Error fetching alert groups:", err)
+		return
+	}
+
+	if len(groups) == 0 {
+		color.New(color.FgCyan).Println("You have no alert groups.")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"ID", "Symbol", "Levels", "Fired", "Status"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetBorder(true)
+	table.SetRowLine(true)
+
+	for _, group := range groups {
+		var levels []string
+		fired := 0
+		for _, alert := range group.Alerts {
+			levels = append(levels, fmt.Sprintf("$%.2f", alert.TargetPrice))
+			if alert.TriggerCount > 0 {
+				fired++
+			}
+		}
+		table.Append([]string{
+			fmt.Sprintf("%d", group.ID),
+			group.Crypto,
+			strings.Join(levels, ", "),
+			fmt.Sprintf("%d/%d", fired, len(group.Alerts)),
+			group.Status,
+		})
+	}
+
+	fmt.Println()
+	table.Render()
+	fmt.Println()
+
+	fmt.Println("1. Pause a group")
+	fmt.Println("2. Resume a group")
+	fmt.Println("3. Delete a group")
+	fmt.Println("4. Back")
+
+	var choice int
+	color.New(color.FgYellow).Print("Enter your choice: ")
+	fmt.Scan(&choice)
+	if choice == 4 {
+		return
+	}
+	if choice < 1 || choice > 4 {
+		color.New(color.FgRed).Println("Invalid choice, please try again.")
+		return
+	}
+
+	var groupID int
+	color.New(color.FgYellow).Print("Enter the group ID: ")
+	fmt.Scan(&groupID)
+
+	switch choice {
+	case 1:
+		err = cryptoService.SetAlertGroupStatus(user.Username, groupID, "Paused", user.Username)
+	case 2:
+		err = cryptoService.SetAlertGroupStatus(user.Username, groupID, "Active", user.Username)
+	case 3:
+		err = cryptoService.DeleteAlertGroup(user.Username, groupID, user.Username)
+	}
+
+	if err != nil {
+		color.New(color.FgRed).Println("Error updating alert group:", err)
+		return
+	}
+	color.New(color.FgGreen).Println("Alert group updated.")
+}
+
+// promptAlertOptions asks for the optional recurrence, expiry and active-hour settings of an alert.
+// The hysteresis band is only offered for alerts on a single target price.
+func promptAlertOptions(user *models.User, withHysteresis bool) (*models.AlertOptions, error) {
+	options := &models.AlertOptions{}
+
+	var recurring string
+	color.New(color.FgCyan).Print("Make this a recurring alert? (y/n): ")
+	fmt.Scan(&recurring)
+	if strings.ToLower(recurring) == "y" {
+		var cooldownMinutes int
+		options.Recurring = true
+
+		color.New(color.FgCyan).Print("Cooldown before re-arming, in minutes (0 for none): ")
+		fmt.Scan(&cooldownMinutes)
+		options.CooldownSeconds = cooldownMinutes * 60
+
+		if withHysteresis {
+			color.New(color.FgCyan).Print("Re-arm once the price falls back by this percent (0 for none): ")
+			fmt.Scan(&options.HysteresisPercent)
+		}
+	}
+
+	loc := alerts.LoadLocation(user.Timezone)
+
+	var expiry string
+	color.New(color.FgCyan).Print("Keep the alert until (YYYY-MM-DD, or 'never'): ")
+	fmt.Scan(&expiry)
+	if strings.ToLower(expiry) != "never" {
+		day, err := time.ParseInLocation("2006-01-02", expiry, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q: use YYYY-MM-DD", expiry)
+		}
+		expiresAt := day.AddDate(0, 0, 1)
+		options.ExpiresAt = &expiresAt
+	}
+
+	var window string
+	color.New(color.FgCyan).Printf("Only notify between (HH:MM-HH:MM in %s, or 'any'): ", loc)
+	fmt.Scan(&window)
+	if strings.ToLower(window) != "any" {
+		from, to, found := strings.Cut(window, "-")
+		if !found {
+			return nil, fmt.Errorf("invalid active hours %q: use HH:MM-HH:MM", window)
+		}
+		options.ActiveFrom = from
+		options.ActiveTo = to
+	}
+
+	return options, nil
+}
+
+// ManageDigestSettings shows the user's digest schedule and lets them change it or preview a digest
+func ManageDigestSettings(user *models.User, notificationService services.NotificationService) {
+	settings, err := notificationService.GetDigestSettings(user.Username)
+	if err != nil {
+		color.New(color.FgRed).Println("Error fetching digest settings:", err)
+		return
+	}
+
+	fmt.Println()
+	color.New(color.FgGreen, color.Bold).Println("==== Digest Settings ====")
+	fmt.Println()
+	width := 20
+	printDetail("Frequency", settings.Frequency, width)
+	printDetail("Send at", settings.SendAt+" "+user.Timezone, width)
+	if settings.Frequency == "weekly" {
+		printDetail("Day", time.Weekday(settings.Weekday).String(), width)
+	}
+	watchlist := strings.Join(settings.Watchlist, ", ")
+	if watchlist == "" {
+		watchlist = "coins with active alerts"
+	}
+	printDetail("Watchlist", watchlist, width)
+	fmt.Println()
+
+	fmt.Println("1. Change schedule")
+	fmt.Println("2. Preview digest")
+	fmt.Println("3. Back")
+
+	var choice int
+	color.New(color.FgYellow).Print("Enter your choice: ")
+	fmt.Scan(&choice)
+
+	switch choice {
+	case 1:
+		color.New(color.FgCyan).Print("Frequency (off, daily, weekly): ")
+		fmt.Scan(&settings.Frequency)
+		settings.Frequency = strings.ToLower(settings.Frequency)
+
+		if settings.Frequency != "off" {
+			color.New(color.FgCyan).Print("Local send time (HH:MM): ")
+			fmt.Scan(&settings.SendAt)
+			if settings.Frequency == "weekly" {
+				color.New(color.FgCyan).Print("Day of the week (0 = Sunday ... 6 = Saturday): ")
+				fmt.Scan(&settings.Weekday)
+			}
+			list := utils.GetLineInput(colorCyan("Watchlist symbols, comma separated (leave empty for coins with active alerts): "))
+			settings.Watchlist = strings.Split(list, ",")
+		}
+
+		if err := notificationService.SaveDigestSettings(settings); err != nil {
+			color.New(color.FgRed).Println("Error saving digest settings:", err)
+			return
+		}
+		color.New(color.FgGreen).Println("Digest settings saved.")
+	case 2:
+		preview, err := notificationService.PreviewDigest(user.Username)
+		if err != nil {
+			color.New(color.FgRed).Println("Error building digest preview:", err)
+			return
+		}
+		fmt.Println()
+		fmt.Println(preview)
+	case 3:
+		return
+	default:
+		color.New(color.FgRed).Println("Invalid choice, please try again.")
+	}
+}
+
+// DisplayAlertHistory lists every time one of the user's alerts has fired
+func DisplayAlertHistory(user *models.User, notificationService services.NotificationService) {
+	occurrences, err := notificationService.GetAlertOccurrences(user.Username)
+	if err != nil {
+		color.New(color.FgRed).Println("Error fetching alert history:", err)
+		return
+	}
+
+	if len(occurrences) == 0 {
+		color.New(color.FgCyan).Println("None of your alerts have fired yet.")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"No.", "Symbol", "Target", "Triggered At Price", "Time"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetBorder(true)
+	table.SetRowLine(true)
+
+	for i, occurrence := range occurrences {
+		target := fmt.Sprintf("$%.2f", occurrence.TargetPrice)
+		triggered := fmt.Sprintf("$%.2f", occurrence.TriggeredPrice)
+		switch occurrence.Kind {
+		case "volume_spike":
+			target = fmt.Sprintf("$%.0f volume", occurrence.TargetPrice)
+			triggered = fmt.Sprintf("$%.0f volume", occurrence.TriggeredPrice)
+		case "rank":
+			target = fmt.Sprintf("rank %.0f", occurrence.TargetPrice)
+			triggered = fmt.Sprintf("#%.0f", occurrence.TriggeredPrice)
+		case alerts.PegDepegged, alerts.PegRecovered:
+			target = fmt.Sprintf("$%.2f peg", occurrence.TargetPrice)
+			triggered = fmt.Sprintf("$%.4f", occurrence.TriggeredPrice)
+		}
+
+		table.Append([]string{
+			fmt.Sprintf("%d", i+1),
+			occurrence.Crypto,
+			target,
+			triggered,
+			occurrence.TriggeredAt.Local().Format("2006-01-02 15:04"),
+		})
+	}
+
+	fmt.Println()
+	color.New(color.FgGreen).Println("Alert History:")
+	fmt.Println()
+	table.Render()
+}
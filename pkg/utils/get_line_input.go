@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// GetLineInput reads a whole line of input, for answers that may contain spaces
+func GetLineInput(prompt string) string {
+	fmt.Print(prompt)
+
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if n == 0 || err != nil {
+			break
+		}
+		if buf[0] == '\n' {
+			// Skip the newline left behind by a previous fmt.Scan
+			if strings.TrimSpace(string(line)) == "" {
+				line = line[:0]
+				continue
+			}
+			break
+		}
+		line = append(line, buf[0])
+	}
+
+	return strings.TrimSpace(string(line))
+}
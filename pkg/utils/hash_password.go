@@ -1,12 +1,119 @@
 package utils
 
 import (
+	"crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
+	"cryptotracker/pkg/config"
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"strings"
 )
 
-// HashPassword hashes the password using SHA-256
-func HashPassword(password string) string {
-	hash := sha256.Sum256([]byte(password))
-	return hex.EncodeToString(hash[:])
+// Default argon2id cost parameters, following the OWASP recommendation for interactive logins.
+const (
+	DefaultHashMemoryKiB   = 64 * 1024
+	DefaultHashIterations  = 3
+	DefaultHashParallelism = 2
+
+	saltLength = 16
+	keyLength  = 32
+)
+
+var errMalformedHash = errors.New("malformed password hash")
+
+// HashPolicy returns the argon2id cost parameters to hash new passwords with, taking any
+// configured value over the default.
+func HashPolicy() config.PasswordHashing {
+	policy := config.AppConfig.PasswordHashing
+	if policy.MemoryKiB == 0 {
+		policy.MemoryKiB = DefaultHashMemoryKiB
+	}
+	if policy.Iterations == 0 {
+		policy.Iterations = DefaultHashIterations
+	}
+	if policy.Parallelism == 0 {
+		policy.Parallelism = DefaultHashParallelism
+	}
+	return policy
+}
+
+// HashPassword hashes the password with argon2id and a random salt under the current policy.
+// The result is self-describing, for example
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash> with unpadded base64 salt and hash.
+func HashPassword(password string) (string, error) {
+	return hashWithPolicy(password, HashPolicy())
+}
+
+func hashWithPolicy(password string, policy config.PasswordHashing) (string, error) {
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %v", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, policy.Iterations, policy.MemoryKiB, policy.Parallelism, keyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		policy.MemoryKiB,
+		policy.Iterations,
+		policy.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// VerifyPassword checks a password against a stored hash in constant time. Besides argon2id
+// hashes it accepts the unsalted SHA-256 hex digests stored before argon2id was introduced.
+// needsRehash reports a correct password whose hash is legacy or uses other cost parameters
+// than the current policy, so it should be hashed again.
+func VerifyPassword(password, encoded string) (match bool, needsRehash bool, err error) {
+	if !strings.HasPrefix(encoded, "$") {
+		digest := sha256.Sum256([]byte(password))
+		match = subtle.ConstantTimeCompare([]byte(hex.EncodeToString(digest[:])), []byte(strings.ToLower(encoded))) == 1
+		return match, match, nil
+	}
+
+	policy, salt, key, err := decodeHash(encoded)
+	if err != nil {
+		return false, false, err
+	}
+
+	candidate := argon2.IDKey([]byte(password), salt, policy.Iterations, policy.MemoryKiB, policy.Parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(candidate, key) != 1 {
+		return false, false, nil
+	}
+
+	return true, policy != HashPolicy() || len(salt) != saltLength || len(key) != keyLength, nil
+}
+
+func decodeHash(encoded string) (config.PasswordHashing, []byte, []byte, error) {
+	var policy config.PasswordHashing
+
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return policy, nil, nil, errMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return policy, nil, nil, errMalformedHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &policy.MemoryKiB, &policy.Iterations, &policy.Parallelism); err != nil {
+		return policy, nil, nil, errMalformedHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return policy, nil, nil, errMalformedHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return policy, nil, nil, errMalformedHash
+	}
+
+	return policy, salt, key, nil
 }
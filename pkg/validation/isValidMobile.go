@@ -1,8 +0,0 @@
-package validation
-
-import "fmt"
-
-// IsValidMobile validates the mobile number format
-func IsValidMobile(mobile int) bool {
-	return len(fmt.Sprintf("%d", mobile)) == 10
-}
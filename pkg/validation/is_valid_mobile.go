@@ -1,8 +1,128 @@
 package validation
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
-// IsValidMobile validates the mobile number format
-func IsValidMobile(mobile int) bool {
-	return len(fmt.Sprintf("%d", mobile)) == 10
+// maxE164Digits is the most digits an E.164 number has, country code included.
+const maxE164Digits = 15
+
+// countryCodes are the ITU-T E.164 calling codes of countries and territories. No code is a
+// prefix of another, so the code of a number is the one its leading digits match.
+var countryCodes = codeSet(`1 7
+	20 27 30 31 32 33 34 36 39 40 41 43 44 45 46 47 48 49 51 52 53 54 55 56 57 58
+	60 61 62 63 64 65 66 81 82 84 86 90 91 92 93 94 95 98
+	211 212 213 216 218 220 221 222 223 224 225 226 227 228 229 230 231 232 233 234 235 236
+	237 238 239 240 241 242 243 244 245 246 247 248 249 250 251 252 253 254 255 256 257 258
+	260 261 262 263 264 265 266 267 268 269 290 291 297 298 299
+	350 351 352 353 354 355 356 357 358 359 370 371 372 373 374 375 376 377 378 379 380 381
+	382 383 385 386 387 389 420 421 423
+	500 501 502 503 504 505 506 507 508 509 590 591 592 593 594 595 596 597 598 599
+	670 672 673 674 675 676 677 678 679 680 681 682 683 685 686 687 688 689 690 691 692
+	850 852 853 855 856 880 886
+	960 961 962 963 964 965 966 967 968 970 971 972 973 974 975 976 977 992 993 994 995 996 998`)
+
+// nationalLengths bounds the digits after the country code where a country's numbers have a
+// known length. Other countries may use anything from 4 digits up to the E.164 limit.
+var nationalLengths = map[string][2]int{
+	"1":   {10, 10},
+	"7":   {10, 10},
+	"33":  {9, 9},
+	"34":  {9, 9},
+	"44":  {9, 10},
+	"61":  {9, 9},
+	"65":  {8, 8},
+	"86":  {10, 11},
+	"91":  {10, 10},
+	"971": {8, 9},
+}
+
+func codeSet(codes string) map[string]bool {
+	set := make(map[string]bool)
+	for _, code := range strings.Fields(codes) {
+		set[code] = true
+	}
+	return set
+}
+
+// NormalizeMobile parses a phone number written with its country code, such as
+// "+91 98765 43210" or "0044 20 7946 0958", and returns it in E.164 form ("+919876543210").
+// Spaces, dashes, dots and parentheses between the digits are ignored.
+func NormalizeMobile(input string) (string, error) {
+	number := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(input))
+
+	switch {
+	case strings.HasPrefix(number, "+"):
+		number = number[1:]
+	case strings.HasPrefix(number, "00"):
+		number = number[2:]
+	default:
+		return "", errors.New("must start with + and the country code, e.g. +91 98765 43210")
+	}
+
+	if number == "" || strings.Trim(number, "0123456789") != "" {
+		return "", errors.New("must contain only digits after the +")
+	}
+	if len(number) > maxE164Digits {
+		return "", fmt.Errorf("must have at most %d digits including the country code", maxE164Digits)
+	}
+
+	code, national := splitCountryCode(number)
+	if code == "" {
+		return "", errors.New("must start with a valid country code")
+	}
+	bounds, known := nationalLengths[code]
+	if !known {
+		bounds = [2]int{4, maxE164Digits - len(code)}
+	}
+	if len(national) < bounds[0] || len(national) > bounds[1] {
+		if bounds[0] == bounds[1] {
+			return "", fmt.Errorf("+%s numbers have %d digits after the country code", code, bounds[0])
+		}
+		return "", fmt.Errorf("+%s numbers have %d to %d digits after the country code", code, bounds[0], bounds[1])
+	}
+
+	return "+" + number, nil
+}
+
+// IsValidMobile reports whether mobile is a valid number already in E.164 form
+func IsValidMobile(mobile string) bool {
+	normalized, err := NormalizeMobile(mobile)
+	return err == nil && normalized == mobile
+}
+
+// SplitMobile splits an E.164 number into its country code and national number
+func SplitMobile(mobile string) (countryCode, national string, ok bool) {
+	if !IsValidMobile(mobile) {
+		return "", "", false
+	}
+	countryCode, national = splitCountryCode(mobile[1:])
+	return countryCode, national, true
+}
+
+// FormatMobile writes an E.164 number for display as "+<country code> <national number>".
+// Anything else, such as an empty number, is returned as it is.
+func FormatMobile(mobile string) string {
+	countryCode, national, ok := SplitMobile(mobile)
+	if !ok {
+		return mobile
+	}
+	return "+" + countryCode + " " + national
+}
+
+func splitCountryCode(digits string) (string, string) {
+	for length := 1; length <= 3 && length < len(digits); length++ {
+		if countryCodes[digits[:length]] {
+			return digits[:length], digits[length:]
+		}
+	}
+	return "", ""
 }
@@ -0,0 +1,12 @@
+package validation
+
+import "time"
+
+// IsValidTimezone validates an IANA timezone name such as "Asia/Kolkata"
+func IsValidTimezone(timezone string) bool {
+	if timezone == "" || timezone == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(timezone)
+	return err == nil
+}
@@ -0,0 +1,157 @@
+package Handlers_test
+
+import (
+	"cryptotracker/REST-API/Handlers"
+	"cryptotracker/internal/services"
+	"cryptotracker/models"
+	mock_services "cryptotracker/test/internal/mocks/services"
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChangeRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		err      error
+		called   bool
+		expected int
+	}{
+		{"promoted", `{"role":"analyst"}`, nil, true, http.StatusOK},
+		{"not allowed", `{"role":"owner"}`, services.ErrPermissionDenied, true, http.StatusForbidden},
+		{"same role", `{"role":"user"}`, errors.New("user is already a user"), true, http.StatusConflict},
+		{"unknown user", `{"role":"user"}`, errors.New("user not found"), true, http.StatusNotFound},
+		{"unknown role", `{"role":"superuser"}`, nil, false, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			admin := mock_services.NewMockAdminService(ctrl)
+			if tt.called {
+				admin.EXPECT().ChangeUserRole("bob", gomock.Any(), "", services.AuditSourceREST).Return(tt.err)
+			}
+
+			req := mux.SetURLVars(httptest.NewRequest(http.MethodPut, "/admin/users/bob/role", strings.NewReader(tt.body)), map[string]string{"username": "bob"})
+			rec := httptest.NewRecorder()
+			Handlers.NewAdminHandler(admin, nil).ChangeRole(rec, req)
+
+			if rec.Code != tt.expected {
+				t.Errorf("status = %d; expected %d (body %s)", rec.Code, tt.expected, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestAccountStatus(t *testing.T) {
+	purgeAfter := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		action   string
+		err      error
+		expected int
+		status   string
+	}{
+		{"deactivated", "deactivate", nil, http.StatusOK, models.AccountDeactivated},
+		{"already deactivated", "deactivate", errors.New("user is already deactivated"), http.StatusConflict, ""},
+		{"outranked", "deactivate", services.ErrPermissionDenied, http.StatusForbidden, ""},
+		{"deleted", "delete", nil, http.StatusOK, models.AccountDeleted},
+		{"unknown user", "delete", errors.New("user not found"), http.StatusNotFound, ""},
+		{"restored", "restore", nil, http.StatusOK, models.AccountActive},
+		{"grace period over", "restore", errors.New("the grace period for restoring bob has ended"), http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			admin := mock_services.NewMockAdminService(ctrl)
+			handler := Handlers.NewAdminHandler(admin, nil)
+			var handle http.HandlerFunc
+			switch tt.action {
+			case "deactivate":
+				admin.EXPECT().DeactivateUser("bob", "", services.AuditSourceREST).Return(tt.err)
+				handle = handler.DeactivateUser
+			case "delete":
+				admin.EXPECT().DeleteUser("bob", "", services.AuditSourceREST).Return(purgeAfter, tt.err)
+				handle = handler.DeleteUser
+			case "restore":
+				admin.EXPECT().RestoreUser("bob", "", services.AuditSourceREST).Return(tt.err)
+				handle = handler.RestoreUser
+			}
+
+			req := mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/admin/users/bob/"+tt.action, nil), map[string]string{"username": "bob"})
+			rec := httptest.NewRecorder()
+			handle(rec, req)
+
+			if rec.Code != tt.expected {
+				t.Errorf("status = %d; expected %d (body %s)", rec.Code, tt.expected, rec.Body.String())
+			}
+			if tt.status != "" && !strings.Contains(rec.Body.String(), fmt.Sprintf(`"status":%q`, tt.status)) {
+				t.Errorf("body = %s; expected status %q", rec.Body.String(), tt.status)
+			}
+		})
+	}
+}
+
+func TestExportAuditLog(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		err         error
+		called      bool
+		expected    int
+		contentType string
+	}{
+		{"csv by default", "?actor=alice", nil, true, http.StatusOK, "text/csv"},
+		{"json", "?format=json&action=role.", nil, true, http.StatusOK, "application/json"},
+		{"unknown format", "?format=xml", nil, false, http.StatusBadRequest, "application/json"},
+		{"unknown search term", "?user=alice", nil, false, http.StatusBadRequest, "application/json"},
+		{"not allowed", "", services.ErrPermissionDenied, true, http.StatusForbidden, "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			admin := mock_services.NewMockAdminService(ctrl)
+			if tt.called {
+				admin.EXPECT().ExportAuditLog(gomock.Any(), gomock.Any(), gomock.Any(), "", services.AuditSourceREST).DoAndReturn(
+					func(w io.Writer, filter *models.AuditFilter, format, actor, source string) (int, error) {
+						if tt.err != nil {
+							return 0, tt.err
+						}
+						fmt.Fprint(w, "exported")
+						return 1, nil
+					})
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/audit/export"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			Handlers.NewAdminHandler(admin, nil).ExportAuditLog(rec, req)
+
+			if rec.Code != tt.expected {
+				t.Errorf("status = %d; expected %d (body %s)", rec.Code, tt.expected, rec.Body.String())
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %q; expected %q", got, tt.contentType)
+			}
+			if tt.expected == http.StatusOK && rec.Body.String() != "exported" {
+				t.Errorf("body = %q; expected the export", rec.Body.String())
+			}
+		})
+	}
+}
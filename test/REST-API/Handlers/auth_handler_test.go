@@ -0,0 +1,314 @@
+package Handlers_test
+
+import (
+	"cryptotracker/REST-API/Handlers"
+	"cryptotracker/REST-API/middleware"
+	"cryptotracker/internal/services"
+	"cryptotracker/models"
+	mock_services "cryptotracker/test/internal/mocks/services"
+	"encoding/json"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoginHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		setup    func(auth *mock_services.MockAuthService, tokens *mock_services.MockTokenService)
+		expected int
+	}{
+		{
+			name: "valid credentials",
+			body: `{"username":"alice","password":"Secret@123"}`,
+			setup: func(auth *mock_services.MockAuthService, tokens *mock_services.MockTokenService) {
+				user := &models.User{Username: "alice"}
+				auth.EXPECT().Login("alice", "Secret@123", "192.0.2.1").Return(user, "user", nil)
+				auth.EXPECT().TwoFactorRequired(user).Return(false, nil)
+				tokens.EXPECT().IssueTokens("alice", "user", gomock.Any()).Return(&services.TokenPair{AccessToken: "access", RefreshToken: "refresh", TokenType: "Bearer"}, nil)
+			},
+			expected: http.StatusOK,
+		},
+		{
+			name: "two-factor enabled",
+			body: `{"username":"alice","password":"Secret@123"}`,
+			setup: func(auth *mock_services.MockAuthService, tokens *mock_services.MockTokenService) {
+				user := &models.User{Username: "alice", TwoFactorEnabled: true}
+				auth.EXPECT().Login("alice", "Secret@123", "192.0.2.1").Return(user, "user", nil)
+				auth.EXPECT().TwoFactorRequired(user).Return(true, nil)
+				tokens.EXPECT().IssueTwoFactorChallenge("alice", "user").Return("challenge", nil)
+			},
+			expected: http.StatusAccepted,
+		},
+		{
+			name: "two-factor mandatory but not set up",
+			body: `{"username":"root","password":"Secret@123"}`,
+			setup: func(auth *mock_services.MockAuthService, tokens *mock_services.MockTokenService) {
+				user := &models.User{Username: "root", Role: "admin"}
+				auth.EXPECT().Login("root", "Secret@123", "192.0.2.1").Return(user, "admin", nil)
+				auth.EXPECT().TwoFactorRequired(user).Return(true, nil)
+			},
+			expected: http.StatusForbidden,
+		},
+		{
+			name: "wrong password",
+			body: `{"username":"alice","password":"wrong"}`,
+			setup: func(auth *mock_services.MockAuthService, tokens *mock_services.MockTokenService) {
+				auth.EXPECT().Login("alice", "wrong", "192.0.2.1").Return(nil, "", errors.New("invalid password"))
+			},
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name: "throttled",
+			body: `{"username":"alice","password":"Secret@123"}`,
+			setup: func(auth *mock_services.MockAuthService, tokens *mock_services.MockTokenService) {
+				auth.EXPECT().Login("alice", "Secret@123", "192.0.2.1").Return(nil, "", &services.LoginThrottledError{RetryAfter: 1500 * time.Millisecond})
+			},
+			expected: http.StatusTooManyRequests,
+		},
+		{
+			name:     "missing password",
+			body:     `{"username":"alice"}`,
+			setup:    func(auth *mock_services.MockAuthService, tokens *mock_services.MockTokenService) {},
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown field",
+			body:     `{"username":"alice","password":"Secret@123","admin":true}`,
+			setup:    func(auth *mock_services.MockAuthService, tokens *mock_services.MockTokenService) {},
+			expected: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			auth := mock_services.NewMockAuthService(ctrl)
+			tokens := mock_services.NewMockTokenService(ctrl)
+			tt.setup(auth, tokens)
+
+			rec := httptest.NewRecorder()
+			Handlers.NewAuthHandler(auth, tokens).LoginHandler(rec, httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(tt.body)))
+
+			if rec.Code != tt.expected {
+				t.Fatalf("status = %d; expected %d (body %s)", rec.Code, tt.expected, rec.Body.String())
+			}
+			if tt.expected == http.StatusTooManyRequests && rec.Header().Get("Retry-After") != "2" {
+				t.Errorf("Retry-After = %q; expected 2", rec.Header().Get("Retry-After"))
+			}
+			if tt.expected == http.StatusOK {
+				var body map[string]interface{}
+				if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+					t.Fatalf("invalid JSON response: %v", err)
+				}
+				if body["access_token"] != "access" || body["refresh_token"] != "refresh" || body["role"] != "user" {
+					t.Errorf("unexpected response %v", body)
+				}
+			}
+			if tt.expected == http.StatusAccepted {
+				var body map[string]interface{}
+				if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+					t.Fatalf("invalid JSON response: %v", err)
+				}
+				if body["challenge_token"] != "challenge" || body["access_token"] != nil {
+					t.Errorf("unexpected response %v", body)
+				}
+			}
+		})
+	}
+}
+
+func TestTwoFactorLoginHandler(t *testing.T) {
+	claims := &services.TokenClaims{Role: "admin"}
+	claims.Subject = "root"
+
+	tests := []struct {
+		name     string
+		body     string
+		setup    func(auth *mock_services.MockAuthService, tokens *mock_services.MockTokenService)
+		expected int
+	}{
+		{
+			name: "valid code",
+			body: `{"challenge_token":"challenge","code":"123456"}`,
+			setup: func(auth *mock_services.MockAuthService, tokens *mock_services.MockTokenService) {
+				tokens.EXPECT().ValidateTwoFactorChallenge("challenge").Return(claims, nil)
+				auth.EXPECT().VerifySecondFactor("root", "123456", "192.0.2.1").Return(nil)
+				tokens.EXPECT().IssueTokens("root", "admin", gomock.Any()).Return(&services.TokenPair{AccessToken: "access", RefreshToken: "refresh", TokenType: "Bearer"}, nil)
+			},
+			expected: http.StatusOK,
+		},
+		{
+			name: "wrong code",
+			body: `{"challenge_token":"challenge","code":"000000"}`,
+			setup: func(auth *mock_services.MockAuthService, tokens *mock_services.MockTokenService) {
+				tokens.EXPECT().ValidateTwoFactorChallenge("challenge").Return(claims, nil)
+				auth.EXPECT().VerifySecondFactor("root", "000000", "192.0.2.1").Return(services.ErrInvalidTwoFactorCode)
+			},
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name: "expired challenge",
+			body: `{"challenge_token":"stale","code":"123456"}`,
+			setup: func(auth *mock_services.MockAuthService, tokens *mock_services.MockTokenService) {
+				tokens.EXPECT().ValidateTwoFactorChallenge("stale").Return(nil, services.ErrInvalidToken)
+			},
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name: "throttled",
+			body: `{"challenge_token":"challenge","code":"123456"}`,
+			setup: func(auth *mock_services.MockAuthService, tokens *mock_services.MockTokenService) {
+				tokens.EXPECT().ValidateTwoFactorChallenge("challenge").Return(claims, nil)
+				auth.EXPECT().VerifySecondFactor("root", "123456", "192.0.2.1").Return(&services.LoginThrottledError{RetryAfter: time.Minute, Locked: true})
+			},
+			expected: http.StatusTooManyRequests,
+		},
+		{
+			name:     "missing code",
+			body:     `{"challenge_token":"challenge"}`,
+			setup:    func(auth *mock_services.MockAuthService, tokens *mock_services.MockTokenService) {},
+			expected: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			auth := mock_services.NewMockAuthService(ctrl)
+			tokens := mock_services.NewMockTokenService(ctrl)
+			tt.setup(auth, tokens)
+
+			rec := httptest.NewRecorder()
+			Handlers.NewAuthHandler(auth, tokens).TwoFactorLoginHandler(rec, httptest.NewRequest(http.MethodPost, "/login/2fa", strings.NewReader(tt.body)))
+
+			if rec.Code != tt.expected {
+				t.Errorf("status = %d; expected %d (body %s)", rec.Code, tt.expected, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRefreshHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokens := mock_services.NewMockTokenService(ctrl)
+	tokens.EXPECT().RefreshTokens("used").Return(nil, services.ErrInvalidToken)
+
+	rec := httptest.NewRecorder()
+	Handlers.NewAuthHandler(nil, tokens).RefreshHandler(rec, httptest.NewRequest(http.MethodPost, "/refresh", strings.NewReader(`{"refresh_token":"used"}`)))
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d; expected %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestSignupHandlerFieldErrors(t *testing.T) {
+	body := `{"username":"alice","password":"password","email":"alice@example.com","mobile":"9876543210","timezone":"Mars/Olympus"}`
+
+	rec := httptest.NewRecorder()
+	Handlers.NewAuthHandler(nil, nil).SignupHandler(rec, httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(body)))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d; expected %d", rec.Code, http.StatusBadRequest)
+	}
+
+	var response middleware.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid JSON response: %v", err)
+	}
+
+	var fields []string
+	for _, field := range response.Fields {
+		fields = append(fields, field.Field)
+	}
+	if strings.Join(fields, ",") != "password,mobile,timezone" {
+		t.Errorf("fields = %v; expected password, mobile and timezone", fields)
+	}
+}
+
+func TestConfirmPasswordResetHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		err      error
+		called   bool
+		expected int
+		field    string
+	}{
+		{"valid code", `{"code":"abc","new_password":"N3w@Password"}`, nil, true, http.StatusNoContent, ""},
+		{"used code", `{"code":"abc","new_password":"N3w@Password"}`, services.ErrInvalidResetToken, true, http.StatusBadRequest, "code"},
+		{"weak password", `{"code":"abc","new_password":"password123"}`, nil, false, http.StatusBadRequest, "new_password"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			auth := mock_services.NewMockAuthService(ctrl)
+			if tt.called {
+				auth.EXPECT().ResetPassword("abc", "N3w@Password", services.AuditSourceREST).Return(tt.err)
+			}
+
+			rec := httptest.NewRecorder()
+			Handlers.NewAuthHandler(auth, nil).ConfirmPasswordResetHandler(rec, httptest.NewRequest(http.MethodPost, "/password-reset/confirm", strings.NewReader(tt.body)))
+
+			if rec.Code != tt.expected {
+				t.Fatalf("status = %d; expected %d (body %s)", rec.Code, tt.expected, rec.Body.String())
+			}
+			if tt.field != "" {
+				var response middleware.ErrorResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+					t.Fatalf("invalid JSON response: %v", err)
+				}
+				if len(response.Fields) != 1 || response.Fields[0].Field != tt.field {
+					t.Errorf("fields = %v; expected one for %s", response.Fields, tt.field)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyEmailHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		err      error
+		called   bool
+		expected int
+	}{
+		{"valid code", `{"code":" abc "}`, nil, true, http.StatusNoContent},
+		{"used code", `{"code":" abc "}`, services.ErrInvalidVerificationToken, true, http.StatusBadRequest},
+		{"missing code", `{}`, nil, false, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			auth := mock_services.NewMockAuthService(ctrl)
+			if tt.called {
+				auth.EXPECT().VerifyEmail(" abc ", services.AuditSourceREST).Return(tt.err)
+			}
+
+			rec := httptest.NewRecorder()
+			Handlers.NewAuthHandler(auth, nil).VerifyEmailHandler(rec, httptest.NewRequest(http.MethodPost, "/verify-email", strings.NewReader(tt.body)))
+
+			if rec.Code != tt.expected {
+				t.Errorf("status = %d; expected %d (body %s)", rec.Code, tt.expected, rec.Body.String())
+			}
+		})
+	}
+}
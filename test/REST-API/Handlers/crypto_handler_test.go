@@ -0,0 +1,93 @@
+package Handlers_test
+
+import (
+	"cryptotracker/REST-API/Handlers"
+	"cryptotracker/models"
+	mock_services "cryptotracker/test/internal/mocks/services"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSetPriceAlert(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		setup    func(crypto *mock_services.MockCryptoService)
+		expected int
+	}{
+		{
+			name: "price is the default kind",
+			body: `{"symbol":"btc","target_price":70000}`,
+			setup: func(crypto *mock_services.MockCryptoService) {
+				crypto.EXPECT().SetPriceAlert(gomock.Any(), "BTC", 70000.0, gomock.Any()).Return(65000.0, nil)
+			},
+			expected: http.StatusCreated,
+		},
+		{
+			name: "trailing",
+			body: `{"kind":"trailing","symbol":"ETH","trail_percent":5}`,
+			setup: func(crypto *mock_services.MockCryptoService) {
+				crypto.EXPECT().SetTrailingAlert(gomock.Any(), "ETH", 5.0, gomock.Any()).Return(3000.0, nil)
+			},
+			expected: http.StatusCreated,
+		},
+		{
+			name:     "unknown kind",
+			body:     `{"kind":"moon","symbol":"BTC"}`,
+			setup:    func(crypto *mock_services.MockCryptoService) {},
+			expected: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			crypto := mock_services.NewMockCryptoService(ctrl)
+			tt.setup(crypto)
+
+			rec := httptest.NewRecorder()
+			Handlers.NewCryptoHandler(crypto).SetPriceAlert(rec, httptest.NewRequest(http.MethodPost, "/cryptos/alert", strings.NewReader(tt.body)))
+
+			if rec.Code != tt.expected {
+				t.Errorf("status = %d; expected %d (body %s)", rec.Code, tt.expected, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestSetLadderAlert(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		levels   []float64
+		expected int
+	}{
+		{"explicit levels", `{"symbol":"BTC","levels":[62000,60000,60000]}`, []float64{60000, 62000}, http.StatusCreated},
+		{"range", `{"symbol":"BTC","from":60000,"to":64000,"step":2000}`, []float64{60000, 62000, 64000}, http.StatusCreated},
+		{"zero step", `{"symbol":"BTC","from":60000,"to":64000}`, nil, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			crypto := mock_services.NewMockCryptoService(ctrl)
+			if tt.levels != nil {
+				crypto.EXPECT().SetLadderAlert(gomock.Any(), "BTC", tt.levels, gomock.Any()).Return(&models.AlertGroup{ID: 1, Crypto: "BTC"}, nil)
+			}
+
+			rec := httptest.NewRecorder()
+			Handlers.NewCryptoHandler(crypto).SetLadderAlert(rec, httptest.NewRequest(http.MethodPost, "/cryptos/alert/ladder", strings.NewReader(tt.body)))
+
+			if rec.Code != tt.expected {
+				t.Errorf("status = %d; expected %d (body %s)", rec.Code, tt.expected, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package Handlers_test
+
+import (
+	"cryptotracker/REST-API/Handlers"
+	"cryptotracker/REST-API/middleware"
+	"cryptotracker/internal/services"
+	"cryptotracker/models"
+	mock_services "cryptotracker/test/internal/mocks/services"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUpdateProfile(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		called       bool
+		emailChanged bool
+		err          error
+		expected     int
+		field        string
+	}{
+		{"new email", `{"email":"new@example.com"}`, true, true, nil, http.StatusOK, ""},
+		{"same email", `{"email":"new@example.com"}`, true, false, nil, http.StatusOK, ""},
+		{"invalid email", `{"email":"not-an-email"}`, false, false, nil, http.StatusBadRequest, "email"},
+		{"weak password", `{"current_password":"Old@Passw0rd","new_password":"password"}`, false, false, nil, http.StatusBadRequest, "new_password"},
+		{"wrong password", `{"current_password":"Wr0ng@Password","new_password":"N3w@Password"}`, true, false, services.ErrWrongPassword, http.StatusBadRequest, "current_password"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			users := mock_services.NewMockUserServices(ctrl)
+			auth := mock_services.NewMockAuthService(ctrl)
+			if tt.called {
+				users.EXPECT().UpdateProfile("", gomock.Any(), services.AuditSourceREST).Return(tt.emailChanged, tt.err)
+			}
+			if tt.called && tt.err == nil {
+				users.EXPECT().GetUserProfile("").Return(&models.User{Email: "new@example.com"}, nil)
+			}
+			if tt.emailChanged {
+				auth.EXPECT().SendEmailVerification("").Return(nil)
+			}
+
+			rec := httptest.NewRecorder()
+			Handlers.NewUserHandler(users, auth).UpdateProfile(rec, httptest.NewRequest(http.MethodPatch, "/users/me", strings.NewReader(tt.body)))
+
+			if rec.Code != tt.expected {
+				t.Fatalf("status = %d; expected %d (body %s)", rec.Code, tt.expected, rec.Body.String())
+			}
+			if tt.field != "" {
+				var response middleware.ErrorResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+					t.Fatalf("invalid JSON response: %v", err)
+				}
+				if len(response.Fields) != 1 || response.Fields[0].Field != tt.field {
+					t.Errorf("fields = %v; expected one for %s", response.Fields, tt.field)
+				}
+			}
+		})
+	}
+}
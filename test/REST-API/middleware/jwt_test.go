@@ -0,0 +1,173 @@
+package middleware_test
+
+import (
+	"cryptotracker/REST-API/middleware"
+	"cryptotracker/internal/rbac"
+	"cryptotracker/internal/services"
+	"cryptotracker/models"
+	mock_repositories "cryptotracker/test/internal/mocks/repository"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func issueAccessToken(t *testing.T, tokens services.TokenService, repo *mock_repositories.MockTokenRepository, role string) string {
+	repo.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Return(nil)
+	repo.EXPECT().SaveRefreshToken(gomock.Any(), "alice", gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	pair, err := tokens.IssueTokens("alice", role, "192.0.2.1")
+	if err != nil {
+		t.Fatalf("IssueTokens() error = %v", err)
+	}
+	return pair.AccessToken
+}
+
+func TestUserMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_repositories.NewMockTokenRepository(ctrl)
+	tokens := services.NewTokenService(repo)
+	accessToken := issueAccessToken(t, tokens, repo, "user")
+	challenge, err := tokens.IssueTwoFactorChallenge("alice", "user")
+	if err != nil {
+		t.Fatalf("IssueTwoFactorChallenge() error = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		revoked       bool
+		sessionEnded  bool
+		expected      int
+	}{
+		{"valid token", "Bearer " + accessToken, false, false, http.StatusOK},
+		{"missing header", "", false, false, http.StatusUnauthorized},
+		{"wrong scheme", "Basic " + accessToken, false, false, http.StatusUnauthorized},
+		{"malformed token", "Bearer not.a.token", false, false, http.StatusUnauthorized},
+		{"revoked token", "Bearer " + accessToken, true, false, http.StatusUnauthorized},
+		{"revoked session", "Bearer " + accessToken, false, true, http.StatusUnauthorized},
+		{"two-factor challenge", "Bearer " + challenge, false, false, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.expected == http.StatusOK || tt.revoked || tt.sessionEnded {
+				repo.EXPECT().IsAccessTokenRevoked(gomock.Any()).Return(tt.revoked, nil)
+			}
+			if tt.expected == http.StatusOK || tt.sessionEnded {
+				repo.EXPECT().TouchSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(!tt.sessionEnded, nil)
+			}
+
+			var username string
+			handler := middleware.NewJWTTokenService(tokens).UserMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				username = middleware.Username(r)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/users/me", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expected {
+				t.Fatalf("status = %d; expected %d", rec.Code, tt.expected)
+			}
+			if tt.expected == http.StatusOK && username != "alice" {
+				t.Errorf("Username() = %q; expected alice", username)
+			}
+			if tt.expected == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected a WWW-Authenticate header on 401")
+			}
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		role     string
+		expected int
+	}{
+		{"owner", http.StatusOK},
+		{"admin", http.StatusOK},
+		{"support", http.StatusOK},
+		{"analyst", http.StatusForbidden},
+		{"user", http.StatusForbidden},
+		{"", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockTokenRepository(ctrl)
+			tokens := services.NewTokenService(repo)
+			accessToken := issueAccessToken(t, tokens, repo, tt.role)
+			repo.EXPECT().IsAccessTokenRevoked(gomock.Any()).Return(false, nil)
+			repo.EXPECT().TouchSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil)
+
+			handler := middleware.NewJWTTokenService(tokens).RequirePermission(rbac.UsersView)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			req := httptest.NewRequest(http.MethodGet, "/admin/profiles", nil)
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expected {
+				t.Errorf("status = %d; expected %d", rec.Code, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAllowAPIKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		scopes   []string
+		route    string
+		live     bool
+		expected int
+	}{
+		{"key with the route's scope", []string{services.ScopeMarketRead}, services.ScopeMarketRead, true, http.StatusOK},
+		{"key without the route's scope", []string{services.ScopeAlertsManage}, services.ScopeMarketRead, true, http.StatusForbidden},
+		{"route that takes no keys", []string{services.ScopeMarketRead}, "", true, http.StatusForbidden},
+		{"revoked or expired key", []string{services.ScopeMarketRead}, services.ScopeMarketRead, false, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mock_repositories.NewMockTokenRepository(ctrl)
+			var key *models.APIKey
+			if tt.live {
+				key = &models.APIKey{ID: 7, Username: "alice", Scopes: tt.scopes, ExpiresAt: time.Now().Add(time.Hour)}
+				repo.EXPECT().GetUserRole("alice").Return("user", nil)
+			}
+			repo.EXPECT().UseAPIKey(gomock.Any(), gomock.Any()).Return(key, nil)
+
+			var username string
+			var handler http.Handler = middleware.NewJWTTokenService(services.NewTokenService(repo)).UserMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				username = middleware.Username(r)
+			}))
+			if tt.route != "" {
+				handler = middleware.AllowAPIKey(tt.route)(handler)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/cryptos", nil)
+			req.Header.Set("Authorization", "Bearer "+services.APIKeyPrefix+"0123456789abcdef")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expected {
+				t.Fatalf("status = %d; expected %d", rec.Code, tt.expected)
+			}
+			if tt.expected == http.StatusOK && username != "alice" {
+				t.Errorf("Username() = %q; expected alice", username)
+			}
+		})
+	}
+}
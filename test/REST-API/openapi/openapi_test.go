@@ -0,0 +1,138 @@
+package openapi_test
+
+import (
+	"cryptotracker/REST-API/openapi"
+	"encoding/json"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestSpecCoversRoutes keeps the document in step with the routes registered in cmd/main.go.
+func TestSpecCoversRoutes(t *testing.T) {
+	source, err := os.ReadFile("../../../cmd/main.go")
+	if err != nil {
+		t.Fatalf("failed to read cmd/main.go: %v", err)
+	}
+
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(openapi.Spec(), &spec); err != nil {
+		t.Fatalf("invalid OpenAPI document: %v", err)
+	}
+
+	route := regexp.MustCompile(`(?m)^\s*r\.Handle(?:Func)?\("([^"]+)".*\.Methods\("([A-Z]+)"\)`)
+	routes := route.FindAllStringSubmatch(string(source), -1)
+	if len(routes) == 0 {
+		t.Fatal("no routes found in cmd/main.go")
+	}
+
+	registered := make(map[string]bool)
+	for _, match := range routes {
+		path, method := match[1], strings.ToLower(match[2])
+		registered[method+" "+path] = true
+		if _, ok := spec.Paths[path][method]; !ok {
+			t.Errorf("%s %s is not in openapi.json", match[2], path)
+		}
+	}
+
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			if method != "parameters" && !registered[method+" "+path] {
+				t.Errorf("openapi.json documents %s %s, which is not registered", strings.ToUpper(method), path)
+			}
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		body     string
+		expected []openapi.FieldError
+	}{
+		{
+			name:   "valid signup",
+			schema: "SignupRequest",
+			body:   `{"username":"alice","password":"Secret@123","email":"alice@example.com","mobile":"+91 98765 43210"}`,
+		},
+		{
+			name:   "signup with missing and mistyped fields",
+			schema: "SignupRequest",
+			body:   `{"username":"alice bob","password":"Secret@123","mobile":9876543210,"admin":true}`,
+			expected: []openapi.FieldError{
+				{Field: "admin", Message: "is not a known field"},
+				{Field: "email", Message: "is required"},
+				{Field: "mobile", Message: "must be a string"},
+				{Field: "username", Message: "must match ^[a-zA-Z0-9_]+$"},
+			},
+		},
+		{
+			name:   "alert with options",
+			schema: "AlertRequest",
+			body:   `{"kind":"trailing","symbol":"BTC","trail_percent":5,"recurring":true,"expires_at":"2030-01-01T00:00:00Z","active_from":"09:00"}`,
+		},
+		{
+			name:   "alert with invalid values",
+			schema: "AlertRequest",
+			body:   `{"kind":"moon","target_price":0,"cooldown_seconds":1.5,"expires_at":"tomorrow","active_to":"25:00"}`,
+			expected: []openapi.FieldError{
+				{Field: "active_to", Message: "must match ^([01][0-9]|2[0-3]):[0-5][0-9]$"},
+				{Field: "cooldown_seconds", Message: "must be a whole number"},
+				{Field: "expires_at", Message: "must be an RFC 3339 date-time such as 2024-09-20T18:30:00Z"},
+				{Field: "kind", Message: "must be one of price, rule, trailing, volume_spike, rank"},
+				{Field: "target_price", Message: "must be greater than 0"},
+			},
+		},
+		{
+			name:   "ladder levels",
+			schema: "LadderRequest",
+			body:   `{"symbol":"ETH","levels":[3000,-1]}`,
+			expected: []openapi.FieldError{
+				{Field: "levels[1]", Message: "must be greater than 0"},
+			},
+		},
+		{
+			name:   "request action",
+			schema: "RequestActionRequest",
+			body:   `{"status":"maybe"}`,
+			expected: []openapi.FieldError{
+				{Field: "status", Message: "must be one of Approved, Rejected, approve, reject"},
+			},
+		},
+		{
+			name:   "nested preferences",
+			schema: "UpdateProfileRequest",
+			body:   `{"notificationPreferences":{"channels":{"digest":["email","pigeon"]},"webhook_url":"not a url","max_per_hour":-1}}`,
+			expected: []openapi.FieldError{
+				{Field: "notificationPreferences.channels.digest[1]", Message: "must be one of in-app, email, webhook, sms"},
+				{Field: "notificationPreferences.max_per_hour", Message: "must be at least 0"},
+				{Field: "notificationPreferences.webhook_url", Message: "must be an absolute URL"},
+			},
+		},
+		{
+			name:     "body that is not an object",
+			schema:   "LoginRequest",
+			body:     `["alice"]`,
+			expected: []openapi.FieldError{{Field: "", Message: "must be an object"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body interface{}
+			if err := json.Unmarshal([]byte(tt.body), &body); err != nil {
+				t.Fatalf("invalid test body: %v", err)
+			}
+
+			got := openapi.Validate(tt.schema, body)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Validate() = %v; expected %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package alerts_test
+
+import (
+	"cryptotracker/internal/alerts"
+	"cryptotracker/models"
+	"testing"
+)
+
+func TestObservePeg(t *testing.T) {
+	peg := &models.StablecoinPeg{Symbol: "USDC", PegPrice: 1, ThresholdPercent: 0.5, SustainedSamples: 3}
+
+	samples := []struct {
+		price    float64
+		event    string
+		breaches int
+	}{
+		{1.001, "", 0},
+		{0.990, "", 1},
+		{0.985, "", 2},
+		{1.002, "", 0},
+		{0.990, "", 1},
+		{0.980, "", 2},
+		{0.970, alerts.PegDepegged, 3},
+		{0.960, "", 4},
+		{0.999, alerts.PegRecovered, 0},
+		{1.000, "", 0},
+	}
+
+	for i, sample := range samples {
+		event := alerts.ObservePeg(peg, sample.price)
+		if event != sample.event {
+			t.Errorf("sample %d (%v): event = %q; expected %q", i, sample.price, event, sample.event)
+		}
+		if peg.BreachCount != sample.breaches {
+			t.Errorf("sample %d (%v): breach count = %d; expected %d", i, sample.price, peg.BreachCount, sample.breaches)
+		}
+		if peg.LastPrice != sample.price {
+			t.Errorf("sample %d: last price = %v; expected %v", i, peg.LastPrice, sample.price)
+		}
+	}
+}
+
+func TestValidatePeg(t *testing.T) {
+	tests := []struct {
+		name    string
+		peg     models.StablecoinPeg
+		wantErr bool
+	}{
+		{"valid", models.StablecoinPeg{Symbol: "DAI", PegPrice: 1, ThresholdPercent: 0.5, SustainedSamples: 3}, false},
+		{"missing symbol", models.StablecoinPeg{PegPrice: 1, ThresholdPercent: 0.5, SustainedSamples: 3}, true},
+		{"zero peg", models.StablecoinPeg{Symbol: "DAI", ThresholdPercent: 0.5, SustainedSamples: 3}, true},
+		{"zero threshold", models.StablecoinPeg{Symbol: "DAI", PegPrice: 1, SustainedSamples: 3}, true},
+		{"threshold too large", models.StablecoinPeg{Symbol: "DAI", PegPrice: 1, ThresholdPercent: 100, SustainedSamples: 3}, true},
+		{"no samples", models.StablecoinPeg{Symbol: "DAI", PegPrice: 1, ThresholdPercent: 0.5}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := alerts.ValidatePeg(&tt.peg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePeg() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package alerts_test
+
+import (
+	"cryptotracker/internal/alerts"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestLadderLevels(t *testing.T) {
+	tests := []struct {
+		name           string
+		from, to, step float64
+		expected       []float64
+		wantErr        bool
+	}{
+		{"Inclusive range", 60000, 70000, 2500, []float64{60000, 62500, 65000, 67500, 70000}, false},
+		{"End not on a step", 1, 2, 0.3, []float64{1, 1.3, 1.6, 1.9}, false},
+		{"Reversed range", 3, 1, 1, []float64{1, 2, 3}, false},
+		{"Zero step", 1, 2, 0, nil, true},
+		{"Negative price", -1, 2, 1, nil, true},
+		{"Too many levels", 1, 1000, 1, nil, true},
+		{"Too many levels to count", 1, 1e300, 1e-300, nil, true},
+		{"NaN start", math.NaN(), 100, 5, nil, true},
+		{"Infinite end", 1, math.Inf(1), 1, nil, true},
+		{"NaN step", 1, 2, math.NaN(), nil, true},
+		{"Infinite step", 1, 2, math.Inf(1), nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			levels, err := alerts.LadderLevels(test.from, test.to, test.step)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("LadderLevels() error = %v; wantErr %v", err, test.wantErr)
+			}
+			if !test.wantErr && !reflect.DeepEqual(levels, test.expected) {
+				t.Errorf("LadderLevels() = %v; expected %v", levels, test.expected)
+			}
+		})
+	}
+}
+
+func TestParseLadderSpec(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected []float64
+		wantErr  bool
+	}{
+		{"60000-70000/5000", []float64{60000, 65000, 70000}, false},
+		{"66000,61000,63500", []float64{61000, 63500, 66000}, false},
+		{"61000, 61000 62000", []float64{61000, 62000}, false},
+		{"60000-70000", nil, true},
+		{"60000/100", nil, true},
+		{"abc", nil, true},
+		{"", nil, true},
+		{"inf-100/1", nil, true},
+		{"1-inf/1", nil, true},
+		{"nan-100/5", nil, true},
+		{"1-100/NaN", nil, true},
+		{"1,NaN,Inf", nil, true},
+		{"100,+Inf", nil, true},
+	}
+
+	for _, test := range tests {
+		levels, err := alerts.ParseLadderSpec(test.spec)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseLadderSpec(%q) error = %v; wantErr %v", test.spec, err, test.wantErr)
+			continue
+		}
+		if !test.wantErr && !reflect.DeepEqual(levels, test.expected) {
+			t.Errorf("ParseLadderSpec(%q) = %v; expected %v", test.spec, levels, test.expected)
+		}
+	}
+}
+
+func TestNormalizeLevels(t *testing.T) {
+	for _, levels := range [][]float64{{1, math.NaN()}, {math.Inf(1)}, {0}, nil} {
+		if normalized, err := alerts.NormalizeLevels(levels); err == nil {
+			t.Errorf("NormalizeLevels(%v) = %v; expected an error", levels, normalized)
+		}
+	}
+}
+
+func TestLevelDirection(t *testing.T) {
+	if direction := alerts.LevelDirection(55000, 60000); direction != "below" {
+		t.Errorf("LevelDirection(55000, 60000) = %q; expected \"below\"", direction)
+	}
+	if direction := alerts.LevelDirection(65000, 60000); direction != "above" {
+		t.Errorf("LevelDirection(65000, 60000) = %q; expected \"above\"", direction)
+	}
+}
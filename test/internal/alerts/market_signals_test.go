@@ -0,0 +1,122 @@
+package alerts_test
+
+import (
+	"cryptotracker/internal/alerts"
+	"cryptotracker/models"
+	"testing"
+	"time"
+)
+
+func TestShouldTriggerVolumeSpike(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		volume   float64
+		average  float64
+		expected bool
+	}{
+		{"above multiple", "Pending", 3100, 1000, true},
+		{"at multiple", "Pending", 3000, 1000, false},
+		{"below multiple", "Pending", 1500, 1000, false},
+		{"no average", "Pending", 3100, 0, false},
+		{"already fired", "Rearming", 3100, 1000, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notification := &models.PriceNotification{Kind: "volume_spike", Status: tt.status, VolumeMultiplier: 3}
+			if got := alerts.ShouldTriggerVolumeSpike(notification, tt.volume, tt.average); got != tt.expected {
+				t.Errorf("ShouldTriggerVolumeSpike(%v, %v) = %v; expected %v", tt.volume, tt.average, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRankAlert(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold int
+		created   int
+		rank      int
+		expected  bool
+	}{
+		{"enters top 20", 20, 25, 19, true},
+		{"reaches threshold", 20, 25, 20, true},
+		{"still outside", 20, 25, 21, false},
+		{"drops out of top 20", 20, 12, 21, true},
+		{"still inside", 20, 12, 20, false},
+		{"rank missing", 20, 25, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notification := &models.PriceNotification{
+				Kind:          "rank",
+				Status:        "Pending",
+				RankThreshold: tt.threshold,
+				Direction:     alerts.RankDirection(tt.threshold, tt.created),
+			}
+			if got := alerts.ShouldTriggerRank(notification, tt.rank); got != tt.expected {
+				t.Errorf("ShouldTriggerRank(%d) = %v; expected %v", tt.rank, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestShouldRearmCondition(t *testing.T) {
+	now := time.Date(2024, 9, 20, 12, 0, 0, 0, time.UTC)
+	tenMinutesAgo := now.Add(-10 * time.Minute)
+
+	tests := []struct {
+		name     string
+		cooldown int
+		holds    bool
+		expected bool
+	}{
+		{"condition still holds", 0, true, false},
+		{"condition cleared", 0, false, true},
+		{"cooldown running", 3600, false, false},
+		{"cooldown elapsed", 300, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notification := &models.PriceNotification{
+				Status:          "Rearming",
+				Recurring:       true,
+				CooldownSeconds: tt.cooldown,
+				LastTriggeredAt: &tenMinutesAgo,
+			}
+			if got := alerts.ShouldRearmCondition(notification, tt.holds, now); got != tt.expected {
+				t.Errorf("ShouldRearmCondition(%v) = %v; expected %v", tt.holds, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAveragesFrom(t *testing.T) {
+	averages := alerts.AveragesFrom(map[string]models.MarketAverage{
+		"SOL": {Symbol: "SOL", Volume24h: 2000, Samples: alerts.MinAverageSamples},
+		"NEW": {Symbol: "NEW", Volume24h: 500, Samples: 1},
+	})
+
+	if avg, ok := averages("SOL", "volume_24h"); !ok || avg != 2000 {
+		t.Errorf("averages(SOL, volume_24h) = %v, %v; expected 2000, true", avg, ok)
+	}
+	if _, ok := averages("NEW", "volume_24h"); ok {
+		t.Errorf("averages(NEW, volume_24h) reported an average from too few samples")
+	}
+	if _, ok := averages("SOL", "unknown"); ok {
+		t.Errorf("averages(SOL, unknown) reported an average for an unknown metric")
+	}
+
+	market := alerts.NewQuoteMarket(map[string]*models.Quote{"SOL": {Symbol: "SOL", Volume24h: 4500}})
+	market.Averages = averages
+	rule, err := alerts.ParseRule("SOL volume_24h > 2x avg")
+	if err != nil {
+		t.Fatalf("ParseRule() error = %v", err)
+	}
+	if !rule.Eval(market) {
+		t.Errorf("%s should hold with volume 4500 against an average of 2000", rule)
+	}
+}
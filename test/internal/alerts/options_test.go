@@ -0,0 +1,39 @@
+package alerts_test
+
+import (
+	"cryptotracker/internal/alerts"
+	"cryptotracker/models"
+	"testing"
+	"time"
+)
+
+func TestValidateOptions(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(24 * time.Hour)
+
+	tests := []struct {
+		name    string
+		options *models.AlertOptions
+		wantErr bool
+	}{
+		{"No options", nil, false},
+		{"Recurring with cooldown", &models.AlertOptions{Recurring: true, CooldownSeconds: 60}, false},
+		{"Negative cooldown", &models.AlertOptions{Recurring: true, CooldownSeconds: -1}, true},
+		{"Hysteresis too large", &models.AlertOptions{Recurring: true, HysteresisPercent: 100}, true},
+		{"Cooldown on one-shot alert", &models.AlertOptions{CooldownSeconds: 60}, true},
+		{"Expiry in the past", &models.AlertOptions{ExpiresAt: &past}, true},
+		{"Expiry in the future", &models.AlertOptions{ExpiresAt: &future}, false},
+		{"Active hours", &models.AlertOptions{ActiveFrom: "09:00", ActiveTo: "18:00"}, false},
+		{"Active hours missing end", &models.AlertOptions{ActiveFrom: "09:00"}, true},
+		{"Active hours malformed", &models.AlertOptions{ActiveFrom: "9", ActiveTo: "18:00"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := alerts.ValidateOptions(test.options)
+			if (err != nil) != test.wantErr {
+				t.Errorf("ValidateOptions() error = %v; wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package alerts_test
+
+import (
+	"cryptotracker/internal/alerts"
+	"cryptotracker/models"
+	"testing"
+	"time"
+)
+
+func TestShouldTrigger(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    string
+		direction string
+		price     float64
+		expected  bool
+	}{
+		{"Below target", "Pending", "", 99, false},
+		{"At target", "Pending", "", 100, true},
+		{"Above target", "Pending", "above", 120, true},
+		{"Waiting to re-arm", "Rearming", "", 120, false},
+		{"Already served", "Served", "", 120, false},
+		{"Falling alert above target", "Pending", "below", 120, false},
+		{"Falling alert reaches target", "Pending", "below", 100, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			notification := &models.PriceNotification{TargetPrice: 100, Status: test.status, Direction: test.direction}
+			if result := alerts.ShouldTrigger(notification, test.price); result != test.expected {
+				t.Errorf("ShouldTrigger() = %v; expected %v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestShouldRearm(t *testing.T) {
+	now := time.Date(2024, 9, 20, 12, 0, 0, 0, time.UTC)
+	tenMinutesAgo := now.Add(-10 * time.Minute)
+
+	tests := []struct {
+		name       string
+		recurring  bool
+		cooldown   int
+		hysteresis float64
+		price      float64
+		expected   bool
+	}{
+		{"One-shot never re-arms", false, 0, 0, 50, false},
+		{"Plain recurring still above target", true, 0, 0, 105, false},
+		{"Plain recurring back below target", true, 0, 0, 99, true},
+		{"Cooldown not elapsed", true, 3600, 0, 50, false},
+		{"Cooldown elapsed", true, 300, 0, 105, true},
+		{"Hysteresis band not crossed", true, 0, 5, 96, false},
+		{"Hysteresis band crossed", true, 0, 5, 95, true},
+		{"Cooldown elapsed but band not crossed", true, 300, 5, 98, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name+" (falling)", func(t *testing.T) {
+			notification := &models.PriceNotification{
+				TargetPrice:       100,
+				Direction:         "below",
+				Status:            "Rearming",
+				Recurring:         test.recurring,
+				CooldownSeconds:   test.cooldown,
+				HysteresisPercent: test.hysteresis,
+				LastTriggeredAt:   &tenMinutesAgo,
+			}
+			// Mirror the price around the target so the falling alert sees the same movement
+			if result := alerts.ShouldRearm(notification, 200-test.price, now); result != test.expected {
+				t.Errorf("ShouldRearm() = %v; expected %v", result, test.expected)
+			}
+		})
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			notification := &models.PriceNotification{
+				TargetPrice:       100,
+				Status:            "Rearming",
+				Recurring:         test.recurring,
+				CooldownSeconds:   test.cooldown,
+				HysteresisPercent: test.hysteresis,
+				LastTriggeredAt:   &tenMinutesAgo,
+			}
+			if result := alerts.ShouldRearm(notification, test.price, now); result != test.expected {
+				t.Errorf("ShouldRearm() = %v; expected %v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestShouldRearmRule(t *testing.T) {
+	now := time.Date(2024, 9, 20, 12, 0, 0, 0, time.UTC)
+	tenMinutesAgo := now.Add(-10 * time.Minute)
+	market := testMarket()
+
+	holds, _ := alerts.ParseRule("BTC < 60000")
+	released, _ := alerts.ParseRule("BTC > 60000")
+
+	tests := []struct {
+		name     string
+		rule     alerts.Rule
+		cooldown int
+		expected bool
+	}{
+		{"Rule still holds", holds, 0, false},
+		{"Rule no longer holds", released, 0, true},
+		{"Cooldown not elapsed", released, 3600, false},
+		{"Cooldown elapsed while rule holds", holds, 300, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			notification := &models.PriceNotification{
+				Kind:            "rule",
+				Status:          "Rearming",
+				Recurring:       true,
+				CooldownSeconds: test.cooldown,
+				LastTriggeredAt: &tenMinutesAgo,
+			}
+			if result := alerts.ShouldRearmRule(notification, test.rule, market, now); result != test.expected {
+				t.Errorf("ShouldRearmRule() = %v; expected %v", result, test.expected)
+			}
+		})
+	}
+}
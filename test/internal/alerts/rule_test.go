@@ -0,0 +1,117 @@
+package alerts_test
+
+import (
+	"cryptotracker/internal/alerts"
+	"cryptotracker/models"
+	"reflect"
+	"testing"
+)
+
+func testMarket() *alerts.QuoteMarket {
+	market := alerts.NewQuoteMarket(map[string]*models.Quote{
+		"BTC": {Symbol: "BTC", Price: 58000, PercentChange24h: -2, Volume24h: 30e9, MarketCap: 1.1e12},
+		"ETH": {Symbol: "ETH", Price: 3600, PercentChange24h: 1, Volume24h: 15e9, MarketCap: 4.3e11},
+		"SOL": {Symbol: "SOL", Price: 150, PercentChange24h: 9.5, Volume24h: 4e9, MarketCap: 6.5e10},
+	})
+	market.Averages = func(symbol, metric string) (float64, bool) {
+		if symbol == "SOL" && metric == "volume_24h" {
+			return 1.5e9, true
+		}
+		return 0, false
+	}
+	return market
+}
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		input     string
+		canonical string
+		symbols   []string
+	}{
+		{"BTC < 60000", "BTC < 60000", []string{"BTC"}},
+		{"btc price >= 60000.5", "BTC >= 60000.5", []string{"BTC"}},
+		{"BTC < 60000 AND ETH/BTC > 0.06", "(BTC < 60000 AND ETH/BTC > 0.06)", []string{"BTC", "ETH"}},
+		{"SOL 24h_change > 8 OR SOL volume_24h > 2x avg", "(SOL 24h_change > 8 OR SOL volume_24h > 2x avg)", []string{"SOL"}},
+		{"SOL volume > 2 x avg", "SOL volume_24h > 2x avg", []string{"SOL"}},
+		{"BTC < 1 or (ETH mcap > 5 and SOL < 2)", "(BTC < 1 OR (ETH market_cap > 5 AND SOL < 2))", []string{"BTC", "ETH", "SOL"}},
+		{"BTC 24h_change < -5", "BTC 24h_change < -5", []string{"BTC"}},
+		{"BTC 24h_change<-.5 OR ETH 24h_change > +3", "(BTC 24h_change < -0.5 OR ETH 24h_change > 3)", []string{"BTC", "ETH"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			rule, err := alerts.ParseRule(test.input)
+			if err != nil {
+				t.Fatalf("ParseRule(%q) unexpected error: %v", test.input, err)
+			}
+			if rule.String() != test.canonical {
+				t.Errorf("ParseRule(%q).String() = %q; expected %q", test.input, rule.String(), test.canonical)
+			}
+			if !reflect.DeepEqual(rule.Symbols(), test.symbols) {
+				t.Errorf("ParseRule(%q).Symbols() = %v; expected %v", test.input, rule.Symbols(), test.symbols)
+			}
+		})
+	}
+}
+
+func TestParseRuleErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"BTC",
+		"BTC 60000",
+		"BTC < abc",
+		"BTC < 60000 AND",
+		"(BTC < 60000",
+		"BTC < 60000)",
+		"ETH/BTC > 2x avg",
+		"BTC == 60000",
+		"60000 > BTC",
+		"BTC < 2x",
+		"BTC < - 5",
+		"BTC < --5",
+		"BTC < inf",
+		"BTC < NaN",
+		"SOL volume > -2 x avg",
+	}
+
+	for _, input := range tests {
+		if _, err := alerts.ParseRule(input); err == nil {
+			t.Errorf("ParseRule(%q) expected an error", input)
+		}
+	}
+}
+
+func TestRuleEval(t *testing.T) {
+	market := testMarket()
+
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"BTC < 60000", true},
+		{"BTC > 60000", false},
+		{"ETH/BTC > 0.06", true},
+		{"ETH/BTC > 0.07", false},
+		{"BTC < 60000 AND ETH/BTC > 0.07", false},
+		{"BTC > 60000 OR ETH/BTC > 0.06", true},
+		{"SOL 24h_change > 8", true},
+		{"BTC 24h_change < -1", true},
+		{"BTC 24h_change < -5", false},
+		{"SOL volume_24h > 2x avg", true},
+		{"SOL volume_24h > 3x avg", false},
+		{"BTC volume_24h > 2x avg", false},
+		{"DOGE < 1", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			rule, err := alerts.ParseRule(test.input)
+			if err != nil {
+				t.Fatalf("ParseRule(%q) unexpected error: %v", test.input, err)
+			}
+			if result := rule.Eval(market); result != test.expected {
+				t.Errorf("Eval(%q) = %v; expected %v", test.input, result, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package alerts_test
+
+import (
+	"cryptotracker/internal/alerts"
+	"testing"
+	"time"
+)
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		clock    string
+		expected int
+		wantErr  bool
+	}{
+		{"00:00", 0, false},
+		{"09:30", 570, false},
+		{"23:59", 1439, false},
+		{"24:00", 0, true},
+		{"9am", 0, true},
+	}
+
+	for _, test := range tests {
+		result, err := alerts.ParseClock(test.clock)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseClock(%q) error = %v; wantErr %v", test.clock, err, test.wantErr)
+		}
+		if err == nil && result != test.expected {
+			t.Errorf("ParseClock(%q) = %d; expected %d", test.clock, result, test.expected)
+		}
+	}
+}
+
+func TestInActiveWindow(t *testing.T) {
+	kolkata := time.FixedZone("IST", 5*3600+1800)
+
+	tests := []struct {
+		name     string
+		from, to string
+		loc      *time.Location
+		now      time.Time
+		expected bool
+	}{
+		{"No window", "", "", time.UTC, time.Date(2024, 9, 20, 3, 0, 0, 0, time.UTC), true},
+		{"Inside daytime window", "09:00", "18:00", time.UTC, time.Date(2024, 9, 20, 12, 0, 0, 0, time.UTC), true},
+		{"Window end is exclusive", "09:00", "18:00", time.UTC, time.Date(2024, 9, 20, 18, 0, 0, 0, time.UTC), false},
+		{"Before daytime window", "09:00", "18:00", time.UTC, time.Date(2024, 9, 20, 8, 59, 0, 0, time.UTC), false},
+		{"Overnight window after midnight", "22:00", "06:00", time.UTC, time.Date(2024, 9, 20, 2, 0, 0, 0, time.UTC), true},
+		{"Outside overnight window", "22:00", "06:00", time.UTC, time.Date(2024, 9, 20, 12, 0, 0, 0, time.UTC), false},
+		{"Evaluated in user timezone", "09:00", "18:00", kolkata, time.Date(2024, 9, 20, 4, 0, 0, 0, time.UTC), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := alerts.InActiveWindow(test.from, test.to, test.loc, test.now); result != test.expected {
+				t.Errorf("InActiveWindow() = %v; expected %v", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestLoadLocation(t *testing.T) {
+	if loc := alerts.LoadLocation(""); loc != time.UTC {
+		t.Errorf("LoadLocation(\"\") = %v; expected UTC", loc)
+	}
+	if loc := alerts.LoadLocation("Not/AZone"); loc != time.UTC {
+		t.Errorf("LoadLocation(\"Not/AZone\") = %v; expected UTC", loc)
+	}
+}
@@ -0,0 +1,73 @@
+package alerts_test
+
+import (
+	"cryptotracker/internal/alerts"
+	"cryptotracker/models"
+	"testing"
+	"time"
+)
+
+func TestTrailingAlert(t *testing.T) {
+	notification := &models.PriceNotification{
+		Kind:          "trailing",
+		Status:        "Pending",
+		TrailPercent:  7,
+		HighWaterMark: 0.50,
+	}
+
+	prices := []struct {
+		price         float64
+		highWaterMark float64
+		raised        bool
+		trigger       bool
+	}{
+		{0.48, 0.50, false, false},
+		{0.60, 0.60, true, false},
+		{0.56, 0.60, false, false},
+		{0.55, 0.60, false, true},
+		{0.40, 0.60, false, true},
+	}
+
+	for _, step := range prices {
+		raised := alerts.UpdateHighWaterMark(notification, step.price)
+		if raised != step.raised {
+			t.Errorf("UpdateHighWaterMark(%v) = %v; expected %v", step.price, raised, step.raised)
+		}
+		if notification.HighWaterMark != step.highWaterMark {
+			t.Errorf("high-water mark after %v = %v; expected %v", step.price, notification.HighWaterMark, step.highWaterMark)
+		}
+		if trigger := alerts.ShouldTriggerTrailing(notification, step.price); trigger != step.trigger {
+			t.Errorf("ShouldTriggerTrailing(%v) = %v; expected %v", step.price, trigger, step.trigger)
+		}
+	}
+}
+
+func TestShouldRearmTrailing(t *testing.T) {
+	now := time.Date(2024, 9, 20, 12, 0, 0, 0, time.UTC)
+	tenMinutesAgo := now.Add(-10 * time.Minute)
+
+	notification := &models.PriceNotification{
+		Kind:            "trailing",
+		Status:          "Rearming",
+		Recurring:       true,
+		CooldownSeconds: 3600,
+		LastTriggeredAt: &tenMinutesAgo,
+	}
+	if alerts.ShouldRearmTrailing(notification, now) {
+		t.Errorf("ShouldRearmTrailing() = true before the cooldown elapsed")
+	}
+	if !alerts.ShouldRearmTrailing(notification, now.Add(time.Hour)) {
+		t.Errorf("ShouldRearmTrailing() = false after the cooldown elapsed")
+	}
+}
+
+func TestValidateTrailPercent(t *testing.T) {
+	for _, trail := range []float64{0, -5, 100, 150} {
+		if err := alerts.ValidateTrailPercent(trail); err == nil {
+			t.Errorf("ValidateTrailPercent(%v) expected an error", trail)
+		}
+	}
+	if err := alerts.ValidateTrailPercent(7); err != nil {
+		t.Errorf("ValidateTrailPercent(7) unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package audit_test
+
+import (
+	"bytes"
+	"cryptotracker/internal/audit"
+	"cryptotracker/models"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// chain builds a chain of three entries, as the repository would write them.
+func chain() []*models.AuditEvent {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 123456789, time.UTC)
+	events := []*models.AuditEvent{
+		{ID: 1, Actor: "alice", Action: "role.promoted", Target: "bob", Before: "user", After: "analyst", Source: "cli", CreatedAt: start},
+		{ID: 2, Actor: "alice", Action: "request.status_changed", Target: "carol", Before: "Pending", After: "Approved", Source: "rest", CreatedAt: start.Add(time.Minute)},
+		{ID: 3, Actor: "alice", Action: "user.deleted", Target: "bob", Before: "analyst", Source: "cli", CreatedAt: start.Add(2 * time.Minute)},
+	}
+
+	prevHash := ""
+	for _, event := range events {
+		audit.Chain(event, prevHash)
+		prevHash = event.Hash
+	}
+	return events
+}
+
+func TestChain(t *testing.T) {
+	events := chain()
+
+	if events[0].PrevHash != "" || events[1].PrevHash != events[0].Hash {
+		t.Errorf("entries are not linked: %q, %q", events[0].PrevHash, events[1].PrevHash)
+	}
+	if events[0].CreatedAt.Nanosecond() != 123456000 {
+		t.Errorf("CreatedAt = %v; expected it truncated to microseconds", events[0].CreatedAt)
+	}
+	if len(events[0].Hash) != 64 || events[0].Hash == events[1].Hash {
+		t.Errorf("unexpected hashes %q and %q", events[0].Hash, events[1].Hash)
+	}
+}
+
+func TestHashSeparatesFields(t *testing.T) {
+	a := &models.AuditEvent{Actor: "ab", Action: "c"}
+	b := &models.AuditEvent{Actor: "a", Action: "bc"}
+	if audit.Hash(a) == audit.Hash(b) {
+		t.Error("entries with shifted field boundaries have the same hash")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		tamper   func(events []*models.AuditEvent) []*models.AuditEvent
+		intact   bool
+		brokenAt int
+	}{
+		{"untouched", func(events []*models.AuditEvent) []*models.AuditEvent { return events }, true, 0},
+		{"empty log", func(events []*models.AuditEvent) []*models.AuditEvent { return nil }, true, 0},
+		{"edited value", func(events []*models.AuditEvent) []*models.AuditEvent {
+			events[1].After = "Rejected"
+			return events
+		}, false, 2},
+		{"edited and rehashed", func(events []*models.AuditEvent) []*models.AuditEvent {
+			events[0].Actor = "mallory"
+			events[0].Hash = audit.Hash(events[0])
+			return events
+		}, false, 2},
+		{"removed entry", func(events []*models.AuditEvent) []*models.AuditEvent {
+			return []*models.AuditEvent{events[0], events[2]}
+		}, false, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := audit.Verify(tt.tamper(chain()))
+			if result.Intact != tt.intact || result.BrokenAt != tt.brokenAt {
+				t.Errorf("Verify() = intact %v, broken at %d; expected %v, %d", result.Intact, result.BrokenAt, tt.intact, tt.brokenAt)
+			}
+		})
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		check   func(filter *models.AuditFilter) bool
+		wantErr bool
+	}{
+		{"empty", "", func(f *models.AuditFilter) bool { return *f == models.AuditFilter{} }, false},
+		{"terms", "actor=alice&action=role.&target=bob&source=rest&limit=20", func(f *models.AuditFilter) bool {
+			return f.Actor == "alice" && f.Action == "role." && f.Target == "bob" && f.Source == "rest" && f.Limit == 20
+		}, false},
+		{"until includes the day", "since=2026-03-01&until=2026-03-01", func(f *models.AuditFilter) bool {
+			return f.Until.Sub(f.Since) == 24*time.Hour
+		}, false},
+		{"RFC 3339 time", "since=2026-03-01T12:00:00Z", func(f *models.AuditFilter) bool {
+			return f.Since.Equal(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+		}, false},
+		{"unknown term", "user=alice", nil, true},
+		{"bad date", "since=yesterday", nil, true},
+		{"bad limit", "limit=0", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, _ := url.ParseQuery(tt.query)
+			filter, err := audit.ParseFilter(values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFilter(%q) error = %v; wantErr %v", tt.query, err, tt.wantErr)
+			}
+			if err == nil && !tt.check(filter) {
+				t.Errorf("ParseFilter(%q) = %+v", tt.query, filter)
+			}
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	events := chain()
+
+	var csv bytes.Buffer
+	if err := audit.Export(&csv, events, audit.FormatCSV); err != nil {
+		t.Fatalf("Export(csv) error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(csv.String()), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "id,created_at,actor") || !strings.Contains(lines[1], events[0].Hash) {
+		t.Errorf("unexpected CSV export:\n%s", csv.String())
+	}
+
+	var json bytes.Buffer
+	if err := audit.Export(&json, nil, audit.FormatJSON); err != nil || strings.TrimSpace(json.String()) != "[]" {
+		t.Errorf("Export(json) of no entries = %q, %v; expected []", json.String(), err)
+	}
+
+	if err := audit.Export(&json, events, "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
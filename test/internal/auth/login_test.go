@@ -2,7 +2,8 @@ package auth
 
 import (
 	"context"
-	"cryptotracker/internal/auth"
+	"cryptotracker/internal/repositories"
+	"cryptotracker/internal/services"
 	"cryptotracker/models"
 	"cryptotracker/pkg/utils"
 	"errors"
@@ -32,6 +33,14 @@ func insertTestUser(conn *pgx.Conn, user *models.User) error {
 	return err
 }
 
+func mustHashPassword(t *testing.T, password string) string {
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	return hash
+}
+
 // TestLogin function for PostgreSQL
 func TestLogin(t *testing.T) {
 	conn, err := setupPostgres()
@@ -40,6 +49,8 @@ func TestLogin(t *testing.T) {
 	}
 	defer conn.Close(context.Background())
 
+	authService := services.NewAuthService(repositories.NewPostgresAuthRepository(conn), nil)
+
 	tests := []struct {
 		name          string
 		existingUser  *models.User
@@ -52,11 +63,11 @@ func TestLogin(t *testing.T) {
 		{
 			name: "Invalid username",
 			existingUser: &models.User{
-				UserID:   "1",
+				UserID:   1,
 				Username: "test_user_invalid_username",
-				Password: utils.HashPassword("password123"),
+				Password: mustHashPassword(t, "password123"),
 				Email:    "invalid@example.com",
-				Mobile:   1234567890,
+				Mobile:   "+911234567890",
 				Role:     "user",
 				IsAdmin:  false,
 			},
@@ -79,7 +90,7 @@ func TestLogin(t *testing.T) {
 			}
 
 			// Perform Login
-			user, role, err := auth.Login(conn, tt.loginUsername, tt.loginPassword)
+			user, role, err := authService.Login(tt.loginUsername, tt.loginPassword, "")
 
 			// Check for expected error
 			if (err != nil) != (tt.expectedErr != nil) || (err != nil && err.Error() != tt.expectedErr.Error()) {
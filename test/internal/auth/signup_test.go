@@ -30,7 +30,7 @@ func TestSignup(t *testing.T) {
 				Username: "test_user_success",
 				Password: "hashed_password",
 				Email:    "success@example.com",
-				Mobile:   1234567890,
+				Mobile:   "+911234567890",
 				Role:     "user",
 				IsAdmin:  false,
 			},
@@ -39,13 +39,13 @@ func TestSignup(t *testing.T) {
 		{
 			name: "User already exists",
 			existingUsers: []*models.User{
-				{Username: "existing_user", Password: "hashed_password", Email: "existing@example.com", Mobile: 1234567890},
+				{Username: "existing_user", Password: "hashed_password", Email: "existing@example.com", Mobile: "+911234567890"},
 			},
 			newUser: &models.User{
 				Username: "existing_user", // Same username as an existing user
 				Password: "hashed_password",
 				Email:    "new@example.com",
-				Mobile:   1234567890,
+				Mobile:   "+911234567890",
 				Role:     "user",
 				IsAdmin:  false,
 			},
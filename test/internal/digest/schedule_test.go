@@ -0,0 +1,89 @@
+package digest_test
+
+import (
+	"cryptotracker/internal/digest"
+	"cryptotracker/models"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings models.DigestSettings
+		wantErr  bool
+	}{
+		{"daily", models.DigestSettings{Frequency: "daily", SendAt: "08:00"}, false},
+		{"weekly", models.DigestSettings{Frequency: "weekly", SendAt: "18:30", Weekday: 5}, false},
+		{"off", models.DigestSettings{Frequency: "off", SendAt: "08:00"}, false},
+		{"unknown frequency", models.DigestSettings{Frequency: "hourly", SendAt: "08:00"}, true},
+		{"bad time", models.DigestSettings{Frequency: "daily", SendAt: "8am"}, true},
+		{"bad weekday", models.DigestSettings{Frequency: "weekly", SendAt: "08:00", Weekday: 7}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := digest.Validate(&tt.settings)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDue(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+
+	// Friday 2024-09-20 09:00 in Kolkata
+	now := time.Date(2024, 9, 20, 9, 0, 0, 0, loc)
+	yesterday := now.Add(-24 * time.Hour)
+	thisMorning := time.Date(2024, 9, 20, 8, 5, 0, 0, loc)
+	lastWeek := now.AddDate(0, 0, -6)
+	yesterdayLate := time.Date(2024, 9, 19, 10, 5, 0, 0, loc)
+
+	tests := []struct {
+		name     string
+		settings models.DigestSettings
+		expected bool
+	}{
+		{"daily, time passed", models.DigestSettings{Frequency: "daily", SendAt: "08:00", LastSentAt: &yesterday}, true},
+		{"daily, already sent", models.DigestSettings{Frequency: "daily", SendAt: "08:00", LastSentAt: &thisMorning}, false},
+		{"daily, time not reached", models.DigestSettings{Frequency: "daily", SendAt: "10:00", LastSentAt: &yesterdayLate}, false},
+		{"weekly, right day", models.DigestSettings{Frequency: "weekly", SendAt: "08:00", Weekday: 5, LastSentAt: &lastWeek}, true},
+		{"weekly, other day", models.DigestSettings{Frequency: "weekly", SendAt: "08:00", Weekday: 1, LastSentAt: &thisMorning}, false},
+		{"off", models.DigestSettings{Frequency: "off", SendAt: "08:00"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := digest.Due(&tt.settings, loc, now); got != tt.expected {
+				t.Errorf("Due() = %v; expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNearTarget(t *testing.T) {
+	tests := []struct {
+		name         string
+		notification models.PriceNotification
+		price        float64
+		near         bool
+	}{
+		{"within range", models.PriceNotification{Kind: "price", Status: "Pending", TargetPrice: 100}, 96, true},
+		{"far away", models.PriceNotification{Kind: "price", Status: "Pending", TargetPrice: 100}, 90, false},
+		{"already fired", models.PriceNotification{Kind: "price", Status: "Rearming", TargetPrice: 100}, 99, false},
+		{"rule alert", models.PriceNotification{Kind: "rule", Status: "Pending"}, 99, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, near := digest.NearTarget(&tt.notification, tt.price); near != tt.near {
+				t.Errorf("NearTarget(%v) = %v; expected %v", tt.price, near, tt.near)
+			}
+		})
+	}
+}
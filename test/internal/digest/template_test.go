@@ -0,0 +1,46 @@
+package digest_test
+
+import (
+	"cryptotracker/internal/digest"
+	"cryptotracker/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	end := time.Date(2024, 9, 20, 8, 0, 0, 0, time.UTC)
+	d := &models.Digest{
+		Username:    "alice",
+		Frequency:   "daily",
+		PeriodStart: end.Add(-24 * time.Hour),
+		PeriodEnd:   end,
+		Location:    time.UTC,
+		Coins:       []*models.Quote{{Symbol: "BTC", Price: 63000, PercentChange24h: -1.25}},
+		Fired: []models.AlertOccurrence{
+			{Crypto: "ETH", TargetPrice: 2500, TriggeredPrice: 2510, TriggeredAt: end.Add(-2 * time.Hour)},
+		},
+		NearlyFired: []models.DigestNearAlert{{Crypto: "SOL", TargetPrice: 150, CurrentPrice: 146, DistancePercent: 2.7}},
+	}
+
+	out, err := digest.Render(d)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"Daily digest for alice",
+		"BTC      $63000.00  -1.25%",
+		"ETH target $2500.00 hit at $2510.00 on Sep 20 06:00 UTC",
+		"SOL target $150.00, now $146.00 (2.7% away)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Render() output is missing %q:\n%s", want, out)
+		}
+	}
+
+	// Empty sections say so instead of disappearing
+	if !strings.Contains(out, "Cryptocurrency requests\n  None.") {
+		t.Errorf("Render() output is missing the empty requests section:\n%s", out)
+	}
+}
@@ -0,0 +1,83 @@
+package lockout_test
+
+import (
+	"cryptotracker/internal/lockout"
+	"cryptotracker/models"
+	"cryptotracker/pkg/config"
+	"testing"
+	"time"
+)
+
+func TestPolicyFromConfig(t *testing.T) {
+	policy := lockout.PolicyFromConfig(config.LoginThrottling{MaxFailures: 3, LockoutMinutes: 60})
+
+	if policy.MaxFailures != 3 || policy.Lockout != time.Hour {
+		t.Errorf("configured values not used: %+v", policy)
+	}
+	if policy.MaxFailuresPerIP != lockout.DefaultMaxFailuresPerIP || policy.BaseDelay != lockout.DefaultBaseDelay {
+		t.Errorf("defaults not used for unset values: %+v", policy)
+	}
+}
+
+func TestDelay(t *testing.T) {
+	policy := lockout.PolicyFromConfig(config.LoginThrottling{})
+
+	tests := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{0, 0},
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{4, 8 * time.Second},
+		{5, 16 * time.Second},
+		{6, 30 * time.Second},
+		{50, 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Delay(tt.failures); got != tt.expected {
+			t.Errorf("Delay(%d) = %v; expected %v", tt.failures, got, tt.expected)
+		}
+	}
+}
+
+func TestWait(t *testing.T) {
+	policy := lockout.PolicyFromConfig(config.LoginThrottling{})
+	now := time.Date(2024, 9, 20, 12, 0, 0, 0, time.UTC)
+	lockedUntil := now.Add(10 * time.Minute)
+	lockExpired := now.Add(-time.Minute)
+
+	tests := []struct {
+		name     string
+		attempt  models.LoginAttempt
+		wait     time.Duration
+		isLocked bool
+	}{
+		{"delay still running", models.LoginAttempt{Failures: 3, LastFailureAt: now.Add(-time.Second)}, 3 * time.Second, false},
+		{"delay over", models.LoginAttempt{Failures: 3, LastFailureAt: now.Add(-5 * time.Second)}, 0, false},
+		{"locked", models.LoginAttempt{Failures: 5, LastFailureAt: now.Add(-5 * time.Minute), LockedUntil: &lockedUntil}, 10 * time.Minute, true},
+		{"lock expired", models.LoginAttempt{Failures: 5, LastFailureAt: now.Add(-16 * time.Minute), LockedUntil: &lockExpired}, 0, false},
+		{"failures outside the window", models.LoginAttempt{Failures: 40, LastFailureAt: now.Add(-20 * time.Minute)}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, locked := policy.Wait(&tt.attempt, now)
+			if wait != tt.wait || locked != tt.isLocked {
+				t.Errorf("Wait() = %v, %v; expected %v, %v", wait, locked, tt.wait, tt.isLocked)
+			}
+		})
+	}
+}
+
+func TestKeys(t *testing.T) {
+	if key := lockout.UserKey("Alice"); key != "user:alice" || !lockout.IsUserKey(key) || lockout.IsIPKey(key) || lockout.Username(key) != "alice" {
+		t.Errorf("UserKey(Alice) = %q", key)
+	}
+
+	policy := lockout.PolicyFromConfig(config.LoginThrottling{})
+	if key := lockout.IPKey("192.0.2.1"); lockout.IsUserKey(key) || !lockout.IsIPKey(key) || policy.Limit(key) != lockout.DefaultMaxFailuresPerIP {
+		t.Errorf("IPKey(192.0.2.1) = %q is not treated as an address", key)
+	}
+}
@@ -7,6 +7,7 @@ package mock_repositories
 import (
 	models "cryptotracker/models"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -34,32 +35,110 @@ func (m *MockAdminRepository) EXPECT() *MockAdminRepositoryMockRecorder {
 	return m.recorder
 }
 
-// ChangeUserStatus mocks base method.
-func (m *MockAdminRepository) ChangeUserStatus(username string) error {
+// GetAuditChain mocks base method.
+func (m *MockAdminRepository) GetAuditChain() ([]*models.AuditEvent, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ChangeUserStatus", username)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "GetAuditChain")
+	ret0, _ := ret[0].([]*models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// ChangeUserStatus indicates an expected call of ChangeUserStatus.
-func (mr *MockAdminRepositoryMockRecorder) ChangeUserStatus(username interface{}) *gomock.Call {
+// GetAuditChain indicates an expected call of GetAuditChain.
+func (mr *MockAdminRepositoryMockRecorder) GetAuditChain() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeUserStatus", reflect.TypeOf((*MockAdminRepository)(nil).ChangeUserStatus), username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditChain", reflect.TypeOf((*MockAdminRepository)(nil).GetAuditChain))
 }
 
-// DeleteUser mocks base method.
-func (m *MockAdminRepository) DeleteUser(username string) error {
+// GetAuditEvents mocks base method.
+func (m *MockAdminRepository) GetAuditEvents(filter *models.AuditFilter) ([]*models.AuditEvent, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteUser", username)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "GetAuditEvents", filter)
+	ret0, _ := ret[0].([]*models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEvents indicates an expected call of GetAuditEvents.
+func (mr *MockAdminRepositoryMockRecorder) GetAuditEvents(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEvents", reflect.TypeOf((*MockAdminRepository)(nil).GetAuditEvents), filter)
+}
+
+// GetLockedLogins mocks base method.
+func (m *MockAdminRepository) GetLockedLogins(now time.Time) ([]*models.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLockedLogins", now)
+	ret0, _ := ret[0].([]*models.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeleteUser indicates an expected call of DeleteUser.
-func (mr *MockAdminRepositoryMockRecorder) DeleteUser(username interface{}) *gomock.Call {
+// GetLockedLogins indicates an expected call of GetLockedLogins.
+func (mr *MockAdminRepositoryMockRecorder) GetLockedLogins(now interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockAdminRepository)(nil).DeleteUser), username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLockedLogins", reflect.TypeOf((*MockAdminRepository)(nil).GetLockedLogins), now)
+}
+
+// GetUserRole mocks base method.
+func (m *MockAdminRepository) GetUserRole(username string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserRole", username)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserRole indicates an expected call of GetUserRole.
+func (mr *MockAdminRepositoryMockRecorder) GetUserRole(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserRole", reflect.TypeOf((*MockAdminRepository)(nil).GetUserRole), username)
+}
+
+// GetUserStatus mocks base method.
+func (m *MockAdminRepository) GetUserStatus(username string) (string, *time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserStatus", username)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*time.Time)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetUserStatus indicates an expected call of GetUserStatus.
+func (mr *MockAdminRepositoryMockRecorder) GetUserStatus(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserStatus", reflect.TypeOf((*MockAdminRepository)(nil).GetUserStatus), username)
+}
+
+// GetUsersDueForPurge mocks base method.
+func (m *MockAdminRepository) GetUsersDueForPurge(now time.Time) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersDueForPurge", now)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersDueForPurge indicates an expected call of GetUsersDueForPurge.
+func (mr *MockAdminRepositoryMockRecorder) GetUsersDueForPurge(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersDueForPurge", reflect.TypeOf((*MockAdminRepository)(nil).GetUsersDueForPurge), now)
+}
+
+// IsTwoFactorRequired mocks base method.
+func (m *MockAdminRepository) IsTwoFactorRequired(role string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsTwoFactorRequired", role)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsTwoFactorRequired indicates an expected call of IsTwoFactorRequired.
+func (mr *MockAdminRepositoryMockRecorder) IsTwoFactorRequired(role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTwoFactorRequired", reflect.TypeOf((*MockAdminRepository)(nil).IsTwoFactorRequired), role)
 }
 
 // ManageSpecificCryptoRequests mocks base method.
@@ -92,18 +171,143 @@ func (mr *MockAdminRepositoryMockRecorder) ManageUserRequests() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ManageUserRequests", reflect.TypeOf((*MockAdminRepository)(nil).ManageUserRequests))
 }
 
-// SaveUnavailableCryptoRequest mocks base method.
-func (m *MockAdminRepository) SaveUnavailableCryptoRequest(requests []*models.UnavailableCryptoRequest) error {
+// PurgeUser mocks base method.
+func (m *MockAdminRepository) PurgeUser(username string, now time.Time, events ...*models.AuditEvent) (bool, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{username, now}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PurgeUser", varargs...)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeUser indicates an expected call of PurgeUser.
+func (mr *MockAdminRepositoryMockRecorder) PurgeUser(username, now interface{}, events ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{username, now}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeUser", reflect.TypeOf((*MockAdminRepository)(nil).PurgeUser), varargs...)
+}
+
+// RecordAuditEvent mocks base method.
+func (m *MockAdminRepository) RecordAuditEvent(event *models.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAuditEvent", event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordAuditEvent indicates an expected call of RecordAuditEvent.
+func (mr *MockAdminRepositoryMockRecorder) RecordAuditEvent(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAuditEvent", reflect.TypeOf((*MockAdminRepository)(nil).RecordAuditEvent), event)
+}
+
+// RevokeUserSessions mocks base method.
+func (m *MockAdminRepository) RevokeUserSessions(username string, now time.Time, event func(int) *models.AuditEvent) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserSessions", username, now, event)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeUserSessions indicates an expected call of RevokeUserSessions.
+func (mr *MockAdminRepositoryMockRecorder) RevokeUserSessions(username, now, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserSessions", reflect.TypeOf((*MockAdminRepository)(nil).RevokeUserSessions), username, now, event)
+}
+
+// SetTwoFactorRequired mocks base method.
+func (m *MockAdminRepository) SetTwoFactorRequired(role string, required bool, events ...*models.AuditEvent) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{role, required}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SetTwoFactorRequired", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTwoFactorRequired indicates an expected call of SetTwoFactorRequired.
+func (mr *MockAdminRepositoryMockRecorder) SetTwoFactorRequired(role, required interface{}, events ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{role, required}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTwoFactorRequired", reflect.TypeOf((*MockAdminRepository)(nil).SetTwoFactorRequired), varargs...)
+}
+
+// SetUserRole mocks base method.
+func (m *MockAdminRepository) SetUserRole(username, role string, events ...*models.AuditEvent) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{username, role}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SetUserRole", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserRole indicates an expected call of SetUserRole.
+func (mr *MockAdminRepositoryMockRecorder) SetUserRole(username, role interface{}, events ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{username, role}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRole", reflect.TypeOf((*MockAdminRepository)(nil).SetUserRole), varargs...)
+}
+
+// SetUserStatus mocks base method.
+func (m *MockAdminRepository) SetUserStatus(username, status string, purgeAfter *time.Time, now time.Time, events ...*models.AuditEvent) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{username, status, purgeAfter, now}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SetUserStatus", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserStatus indicates an expected call of SetUserStatus.
+func (mr *MockAdminRepositoryMockRecorder) SetUserStatus(username, status, purgeAfter, now interface{}, events ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{username, status, purgeAfter, now}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserStatus", reflect.TypeOf((*MockAdminRepository)(nil).SetUserStatus), varargs...)
+}
+
+// UnlockLogin mocks base method.
+func (m *MockAdminRepository) UnlockLogin(key string, events ...*models.AuditEvent) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{key}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UnlockLogin", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlockLogin indicates an expected call of UnlockLogin.
+func (mr *MockAdminRepositoryMockRecorder) UnlockLogin(key interface{}, events ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{key}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockLogin", reflect.TypeOf((*MockAdminRepository)(nil).UnlockLogin), varargs...)
+}
+
+// UpdateRequestStatus mocks base method.
+func (m *MockAdminRepository) UpdateRequestStatus(requests []*models.UnavailableCryptoRequest, status string, events []*models.AuditEvent) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveUnavailableCryptoRequest", requests)
+	ret := m.ctrl.Call(m, "UpdateRequestStatus", requests, status, events)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SaveUnavailableCryptoRequest indicates an expected call of SaveUnavailableCryptoRequest.
-func (mr *MockAdminRepositoryMockRecorder) SaveUnavailableCryptoRequest(requests interface{}) *gomock.Call {
+// UpdateRequestStatus indicates an expected call of UpdateRequestStatus.
+func (mr *MockAdminRepositoryMockRecorder) UpdateRequestStatus(requests, status, events interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUnavailableCryptoRequest", reflect.TypeOf((*MockAdminRepository)(nil).SaveUnavailableCryptoRequest), requests)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRequestStatus", reflect.TypeOf((*MockAdminRepository)(nil).UpdateRequestStatus), requests, status, events)
 }
 
 // ViewUserProfiles mocks base method.
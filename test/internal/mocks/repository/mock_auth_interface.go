@@ -7,6 +7,7 @@ package mock_repositories
 import (
 	models "cryptotracker/models"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -34,6 +35,167 @@ func (m *MockAuthRepository) EXPECT() *MockAuthRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ClearLoginAttempts mocks base method.
+func (m *MockAuthRepository) ClearLoginAttempts(key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearLoginAttempts", key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearLoginAttempts indicates an expected call of ClearLoginAttempts.
+func (mr *MockAuthRepositoryMockRecorder) ClearLoginAttempts(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearLoginAttempts", reflect.TypeOf((*MockAuthRepository)(nil).ClearLoginAttempts), key)
+}
+
+// CountEmailVerifications mocks base method.
+func (m *MockAuthRepository) CountEmailVerifications(username string, since time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountEmailVerifications", username, since)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountEmailVerifications indicates an expected call of CountEmailVerifications.
+func (mr *MockAuthRepositoryMockRecorder) CountEmailVerifications(username, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountEmailVerifications", reflect.TypeOf((*MockAuthRepository)(nil).CountEmailVerifications), username, since)
+}
+
+// CountPasswordResets mocks base method.
+func (m *MockAuthRepository) CountPasswordResets(username string, since time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountPasswordResets", username, since)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountPasswordResets indicates an expected call of CountPasswordResets.
+func (mr *MockAuthRepositoryMockRecorder) CountPasswordResets(username, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountPasswordResets", reflect.TypeOf((*MockAuthRepository)(nil).CountPasswordResets), username, since)
+}
+
+// DisableTwoFactor mocks base method.
+func (m *MockAuthRepository) DisableTwoFactor(username string, events ...*models.AuditEvent) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{username}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DisableTwoFactor", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTwoFactor indicates an expected call of DisableTwoFactor.
+func (mr *MockAuthRepositoryMockRecorder) DisableTwoFactor(username interface{}, events ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{username}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTwoFactor", reflect.TypeOf((*MockAuthRepository)(nil).DisableTwoFactor), varargs...)
+}
+
+// EnableTwoFactor mocks base method.
+func (m *MockAuthRepository) EnableTwoFactor(username string, step int64, recoveryCodeHashes []string, now time.Time, events ...*models.AuditEvent) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{username, step, recoveryCodeHashes, now}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "EnableTwoFactor", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableTwoFactor indicates an expected call of EnableTwoFactor.
+func (mr *MockAuthRepositoryMockRecorder) EnableTwoFactor(username, step, recoveryCodeHashes, now interface{}, events ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{username, step, recoveryCodeHashes, now}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTwoFactor", reflect.TypeOf((*MockAuthRepository)(nil).EnableTwoFactor), varargs...)
+}
+
+// FindUsersForReset mocks base method.
+func (m *MockAuthRepository) FindUsersForReset(identifier string) ([]*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUsersForReset", identifier)
+	ret0, _ := ret[0].([]*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUsersForReset indicates an expected call of FindUsersForReset.
+func (mr *MockAuthRepositoryMockRecorder) FindUsersForReset(identifier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUsersForReset", reflect.TypeOf((*MockAuthRepository)(nil).FindUsersForReset), identifier)
+}
+
+// GetLoginAttempts mocks base method.
+func (m *MockAuthRepository) GetLoginAttempts(keys []string) ([]*models.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginAttempts", keys)
+	ret0, _ := ret[0].([]*models.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginAttempts indicates an expected call of GetLoginAttempts.
+func (mr *MockAuthRepositoryMockRecorder) GetLoginAttempts(keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginAttempts", reflect.TypeOf((*MockAuthRepository)(nil).GetLoginAttempts), keys)
+}
+
+// GetTwoFactor mocks base method.
+func (m *MockAuthRepository) GetTwoFactor(username string) (*models.TwoFactor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTwoFactor", username)
+	ret0, _ := ret[0].(*models.TwoFactor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTwoFactor indicates an expected call of GetTwoFactor.
+func (mr *MockAuthRepositoryMockRecorder) GetTwoFactor(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTwoFactor", reflect.TypeOf((*MockAuthRepository)(nil).GetTwoFactor), username)
+}
+
+// IsTwoFactorRequired mocks base method.
+func (m *MockAuthRepository) IsTwoFactorRequired(role string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsTwoFactorRequired", role)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsTwoFactorRequired indicates an expected call of IsTwoFactorRequired.
+func (mr *MockAuthRepositoryMockRecorder) IsTwoFactorRequired(role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTwoFactorRequired", reflect.TypeOf((*MockAuthRepository)(nil).IsTwoFactorRequired), role)
+}
+
+// LockLogin mocks base method.
+func (m *MockAuthRepository) LockLogin(key string, until time.Time, events ...*models.AuditEvent) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{key, until}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "LockLogin", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockLogin indicates an expected call of LockLogin.
+func (mr *MockAuthRepositoryMockRecorder) LockLogin(key, until interface{}, events ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{key, until}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockLogin", reflect.TypeOf((*MockAuthRepository)(nil).LockLogin), varargs...)
+}
+
 // LoginDBRepository mocks base method.
 func (m *MockAuthRepository) LoginDBRepository(username string) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -49,6 +211,83 @@ func (mr *MockAuthRepositoryMockRecorder) LoginDBRepository(username interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginDBRepository", reflect.TypeOf((*MockAuthRepository)(nil).LoginDBRepository), username)
 }
 
+// RecordLoginFailure mocks base method.
+func (m *MockAuthRepository) RecordLoginFailure(key string, now, windowStart time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordLoginFailure", key, now, windowStart)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordLoginFailure indicates an expected call of RecordLoginFailure.
+func (mr *MockAuthRepositoryMockRecorder) RecordLoginFailure(key, now, windowStart interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLoginFailure", reflect.TypeOf((*MockAuthRepository)(nil).RecordLoginFailure), key, now, windowStart)
+}
+
+// ResetPassword mocks base method.
+func (m *MockAuthRepository) ResetPassword(tokenHash, passwordHash string, now time.Time, events ...*models.AuditEvent) (string, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{tokenHash, passwordHash, now}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ResetPassword", varargs...)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockAuthRepositoryMockRecorder) ResetPassword(tokenHash, passwordHash, now interface{}, events ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{tokenHash, passwordHash, now}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockAuthRepository)(nil).ResetPassword), varargs...)
+}
+
+// SaveEmailVerificationToken mocks base method.
+func (m *MockAuthRepository) SaveEmailVerificationToken(hash, username, email string, createdAt, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveEmailVerificationToken", hash, username, email, createdAt, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveEmailVerificationToken indicates an expected call of SaveEmailVerificationToken.
+func (mr *MockAuthRepositoryMockRecorder) SaveEmailVerificationToken(hash, username, email, createdAt, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveEmailVerificationToken", reflect.TypeOf((*MockAuthRepository)(nil).SaveEmailVerificationToken), hash, username, email, createdAt, expiresAt)
+}
+
+// SavePasswordResetToken mocks base method.
+func (m *MockAuthRepository) SavePasswordResetToken(hash, username string, createdAt, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePasswordResetToken", hash, username, createdAt, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePasswordResetToken indicates an expected call of SavePasswordResetToken.
+func (mr *MockAuthRepositoryMockRecorder) SavePasswordResetToken(hash, username, createdAt, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePasswordResetToken", reflect.TypeOf((*MockAuthRepository)(nil).SavePasswordResetToken), hash, username, createdAt, expiresAt)
+}
+
+// SaveTwoFactorSecret mocks base method.
+func (m *MockAuthRepository) SaveTwoFactorSecret(username, secret string, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTwoFactorSecret", username, secret, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTwoFactorSecret indicates an expected call of SaveTwoFactorSecret.
+func (mr *MockAuthRepositoryMockRecorder) SaveTwoFactorSecret(username, secret, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTwoFactorSecret", reflect.TypeOf((*MockAuthRepository)(nil).SaveTwoFactorSecret), username, secret, now)
+}
+
 // SignupDBRepository mocks base method.
 func (m *MockAuthRepository) SignupDBRepository(user *models.User) error {
 	m.ctrl.T.Helper()
@@ -62,3 +301,72 @@ func (mr *MockAuthRepositoryMockRecorder) SignupDBRepository(user interface{}) *
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignupDBRepository", reflect.TypeOf((*MockAuthRepository)(nil).SignupDBRepository), user)
 }
+
+// UpdatePasswordHash mocks base method.
+func (m *MockAuthRepository) UpdatePasswordHash(username, hash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePasswordHash", username, hash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePasswordHash indicates an expected call of UpdatePasswordHash.
+func (mr *MockAuthRepositoryMockRecorder) UpdatePasswordHash(username, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePasswordHash", reflect.TypeOf((*MockAuthRepository)(nil).UpdatePasswordHash), username, hash)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockAuthRepository) UseRecoveryCode(username, codeHash string, now time.Time, events ...*models.AuditEvent) (bool, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{username, codeHash, now}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UseRecoveryCode", varargs...)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockAuthRepositoryMockRecorder) UseRecoveryCode(username, codeHash, now interface{}, events ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{username, codeHash, now}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockAuthRepository)(nil).UseRecoveryCode), varargs...)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockAuthRepository) UseTOTPStep(username string, step int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", username, step)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockAuthRepositoryMockRecorder) UseTOTPStep(username, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockAuthRepository)(nil).UseTOTPStep), username, step)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockAuthRepository) VerifyEmail(tokenHash string, now time.Time, events ...*models.AuditEvent) (string, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{tokenHash, now}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "VerifyEmail", varargs...)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockAuthRepositoryMockRecorder) VerifyEmail(tokenHash, now interface{}, events ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{tokenHash, now}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockAuthRepository)(nil).VerifyEmail), varargs...)
+}
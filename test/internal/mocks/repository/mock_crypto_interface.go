@@ -34,6 +34,20 @@ func (m *MockCryptoRepository) EXPECT() *MockCryptoRepositoryMockRecorder {
 	return m.recorder
 }
 
+// DeleteAlertGroup mocks base method.
+func (m *MockCryptoRepository) DeleteAlertGroup(username string, groupID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAlertGroup", username, groupID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAlertGroup indicates an expected call of DeleteAlertGroup.
+func (mr *MockCryptoRepositoryMockRecorder) DeleteAlertGroup(username, groupID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAlertGroup", reflect.TypeOf((*MockCryptoRepository)(nil).DeleteAlertGroup), username, groupID)
+}
+
 // DisplayTopCryptocurrencies mocks base method.
 func (m *MockCryptoRepository) DisplayTopCryptocurrencies(count int) ([]interface{}, error) {
 	m.ctrl.T.Helper()
@@ -49,6 +63,36 @@ func (mr *MockCryptoRepositoryMockRecorder) DisplayTopCryptocurrencies(count int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisplayTopCryptocurrencies", reflect.TypeOf((*MockCryptoRepository)(nil).DisplayTopCryptocurrencies), count)
 }
 
+// GetAlertGroups mocks base method.
+func (m *MockCryptoRepository) GetAlertGroups(username string) ([]*models.AlertGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlertGroups", username)
+	ret0, _ := ret[0].([]*models.AlertGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAlertGroups indicates an expected call of GetAlertGroups.
+func (mr *MockCryptoRepositoryMockRecorder) GetAlertGroups(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlertGroups", reflect.TypeOf((*MockCryptoRepository)(nil).GetAlertGroups), username)
+}
+
+// GetUserRole mocks base method.
+func (m *MockCryptoRepository) GetUserRole(username string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserRole", username)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserRole indicates an expected call of GetUserRole.
+func (mr *MockCryptoRepositoryMockRecorder) GetUserRole(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserRole", reflect.TypeOf((*MockCryptoRepository)(nil).GetUserRole), username)
+}
+
 // SearchCryptocurrency mocks base method.
 func (m *MockCryptoRepository) SearchCryptocurrency(user *models.User, cryptoSymbol string) (float64, string, string, error) {
 	m.ctrl.T.Helper()
@@ -66,17 +110,106 @@ func (mr *MockCryptoRepositoryMockRecorder) SearchCryptocurrency(user, cryptoSym
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchCryptocurrency", reflect.TypeOf((*MockCryptoRepository)(nil).SearchCryptocurrency), user, cryptoSymbol)
 }
 
+// SetAlertGroupStatus mocks base method.
+func (m *MockCryptoRepository) SetAlertGroupStatus(username string, groupID int, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAlertGroupStatus", username, groupID, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAlertGroupStatus indicates an expected call of SetAlertGroupStatus.
+func (mr *MockCryptoRepositoryMockRecorder) SetAlertGroupStatus(username, groupID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAlertGroupStatus", reflect.TypeOf((*MockCryptoRepository)(nil).SetAlertGroupStatus), username, groupID, status)
+}
+
+// SetLadderAlert mocks base method.
+func (m *MockCryptoRepository) SetLadderAlert(user *models.User, symbol string, levels []float64, options *models.AlertOptions) (*models.AlertGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLadderAlert", user, symbol, levels, options)
+	ret0, _ := ret[0].(*models.AlertGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetLadderAlert indicates an expected call of SetLadderAlert.
+func (mr *MockCryptoRepositoryMockRecorder) SetLadderAlert(user, symbol, levels, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLadderAlert", reflect.TypeOf((*MockCryptoRepository)(nil).SetLadderAlert), user, symbol, levels, options)
+}
+
 // SetPriceAlert mocks base method.
-func (m *MockCryptoRepository) SetPriceAlert(user *models.User, symbol string, targetPrice float64) (float64, error) {
+func (m *MockCryptoRepository) SetPriceAlert(user *models.User, symbol string, targetPrice float64, options *models.AlertOptions) (float64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetPriceAlert", user, symbol, targetPrice)
+	ret := m.ctrl.Call(m, "SetPriceAlert", user, symbol, targetPrice, options)
 	ret0, _ := ret[0].(float64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SetPriceAlert indicates an expected call of SetPriceAlert.
-func (mr *MockCryptoRepositoryMockRecorder) SetPriceAlert(user, symbol, targetPrice interface{}) *gomock.Call {
+func (mr *MockCryptoRepositoryMockRecorder) SetPriceAlert(user, symbol, targetPrice, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPriceAlert", reflect.TypeOf((*MockCryptoRepository)(nil).SetPriceAlert), user, symbol, targetPrice, options)
+}
+
+// SetRankAlert mocks base method.
+func (m *MockCryptoRepository) SetRankAlert(user *models.User, symbol string, threshold int, options *models.AlertOptions) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRankAlert", user, symbol, threshold, options)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetRankAlert indicates an expected call of SetRankAlert.
+func (mr *MockCryptoRepositoryMockRecorder) SetRankAlert(user, symbol, threshold, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRankAlert", reflect.TypeOf((*MockCryptoRepository)(nil).SetRankAlert), user, symbol, threshold, options)
+}
+
+// SetRuleAlert mocks base method.
+func (m *MockCryptoRepository) SetRuleAlert(user *models.User, expression string, options *models.AlertOptions) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRuleAlert", user, expression, options)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetRuleAlert indicates an expected call of SetRuleAlert.
+func (mr *MockCryptoRepositoryMockRecorder) SetRuleAlert(user, expression, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRuleAlert", reflect.TypeOf((*MockCryptoRepository)(nil).SetRuleAlert), user, expression, options)
+}
+
+// SetTrailingAlert mocks base method.
+func (m *MockCryptoRepository) SetTrailingAlert(user *models.User, symbol string, trailPercent float64, options *models.AlertOptions) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTrailingAlert", user, symbol, trailPercent, options)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetTrailingAlert indicates an expected call of SetTrailingAlert.
+func (mr *MockCryptoRepositoryMockRecorder) SetTrailingAlert(user, symbol, trailPercent, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailingAlert", reflect.TypeOf((*MockCryptoRepository)(nil).SetTrailingAlert), user, symbol, trailPercent, options)
+}
+
+// SetVolumeSpikeAlert mocks base method.
+func (m *MockCryptoRepository) SetVolumeSpikeAlert(user *models.User, symbol string, multiplier float64, options *models.AlertOptions) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetVolumeSpikeAlert", user, symbol, multiplier, options)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetVolumeSpikeAlert indicates an expected call of SetVolumeSpikeAlert.
+func (mr *MockCryptoRepositoryMockRecorder) SetVolumeSpikeAlert(user, symbol, multiplier, options interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPriceAlert", reflect.TypeOf((*MockCryptoRepository)(nil).SetPriceAlert), user, symbol, targetPrice)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVolumeSpikeAlert", reflect.TypeOf((*MockCryptoRepository)(nil).SetVolumeSpikeAlert), user, symbol, multiplier, options)
 }
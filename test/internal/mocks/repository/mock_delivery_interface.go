@@ -0,0 +1,124 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: C:\Users\akawadia\Downloads\CryptoTracker\internal\repositories\delivery_repository.go
+
+// Package mock_repositories is a generated GoMock package.
+package mock_repositories
+
+import (
+	models "cryptotracker/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDeliveryRepository is a mock of DeliveryRepository interface.
+type MockDeliveryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeliveryRepositoryMockRecorder
+}
+
+// MockDeliveryRepositoryMockRecorder is the mock recorder for MockDeliveryRepository.
+type MockDeliveryRepositoryMockRecorder struct {
+	mock *MockDeliveryRepository
+}
+
+// NewMockDeliveryRepository creates a new mock instance.
+func NewMockDeliveryRepository(ctrl *gomock.Controller) *MockDeliveryRepository {
+	mock := &MockDeliveryRepository{ctrl: ctrl}
+	mock.recorder = &MockDeliveryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeliveryRepository) EXPECT() *MockDeliveryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimDueDeliveries mocks base method.
+func (m *MockDeliveryRepository) ClaimDueDeliveries(now, leaseUntil time.Time, limit int) ([]*models.NotificationDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueDeliveries", now, leaseUntil, limit)
+	ret0, _ := ret[0].([]*models.NotificationDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueDeliveries indicates an expected call of ClaimDueDeliveries.
+func (mr *MockDeliveryRepositoryMockRecorder) ClaimDueDeliveries(now, leaseUntil, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueDeliveries", reflect.TypeOf((*MockDeliveryRepository)(nil).ClaimDueDeliveries), now, leaseUntil, limit)
+}
+
+// CountSentDeliveries mocks base method.
+func (m *MockDeliveryRepository) CountSentDeliveries(username string, since time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountSentDeliveries", username, since)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountSentDeliveries indicates an expected call of CountSentDeliveries.
+func (mr *MockDeliveryRepositoryMockRecorder) CountSentDeliveries(username, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountSentDeliveries", reflect.TypeOf((*MockDeliveryRepository)(nil).CountSentDeliveries), username, since)
+}
+
+// GetFailedDeliveries mocks base method.
+func (m *MockDeliveryRepository) GetFailedDeliveries(limit int) ([]*models.NotificationDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFailedDeliveries", limit)
+	ret0, _ := ret[0].([]*models.NotificationDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFailedDeliveries indicates an expected call of GetFailedDeliveries.
+func (mr *MockDeliveryRepositoryMockRecorder) GetFailedDeliveries(limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFailedDeliveries", reflect.TypeOf((*MockDeliveryRepository)(nil).GetFailedDeliveries), limit)
+}
+
+// GetRecipient mocks base method.
+func (m *MockDeliveryRepository) GetRecipient(username string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecipient", username)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecipient indicates an expected call of GetRecipient.
+func (mr *MockDeliveryRepositoryMockRecorder) GetRecipient(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecipient", reflect.TypeOf((*MockDeliveryRepository)(nil).GetRecipient), username)
+}
+
+// RequeueDelivery mocks base method.
+func (m *MockDeliveryRepository) RequeueDelivery(id int, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueDelivery", id, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequeueDelivery indicates an expected call of RequeueDelivery.
+func (mr *MockDeliveryRepositoryMockRecorder) RequeueDelivery(id, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueDelivery", reflect.TypeOf((*MockDeliveryRepository)(nil).RequeueDelivery), id, now)
+}
+
+// UpdateDelivery mocks base method.
+func (m *MockDeliveryRepository) UpdateDelivery(delivery *models.NotificationDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDelivery", delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDelivery indicates an expected call of UpdateDelivery.
+func (mr *MockDeliveryRepositoryMockRecorder) UpdateDelivery(delivery interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDelivery", reflect.TypeOf((*MockDeliveryRepository)(nil).UpdateDelivery), delivery)
+}
@@ -7,6 +7,7 @@ package mock_repositories
 import (
 	models "cryptotracker/models"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	pgx "github.com/jackc/pgx/v4"
@@ -65,6 +66,282 @@ func (mr *MockNotificationRepositoryMockRecorder) CheckUnavailableCryptoRequests
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckUnavailableCryptoRequestsRepo", reflect.TypeOf((*MockNotificationRepository)(nil).CheckUnavailableCryptoRequestsRepo), username)
 }
 
+// EnqueueDeliveryRepo mocks base method.
+func (m *MockNotificationRepository) EnqueueDeliveryRepo(delivery *models.NotificationDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueDeliveryRepo", delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnqueueDeliveryRepo indicates an expected call of EnqueueDeliveryRepo.
+func (mr *MockNotificationRepositoryMockRecorder) EnqueueDeliveryRepo(delivery interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueDeliveryRepo", reflect.TypeOf((*MockNotificationRepository)(nil).EnqueueDeliveryRepo), delivery)
+}
+
+// ExpirePriceAlertsRepo mocks base method.
+func (m *MockNotificationRepository) ExpirePriceAlertsRepo(username string, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpirePriceAlertsRepo", username, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExpirePriceAlertsRepo indicates an expected call of ExpirePriceAlertsRepo.
+func (mr *MockNotificationRepositoryMockRecorder) ExpirePriceAlertsRepo(username, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpirePriceAlertsRepo", reflect.TypeOf((*MockNotificationRepository)(nil).ExpirePriceAlertsRepo), username, now)
+}
+
+// GetAlertOccurrencesRepo mocks base method.
+func (m *MockNotificationRepository) GetAlertOccurrencesRepo(username string) ([]models.AlertOccurrence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlertOccurrencesRepo", username)
+	ret0, _ := ret[0].([]models.AlertOccurrence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAlertOccurrencesRepo indicates an expected call of GetAlertOccurrencesRepo.
+func (mr *MockNotificationRepositoryMockRecorder) GetAlertOccurrencesRepo(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlertOccurrencesRepo", reflect.TypeOf((*MockNotificationRepository)(nil).GetAlertOccurrencesRepo), username)
+}
+
+// GetCryptoRequestsRepo mocks base method.
+func (m *MockNotificationRepository) GetCryptoRequestsRepo(username string) ([]*models.UnavailableCryptoRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCryptoRequestsRepo", username)
+	ret0, _ := ret[0].([]*models.UnavailableCryptoRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCryptoRequestsRepo indicates an expected call of GetCryptoRequestsRepo.
+func (mr *MockNotificationRepositoryMockRecorder) GetCryptoRequestsRepo(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCryptoRequestsRepo", reflect.TypeOf((*MockNotificationRepository)(nil).GetCryptoRequestsRepo), username)
+}
+
+// GetDigestSettingsRepo mocks base method.
+func (m *MockNotificationRepository) GetDigestSettingsRepo(username string) (*models.DigestSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDigestSettingsRepo", username)
+	ret0, _ := ret[0].(*models.DigestSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDigestSettingsRepo indicates an expected call of GetDigestSettingsRepo.
+func (mr *MockNotificationRepositoryMockRecorder) GetDigestSettingsRepo(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDigestSettingsRepo", reflect.TypeOf((*MockNotificationRepository)(nil).GetDigestSettingsRepo), username)
+}
+
+// GetLatestQuotesRepo mocks base method.
+func (m *MockNotificationRepository) GetLatestQuotesRepo() (map[string]*models.Quote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestQuotesRepo")
+	ret0, _ := ret[0].(map[string]*models.Quote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestQuotesRepo indicates an expected call of GetLatestQuotesRepo.
+func (mr *MockNotificationRepositoryMockRecorder) GetLatestQuotesRepo() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestQuotesRepo", reflect.TypeOf((*MockNotificationRepository)(nil).GetLatestQuotesRepo))
+}
+
+// GetNotificationProfileRepo mocks base method.
+func (m *MockNotificationRepository) GetNotificationProfileRepo(username string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationProfileRepo", username)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationProfileRepo indicates an expected call of GetNotificationProfileRepo.
+func (mr *MockNotificationRepositoryMockRecorder) GetNotificationProfileRepo(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationProfileRepo", reflect.TypeOf((*MockNotificationRepository)(nil).GetNotificationProfileRepo), username)
+}
+
+// GetScheduledDigestsRepo mocks base method.
+func (m *MockNotificationRepository) GetScheduledDigestsRepo() ([]*models.DigestSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledDigestsRepo")
+	ret0, _ := ret[0].([]*models.DigestSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledDigestsRepo indicates an expected call of GetScheduledDigestsRepo.
+func (mr *MockNotificationRepositoryMockRecorder) GetScheduledDigestsRepo() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledDigestsRepo", reflect.TypeOf((*MockNotificationRepository)(nil).GetScheduledDigestsRepo))
+}
+
+// GetTrailingAveragesRepo mocks base method.
+func (m *MockNotificationRepository) GetTrailingAveragesRepo(symbols []string, since time.Time) (map[string]models.MarketAverage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrailingAveragesRepo", symbols, since)
+	ret0, _ := ret[0].(map[string]models.MarketAverage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrailingAveragesRepo indicates an expected call of GetTrailingAveragesRepo.
+func (mr *MockNotificationRepositoryMockRecorder) GetTrailingAveragesRepo(symbols, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrailingAveragesRepo", reflect.TypeOf((*MockNotificationRepository)(nil).GetTrailingAveragesRepo), symbols, since)
+}
+
+// GetUndeliveredOccurrencesRepo mocks base method.
+func (m *MockNotificationRepository) GetUndeliveredOccurrencesRepo(username string) ([]models.AlertOccurrence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUndeliveredOccurrencesRepo", username)
+	ret0, _ := ret[0].([]models.AlertOccurrence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUndeliveredOccurrencesRepo indicates an expected call of GetUndeliveredOccurrencesRepo.
+func (mr *MockNotificationRepositoryMockRecorder) GetUndeliveredOccurrencesRepo(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUndeliveredOccurrencesRepo", reflect.TypeOf((*MockNotificationRepository)(nil).GetUndeliveredOccurrencesRepo), username)
+}
+
+// GetUserTimezoneRepo mocks base method.
+func (m *MockNotificationRepository) GetUserTimezoneRepo(username string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTimezoneRepo", username)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTimezoneRepo indicates an expected call of GetUserTimezoneRepo.
+func (mr *MockNotificationRepositoryMockRecorder) GetUserTimezoneRepo(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTimezoneRepo", reflect.TypeOf((*MockNotificationRepository)(nil).GetUserTimezoneRepo), username)
+}
+
+// MarkDigestSentRepo mocks base method.
+func (m *MockNotificationRepository) MarkDigestSentRepo(username string, sentAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDigestSentRepo", username, sentAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkDigestSentRepo indicates an expected call of MarkDigestSentRepo.
+func (mr *MockNotificationRepositoryMockRecorder) MarkDigestSentRepo(username, sentAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDigestSentRepo", reflect.TypeOf((*MockNotificationRepository)(nil).MarkDigestSentRepo), username, sentAt)
+}
+
+// MarkOccurrencesDeliveredRepo mocks base method.
+func (m *MockNotificationRepository) MarkOccurrencesDeliveredRepo(occurrenceIDs []int, deliveredAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOccurrencesDeliveredRepo", occurrenceIDs, deliveredAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOccurrencesDeliveredRepo indicates an expected call of MarkOccurrencesDeliveredRepo.
+func (mr *MockNotificationRepositoryMockRecorder) MarkOccurrencesDeliveredRepo(occurrenceIDs, deliveredAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOccurrencesDeliveredRepo", reflect.TypeOf((*MockNotificationRepository)(nil).MarkOccurrencesDeliveredRepo), occurrenceIDs, deliveredAt)
+}
+
+// RearmPriceAlertRepo mocks base method.
+func (m *MockNotificationRepository) RearmPriceAlertRepo(notificationID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RearmPriceAlertRepo", notificationID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RearmPriceAlertRepo indicates an expected call of RearmPriceAlertRepo.
+func (mr *MockNotificationRepositoryMockRecorder) RearmPriceAlertRepo(notificationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RearmPriceAlertRepo", reflect.TypeOf((*MockNotificationRepository)(nil).RearmPriceAlertRepo), notificationID)
+}
+
+// SaveDigestRepo mocks base method.
+func (m *MockNotificationRepository) SaveDigestRepo(username, body string, createdAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveDigestRepo", username, body, createdAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveDigestRepo indicates an expected call of SaveDigestRepo.
+func (mr *MockNotificationRepositoryMockRecorder) SaveDigestRepo(username, body, createdAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDigestRepo", reflect.TypeOf((*MockNotificationRepository)(nil).SaveDigestRepo), username, body, createdAt)
+}
+
+// SaveDigestSettingsRepo mocks base method.
+func (m *MockNotificationRepository) SaveDigestSettingsRepo(settings *models.DigestSettings) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveDigestSettingsRepo", settings)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveDigestSettingsRepo indicates an expected call of SaveDigestSettingsRepo.
+func (mr *MockNotificationRepositoryMockRecorder) SaveDigestSettingsRepo(settings interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDigestSettingsRepo", reflect.TypeOf((*MockNotificationRepository)(nil).SaveDigestSettingsRepo), settings)
+}
+
+// SaveMarketSnapshotsRepo mocks base method.
+func (m *MockNotificationRepository) SaveMarketSnapshotsRepo(quotes map[string]*models.Quote, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveMarketSnapshotsRepo", quotes, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveMarketSnapshotsRepo indicates an expected call of SaveMarketSnapshotsRepo.
+func (mr *MockNotificationRepositoryMockRecorder) SaveMarketSnapshotsRepo(quotes, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMarketSnapshotsRepo", reflect.TypeOf((*MockNotificationRepository)(nil).SaveMarketSnapshotsRepo), quotes, now)
+}
+
+// TakeUndeliveredDigestsRepo mocks base method.
+func (m *MockNotificationRepository) TakeUndeliveredDigestsRepo(username string, deliveredAt time.Time) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeUndeliveredDigestsRepo", username, deliveredAt)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeUndeliveredDigestsRepo indicates an expected call of TakeUndeliveredDigestsRepo.
+func (mr *MockNotificationRepositoryMockRecorder) TakeUndeliveredDigestsRepo(username, deliveredAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeUndeliveredDigestsRepo", reflect.TypeOf((*MockNotificationRepository)(nil).TakeUndeliveredDigestsRepo), username, deliveredAt)
+}
+
+// UpdateHighWaterMarkRepo mocks base method.
+func (m *MockNotificationRepository) UpdateHighWaterMarkRepo(notificationID int, highWaterMark float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHighWaterMarkRepo", notificationID, highWaterMark)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateHighWaterMarkRepo indicates an expected call of UpdateHighWaterMarkRepo.
+func (mr *MockNotificationRepositoryMockRecorder) UpdateHighWaterMarkRepo(notificationID, highWaterMark interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHighWaterMarkRepo", reflect.TypeOf((*MockNotificationRepository)(nil).UpdateHighWaterMarkRepo), notificationID, highWaterMark)
+}
+
 // UpdatePriceNotificationStatusRepo mocks base method.
 func (m *MockNotificationRepository) UpdatePriceNotificationStatusRepo(notification *models.PriceNotification, username string, currentPrice float64) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,180 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: C:\Users\akawadia\Downloads\CryptoTracker\internal\repositories\stablecoin_repository.go
+
+// Package mock_repositories is a generated GoMock package.
+package mock_repositories
+
+import (
+	models "cryptotracker/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockStablecoinRepository is a mock of StablecoinRepository interface.
+type MockStablecoinRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockStablecoinRepositoryMockRecorder
+}
+
+// MockStablecoinRepositoryMockRecorder is the mock recorder for MockStablecoinRepository.
+type MockStablecoinRepositoryMockRecorder struct {
+	mock *MockStablecoinRepository
+}
+
+// NewMockStablecoinRepository creates a new mock instance.
+func NewMockStablecoinRepository(ctrl *gomock.Controller) *MockStablecoinRepository {
+	mock := &MockStablecoinRepository{ctrl: ctrl}
+	mock.recorder = &MockStablecoinRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStablecoinRepository) EXPECT() *MockStablecoinRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeletePeg mocks base method.
+func (m *MockStablecoinRepository) DeletePeg(symbol string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePeg", symbol)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePeg indicates an expected call of DeletePeg.
+func (mr *MockStablecoinRepositoryMockRecorder) DeletePeg(symbol interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePeg", reflect.TypeOf((*MockStablecoinRepository)(nil).DeletePeg), symbol)
+}
+
+// GetDepegRecipients mocks base method.
+func (m *MockStablecoinRepository) GetDepegRecipients() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDepegRecipients")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDepegRecipients indicates an expected call of GetDepegRecipients.
+func (mr *MockStablecoinRepositoryMockRecorder) GetDepegRecipients() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDepegRecipients", reflect.TypeOf((*MockStablecoinRepository)(nil).GetDepegRecipients))
+}
+
+// GetLatestQuotes mocks base method.
+func (m *MockStablecoinRepository) GetLatestQuotes() (map[string]*models.Quote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestQuotes")
+	ret0, _ := ret[0].(map[string]*models.Quote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestQuotes indicates an expected call of GetLatestQuotes.
+func (mr *MockStablecoinRepositoryMockRecorder) GetLatestQuotes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestQuotes", reflect.TypeOf((*MockStablecoinRepository)(nil).GetLatestQuotes))
+}
+
+// GetPegs mocks base method.
+func (m *MockStablecoinRepository) GetPegs() ([]*models.StablecoinPeg, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPegs")
+	ret0, _ := ret[0].([]*models.StablecoinPeg)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPegs indicates an expected call of GetPegs.
+func (mr *MockStablecoinRepositoryMockRecorder) GetPegs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPegs", reflect.TypeOf((*MockStablecoinRepository)(nil).GetPegs))
+}
+
+// IsSubscribed mocks base method.
+func (m *MockStablecoinRepository) IsSubscribed(username string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsSubscribed", username)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsSubscribed indicates an expected call of IsSubscribed.
+func (mr *MockStablecoinRepositoryMockRecorder) IsSubscribed(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsSubscribed", reflect.TypeOf((*MockStablecoinRepository)(nil).IsSubscribed), username)
+}
+
+// RecordPegEvent mocks base method.
+func (m *MockStablecoinRepository) RecordPegEvent(peg *models.StablecoinPeg, kind string, recipients []string, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordPegEvent", peg, kind, recipients, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordPegEvent indicates an expected call of RecordPegEvent.
+func (mr *MockStablecoinRepositoryMockRecorder) RecordPegEvent(peg, kind, recipients, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordPegEvent", reflect.TypeOf((*MockStablecoinRepository)(nil).RecordPegEvent), peg, kind, recipients, now)
+}
+
+// SavePeg mocks base method.
+func (m *MockStablecoinRepository) SavePeg(peg *models.StablecoinPeg) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePeg", peg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePeg indicates an expected call of SavePeg.
+func (mr *MockStablecoinRepositoryMockRecorder) SavePeg(peg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePeg", reflect.TypeOf((*MockStablecoinRepository)(nil).SavePeg), peg)
+}
+
+// Subscribe mocks base method.
+func (m *MockStablecoinRepository) Subscribe(username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockStablecoinRepositoryMockRecorder) Subscribe(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockStablecoinRepository)(nil).Subscribe), username)
+}
+
+// Unsubscribe mocks base method.
+func (m *MockStablecoinRepository) Unsubscribe(username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unsubscribe", username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unsubscribe indicates an expected call of Unsubscribe.
+func (mr *MockStablecoinRepositoryMockRecorder) Unsubscribe(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockStablecoinRepository)(nil).Unsubscribe), username)
+}
+
+// UpdatePegState mocks base method.
+func (m *MockStablecoinRepository) UpdatePegState(peg *models.StablecoinPeg) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePegState", peg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePegState indicates an expected call of UpdatePegState.
+func (mr *MockStablecoinRepositoryMockRecorder) UpdatePegState(peg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePegState", reflect.TypeOf((*MockStablecoinRepository)(nil).UpdatePegState), peg)
+}
@@ -7,6 +7,7 @@ package mock_repositories
 import (
 	models "cryptotracker/models"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -34,6 +35,65 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ClearLoginAttempts mocks base method.
+func (m *MockUserRepository) ClearLoginAttempts(key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearLoginAttempts", key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearLoginAttempts indicates an expected call of ClearLoginAttempts.
+func (mr *MockUserRepositoryMockRecorder) ClearLoginAttempts(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearLoginAttempts", reflect.TypeOf((*MockUserRepository)(nil).ClearLoginAttempts), key)
+}
+
+// GetLoginAttempts mocks base method.
+func (m *MockUserRepository) GetLoginAttempts(keys []string) ([]*models.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginAttempts", keys)
+	ret0, _ := ret[0].([]*models.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginAttempts indicates an expected call of GetLoginAttempts.
+func (mr *MockUserRepositoryMockRecorder) GetLoginAttempts(keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginAttempts", reflect.TypeOf((*MockUserRepository)(nil).GetLoginAttempts), keys)
+}
+
+// GetNotificationPreferences mocks base method.
+func (m *MockUserRepository) GetNotificationPreferences(username string) (*models.NotificationPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationPreferences", username)
+	ret0, _ := ret[0].(*models.NotificationPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationPreferences indicates an expected call of GetNotificationPreferences.
+func (mr *MockUserRepositoryMockRecorder) GetNotificationPreferences(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationPreferences", reflect.TypeOf((*MockUserRepository)(nil).GetNotificationPreferences), username)
+}
+
+// GetPasswordHash mocks base method.
+func (m *MockUserRepository) GetPasswordHash(username string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordHash", username)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordHash indicates an expected call of GetPasswordHash.
+func (mr *MockUserRepositoryMockRecorder) GetPasswordHash(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordHash", reflect.TypeOf((*MockUserRepository)(nil).GetPasswordHash), username)
+}
+
 // GetUserProfile mocks base method.
 func (m *MockUserRepository) GetUserProfile(username string) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -48,3 +108,70 @@ func (mr *MockUserRepositoryMockRecorder) GetUserProfile(username interface{}) *
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserProfile", reflect.TypeOf((*MockUserRepository)(nil).GetUserProfile), username)
 }
+
+// LockLogin mocks base method.
+func (m *MockUserRepository) LockLogin(key string, until time.Time, events ...*models.AuditEvent) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{key, until}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "LockLogin", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockLogin indicates an expected call of LockLogin.
+func (mr *MockUserRepositoryMockRecorder) LockLogin(key, until interface{}, events ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{key, until}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockLogin", reflect.TypeOf((*MockUserRepository)(nil).LockLogin), varargs...)
+}
+
+// RecordLoginFailure mocks base method.
+func (m *MockUserRepository) RecordLoginFailure(key string, now, windowStart time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordLoginFailure", key, now, windowStart)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordLoginFailure indicates an expected call of RecordLoginFailure.
+func (mr *MockUserRepositoryMockRecorder) RecordLoginFailure(key, now, windowStart interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLoginFailure", reflect.TypeOf((*MockUserRepository)(nil).RecordLoginFailure), key, now, windowStart)
+}
+
+// UpdateNotificationPreferences mocks base method.
+func (m *MockUserRepository) UpdateNotificationPreferences(username string, preferences *models.NotificationPreferences) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNotificationPreferences", username, preferences)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateNotificationPreferences indicates an expected call of UpdateNotificationPreferences.
+func (mr *MockUserRepositoryMockRecorder) UpdateNotificationPreferences(username, preferences interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNotificationPreferences", reflect.TypeOf((*MockUserRepository)(nil).UpdateNotificationPreferences), username, preferences)
+}
+
+// UpdateProfile mocks base method.
+func (m *MockUserRepository) UpdateProfile(username string, changes *models.ProfileChanges, now time.Time, events ...*models.AuditEvent) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{username, changes, now}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateProfile", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile.
+func (mr *MockUserRepositoryMockRecorder) UpdateProfile(username, changes, now interface{}, events ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{username, changes, now}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockUserRepository)(nil).UpdateProfile), varargs...)
+}
@@ -6,7 +6,9 @@ package mock_services
 
 import (
 	models "cryptotracker/models"
+	io "io"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -34,89 +36,251 @@ func (m *MockAdminService) EXPECT() *MockAdminServiceMockRecorder {
 	return m.recorder
 }
 
-// ChangeUserStatus mocks base method.
-func (m *MockAdminService) ChangeUserStatus(username string) error {
+// ChangeUserRole mocks base method.
+func (m *MockAdminService) ChangeUserRole(username, role, actor, source string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ChangeUserStatus", username)
+	ret := m.ctrl.Call(m, "ChangeUserRole", username, role, actor, source)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// ChangeUserStatus indicates an expected call of ChangeUserStatus.
-func (mr *MockAdminServiceMockRecorder) ChangeUserStatus(username interface{}) *gomock.Call {
+// ChangeUserRole indicates an expected call of ChangeUserRole.
+func (mr *MockAdminServiceMockRecorder) ChangeUserRole(username, role, actor, source interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeUserStatus", reflect.TypeOf((*MockAdminService)(nil).ChangeUserStatus), username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeUserRole", reflect.TypeOf((*MockAdminService)(nil).ChangeUserRole), username, role, actor, source)
 }
 
-// DeleteUser mocks base method.
-func (m *MockAdminService) DeleteUser(username string) error {
+// DeactivateUser mocks base method.
+func (m *MockAdminService) DeactivateUser(username, actor, source string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteUser", username)
+	ret := m.ctrl.Call(m, "DeactivateUser", username, actor, source)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// DeactivateUser indicates an expected call of DeactivateUser.
+func (mr *MockAdminServiceMockRecorder) DeactivateUser(username, actor, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateUser", reflect.TypeOf((*MockAdminService)(nil).DeactivateUser), username, actor, source)
+}
+
+// DeleteUser mocks base method.
+func (m *MockAdminService) DeleteUser(username, actor, source string) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", username, actor, source)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // DeleteUser indicates an expected call of DeleteUser.
-func (mr *MockAdminServiceMockRecorder) DeleteUser(username interface{}) *gomock.Call {
+func (mr *MockAdminServiceMockRecorder) DeleteUser(username, actor, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockAdminService)(nil).DeleteUser), username, actor, source)
+}
+
+// ExportAuditLog mocks base method.
+func (m *MockAdminService) ExportAuditLog(w io.Writer, filter *models.AuditFilter, format, actor, source string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportAuditLog", w, filter, format, actor, source)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportAuditLog indicates an expected call of ExportAuditLog.
+func (mr *MockAdminServiceMockRecorder) ExportAuditLog(w, filter, format, actor, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportAuditLog", reflect.TypeOf((*MockAdminService)(nil).ExportAuditLog), w, filter, format, actor, source)
+}
+
+// ForceLogout mocks base method.
+func (m *MockAdminService) ForceLogout(username, actor, source string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForceLogout", username, actor, source)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ForceLogout indicates an expected call of ForceLogout.
+func (mr *MockAdminServiceMockRecorder) ForceLogout(username, actor, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForceLogout", reflect.TypeOf((*MockAdminService)(nil).ForceLogout), username, actor, source)
+}
+
+// GetAuditLog mocks base method.
+func (m *MockAdminService) GetAuditLog(filter *models.AuditFilter, actor string) ([]*models.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLog", filter, actor)
+	ret0, _ := ret[0].([]*models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLog indicates an expected call of GetAuditLog.
+func (mr *MockAdminServiceMockRecorder) GetAuditLog(filter, actor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLog", reflect.TypeOf((*MockAdminService)(nil).GetAuditLog), filter, actor)
+}
+
+// GetLockedLogins mocks base method.
+func (m *MockAdminService) GetLockedLogins(actor string) ([]*models.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLockedLogins", actor)
+	ret0, _ := ret[0].([]*models.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLockedLogins indicates an expected call of GetLockedLogins.
+func (mr *MockAdminServiceMockRecorder) GetLockedLogins(actor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLockedLogins", reflect.TypeOf((*MockAdminService)(nil).GetLockedLogins), actor)
+}
+
+// IsTwoFactorRequired mocks base method.
+func (m *MockAdminService) IsTwoFactorRequired(role string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsTwoFactorRequired", role)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsTwoFactorRequired indicates an expected call of IsTwoFactorRequired.
+func (mr *MockAdminServiceMockRecorder) IsTwoFactorRequired(role interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockAdminService)(nil).DeleteUser), username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTwoFactorRequired", reflect.TypeOf((*MockAdminService)(nil).IsTwoFactorRequired), role)
 }
 
 // ManageSpecificCryptoRequests mocks base method.
-func (m *MockAdminService) ManageSpecificCryptoRequests(cryptoSymbol string) ([]*models.UnavailableCryptoRequest, error) {
+func (m *MockAdminService) ManageSpecificCryptoRequests(cryptoSymbol, actor string) ([]*models.UnavailableCryptoRequest, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ManageSpecificCryptoRequests", cryptoSymbol)
+	ret := m.ctrl.Call(m, "ManageSpecificCryptoRequests", cryptoSymbol, actor)
 	ret0, _ := ret[0].([]*models.UnavailableCryptoRequest)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ManageSpecificCryptoRequests indicates an expected call of ManageSpecificCryptoRequests.
-func (mr *MockAdminServiceMockRecorder) ManageSpecificCryptoRequests(cryptoSymbol interface{}) *gomock.Call {
+func (mr *MockAdminServiceMockRecorder) ManageSpecificCryptoRequests(cryptoSymbol, actor interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ManageSpecificCryptoRequests", reflect.TypeOf((*MockAdminService)(nil).ManageSpecificCryptoRequests), cryptoSymbol)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ManageSpecificCryptoRequests", reflect.TypeOf((*MockAdminService)(nil).ManageSpecificCryptoRequests), cryptoSymbol, actor)
 }
 
 // ManageUserRequests mocks base method.
-func (m *MockAdminService) ManageUserRequests() ([]*models.UnavailableCryptoRequest, error) {
+func (m *MockAdminService) ManageUserRequests(actor string) ([]*models.UnavailableCryptoRequest, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ManageUserRequests")
+	ret := m.ctrl.Call(m, "ManageUserRequests", actor)
 	ret0, _ := ret[0].([]*models.UnavailableCryptoRequest)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ManageUserRequests indicates an expected call of ManageUserRequests.
-func (mr *MockAdminServiceMockRecorder) ManageUserRequests() *gomock.Call {
+func (mr *MockAdminServiceMockRecorder) ManageUserRequests(actor interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ManageUserRequests", reflect.TypeOf((*MockAdminService)(nil).ManageUserRequests))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ManageUserRequests", reflect.TypeOf((*MockAdminService)(nil).ManageUserRequests), actor)
+}
+
+// PurgeDeletedUsers mocks base method.
+func (m *MockAdminService) PurgeDeletedUsers(now time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedUsers", now)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedUsers indicates an expected call of PurgeDeletedUsers.
+func (mr *MockAdminServiceMockRecorder) PurgeDeletedUsers(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedUsers", reflect.TypeOf((*MockAdminService)(nil).PurgeDeletedUsers), now)
+}
+
+// RestoreUser mocks base method.
+func (m *MockAdminService) RestoreUser(username, actor, source string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreUser", username, actor, source)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreUser indicates an expected call of RestoreUser.
+func (mr *MockAdminServiceMockRecorder) RestoreUser(username, actor, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreUser", reflect.TypeOf((*MockAdminService)(nil).RestoreUser), username, actor, source)
+}
+
+// SetTwoFactorRequired mocks base method.
+func (m *MockAdminService) SetTwoFactorRequired(role string, required bool, actor, source string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTwoFactorRequired", role, required, actor, source)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTwoFactorRequired indicates an expected call of SetTwoFactorRequired.
+func (mr *MockAdminServiceMockRecorder) SetTwoFactorRequired(role, required, actor, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTwoFactorRequired", reflect.TypeOf((*MockAdminService)(nil).SetTwoFactorRequired), role, required, actor, source)
+}
+
+// UnlockLogin mocks base method.
+func (m *MockAdminService) UnlockLogin(key, actor, source string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockLogin", key, actor, source)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlockLogin indicates an expected call of UnlockLogin.
+func (mr *MockAdminServiceMockRecorder) UnlockLogin(key, actor, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockLogin", reflect.TypeOf((*MockAdminService)(nil).UnlockLogin), key, actor, source)
 }
 
 // UpdateRequestStatus mocks base method.
-func (m *MockAdminService) UpdateRequestStatus(requests []*models.UnavailableCryptoRequest, status string) error {
+func (m *MockAdminService) UpdateRequestStatus(requests []*models.UnavailableCryptoRequest, status, actor, source string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateRequestStatus", requests, status)
+	ret := m.ctrl.Call(m, "UpdateRequestStatus", requests, status, actor, source)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateRequestStatus indicates an expected call of UpdateRequestStatus.
-func (mr *MockAdminServiceMockRecorder) UpdateRequestStatus(requests, status interface{}) *gomock.Call {
+func (mr *MockAdminServiceMockRecorder) UpdateRequestStatus(requests, status, actor, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRequestStatus", reflect.TypeOf((*MockAdminService)(nil).UpdateRequestStatus), requests, status, actor, source)
+}
+
+// VerifyAuditLog mocks base method.
+func (m *MockAdminService) VerifyAuditLog(actor string) (*models.AuditVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyAuditLog", actor)
+	ret0, _ := ret[0].(*models.AuditVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyAuditLog indicates an expected call of VerifyAuditLog.
+func (mr *MockAdminServiceMockRecorder) VerifyAuditLog(actor interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRequestStatus", reflect.TypeOf((*MockAdminService)(nil).UpdateRequestStatus), requests, status)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyAuditLog", reflect.TypeOf((*MockAdminService)(nil).VerifyAuditLog), actor)
 }
 
 // ViewUserProfiles mocks base method.
-func (m *MockAdminService) ViewUserProfiles() ([]*models.User, error) {
+func (m *MockAdminService) ViewUserProfiles(actor string) ([]*models.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ViewUserProfiles")
+	ret := m.ctrl.Call(m, "ViewUserProfiles", actor)
 	ret0, _ := ret[0].([]*models.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ViewUserProfiles indicates an expected call of ViewUserProfiles.
-func (mr *MockAdminServiceMockRecorder) ViewUserProfiles() *gomock.Call {
+func (mr *MockAdminServiceMockRecorder) ViewUserProfiles(actor interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ViewUserProfiles", reflect.TypeOf((*MockAdminService)(nil).ViewUserProfiles))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ViewUserProfiles", reflect.TypeOf((*MockAdminService)(nil).ViewUserProfiles), actor)
 }
@@ -5,6 +5,7 @@
 package mock_services
 
 import (
+	services "cryptotracker/internal/services"
 	models "cryptotracker/models"
 	reflect "reflect"
 
@@ -34,10 +35,54 @@ func (m *MockAuthService) EXPECT() *MockAuthServiceMockRecorder {
 	return m.recorder
 }
 
+// BeginTwoFactorEnrollment mocks base method.
+func (m *MockAuthService) BeginTwoFactorEnrollment(username string) (*services.TwoFactorEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginTwoFactorEnrollment", username)
+	ret0, _ := ret[0].(*services.TwoFactorEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginTwoFactorEnrollment indicates an expected call of BeginTwoFactorEnrollment.
+func (mr *MockAuthServiceMockRecorder) BeginTwoFactorEnrollment(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginTwoFactorEnrollment", reflect.TypeOf((*MockAuthService)(nil).BeginTwoFactorEnrollment), username)
+}
+
+// ConfirmTwoFactorEnrollment mocks base method.
+func (m *MockAuthService) ConfirmTwoFactorEnrollment(username, code, source string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTwoFactorEnrollment", username, code, source)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmTwoFactorEnrollment indicates an expected call of ConfirmTwoFactorEnrollment.
+func (mr *MockAuthServiceMockRecorder) ConfirmTwoFactorEnrollment(username, code, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTwoFactorEnrollment", reflect.TypeOf((*MockAuthService)(nil).ConfirmTwoFactorEnrollment), username, code, source)
+}
+
+// DisableTwoFactor mocks base method.
+func (m *MockAuthService) DisableTwoFactor(username, code, clientIP string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTwoFactor", username, code, clientIP)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTwoFactor indicates an expected call of DisableTwoFactor.
+func (mr *MockAuthServiceMockRecorder) DisableTwoFactor(username, code, clientIP interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTwoFactor", reflect.TypeOf((*MockAuthService)(nil).DisableTwoFactor), username, code, clientIP)
+}
+
 // Login mocks base method.
-func (m *MockAuthService) Login(username, password string) (*models.User, string, error) {
+func (m *MockAuthService) Login(username, password, clientIP string) (*models.User, string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Login", username, password)
+	ret := m.ctrl.Call(m, "Login", username, password, clientIP)
 	ret0, _ := ret[0].(*models.User)
 	ret1, _ := ret[1].(string)
 	ret2, _ := ret[2].(error)
@@ -45,9 +90,51 @@ func (m *MockAuthService) Login(username, password string) (*models.User, string
 }
 
 // Login indicates an expected call of Login.
-func (mr *MockAuthServiceMockRecorder) Login(username, password interface{}) *gomock.Call {
+func (mr *MockAuthServiceMockRecorder) Login(username, password, clientIP interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockAuthService)(nil).Login), username, password, clientIP)
+}
+
+// RequestPasswordReset mocks base method.
+func (m *MockAuthService) RequestPasswordReset(identifier string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestPasswordReset", identifier)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestPasswordReset indicates an expected call of RequestPasswordReset.
+func (mr *MockAuthServiceMockRecorder) RequestPasswordReset(identifier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestPasswordReset", reflect.TypeOf((*MockAuthService)(nil).RequestPasswordReset), identifier)
+}
+
+// ResetPassword mocks base method.
+func (m *MockAuthService) ResetPassword(code, newPassword, source string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", code, newPassword, source)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockAuthServiceMockRecorder) ResetPassword(code, newPassword, source interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockAuthService)(nil).Login), username, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockAuthService)(nil).ResetPassword), code, newPassword, source)
+}
+
+// SendEmailVerification mocks base method.
+func (m *MockAuthService) SendEmailVerification(username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendEmailVerification", username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendEmailVerification indicates an expected call of SendEmailVerification.
+func (mr *MockAuthServiceMockRecorder) SendEmailVerification(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEmailVerification", reflect.TypeOf((*MockAuthService)(nil).SendEmailVerification), username)
 }
 
 // Signup mocks base method.
@@ -63,3 +150,46 @@ func (mr *MockAuthServiceMockRecorder) Signup(user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Signup", reflect.TypeOf((*MockAuthService)(nil).Signup), user)
 }
+
+// TwoFactorRequired mocks base method.
+func (m *MockAuthService) TwoFactorRequired(user *models.User) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TwoFactorRequired", user)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TwoFactorRequired indicates an expected call of TwoFactorRequired.
+func (mr *MockAuthServiceMockRecorder) TwoFactorRequired(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TwoFactorRequired", reflect.TypeOf((*MockAuthService)(nil).TwoFactorRequired), user)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockAuthService) VerifyEmail(code, source string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", code, source)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockAuthServiceMockRecorder) VerifyEmail(code, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockAuthService)(nil).VerifyEmail), code, source)
+}
+
+// VerifySecondFactor mocks base method.
+func (m *MockAuthService) VerifySecondFactor(username, code, clientIP string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifySecondFactor", username, code, clientIP)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifySecondFactor indicates an expected call of VerifySecondFactor.
+func (mr *MockAuthServiceMockRecorder) VerifySecondFactor(username, code, clientIP interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifySecondFactor", reflect.TypeOf((*MockAuthService)(nil).VerifySecondFactor), username, code, clientIP)
+}
@@ -34,6 +34,20 @@ func (m *MockCryptoService) EXPECT() *MockCryptoServiceMockRecorder {
 	return m.recorder
 }
 
+// DeleteAlertGroup mocks base method.
+func (m *MockCryptoService) DeleteAlertGroup(username string, groupID int, actor string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAlertGroup", username, groupID, actor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAlertGroup indicates an expected call of DeleteAlertGroup.
+func (mr *MockCryptoServiceMockRecorder) DeleteAlertGroup(username, groupID, actor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAlertGroup", reflect.TypeOf((*MockCryptoService)(nil).DeleteAlertGroup), username, groupID, actor)
+}
+
 // DisplayTopCryptocurrencies mocks base method.
 func (m *MockCryptoService) DisplayTopCryptocurrencies(count int) ([]interface{}, error) {
 	m.ctrl.T.Helper()
@@ -49,6 +63,21 @@ func (mr *MockCryptoServiceMockRecorder) DisplayTopCryptocurrencies(count interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisplayTopCryptocurrencies", reflect.TypeOf((*MockCryptoService)(nil).DisplayTopCryptocurrencies), count)
 }
 
+// GetAlertGroups mocks base method.
+func (m *MockCryptoService) GetAlertGroups(username, actor string) ([]*models.AlertGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlertGroups", username, actor)
+	ret0, _ := ret[0].([]*models.AlertGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAlertGroups indicates an expected call of GetAlertGroups.
+func (mr *MockCryptoServiceMockRecorder) GetAlertGroups(username, actor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlertGroups", reflect.TypeOf((*MockCryptoService)(nil).GetAlertGroups), username, actor)
+}
+
 // SearchCryptocurrency mocks base method.
 func (m *MockCryptoService) SearchCryptocurrency(user *models.User, cryptoSymbol string) (float64, string, string, error) {
 	m.ctrl.T.Helper()
@@ -66,17 +95,106 @@ func (mr *MockCryptoServiceMockRecorder) SearchCryptocurrency(user, cryptoSymbol
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchCryptocurrency", reflect.TypeOf((*MockCryptoService)(nil).SearchCryptocurrency), user, cryptoSymbol)
 }
 
+// SetAlertGroupStatus mocks base method.
+func (m *MockCryptoService) SetAlertGroupStatus(username string, groupID int, status, actor string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAlertGroupStatus", username, groupID, status, actor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAlertGroupStatus indicates an expected call of SetAlertGroupStatus.
+func (mr *MockCryptoServiceMockRecorder) SetAlertGroupStatus(username, groupID, status, actor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAlertGroupStatus", reflect.TypeOf((*MockCryptoService)(nil).SetAlertGroupStatus), username, groupID, status, actor)
+}
+
+// SetLadderAlert mocks base method.
+func (m *MockCryptoService) SetLadderAlert(user *models.User, symbol string, levels []float64, options *models.AlertOptions) (*models.AlertGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLadderAlert", user, symbol, levels, options)
+	ret0, _ := ret[0].(*models.AlertGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetLadderAlert indicates an expected call of SetLadderAlert.
+func (mr *MockCryptoServiceMockRecorder) SetLadderAlert(user, symbol, levels, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLadderAlert", reflect.TypeOf((*MockCryptoService)(nil).SetLadderAlert), user, symbol, levels, options)
+}
+
 // SetPriceAlert mocks base method.
-func (m *MockCryptoService) SetPriceAlert(user *models.User, symbol string, targetPrice float64) (float64, error) {
+func (m *MockCryptoService) SetPriceAlert(user *models.User, symbol string, targetPrice float64, options *models.AlertOptions) (float64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetPriceAlert", user, symbol, targetPrice)
+	ret := m.ctrl.Call(m, "SetPriceAlert", user, symbol, targetPrice, options)
 	ret0, _ := ret[0].(float64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SetPriceAlert indicates an expected call of SetPriceAlert.
-func (mr *MockCryptoServiceMockRecorder) SetPriceAlert(user, symbol, targetPrice interface{}) *gomock.Call {
+func (mr *MockCryptoServiceMockRecorder) SetPriceAlert(user, symbol, targetPrice, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPriceAlert", reflect.TypeOf((*MockCryptoService)(nil).SetPriceAlert), user, symbol, targetPrice, options)
+}
+
+// SetRankAlert mocks base method.
+func (m *MockCryptoService) SetRankAlert(user *models.User, symbol string, threshold int, options *models.AlertOptions) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRankAlert", user, symbol, threshold, options)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetRankAlert indicates an expected call of SetRankAlert.
+func (mr *MockCryptoServiceMockRecorder) SetRankAlert(user, symbol, threshold, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRankAlert", reflect.TypeOf((*MockCryptoService)(nil).SetRankAlert), user, symbol, threshold, options)
+}
+
+// SetRuleAlert mocks base method.
+func (m *MockCryptoService) SetRuleAlert(user *models.User, expression string, options *models.AlertOptions) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRuleAlert", user, expression, options)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetRuleAlert indicates an expected call of SetRuleAlert.
+func (mr *MockCryptoServiceMockRecorder) SetRuleAlert(user, expression, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRuleAlert", reflect.TypeOf((*MockCryptoService)(nil).SetRuleAlert), user, expression, options)
+}
+
+// SetTrailingAlert mocks base method.
+func (m *MockCryptoService) SetTrailingAlert(user *models.User, symbol string, trailPercent float64, options *models.AlertOptions) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTrailingAlert", user, symbol, trailPercent, options)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetTrailingAlert indicates an expected call of SetTrailingAlert.
+func (mr *MockCryptoServiceMockRecorder) SetTrailingAlert(user, symbol, trailPercent, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailingAlert", reflect.TypeOf((*MockCryptoService)(nil).SetTrailingAlert), user, symbol, trailPercent, options)
+}
+
+// SetVolumeSpikeAlert mocks base method.
+func (m *MockCryptoService) SetVolumeSpikeAlert(user *models.User, symbol string, multiplier float64, options *models.AlertOptions) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetVolumeSpikeAlert", user, symbol, multiplier, options)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetVolumeSpikeAlert indicates an expected call of SetVolumeSpikeAlert.
+func (mr *MockCryptoServiceMockRecorder) SetVolumeSpikeAlert(user, symbol, multiplier, options interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPriceAlert", reflect.TypeOf((*MockCryptoService)(nil).SetPriceAlert), user, symbol, targetPrice)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVolumeSpikeAlert", reflect.TypeOf((*MockCryptoService)(nil).SetVolumeSpikeAlert), user, symbol, multiplier, options)
 }
@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: C:\Users\akawadia\Downloads\CryptoTracker\internal\services\delivery_services.go
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	models "cryptotracker/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDeliveryService is a mock of DeliveryService interface.
+type MockDeliveryService struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeliveryServiceMockRecorder
+}
+
+// MockDeliveryServiceMockRecorder is the mock recorder for MockDeliveryService.
+type MockDeliveryServiceMockRecorder struct {
+	mock *MockDeliveryService
+}
+
+// NewMockDeliveryService creates a new mock instance.
+func NewMockDeliveryService(ctrl *gomock.Controller) *MockDeliveryService {
+	mock := &MockDeliveryService{ctrl: ctrl}
+	mock.recorder = &MockDeliveryServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeliveryService) EXPECT() *MockDeliveryServiceMockRecorder {
+	return m.recorder
+}
+
+// GetFailedDeliveries mocks base method.
+func (m *MockDeliveryService) GetFailedDeliveries() ([]*models.NotificationDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFailedDeliveries")
+	ret0, _ := ret[0].([]*models.NotificationDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFailedDeliveries indicates an expected call of GetFailedDeliveries.
+func (mr *MockDeliveryServiceMockRecorder) GetFailedDeliveries() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFailedDeliveries", reflect.TypeOf((*MockDeliveryService)(nil).GetFailedDeliveries))
+}
+
+// ProcessDueDeliveries mocks base method.
+func (m *MockDeliveryService) ProcessDueDeliveries(now time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessDueDeliveries", now)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProcessDueDeliveries indicates an expected call of ProcessDueDeliveries.
+func (mr *MockDeliveryServiceMockRecorder) ProcessDueDeliveries(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessDueDeliveries", reflect.TypeOf((*MockDeliveryService)(nil).ProcessDueDeliveries), now)
+}
+
+// RequeueDelivery mocks base method.
+func (m *MockDeliveryService) RequeueDelivery(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueDelivery", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequeueDelivery indicates an expected call of RequeueDelivery.
+func (mr *MockDeliveryServiceMockRecorder) RequeueDelivery(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueDelivery", reflect.TypeOf((*MockDeliveryService)(nil).RequeueDelivery), id)
+}
@@ -6,7 +6,9 @@ package mock_services
 
 import (
 	services "cryptotracker/internal/services"
+	models "cryptotracker/models"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -64,6 +66,21 @@ func (mr *MockNotificationServiceMockRecorder) CheckPriceAlertService(username i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckPriceAlertService", reflect.TypeOf((*MockNotificationService)(nil).CheckPriceAlertService), username)
 }
 
+// CheckSessionNotifications mocks base method.
+func (m *MockNotificationService) CheckSessionNotifications(username string) ([]services.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckSessionNotifications", username)
+	ret0, _ := ret[0].([]services.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckSessionNotifications indicates an expected call of CheckSessionNotifications.
+func (mr *MockNotificationServiceMockRecorder) CheckSessionNotifications(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckSessionNotifications", reflect.TypeOf((*MockNotificationService)(nil).CheckSessionNotifications), username)
+}
+
 // CheckUnavailableCryptoRequestsService mocks base method.
 func (m *MockNotificationService) CheckUnavailableCryptoRequestsService(username string) ([]services.Notification, error) {
 	m.ctrl.T.Helper()
@@ -78,3 +95,90 @@ func (mr *MockNotificationServiceMockRecorder) CheckUnavailableCryptoRequestsSer
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckUnavailableCryptoRequestsService", reflect.TypeOf((*MockNotificationService)(nil).CheckUnavailableCryptoRequestsService), username)
 }
+
+// GetAlertOccurrences mocks base method.
+func (m *MockNotificationService) GetAlertOccurrences(username string) ([]models.AlertOccurrence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlertOccurrences", username)
+	ret0, _ := ret[0].([]models.AlertOccurrence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAlertOccurrences indicates an expected call of GetAlertOccurrences.
+func (mr *MockNotificationServiceMockRecorder) GetAlertOccurrences(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlertOccurrences", reflect.TypeOf((*MockNotificationService)(nil).GetAlertOccurrences), username)
+}
+
+// GetDigestSettings mocks base method.
+func (m *MockNotificationService) GetDigestSettings(username string) (*models.DigestSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDigestSettings", username)
+	ret0, _ := ret[0].(*models.DigestSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDigestSettings indicates an expected call of GetDigestSettings.
+func (mr *MockNotificationServiceMockRecorder) GetDigestSettings(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDigestSettings", reflect.TypeOf((*MockNotificationService)(nil).GetDigestSettings), username)
+}
+
+// NotifyRequestDecisions mocks base method.
+func (m *MockNotificationService) NotifyRequestDecisions(requests []*models.UnavailableCryptoRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyRequestDecisions", requests)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NotifyRequestDecisions indicates an expected call of NotifyRequestDecisions.
+func (mr *MockNotificationServiceMockRecorder) NotifyRequestDecisions(requests interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyRequestDecisions", reflect.TypeOf((*MockNotificationService)(nil).NotifyRequestDecisions), requests)
+}
+
+// PreviewDigest mocks base method.
+func (m *MockNotificationService) PreviewDigest(username string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewDigest", username)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewDigest indicates an expected call of PreviewDigest.
+func (mr *MockNotificationServiceMockRecorder) PreviewDigest(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewDigest", reflect.TypeOf((*MockNotificationService)(nil).PreviewDigest), username)
+}
+
+// SaveDigestSettings mocks base method.
+func (m *MockNotificationService) SaveDigestSettings(settings *models.DigestSettings) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveDigestSettings", settings)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveDigestSettings indicates an expected call of SaveDigestSettings.
+func (mr *MockNotificationServiceMockRecorder) SaveDigestSettings(settings interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDigestSettings", reflect.TypeOf((*MockNotificationService)(nil).SaveDigestSettings), settings)
+}
+
+// SendDueDigests mocks base method.
+func (m *MockNotificationService) SendDueDigests(now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDueDigests", now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendDueDigests indicates an expected call of SendDueDigests.
+func (mr *MockNotificationServiceMockRecorder) SendDueDigests(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDueDigests", reflect.TypeOf((*MockNotificationService)(nil).SendDueDigests), now)
+}
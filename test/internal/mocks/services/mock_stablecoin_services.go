@@ -0,0 +1,136 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: C:\Users\akawadia\Downloads\CryptoTracker\internal\services\stablecoin_services.go
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	models "cryptotracker/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockStablecoinService is a mock of StablecoinService interface.
+type MockStablecoinService struct {
+	ctrl     *gomock.Controller
+	recorder *MockStablecoinServiceMockRecorder
+}
+
+// MockStablecoinServiceMockRecorder is the mock recorder for MockStablecoinService.
+type MockStablecoinServiceMockRecorder struct {
+	mock *MockStablecoinService
+}
+
+// NewMockStablecoinService creates a new mock instance.
+func NewMockStablecoinService(ctrl *gomock.Controller) *MockStablecoinService {
+	mock := &MockStablecoinService{ctrl: ctrl}
+	mock.recorder = &MockStablecoinServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStablecoinService) EXPECT() *MockStablecoinServiceMockRecorder {
+	return m.recorder
+}
+
+// CheckPegs mocks base method.
+func (m *MockStablecoinService) CheckPegs(now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckPegs", now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckPegs indicates an expected call of CheckPegs.
+func (mr *MockStablecoinServiceMockRecorder) CheckPegs(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckPegs", reflect.TypeOf((*MockStablecoinService)(nil).CheckPegs), now)
+}
+
+// DeletePeg mocks base method.
+func (m *MockStablecoinService) DeletePeg(symbol string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePeg", symbol)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePeg indicates an expected call of DeletePeg.
+func (mr *MockStablecoinServiceMockRecorder) DeletePeg(symbol interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePeg", reflect.TypeOf((*MockStablecoinService)(nil).DeletePeg), symbol)
+}
+
+// GetPegs mocks base method.
+func (m *MockStablecoinService) GetPegs() ([]*models.StablecoinPeg, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPegs")
+	ret0, _ := ret[0].([]*models.StablecoinPeg)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPegs indicates an expected call of GetPegs.
+func (mr *MockStablecoinServiceMockRecorder) GetPegs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPegs", reflect.TypeOf((*MockStablecoinService)(nil).GetPegs))
+}
+
+// IsSubscribed mocks base method.
+func (m *MockStablecoinService) IsSubscribed(username string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsSubscribed", username)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsSubscribed indicates an expected call of IsSubscribed.
+func (mr *MockStablecoinServiceMockRecorder) IsSubscribed(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsSubscribed", reflect.TypeOf((*MockStablecoinService)(nil).IsSubscribed), username)
+}
+
+// SavePeg mocks base method.
+func (m *MockStablecoinService) SavePeg(peg *models.StablecoinPeg) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePeg", peg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePeg indicates an expected call of SavePeg.
+func (mr *MockStablecoinServiceMockRecorder) SavePeg(peg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePeg", reflect.TypeOf((*MockStablecoinService)(nil).SavePeg), peg)
+}
+
+// Subscribe mocks base method.
+func (m *MockStablecoinService) Subscribe(username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockStablecoinServiceMockRecorder) Subscribe(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockStablecoinService)(nil).Subscribe), username)
+}
+
+// Unsubscribe mocks base method.
+func (m *MockStablecoinService) Unsubscribe(username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unsubscribe", username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unsubscribe indicates an expected call of Unsubscribe.
+func (mr *MockStablecoinServiceMockRecorder) Unsubscribe(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockStablecoinService)(nil).Unsubscribe), username)
+}
@@ -0,0 +1,273 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: C:\Users\akawadia\Downloads\CryptoTracker\internal\services\token_services.go
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	services "cryptotracker/internal/services"
+	models "cryptotracker/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTokenService is a mock of TokenService interface.
+type MockTokenService struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenServiceMockRecorder
+}
+
+// MockTokenServiceMockRecorder is the mock recorder for MockTokenService.
+type MockTokenServiceMockRecorder struct {
+	mock *MockTokenService
+}
+
+// NewMockTokenService creates a new mock instance.
+func NewMockTokenService(ctrl *gomock.Controller) *MockTokenService {
+	mock := &MockTokenService{ctrl: ctrl}
+	mock.recorder = &MockTokenServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenService) EXPECT() *MockTokenServiceMockRecorder {
+	return m.recorder
+}
+
+// CheckSession mocks base method.
+func (m *MockTokenService) CheckSession(sessionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckSession", sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckSession indicates an expected call of CheckSession.
+func (mr *MockTokenServiceMockRecorder) CheckSession(sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckSession", reflect.TypeOf((*MockTokenService)(nil).CheckSession), sessionID)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockTokenService) CreateAPIKey(username, name string, scopes []string, expiresInDays int, source string) (*models.APIKey, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", username, name, scopes, expiresInDays, source)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockTokenServiceMockRecorder) CreateAPIKey(username, name, scopes, expiresInDays, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockTokenService)(nil).CreateAPIKey), username, name, scopes, expiresInDays, source)
+}
+
+// IssueTokens mocks base method.
+func (m *MockTokenService) IssueTokens(username, role, clientIP string) (*services.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueTokens", username, role, clientIP)
+	ret0, _ := ret[0].(*services.TokenPair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueTokens indicates an expected call of IssueTokens.
+func (mr *MockTokenServiceMockRecorder) IssueTokens(username, role, clientIP interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueTokens", reflect.TypeOf((*MockTokenService)(nil).IssueTokens), username, role, clientIP)
+}
+
+// IssueTwoFactorChallenge mocks base method.
+func (m *MockTokenService) IssueTwoFactorChallenge(username, role string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueTwoFactorChallenge", username, role)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueTwoFactorChallenge indicates an expected call of IssueTwoFactorChallenge.
+func (mr *MockTokenServiceMockRecorder) IssueTwoFactorChallenge(username, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueTwoFactorChallenge", reflect.TypeOf((*MockTokenService)(nil).IssueTwoFactorChallenge), username, role)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockTokenService) ListAPIKeys(username string) ([]*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", username)
+	ret0, _ := ret[0].([]*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockTokenServiceMockRecorder) ListAPIKeys(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockTokenService)(nil).ListAPIKeys), username)
+}
+
+// ListSessions mocks base method.
+func (m *MockTokenService) ListSessions(username string) ([]*models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessions", username)
+	ret0, _ := ret[0].([]*models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockTokenServiceMockRecorder) ListSessions(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockTokenService)(nil).ListSessions), username)
+}
+
+// PurgeExpiredTokens mocks base method.
+func (m *MockTokenService) PurgeExpiredTokens(now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpiredTokens", now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeExpiredTokens indicates an expected call of PurgeExpiredTokens.
+func (mr *MockTokenServiceMockRecorder) PurgeExpiredTokens(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpiredTokens", reflect.TypeOf((*MockTokenService)(nil).PurgeExpiredTokens), now)
+}
+
+// RefreshTokens mocks base method.
+func (m *MockTokenService) RefreshTokens(refreshToken string) (*services.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshTokens", refreshToken)
+	ret0, _ := ret[0].(*services.TokenPair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshTokens indicates an expected call of RefreshTokens.
+func (mr *MockTokenServiceMockRecorder) RefreshTokens(refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshTokens", reflect.TypeOf((*MockTokenService)(nil).RefreshTokens), refreshToken)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockTokenService) RevokeAPIKey(username string, id int, source string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", username, id, source)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockTokenServiceMockRecorder) RevokeAPIKey(username, id, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockTokenService)(nil).RevokeAPIKey), username, id, source)
+}
+
+// RevokeSession mocks base method.
+func (m *MockTokenService) RevokeSession(username, sessionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", username, sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockTokenServiceMockRecorder) RevokeSession(username, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockTokenService)(nil).RevokeSession), username, sessionID)
+}
+
+// RevokeTokens mocks base method.
+func (m *MockTokenService) RevokeTokens(claims *services.TokenClaims, refreshToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeTokens", claims, refreshToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeTokens indicates an expected call of RevokeTokens.
+func (mr *MockTokenServiceMockRecorder) RevokeTokens(claims, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeTokens", reflect.TypeOf((*MockTokenService)(nil).RevokeTokens), claims, refreshToken)
+}
+
+// RevokeUserSessions mocks base method.
+func (m *MockTokenService) RevokeUserSessions(username string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserSessions", username)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeUserSessions indicates an expected call of RevokeUserSessions.
+func (mr *MockTokenServiceMockRecorder) RevokeUserSessions(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserSessions", reflect.TypeOf((*MockTokenService)(nil).RevokeUserSessions), username)
+}
+
+// StartSession mocks base method.
+func (m *MockTokenService) StartSession(username, client, clientIP string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartSession", username, client, clientIP)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartSession indicates an expected call of StartSession.
+func (mr *MockTokenServiceMockRecorder) StartSession(username, client, clientIP interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartSession", reflect.TypeOf((*MockTokenService)(nil).StartSession), username, client, clientIP)
+}
+
+// ValidateAPIKey mocks base method.
+func (m *MockTokenService) ValidateAPIKey(apiKey string) (*services.TokenClaims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateAPIKey", apiKey)
+	ret0, _ := ret[0].(*services.TokenClaims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ValidateAPIKey indicates an expected call of ValidateAPIKey.
+func (mr *MockTokenServiceMockRecorder) ValidateAPIKey(apiKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateAPIKey", reflect.TypeOf((*MockTokenService)(nil).ValidateAPIKey), apiKey)
+}
+
+// ValidateAccessToken mocks base method.
+func (m *MockTokenService) ValidateAccessToken(accessToken string) (*services.TokenClaims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateAccessToken", accessToken)
+	ret0, _ := ret[0].(*services.TokenClaims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ValidateAccessToken indicates an expected call of ValidateAccessToken.
+func (mr *MockTokenServiceMockRecorder) ValidateAccessToken(accessToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateAccessToken", reflect.TypeOf((*MockTokenService)(nil).ValidateAccessToken), accessToken)
+}
+
+// ValidateTwoFactorChallenge mocks base method.
+func (m *MockTokenService) ValidateTwoFactorChallenge(challengeToken string) (*services.TokenClaims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateTwoFactorChallenge", challengeToken)
+	ret0, _ := ret[0].(*services.TokenClaims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ValidateTwoFactorChallenge indicates an expected call of ValidateTwoFactorChallenge.
+func (mr *MockTokenServiceMockRecorder) ValidateTwoFactorChallenge(challengeToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateTwoFactorChallenge", reflect.TypeOf((*MockTokenService)(nil).ValidateTwoFactorChallenge), challengeToken)
+}
@@ -5,6 +5,7 @@
 package mock_services
 
 import (
+	services "cryptotracker/internal/services"
 	models "cryptotracker/models"
 	reflect "reflect"
 
@@ -34,6 +35,21 @@ func (m *MockUserServices) EXPECT() *MockUserServicesMockRecorder {
 	return m.recorder
 }
 
+// GetNotificationPreferences mocks base method.
+func (m *MockUserServices) GetNotificationPreferences(username string) (*models.NotificationPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationPreferences", username)
+	ret0, _ := ret[0].(*models.NotificationPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationPreferences indicates an expected call of GetNotificationPreferences.
+func (mr *MockUserServicesMockRecorder) GetNotificationPreferences(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationPreferences", reflect.TypeOf((*MockUserServices)(nil).GetNotificationPreferences), username)
+}
+
 // GetUserProfile mocks base method.
 func (m *MockUserServices) GetUserProfile(username string) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -48,3 +64,32 @@ func (mr *MockUserServicesMockRecorder) GetUserProfile(username interface{}) *go
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserProfile", reflect.TypeOf((*MockUserServices)(nil).GetUserProfile), username)
 }
+
+// UpdateNotificationPreferences mocks base method.
+func (m *MockUserServices) UpdateNotificationPreferences(username string, preferences *models.NotificationPreferences) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNotificationPreferences", username, preferences)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateNotificationPreferences indicates an expected call of UpdateNotificationPreferences.
+func (mr *MockUserServicesMockRecorder) UpdateNotificationPreferences(username, preferences interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNotificationPreferences", reflect.TypeOf((*MockUserServices)(nil).UpdateNotificationPreferences), username, preferences)
+}
+
+// UpdateProfile mocks base method.
+func (m *MockUserServices) UpdateProfile(username string, update *services.ProfileUpdate, source string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", username, update, source)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile.
+func (mr *MockUserServicesMockRecorder) UpdateProfile(username, update, source interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockUserServices)(nil).UpdateProfile), username, update, source)
+}
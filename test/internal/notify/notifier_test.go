@@ -0,0 +1,89 @@
+package notify_test
+
+import (
+	"cryptotracker/internal/notify"
+	"cryptotracker/pkg/config"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("webhook body is not JSON: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := &notify.WebhookNotifier{Client: server.Client()}
+	message := notify.Message{Event: notify.EventPriceAlert, Subject: "BTC", Body: "BTC reached $60000.00"}
+	if err := notifier.Send(notify.Recipient{Username: "alice", WebhookURL: server.URL}, message); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if received["event"] != notify.EventPriceAlert || received["body"] != message.Body || received["username"] != "alice" {
+		t.Errorf("webhook received %v", received)
+	}
+}
+
+func TestWebhookNotifierFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	notifier := &notify.WebhookNotifier{Client: server.Client()}
+	if err := notifier.Send(notify.Recipient{WebhookURL: server.URL}, notify.Message{}); err == nil {
+		t.Errorf("Send() error = nil; expected the 500 response to be reported")
+	}
+	if err := notifier.Send(notify.Recipient{}, notify.Message{}); err == nil {
+		t.Errorf("Send() error = nil; expected a missing webhook URL to be reported")
+	}
+}
+
+func TestSMSNotifier(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("gateway body is not JSON: %v", err)
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("Authorization = %q; expected the gateway API key", r.Header.Get("Authorization"))
+		}
+	}))
+	defer server.Close()
+
+	notifier := &notify.SMSNotifier{Client: server.Client(), GatewayURL: server.URL, APIKey: "secret"}
+	message := notify.Message{Event: notify.EventPriceAlert, Body: "BTC reached $60000.00"}
+	if err := notifier.Send(notify.Recipient{Mobile: "+442079460958"}, message); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if received["to"] != "+442079460958" || received["text"] != message.Body {
+		t.Errorf("gateway received %v", received)
+	}
+
+	if err := notifier.Send(notify.Recipient{}, message); err == nil {
+		t.Errorf("Send() error = nil; expected a missing mobile number to be reported")
+	}
+}
+
+func TestNotifiersFromConfig(t *testing.T) {
+	notifiers := notify.NotifiersFromConfig(config.Config{})
+	if _, ok := notifiers[notify.ChannelWebhook]; !ok {
+		t.Errorf("webhook notifier missing from an empty configuration")
+	}
+	if _, ok := notifiers[notify.ChannelEmail]; ok {
+		t.Errorf("email notifier enabled without an SMTP host")
+	}
+
+	notifiers = notify.NotifiersFromConfig(config.Config{SMTPHost: "smtp.example.com", SMSGatewayURL: "https://sms.example.com"})
+	for _, channel := range []string{notify.ChannelEmail, notify.ChannelSMS, notify.ChannelWebhook} {
+		if _, ok := notifiers[channel]; !ok {
+			t.Errorf("%s notifier missing", channel)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package notify_test
+
+import (
+	"cryptotracker/internal/notify"
+	"cryptotracker/models"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefs   models.NotificationPreferences
+		wantErr bool
+	}{
+		{"defaults", *notify.DefaultPreferences(), false},
+		{"email and webhook", models.NotificationPreferences{
+			Channels:   map[string][]string{notify.EventPriceAlert: {notify.ChannelEmail, notify.ChannelWebhook}},
+			WebhookURL: "https://example.com/hook",
+		}, false},
+		{"unknown event", models.NotificationPreferences{Channels: map[string][]string{"weather": {notify.ChannelInApp}}}, true},
+		{"unknown channel", models.NotificationPreferences{Channels: map[string][]string{notify.EventDigest: {"pager"}}}, true},
+		{"webhook without URL", models.NotificationPreferences{Channels: map[string][]string{notify.EventDigest: {notify.ChannelWebhook}}}, true},
+		{"bad webhook URL", models.NotificationPreferences{WebhookURL: "ftp://example.com"}, true},
+		{"half quiet hours", models.NotificationPreferences{QuietFrom: "22:00"}, true},
+		{"bad quiet hours", models.NotificationPreferences{QuietFrom: "22:00", QuietTo: "7am"}, true},
+		{"negative cap", models.NotificationPreferences{MaxPerHour: -1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := notify.Validate(&tt.prefs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	prefs := notify.Normalize(&models.NotificationPreferences{
+		Channels: map[string][]string{notify.EventPriceAlert: {notify.ChannelEmail}},
+	})
+
+	if got := notify.ChannelsFor(prefs, notify.EventPriceAlert); len(got) != 1 || got[0] != notify.ChannelEmail {
+		t.Errorf("ChannelsFor(price_alert) = %v; expected [email]", got)
+	}
+	if !notify.WantsInApp(prefs, notify.EventDigest) {
+		t.Errorf("WantsInApp(digest) = false; events missing from the preferences should default to in-app")
+	}
+	if notify.WantsInApp(prefs, notify.EventPriceAlert) {
+		t.Errorf("WantsInApp(price_alert) = true; expected the stored choice to be kept")
+	}
+	if !notify.WantsInApp(nil, notify.EventPriceAlert) {
+		t.Errorf("WantsInApp() with no preferences = false; expected in-app by default")
+	}
+}
+
+func TestAllow(t *testing.T) {
+	prefs := &models.NotificationPreferences{QuietFrom: "22:00", QuietTo: "07:00", MaxPerHour: 3}
+	night := time.Date(2024, 9, 20, 23, 30, 0, 0, time.UTC)
+	day := time.Date(2024, 9, 20, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		channel string
+		now     time.Time
+		sent    int
+		allowed bool
+	}{
+		{"daytime email", notify.ChannelEmail, day, 0, true},
+		{"quiet hours email", notify.ChannelEmail, night, 0, false},
+		{"quiet hours in-app", notify.ChannelInApp, night, 0, true},
+		{"cap reached", notify.ChannelSMS, day, 3, false},
+		{"cap reached in-app", notify.ChannelInApp, day, 3, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, reason := notify.Allow(prefs, tt.channel, time.UTC, tt.now, tt.sent)
+			if allowed != tt.allowed {
+				t.Errorf("Allow() = %v (%s); expected %v", allowed, reason, tt.allowed)
+			}
+			if !allowed && reason == "" {
+				t.Errorf("Allow() refused without a reason")
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package notify_test
+
+import (
+	"cryptotracker/internal/notify"
+	"cryptotracker/models"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{0, 0},
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{6, 32 * time.Minute},
+		{7, time.Hour},
+		{20, time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := notify.Backoff(tt.attempts); got != tt.expected {
+			t.Errorf("Backoff(%d) = %v; expected %v", tt.attempts, got, tt.expected)
+		}
+	}
+}
+
+func TestRecordFailure(t *testing.T) {
+	now := time.Date(2024, 9, 20, 12, 0, 0, 0, time.UTC)
+	delivery := &models.NotificationDelivery{Status: notify.DeliverySending, MaxAttempts: 3}
+
+	notify.RecordFailure(delivery, errors.New("connection refused"), now)
+	if delivery.Status != notify.DeliveryPending || delivery.Attempts != 1 {
+		t.Fatalf("after one failure: status %q, attempts %d; expected pending, 1", delivery.Status, delivery.Attempts)
+	}
+	if !delivery.NextAttemptAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("NextAttemptAt = %v; expected %v", delivery.NextAttemptAt, now.Add(time.Minute))
+	}
+	if delivery.Error != "connection refused" {
+		t.Errorf("Error = %q; expected the failure to be recorded", delivery.Error)
+	}
+
+	notify.RecordFailure(delivery, errors.New("connection refused"), now)
+	if !delivery.NextAttemptAt.Equal(now.Add(2 * time.Minute)) {
+		t.Errorf("NextAttemptAt after two failures = %v; expected %v", delivery.NextAttemptAt, now.Add(2*time.Minute))
+	}
+
+	notify.RecordFailure(delivery, errors.New("connection refused"), now)
+	if delivery.Status != notify.DeliveryDead {
+		t.Errorf("status after the last attempt = %q; expected dead", delivery.Status)
+	}
+}
+
+func TestRecordFailureDefaultMaxAttempts(t *testing.T) {
+	delivery := &models.NotificationDelivery{}
+	for i := 0; i < notify.DefaultMaxAttempts-1; i++ {
+		notify.RecordFailure(delivery, errors.New("timeout"), time.Now())
+	}
+	if delivery.Status != notify.DeliveryPending {
+		t.Fatalf("status = %q before the attempts ran out; expected pending", delivery.Status)
+	}
+	notify.RecordFailure(delivery, errors.New("timeout"), time.Now())
+	if delivery.Status != notify.DeliveryDead {
+		t.Errorf("status = %q after %d failures; expected dead", delivery.Status, notify.DefaultMaxAttempts)
+	}
+}
+
+func TestQuietHoursEnd(t *testing.T) {
+	prefs := &models.NotificationPreferences{QuietFrom: "22:00", QuietTo: "07:00"}
+	loc := time.FixedZone("IST", 5*3600+1800)
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		expected time.Time
+	}{
+		{"late evening", time.Date(2024, 9, 20, 23, 15, 0, 0, loc), time.Date(2024, 9, 21, 7, 0, 0, 0, loc)},
+		{"early morning", time.Date(2024, 9, 21, 3, 0, 0, 0, loc), time.Date(2024, 9, 21, 7, 0, 0, 0, loc)},
+		{"other timezone", time.Date(2024, 9, 20, 17, 0, 0, 0, time.UTC), time.Date(2024, 9, 21, 7, 0, 0, 0, loc)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notify.QuietHoursEnd(prefs, loc, tt.now); !got.Equal(tt.expected) {
+				t.Errorf("QuietHoursEnd() = %v; expected %v", got, tt.expected)
+			}
+		})
+	}
+}
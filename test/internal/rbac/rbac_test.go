@@ -0,0 +1,94 @@
+package rbac_test
+
+import (
+	"cryptotracker/internal/rbac"
+	"strings"
+	"testing"
+)
+
+func TestCan(t *testing.T) {
+	tests := []struct {
+		role       string
+		permission rbac.Permission
+		expected   bool
+	}{
+		{rbac.Viewer, rbac.AlertsManage, false},
+		{rbac.User, rbac.AlertsManage, true},
+		{rbac.User, rbac.AdminPanel, false},
+		{rbac.Analyst, rbac.RequestsApprove, true},
+		{rbac.Analyst, rbac.UsersView, false},
+		{rbac.Support, rbac.AlertsManageAny, true},
+		{rbac.Support, rbac.UsersDelete, false},
+		{rbac.Admin, rbac.UsersDelete, true},
+		{rbac.Owner, rbac.SecurityManage, true},
+		{rbac.Admin, rbac.AuditView, true},
+		{rbac.Support, rbac.AuditView, false},
+		{rbac.Owner, rbac.DepegAlerts, true},
+		{rbac.Analyst, rbac.DepegAlerts, false},
+		{"superuser", rbac.AlertsManage, false},
+		{"", rbac.AlertsManage, false},
+	}
+
+	for _, tt := range tests {
+		if got := rbac.Can(tt.role, tt.permission); got != tt.expected {
+			t.Errorf("Can(%q, %s) = %v; expected %v", tt.role, tt.permission, got, tt.expected)
+		}
+	}
+}
+
+func TestRolesWith(t *testing.T) {
+	if got := strings.Join(rbac.RolesWith(rbac.DepegAlerts), ","); got != "admin,owner" {
+		t.Errorf("RolesWith(%s) = %s; expected admin,owner", rbac.DepegAlerts, got)
+	}
+	if got := rbac.RolesWith("unknown.permission"); len(got) != 0 {
+		t.Errorf("RolesWith(unknown) = %v; expected none", got)
+	}
+}
+
+func TestCanManage(t *testing.T) {
+	tests := []struct {
+		actor    string
+		target   string
+		expected bool
+	}{
+		{rbac.Support, rbac.User, true},
+		{rbac.Support, rbac.Support, false},
+		{rbac.Admin, rbac.Owner, false},
+		{rbac.Owner, rbac.Owner, true},
+		{"", rbac.Viewer, false},
+	}
+
+	for _, tt := range tests {
+		if got := rbac.CanManage(tt.actor, tt.target); got != tt.expected {
+			t.Errorf("CanManage(%q, %q) = %v; expected %v", tt.actor, tt.target, got, tt.expected)
+		}
+	}
+}
+
+func TestCanAssign(t *testing.T) {
+	tests := []struct {
+		name     string
+		actor    string
+		from     string
+		to       string
+		expected bool
+	}{
+		{"admin promotes user to analyst", rbac.Admin, rbac.User, rbac.Analyst, true},
+		{"admin promotes user to admin", rbac.Admin, rbac.User, rbac.Admin, true},
+		{"admin demotes support to viewer", rbac.Admin, rbac.Support, rbac.Viewer, true},
+		{"admin cannot make an owner", rbac.Admin, rbac.User, rbac.Owner, false},
+		{"admin cannot demote another admin", rbac.Admin, rbac.Admin, rbac.User, false},
+		{"owner demotes an admin", rbac.Owner, rbac.Admin, rbac.User, true},
+		{"owner demotes another owner", rbac.Owner, rbac.Owner, rbac.Admin, true},
+		{"owner cannot assign an unknown role", rbac.Owner, rbac.User, "superuser", false},
+		{"support cannot change roles", rbac.Support, rbac.Viewer, rbac.User, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rbac.CanAssign(tt.actor, tt.from, tt.to); got != tt.expected {
+				t.Errorf("CanAssign(%s, %s, %s) = %v; expected %v", tt.actor, tt.from, tt.to, got, tt.expected)
+			}
+		})
+	}
+}
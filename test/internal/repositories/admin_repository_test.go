@@ -40,7 +40,7 @@ func setupTestDB() (*pgx.Conn, func(), error) {
 			userid TEXT NOT NULL,
 			username TEXT NOT NULL,
 			email TEXT NOT NULL,
-			mobile BIGINT NOT NULL,
+			mobile TEXT NOT NULL,
 			isadmin BOOLEAN NOT NULL,
 			role TEXT NOT NULL
 		);
@@ -59,6 +59,32 @@ func setupTestDB() (*pgx.Conn, func(), error) {
 			status TEXT NOT NULL,
 			timestamp TIMESTAMPTZ NOT NULL
 		);
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active';
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS purge_after TIMESTAMPTZ;
+		CREATE TABLE IF NOT EXISTS sessions (
+			id TEXT,
+			username TEXT NOT NULL,
+			expires_at TIMESTAMPTZ,
+			revoked_at TIMESTAMPTZ
+		);
+		CREATE TABLE IF NOT EXISTS refresh_tokens (
+			username TEXT NOT NULL,
+			revoked_at TIMESTAMPTZ
+		);
+		CREATE TABLE IF NOT EXISTS audit_log (
+			id SERIAL PRIMARY KEY,
+			actor TEXT NOT NULL,
+			action TEXT NOT NULL,
+			target TEXT NOT NULL DEFAULT '',
+			details TEXT NOT NULL DEFAULT '',
+			before_value TEXT NOT NULL DEFAULT '',
+			after_value TEXT NOT NULL DEFAULT '',
+			source TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			prev_hash TEXT NOT NULL DEFAULT '',
+			hash TEXT NOT NULL DEFAULT ''
+		);
+		TRUNCATE users, price_notifications, unavailable_cryptos, sessions, refresh_tokens, audit_log;
 	`)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to set up database schema: %v", err)
@@ -67,54 +93,150 @@ func setupTestDB() (*pgx.Conn, func(), error) {
 	return conn, cleanup, nil
 }
 
-func TestChangeUserStatus(t *testing.T) {
+func TestSetUserRole(t *testing.T) {
 	conn, cleanup, err := setupTestDB()
 	require.NoError(t, err)
 	defer cleanup()
 
 	repo := repositories.NewPostgresAdminRepository(conn)
 
-	// Insert a user with a non-admin role
+	// Insert a user with a non-admin role, signed in once
 	_, err = conn.Exec(context.Background(), "INSERT INTO users (userid, username, email, mobile, isadmin, role) VALUES ($1, $2, $3, $4, $5, $6)",
-		"0", "testuser", "testuser@example.com", "1234567890", false, "user")
+		"0", "testuser", "testuser@example.com", "+911234567890", false, "user")
 	require.NoError(t, err)
-
-	// Change the user's role to admin
-	err = repo.ChangeUserStatus(conn, "testuser")
+	_, err = conn.Exec(context.Background(), "INSERT INTO sessions (id, username, expires_at) VALUES ($1, $2, $3)", "s1", "testuser", time.Now().Add(time.Hour))
 	require.NoError(t, err)
 
-	// Verify the user role has been updated to admin
-	row := conn.QueryRow(context.Background(), "SELECT role FROM users WHERE username=$1", "testuser")
-	var role string
-	err = row.Scan(&role)
+	roleOf := func() (string, bool) {
+		var role string
+		var isAdmin bool
+		err := conn.QueryRow(context.Background(), "SELECT role, isadmin FROM users WHERE username=$1", "testuser").Scan(&role, &isAdmin)
+		require.NoError(t, err)
+		return role, isAdmin
+	}
+	liveSessions := func() int {
+		var count int
+		err := conn.QueryRow(context.Background(), "SELECT COUNT(*) FROM sessions WHERE username=$1 AND revoked_at IS NULL", "testuser").Scan(&count)
+		require.NoError(t, err)
+		return count
+	}
+
+	// A promotion keeps the user's sessions and is audited with the change
+	err = repo.SetUserRole("testuser", "admin", &models.AuditEvent{Actor: "root", Action: "role.promoted", Target: "testuser", CreatedAt: time.Now()})
 	require.NoError(t, err)
+	role, isAdmin := roleOf()
 	assert.Equal(t, "admin", role)
+	assert.True(t, isAdmin)
+	assert.Equal(t, 1, liveSessions())
+
+	// A demotion ends them, so tokens carrying the old role stop working
+	err = repo.SetUserRole("testuser", "user")
+	require.NoError(t, err)
+	role, isAdmin = roleOf()
+	assert.Equal(t, "user", role)
+	assert.False(t, isAdmin)
+	assert.Equal(t, 0, liveSessions())
 
-	// Test failure case: user is already an admin
-	err = repo.ChangeUserStatus(conn, "testuser")
-	assert.EqualError(t, err, "user is already an admin")
+	var audited int
+	err = conn.QueryRow(context.Background(), "SELECT COUNT(*) FROM audit_log WHERE target=$1", "testuser").Scan(&audited)
+	require.NoError(t, err)
+	assert.Equal(t, 1, audited)
 
 	// Test failure case: user not found
-	err = repo.ChangeUserStatus(conn, "nonexistentuser")
+	err = repo.SetUserRole("nonexistentuser", "admin")
 	assert.EqualError(t, err, "user not found")
 }
 
-func TestDeleteUser(t *testing.T) {
+// purgeTables are the tables PurgeUser clears, with the column that holds the username.
+var purgeTables = map[string]string{
+	"price_alert_occurrences":   "username",
+	"price_notifications":       "username",
+	"alert_groups":              "username",
+	"unavailable_cryptos":       "username",
+	"depeg_subscriptions":       "username",
+	"digest_settings":           "username",
+	"digests":                   "username",
+	"notification_deliveries":   "username",
+	"refresh_tokens":            "username",
+	"api_keys":                  "username",
+	"sessions":                  "username",
+	"login_attempts":            "key",
+	"password_reset_tokens":     "username",
+	"email_verification_tokens": "username",
+	"recovery_codes":            "username",
+	"user_two_factor":           "username",
+}
+
+func TestPurgeUser(t *testing.T) {
 	conn, cleanup, err := setupTestDB()
 	require.NoError(t, err)
 	defer cleanup()
 
+	_, err = conn.Exec(context.Background(), `
+		ALTER TABLE price_notifications ALTER COLUMN crypto_symbol SET DEFAULT '';
+		ALTER TABLE price_notifications ALTER COLUMN target_price SET DEFAULT 0;
+		ALTER TABLE price_notifications ALTER COLUMN timestamp SET DEFAULT NOW();
+		ALTER TABLE unavailable_cryptos ALTER COLUMN crypto_symbol SET DEFAULT '';
+		ALTER TABLE unavailable_cryptos ALTER COLUMN request_message SET DEFAULT '';
+		ALTER TABLE unavailable_cryptos ALTER COLUMN status SET DEFAULT '';
+		ALTER TABLE unavailable_cryptos ALTER COLUMN timestamp SET DEFAULT NOW();
+	`)
+	require.NoError(t, err)
+	for table, column := range purgeTables {
+		_, err = conn.Exec(context.Background(), fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s TEXT NOT NULL)", table, column))
+		require.NoError(t, err)
+		_, err = conn.Exec(context.Background(), fmt.Sprintf("TRUNCATE %s", table))
+		require.NoError(t, err)
+	}
+
+	now := time.Now()
+	insertUser := func(username string, purgeAfter time.Time) {
+		_, err := conn.Exec(context.Background(), "INSERT INTO users (userid, username, email, mobile, isadmin, role, status, purge_after) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+			"0", username, username+"@example.com", "+911234567890", false, "user", "deleted", purgeAfter)
+		require.NoError(t, err)
+		for table, column := range purgeTables {
+			value := username
+			if column == "key" {
+				value = "user:" + username
+			}
+			_, err := conn.Exec(context.Background(), fmt.Sprintf("INSERT INTO %s (%s) VALUES ($1)", table, column), value)
+			require.NoError(t, err)
+		}
+	}
+	insertUser("purgeduser", now.Add(-time.Hour))
+	insertUser("graceuser", now.Add(time.Hour))
+
 	repo := repositories.NewPostgresAdminRepository(conn)
+	purgeEvent := func(username string) *models.AuditEvent {
+		return &models.AuditEvent{Actor: "system", Action: "user.purged", Target: username, Source: "system", CreatedAt: now}
+	}
 
-	_, err = conn.Exec(context.Background(), "INSERT INTO users (userid, username, email, mobile, isadmin, role) VALUES ($1, $2, $3, $4, $5, $6)",
-		"0", "testuser", "testuser@example.com", "1234567890", false, "user")
+	purged, err := repo.PurgeUser("purgeduser", now, purgeEvent("purgeduser"))
 	require.NoError(t, err)
+	assert.True(t, purged)
 
-	err = repo.DeleteUser(conn, "testuser")
-	assert.NoError(t, err)
+	// A user still within the grace period keeps everything, and nothing is audited
+	purged, err = repo.PurgeUser("graceuser", now, purgeEvent("graceuser"))
+	require.NoError(t, err)
+	assert.False(t, purged)
 
-	// Test failure case: transaction error
-	// This would require further configuration, such as simulating transaction errors
+	rowsFor := func(table, column, username string) int {
+		if column == "key" {
+			username = "user:" + username
+		}
+		var count int
+		err := conn.QueryRow(context.Background(), fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = $1", table, column), username).Scan(&count)
+		require.NoError(t, err)
+		return count
+	}
+	assert.Equal(t, 0, rowsFor("users", "username", "purgeduser"))
+	assert.Equal(t, 1, rowsFor("users", "username", "graceuser"))
+	assert.Equal(t, 1, rowsFor("audit_log", "target", "purgeduser"))
+	assert.Equal(t, 0, rowsFor("audit_log", "target", "graceuser"))
+	for table, column := range purgeTables {
+		assert.Equal(t, 0, rowsFor(table, column, "purgeduser"), "rows left in %s", table)
+		assert.Equal(t, 1, rowsFor(table, column, "graceuser"), "rows removed from %s", table)
+	}
 }
 
 func TestViewUserProfiles(t *testing.T) {
@@ -126,16 +248,16 @@ func TestViewUserProfiles(t *testing.T) {
 
 	// Insert a user with admin role for completeness
 	_, err = conn.Exec(context.Background(), "INSERT INTO users (userid, username, email, mobile, isadmin, role) VALUES ($1, $2, $3, $4, $5, $6)",
-		"0", "adminuser", "adminuser@example.com", 1234567890, true, "admin")
+		"0", "adminuser", "adminuser@example.com", "+911234567890", true, "admin")
 	require.NoError(t, err)
 
 	// Insert a user with non-admin role for testing
 	_, err = conn.Exec(context.Background(), "INSERT INTO users (userid, username, email, mobile, isadmin, role) VALUES ($1, $2, $3, $4, $5, $6)",
-		"0", "testuser", "testuser@example.com", 987654321, false, "user")
+		"0", "testuser", "testuser@example.com", "+919876543210", false, "user")
 	require.NoError(t, err)
 
 	// Fetch user profiles
-	profiles, err := repo.ViewUserProfiles(conn)
+	profiles, err := repo.ViewUserProfiles()
 	if err != nil {
 		t.Fatalf("Failed to fetch user profiles: %v", err)
 	}
@@ -151,7 +273,7 @@ func TestViewUserProfiles(t *testing.T) {
 			if profile.Username == "testuser" {
 				found = true
 				assert.Equal(t, "testuser@example.com", profile.Email)
-				assert.Equal(t, 987654321, profile.Mobile)
+				assert.Equal(t, "+919876543210", profile.Mobile)
 				assert.False(t, profile.IsAdmin)
 				assert.Equal(t, "user", profile.Role)
 				break
@@ -172,29 +294,61 @@ func TestManageUserRequests(t *testing.T) {
 		"BTC", "testuser", "Request message", "pending", time.Now())
 	require.NoError(t, err)
 
-	requests, err := repo.ManageUserRequests(conn)
+	requests, err := repo.ManageUserRequests()
 	assert.NoError(t, err)
 	assert.Len(t, requests, 1)
 	assert.Equal(t, "BTC", requests[0].CryptoSymbol)
 }
 
-func TestSaveUnavailableCryptoRequest(t *testing.T) {
+func TestUpdateRequestStatus(t *testing.T) {
 	conn, cleanup, err := setupTestDB()
 	require.NoError(t, err)
 	defer cleanup()
 
 	repo := repositories.NewPostgresAdminRepository(conn)
 
-	request := &models.UnavailableCryptoRequest{
-		CryptoSymbol:   "BTC",
-		UserName:       "testuser",
-		RequestMessage: "Request message",
-		Status:         "pending",
-		Timestamp:      time.Now(),
+	for _, username := range []string{"alice", "bob"} {
+		_, err = conn.Exec(context.Background(), "INSERT INTO unavailable_cryptos (crypto_symbol, username, request_message, status, timestamp) VALUES ($1, $2, $3, $4, $5)",
+			"BTC", username, "Request message", "Pending", time.Now())
+		require.NoError(t, err)
 	}
 
-	err = repo.SaveUnavailableCryptoRequest(conn, request)
-	assert.NoError(t, err)
+	requests, err := repo.ManageSpecificCryptoRequests("BTC")
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
 
-	// Test failure case: simulate insert error if needed
+	// Someone else rejects bob's request after it was read
+	_, err = conn.Exec(context.Background(), "UPDATE unavailable_cryptos SET status = 'Rejected' WHERE username = 'bob'")
+	require.NoError(t, err)
+
+	events := make([]*models.AuditEvent, len(requests))
+	for i, request := range requests {
+		events[i] = &models.AuditEvent{Actor: "root", Action: "request.status_changed", Target: request.UserName, Before: request.Status, After: "Approved", CreatedAt: time.Now()}
+	}
+	err = repo.UpdateRequestStatus(requests, "Approved", events)
+	require.NoError(t, err)
+
+	statusOf := func(username string) string {
+		var status string
+		err := conn.QueryRow(context.Background(), "SELECT status FROM unavailable_cryptos WHERE username=$1", username).Scan(&status)
+		require.NoError(t, err)
+		return status
+	}
+	auditedFor := func(username string) int {
+		var count int
+		err := conn.QueryRow(context.Background(), "SELECT COUNT(*) FROM audit_log WHERE target=$1", username).Scan(&count)
+		require.NoError(t, err)
+		return count
+	}
+
+	// Only the request that was still pending changed, and only it was audited
+	assert.Equal(t, "Approved", statusOf("alice"))
+	assert.Equal(t, "Rejected", statusOf("bob"))
+	assert.Equal(t, 1, auditedFor("alice"))
+	assert.Equal(t, 0, auditedFor("bob"))
+
+	var rows int
+	err = conn.QueryRow(context.Background(), "SELECT COUNT(*) FROM unavailable_cryptos").Scan(&rows)
+	require.NoError(t, err)
+	assert.Equal(t, 2, rows, "requests are updated in place")
 }
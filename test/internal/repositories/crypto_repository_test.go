@@ -20,7 +20,7 @@ func TestPostgresCryptoRepository(t *testing.T) {
 	repo := repositories.NewPostgresCryptoRepository(conn)
 
 	t.Run("DisplayTopCryptocurrencies", func(t *testing.T) {
-		topCryptos, err := repo.DisplayTopCryptocurrencies()
+		topCryptos, err := repo.DisplayTopCryptocurrencies(10)
 		assert.NoError(t, err)
 		assert.NotNil(t, topCryptos)
 	})
@@ -38,7 +38,7 @@ func TestPostgresCryptoRepository(t *testing.T) {
 
 		for _, tt := range tests {
 			t.Run(tt.name, func(t *testing.T) {
-				price, name, symbol, err := repo.SearchCryptocurrency(nil, &models.User{}, tt.symbol)
+				price, name, symbol, err := repo.SearchCryptocurrency(&models.User{}, tt.symbol)
 				if (err != nil) != tt.wantErr {
 					t.Errorf("SearchCryptocurrency() error = %v, wantErr %v", err, tt.wantErr)
 					return
@@ -65,7 +65,7 @@ func TestPostgresCryptoRepository(t *testing.T) {
 
 		for _, tt := range tests {
 			t.Run(tt.name, func(t *testing.T) {
-				currentPrice, err := repo.SetPriceAlert(nil, &models.User{}, tt.symbol, tt.targetPrice)
+				currentPrice, err := repo.SetPriceAlert(&models.User{}, tt.symbol, tt.targetPrice, &models.AlertOptions{})
 				if (err != nil) != tt.wantErr {
 					t.Errorf("SetPriceAlert() error = %v, wantErr %v", err, tt.wantErr)
 					return
@@ -85,12 +85,12 @@ func TestCheckCryptocurrencyExists_RealAPI(t *testing.T) {
 	//assert.True(t, exists, "BTC should exist")
 
 	// Test case 2: Invalid cryptocurrency symbol
-	exists, err := repositories.CheckCryptocurrencyExists("INVALID_SYMBOL")
+	exists, _, err := repositories.CheckCryptocurrencyExists("INVALID_SYMBOL")
 	assert.NoError(t, err, "error should be nil for non-existing cryptocurrency")
 	assert.False(t, exists, "INVALID_SYMBOL should not exist")
 
 	// Test case 3: Empty symbol
-	exists, err = repositories.CheckCryptocurrencyExists("")
+	exists, _, err = repositories.CheckCryptocurrencyExists("")
 	assert.NoError(t, err, "error should be nil for empty symbol")
 	assert.False(t, exists, "Empty symbol should not exist")
 }
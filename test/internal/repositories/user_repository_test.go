@@ -19,7 +19,7 @@ func TestGetUserProfile(t *testing.T) {
 	repo := repositories.NewPostgresUserRepository(conn)
 
 	_, err = conn.Exec(context.Background(), "INSERT INTO users (userid, username, email, mobile, isadmin, role) VALUES ($1, $2, $3, $4, $5, $6)",
-		"0", "testuser1", "testuser@example.com", 1234567890, false, "user")
+		"0", "testuser1", "testuser@example.com", "+911234567890", false, "user")
 	require.NoError(t, err)
 
 	user, err := repo.GetUserProfile("testuser1")
@@ -28,7 +28,7 @@ func TestGetUserProfile(t *testing.T) {
 	//assert.Equal(t, "0", user.UserID)
 	assert.Equal(t, "testuser1", user.Username)
 	assert.Equal(t, "testuser@example.com", user.Email)
-	assert.Equal(t, 1234567890, user.Mobile)
+	assert.Equal(t, "+911234567890", user.Mobile)
 	assert.Equal(t, "user", user.Role)
 
 	user, err = repo.GetUserProfile("nonexistentuser")
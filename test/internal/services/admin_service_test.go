@@ -1,16 +1,17 @@
 package services_test
 
 import (
+	"cryptotracker/internal/rbac"
 	"cryptotracker/internal/services"
 	"cryptotracker/models"
 	mock_repositories "cryptotracker/test/internal/mocks/repository"
 	"errors"
 	"github.com/golang/mock/gomock"
-	//"github.com/jackc/pgx/v4"
 	"testing"
+	"time"
 )
 
-func TestAdminServiceImpl_ChangeUserStatus(t *testing.T) {
+func TestAdminServiceImpl_ChangeUserRole(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -18,20 +19,172 @@ func TestAdminServiceImpl_ChangeUserStatus(t *testing.T) {
 	service := services.NewAdminService(mockRepo)
 
 	testCases := []struct {
-		name     string
-		username string
-		mockErr  error
+		name        string
+		actor       string
+		actorRole   string
+		username    string
+		currentRole string
+		role        string
+		mockErr     error
+		wantSet     bool
+		wantErr     bool
+		expectedErr error
 	}{
-		{"Success", "testuser", nil},
-		{"Error", "erroruser", errors.New("database error")},
+		{"Admin promotes a user", "root", rbac.Admin, "testuser", rbac.User, rbac.Analyst, nil, true, false, nil},
+		{"Repository error", "root", rbac.Admin, "erroruser", rbac.User, rbac.Analyst, errors.New("database error"), true, true, nil},
+		{"Analyst lacks the permission", "ann", rbac.Analyst, "testuser", rbac.User, rbac.Viewer, nil, false, true, services.ErrPermissionDenied},
+		{"Admin cannot demote another admin", "root", rbac.Admin, "otheradmin", rbac.Admin, rbac.User, nil, false, true, services.ErrPermissionDenied},
+		{"Unknown role", "root", rbac.Admin, "testuser", rbac.User, "superuser", nil, false, true, nil},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			mockRepo.EXPECT().ChangeUserStatus(gomock.Any(), tc.username).Return(tc.mockErr)
-			err := service.ChangeUserStatus(nil, tc.username)
-			if (err != nil) != (tc.mockErr != nil) {
-				t.Errorf("Expected error: %v, got: %v", tc.mockErr, err)
+			if rbac.IsRole(tc.role) {
+				mockRepo.EXPECT().GetUserRole(tc.actor).Return(tc.actorRole, nil)
+				if rbac.Can(tc.actorRole, rbac.UsersChangeRole) {
+					mockRepo.EXPECT().GetUserRole(tc.username).Return(tc.currentRole, nil)
+				}
+			}
+			if tc.wantSet {
+				mockRepo.EXPECT().SetUserRole(tc.username, tc.role, gomock.Any()).Return(tc.mockErr)
+			}
+
+			err := service.ChangeUserRole(tc.username, tc.role, tc.actor, services.AuditSourceCLI)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error: %v, got: %v", tc.wantErr, err)
+			}
+			if tc.expectedErr != nil && err != tc.expectedErr {
+				t.Errorf("Expected %v, got: %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestAdminServiceImpl_ChangeOwnRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock_repositories.NewMockAdminRepository(ctrl)
+	service := services.NewAdminService(mockRepo)
+
+	mockRepo.EXPECT().GetUserRole("root").Return(rbac.Admin, nil)
+	if err := service.ChangeUserRole("root", rbac.Owner, "root", services.AuditSourceCLI); err == nil {
+		t.Error("Expected changing one's own role to be refused")
+	}
+}
+
+func TestAdminServiceImpl_DeactivateUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock_repositories.NewMockAdminRepository(ctrl)
+	service := services.NewAdminService(mockRepo)
+
+	testCases := []struct {
+		name        string
+		actorRole   string
+		targetRole  string
+		status      string
+		wantSet     bool
+		expectedErr error
+		wantErr     bool
+	}{
+		{"Support deactivates a user", rbac.Support, rbac.User, models.AccountActive, true, nil, false},
+		{"Support cannot deactivate an admin", rbac.Support, rbac.Admin, models.AccountActive, false, services.ErrPermissionDenied, true},
+		{"Analyst lacks the permission", rbac.Analyst, rbac.User, models.AccountActive, false, services.ErrPermissionDenied, true},
+		{"Already deactivated", rbac.Admin, rbac.User, models.AccountDeactivated, false, nil, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo.EXPECT().GetUserRole("actor").Return(tc.actorRole, nil)
+			if rbac.Can(tc.actorRole, rbac.UsersDeactivate) {
+				mockRepo.EXPECT().GetUserRole("target").Return(tc.targetRole, nil)
+				if rbac.CanManage(tc.actorRole, tc.targetRole) {
+					mockRepo.EXPECT().GetUserStatus("target").Return(tc.status, nil, nil)
+				}
+			}
+			if tc.wantSet {
+				mockRepo.EXPECT().SetUserStatus("target", models.AccountDeactivated, nil, gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			err := service.DeactivateUser("target", "actor", services.AuditSourceREST)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error: %v, got: %v", tc.wantErr, err)
+			}
+			if tc.expectedErr != nil && err != tc.expectedErr {
+				t.Errorf("Expected %v, got: %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestAdminServiceImpl_ForceLogout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock_repositories.NewMockAdminRepository(ctrl)
+	service := services.NewAdminService(mockRepo)
+
+	testCases := []struct {
+		name        string
+		actorRole   string
+		targetRole  string
+		wantRevoke  bool
+		expectedErr error
+	}{
+		{"Support signs out a user", rbac.Support, rbac.User, true, nil},
+		{"Support cannot sign out an admin", rbac.Support, rbac.Admin, false, services.ErrPermissionDenied},
+		{"Support cannot sign out an owner", rbac.Support, rbac.Owner, false, services.ErrPermissionDenied},
+		{"Owner signs out an admin", rbac.Owner, rbac.Admin, true, nil},
+		{"Analyst lacks the permission", rbac.Analyst, rbac.User, false, services.ErrPermissionDenied},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo.EXPECT().GetUserRole("actor").Return(tc.actorRole, nil)
+			if rbac.Can(tc.actorRole, rbac.SessionsRevokeAny) {
+				mockRepo.EXPECT().GetUserRole("target").Return(tc.targetRole, nil)
+			}
+			if tc.wantRevoke {
+				mockRepo.EXPECT().RevokeUserSessions("target", gomock.Any(), gomock.Any()).Return(2, nil)
+			}
+
+			count, err := service.ForceLogout("target", "actor", services.AuditSourceREST)
+			if err != tc.expectedErr {
+				t.Errorf("Expected %v, got: %v", tc.expectedErr, err)
+			}
+			if tc.wantRevoke && count != 2 {
+				t.Errorf("Expected 2 sessions ended, got %d", count)
+			}
+		})
+	}
+}
+
+func TestAdminServiceImpl_UnlockLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock_repositories.NewMockAdminRepository(ctrl)
+	service := services.NewAdminService(mockRepo)
+
+	testCases := []struct {
+		name        string
+		key         string
+		expectedKey string
+	}{
+		{"Bare username", "Alice", "user:alice"},
+		{"User key", "user:alice", "user:alice"},
+		{"Address key", "ip:203.0.113.7", "ip:203.0.113.7"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo.EXPECT().GetUserRole("actor").Return(rbac.Support, nil)
+			mockRepo.EXPECT().UnlockLogin(tc.expectedKey, gomock.Any()).Return(nil)
+
+			if err := service.UnlockLogin(tc.key, "actor", services.AuditSourceCLI); err != nil {
+				t.Errorf("Expected no error, got: %v", err)
 			}
 		})
 	}
@@ -55,15 +208,34 @@ func TestAdminServiceImpl_DeleteUser(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			mockRepo.EXPECT().DeleteUser(gomock.Any(), tc.username).Return(tc.mockErr)
-			err := service.DeleteUser(nil, tc.username)
+			mockRepo.EXPECT().GetUserRole("root").Return(rbac.Admin, nil)
+			mockRepo.EXPECT().GetUserRole(tc.username).Return(rbac.User, nil)
+			mockRepo.EXPECT().GetUserStatus(tc.username).Return(models.AccountActive, nil, nil)
+			mockRepo.EXPECT().SetUserStatus(tc.username, models.AccountDeleted, gomock.Any(), gomock.Any(), gomock.Any()).Return(tc.mockErr)
+
+			purgeAfter, err := service.DeleteUser(tc.username, "root", services.AuditSourceCLI)
 			if (err != nil) != (tc.mockErr != nil) {
 				t.Errorf("Expected error: %v, got: %v", tc.mockErr, err)
 			}
+			if err == nil && !purgeAfter.After(time.Now()) {
+				t.Errorf("Expected the purge to be in the future, got %v", purgeAfter)
+			}
 		})
 	}
 }
 
+func TestAdminServiceImpl_DeleteOwnAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock_repositories.NewMockAdminRepository(ctrl)
+	service := services.NewAdminService(mockRepo)
+
+	if _, err := service.DeleteUser("root", "root", services.AuditSourceCLI); err == nil {
+		t.Error("Expected deleting one's own account to be refused")
+	}
+}
+
 func TestAdminServiceImpl_ViewUserProfiles(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -77,20 +249,27 @@ func TestAdminServiceImpl_ViewUserProfiles(t *testing.T) {
 	}
 
 	testCases := []struct {
-		name      string
-		mockUsers []*models.User
-		mockErr   error
+		name        string
+		actorRole   string
+		mockUsers   []*models.User
+		mockErr     error
+		expectedErr error
 	}{
-		{"Success", mockUsers, nil},
-		{"Error", nil, errors.New("database error")},
+		{"Success", rbac.Support, mockUsers, nil, nil},
+		{"Error", rbac.Admin, nil, errors.New("database error"), errors.New("database error")},
+		{"User lacks the permission", rbac.User, nil, nil, services.ErrPermissionDenied},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			mockRepo.EXPECT().ViewUserProfiles(gomock.Any()).Return(tc.mockUsers, tc.mockErr)
-			users, err := service.ViewUserProfiles(nil)
-			if (err != nil) != (tc.mockErr != nil) {
-				t.Errorf("Expected error: %v, got: %v", tc.mockErr, err)
+			mockRepo.EXPECT().GetUserRole("actor").Return(tc.actorRole, nil)
+			if rbac.Can(tc.actorRole, rbac.UsersView) {
+				mockRepo.EXPECT().ViewUserProfiles().Return(tc.mockUsers, tc.mockErr)
+			}
+
+			users, err := service.ViewUserProfiles("actor")
+			if (err == nil) != (tc.expectedErr == nil) || (err != nil && err.Error() != tc.expectedErr.Error()) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectedErr, err)
 			}
 			if err == nil && len(users) != len(tc.mockUsers) {
 				t.Errorf("Expected %d users, got %d", len(tc.mockUsers), len(users))
@@ -113,19 +292,26 @@ func TestAdminServiceImpl_ManageUserRequests(t *testing.T) {
 
 	testCases := []struct {
 		name         string
+		actorRole    string
 		mockRequests []*models.UnavailableCryptoRequest
 		mockErr      error
+		expectedErr  error
 	}{
-		{"Success", mockRequests, nil},
-		{"Error", nil, errors.New("database error")},
+		{"Success", rbac.Analyst, mockRequests, nil, nil},
+		{"Error", rbac.Admin, nil, errors.New("database error"), errors.New("database error")},
+		{"Viewer lacks the permission", rbac.Viewer, nil, nil, services.ErrPermissionDenied},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			mockRepo.EXPECT().ManageUserRequests(gomock.Any()).Return(tc.mockRequests, tc.mockErr)
-			requests, err := service.ManageUserRequests(nil)
-			if (err != nil) != (tc.mockErr != nil) {
-				t.Errorf("Expected error: %v, got: %v", tc.mockErr, err)
+			mockRepo.EXPECT().GetUserRole("actor").Return(tc.actorRole, nil)
+			if rbac.Can(tc.actorRole, rbac.RequestsView) {
+				mockRepo.EXPECT().ManageUserRequests().Return(tc.mockRequests, tc.mockErr)
+			}
+
+			requests, err := service.ManageUserRequests("actor")
+			if (err == nil) != (tc.expectedErr == nil) || (err != nil && err.Error() != tc.expectedErr.Error()) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectedErr, err)
 			}
 			if err == nil && len(requests) != len(tc.mockRequests) {
 				t.Errorf("Expected %d requests, got %d", len(tc.mockRequests), len(requests))
@@ -141,31 +327,45 @@ func TestAdminServiceImpl_UpdateRequestStatus(t *testing.T) {
 	mockRepo := mock_repositories.NewMockAdminRepository(ctrl)
 	service := services.NewAdminService(mockRepo)
 
-	request := &models.UnavailableCryptoRequest{
-		CryptoSymbol: "ABC",
-		UserName:     "user1",
-		Status:       "pending",
-	}
-
 	testCases := []struct {
-		name    string
-		request *models.UnavailableCryptoRequest
-		status  string
-		mockErr error
+		name        string
+		actorRole   string
+		statuses    []string
+		status      string
+		wantPending int
+		mockErr     error
+		expectedErr error
 	}{
-		{"Success", request, "approved", nil},
-		{"Error", request, "rejected", errors.New("database error")},
+		{"Approves pending requests", rbac.Analyst, []string{"Pending", "Pending"}, "Approved", 2, nil, nil},
+		{"Skips requests that already have the status", rbac.Admin, []string{"Approved", "Pending"}, "Approved", 1, nil, nil},
+		{"Nothing to change", rbac.Admin, []string{"Rejected"}, "Rejected", 0, nil, nil},
+		{"Error", rbac.Admin, []string{"Pending"}, "Rejected", 1, errors.New("database error"), errors.New("database error")},
+		{"Support lacks the permission", rbac.Support, []string{"Pending"}, "Approved", 0, nil, services.ErrPermissionDenied},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			mockRepo.EXPECT().SaveUnavailableCryptoRequest(gomock.Any(), gomock.Any()).Return(tc.mockErr)
-			err := service.UpdateRequestStatus(nil, tc.request, tc.status)
-			if (err != nil) != (tc.mockErr != nil) {
-				t.Errorf("Expected error: %v, got: %v", tc.mockErr, err)
+			var requests []*models.UnavailableCryptoRequest
+			for _, status := range tc.statuses {
+				requests = append(requests, &models.UnavailableCryptoRequest{CryptoSymbol: "ABC", UserName: "user1", Status: status})
 			}
-			if err == nil && tc.request.Status != tc.status {
-				t.Errorf("Expected status to be '%s', got '%s'", tc.status, tc.request.Status)
+
+			mockRepo.EXPECT().GetUserRole("actor").Return(tc.actorRole, nil)
+			if tc.wantPending > 0 {
+				mockRepo.EXPECT().UpdateRequestStatus(gomock.Len(tc.wantPending), tc.status, gomock.Len(tc.wantPending)).
+					DoAndReturn(func(pending []*models.UnavailableCryptoRequest, status string, events []*models.AuditEvent) error {
+						for i, event := range events {
+							if event.Before != pending[i].Status || event.After != status || event.Actor != "actor" {
+								t.Errorf("Unexpected audit event %+v for request %+v", event, pending[i])
+							}
+						}
+						return tc.mockErr
+					})
+			}
+
+			err := service.UpdateRequestStatus(requests, tc.status, "actor", services.AuditSourceCLI)
+			if (err == nil) != (tc.expectedErr == nil) || (err != nil && err.Error() != tc.expectedErr.Error()) {
+				t.Errorf("Expected error: %v, got: %v", tc.expectedErr, err)
 			}
 		})
 	}
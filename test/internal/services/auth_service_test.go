@@ -0,0 +1,69 @@
+package services_test
+
+import (
+	"cryptotracker/internal/services"
+	"cryptotracker/models"
+	mock_repositories "cryptotracker/test/internal/mocks/repository"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestAuthServiceImpl_DisableTwoFactor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock_repositories.NewMockAuthRepository(ctrl)
+	service := services.NewAuthService(mockRepo, nil)
+
+	lockedUntil := time.Now().Add(time.Hour)
+
+	testCases := []struct {
+		name         string
+		clientIP     string
+		attempts     []*models.LoginAttempt
+		expectedKeys []string
+		throttled    bool
+	}{
+		{
+			name:         "Wrong code from the CLI is counted against the user",
+			expectedKeys: []string{"user:alice"},
+		},
+		{
+			name:         "Wrong code over REST is counted against the user and the address",
+			clientIP:     "203.0.113.7",
+			expectedKeys: []string{"user:alice", "ip:203.0.113.7"},
+		},
+		{
+			name:         "Locked user is refused without checking the code",
+			attempts:     []*models.LoginAttempt{{Key: "user:alice", Failures: 5, LastFailureAt: time.Now(), LockedUntil: &lockedUntil}},
+			expectedKeys: []string{"user:alice"},
+			throttled:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo.EXPECT().GetLoginAttempts(tc.expectedKeys).Return(tc.attempts, nil)
+			if !tc.throttled {
+				mockRepo.EXPECT().LoginDBRepository("alice").Return(&models.User{Username: "alice", Role: "user"}, nil)
+				mockRepo.EXPECT().IsTwoFactorRequired("user").Return(false, nil)
+				mockRepo.EXPECT().GetTwoFactor("alice").Return(&models.TwoFactor{Username: "alice", Secret: "JBSWY3DPEHPK3PXP", Enabled: true}, nil)
+				for _, key := range tc.expectedKeys {
+					mockRepo.EXPECT().RecordLoginFailure(key, gomock.Any(), gomock.Any()).Return(1, nil)
+				}
+			}
+
+			err := service.DisableTwoFactor("alice", "12", tc.clientIP)
+
+			var throttled *services.LoginThrottledError
+			if tc.throttled && !errors.As(err, &throttled) {
+				t.Errorf("Expected a throttling error, got: %v", err)
+			}
+			if !tc.throttled && err != services.ErrInvalidTwoFactorCode {
+				t.Errorf("Expected error %v, got: %v", services.ErrInvalidTwoFactorCode, err)
+			}
+		})
+	}
+}
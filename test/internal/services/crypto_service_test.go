@@ -1,12 +1,12 @@
 package services_test
 
 import (
+	"cryptotracker/internal/rbac"
 	"cryptotracker/internal/services"
 	"cryptotracker/models"
 	mock_repositories "cryptotracker/test/internal/mocks/repository"
 	"errors"
 	"github.com/golang/mock/gomock"
-	"github.com/jackc/pgx/v4"
 	"reflect"
 	"testing"
 )
@@ -56,9 +56,9 @@ func TestCryptoServiceImpl_DisplayTopCryptocurrencies(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			mockRepo.EXPECT().DisplayTopCryptocurrencies().Return(tc.mockResult, tc.mockErr)
+			mockRepo.EXPECT().DisplayTopCryptocurrencies(10).Return(tc.mockResult, tc.mockErr)
 
-			result, err := service.DisplayTopCryptocurrencies()
+			result, err := service.DisplayTopCryptocurrencies(10)
 
 			if tc.expectedError && err == nil {
 				t.Error("Expected an error, got nil")
@@ -83,7 +83,6 @@ func TestCryptoServiceImpl_SearchCryptocurrency(t *testing.T) {
 	service := services.NewCryptoService(mockRepo)
 
 	testUser := &models.User{Username: "testuser"}
-	testConn := &pgx.Conn{}
 
 	testCases := []struct {
 		name          string
@@ -116,9 +115,9 @@ func TestCryptoServiceImpl_SearchCryptocurrency(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			mockRepo.EXPECT().SearchCryptocurrency(testConn, testUser, tc.cryptoSymbol).Return(tc.mockPrice, tc.mockName, tc.mockCoinID, tc.mockErr)
+			mockRepo.EXPECT().SearchCryptocurrency(testUser, tc.cryptoSymbol).Return(tc.mockPrice, tc.mockName, tc.mockCoinID, tc.mockErr)
 
-			price, name, coinID, err := service.SearchCryptocurrency(testConn, testUser, tc.cryptoSymbol)
+			price, name, coinID, err := service.SearchCryptocurrency(testUser, tc.cryptoSymbol)
 
 			if tc.expectedError && err == nil {
 				t.Error("Expected an error, got nil")
@@ -151,50 +150,93 @@ func TestCryptoServiceImpl_SetPriceAlert(t *testing.T) {
 	service := services.NewCryptoService(mockRepo)
 
 	testUser := &models.User{Username: "testuser"}
-	testConn := &pgx.Conn{}
 
 	testCases := []struct {
 		name             string
+		role             string
 		symbol           string
 		targetPrice      float64
 		mockCurrentPrice float64
 		mockErr          error
-		expectedError    bool
+		expectedErr      error
+		expectedPrice    float64
 	}{
 		{
 			name:             "Success",
+			role:             rbac.User,
 			symbol:           "BTC",
 			targetPrice:      60000.0,
 			mockCurrentPrice: 50000.0,
-			mockErr:          nil,
-			expectedError:    false,
+			expectedPrice:    50000.0,
 		},
 		{
-			name:             "Error",
-			symbol:           "INVALID",
-			targetPrice:      1000.0,
-			mockCurrentPrice: 0,
-			mockErr:          errors.New("Failed to set price alert"),
-			expectedError:    true,
+			name:        "Error",
+			role:        rbac.User,
+			symbol:      "INVALID",
+			targetPrice: 1000.0,
+			mockErr:     errors.New("Failed to set price alert"),
+			expectedErr: errors.New("Failed to set price alert"),
+		},
+		{
+			name:        "Viewer cannot set alerts",
+			role:        rbac.Viewer,
+			symbol:      "BTC",
+			targetPrice: 60000.0,
+			expectedErr: services.ErrPermissionDenied,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			mockRepo.EXPECT().SetPriceAlert(testConn, testUser, tc.symbol, tc.targetPrice).Return(tc.mockCurrentPrice, tc.mockErr)
+			mockRepo.EXPECT().GetUserRole("testuser").Return(tc.role, nil)
+			if rbac.Can(tc.role, rbac.AlertsManage) {
+				mockRepo.EXPECT().SetPriceAlert(testUser, tc.symbol, tc.targetPrice, gomock.Any()).Return(tc.mockCurrentPrice, tc.mockErr)
+			}
 
-			currentPrice, err := service.SetPriceAlert(testConn, testUser, tc.symbol, tc.targetPrice)
+			currentPrice, err := service.SetPriceAlert(testUser, tc.symbol, tc.targetPrice, &models.AlertOptions{})
 
-			if tc.expectedError && err == nil {
-				t.Error("Expected an error, got nil")
+			if (err == nil) != (tc.expectedErr == nil) || (err != nil && err.Error() != tc.expectedErr.Error()) {
+				t.Errorf("Expected error %v, got: %v", tc.expectedErr, err)
 			}
 
-			if !tc.expectedError && err != nil {
-				t.Errorf("Expected no error, got: %v", err)
+			if currentPrice != tc.expectedPrice {
+				t.Errorf("Expected current price %v, got %v", tc.expectedPrice, currentPrice)
+			}
+		})
+	}
+}
+
+func TestCryptoServiceImpl_DeleteAlertGroup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock_repositories.NewMockCryptoRepository(ctrl)
+	service := services.NewCryptoService(mockRepo)
+
+	testCases := []struct {
+		name        string
+		owner       string
+		actor       string
+		actorRole   string
+		expectedErr error
+	}{
+		{"Own alerts", "alice", "alice", rbac.User, nil},
+		{"Viewer cannot delete own alerts", "alice", "alice", rbac.Viewer, services.ErrPermissionDenied},
+		{"Support may delete anyone's alerts", "alice", "sam", rbac.Support, nil},
+		{"Analyst cannot delete others' alerts", "alice", "ann", rbac.Analyst, services.ErrPermissionDenied},
+		{"User cannot delete others' alerts", "alice", "bob", rbac.User, services.ErrPermissionDenied},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo.EXPECT().GetUserRole(tc.actor).Return(tc.actorRole, nil)
+			if tc.expectedErr == nil {
+				mockRepo.EXPECT().DeleteAlertGroup(tc.owner, 7).Return(nil)
 			}
 
-			if currentPrice != tc.mockCurrentPrice {
-				t.Errorf("Expected current price %v, got %v", tc.mockCurrentPrice, currentPrice)
+			err := service.DeleteAlertGroup(tc.owner, 7, tc.actor)
+			if err != tc.expectedErr {
+				t.Errorf("Expected error %v, got: %v", tc.expectedErr, err)
 			}
 		})
 	}
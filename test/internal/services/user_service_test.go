@@ -3,10 +3,13 @@ package services_test
 import (
 	"cryptotracker/internal/services"
 	"cryptotracker/models"
+	"cryptotracker/pkg/utils"
 	mock_repositories "cryptotracker/test/internal/mocks/repository"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestGetUserProfile_Success(t *testing.T) {
@@ -20,7 +23,7 @@ func TestGetUserProfile_Success(t *testing.T) {
 	expectedUser := &models.User{
 		Username: "testuser",
 		Email:    "testuser@example.com",
-		Mobile:   1234567890,
+		Mobile:   "+911234567890",
 		//PAN:      "ABCDE1234F",
 	}
 
@@ -64,3 +67,49 @@ func TestGetUserProfile_Error(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, result)
 }
+
+func TestUpdateProfile_WrongCurrentPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock_repositories.NewMockUserRepository(ctrl)
+	hash, err := utils.HashPassword("Current#Pass1")
+	require.NoError(t, err)
+
+	// The fifth wrong password locks the user out, like a fifth failed login
+	mockRepo.EXPECT().GetUserProfile("testuser").Return(&models.User{Username: "testuser"}, nil)
+	mockRepo.EXPECT().GetLoginAttempts([]string{"user:testuser"}).Return(nil, nil)
+	mockRepo.EXPECT().GetPasswordHash("testuser").Return(hash, nil)
+	mockRepo.EXPECT().RecordLoginFailure("user:testuser", gomock.Any(), gomock.Any()).Return(5, nil)
+	mockRepo.EXPECT().LockLogin("user:testuser", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(key string, until time.Time, events ...*models.AuditEvent) error {
+			assert.Equal(t, "login.locked", events[0].Action)
+			assert.Equal(t, services.AuditSourceREST, events[0].Source)
+			return nil
+		})
+
+	service := services.NewUserService(mockRepo)
+	_, err = service.UpdateProfile("testuser", &services.ProfileUpdate{CurrentPassword: "Wrong#Pass1", NewPassword: "New#Pass12"}, services.AuditSourceREST)
+
+	assert.Equal(t, services.ErrWrongPassword, err)
+}
+
+func TestUpdateProfile_PasswordChangeThrottled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mock_repositories.NewMockUserRepository(ctrl)
+	lockedUntil := time.Now().Add(time.Hour)
+
+	// A locked user is refused without the current password being checked
+	mockRepo.EXPECT().GetUserProfile("testuser").Return(&models.User{Username: "testuser"}, nil)
+	mockRepo.EXPECT().GetLoginAttempts([]string{"user:testuser"}).
+		Return([]*models.LoginAttempt{{Key: "user:testuser", Failures: 5, LastFailureAt: time.Now(), LockedUntil: &lockedUntil}}, nil)
+
+	service := services.NewUserService(mockRepo)
+	_, err := service.UpdateProfile("testuser", &services.ProfileUpdate{CurrentPassword: "Current#Pass1", NewPassword: "New#Pass12"}, services.AuditSourceCLI)
+
+	var throttled *services.LoginThrottledError
+	require.ErrorAs(t, err, &throttled)
+	assert.True(t, throttled.Locked)
+}
@@ -0,0 +1,133 @@
+package totp_test
+
+import (
+	"cryptotracker/internal/totp"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The SHA-1 key from the RFC 6238 test vectors, "12345678901234567890", in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	// The RFC gives 8-digit codes; these are their last 6 digits
+	tests := []struct {
+		unix     int64
+		expected string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		code, err := totp.Code(rfcSecret, totp.Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code(%d) returned error: %v", tt.unix, err)
+		}
+		if code != tt.expected {
+			t.Errorf("Code(%d) = %s; expected %s", tt.unix, code, tt.expected)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+
+	tests := []struct {
+		name string
+		code string
+		at   time.Time
+		ok   bool
+	}{
+		{"current code", "081804", now, true},
+		{"code with spaces", "081 804", now, true},
+		{"previous period", "081804", now.Add(totp.Period), true},
+		{"two periods late", "081804", now.Add(2 * totp.Period), false},
+		{"wrong code", "123456", now, false},
+		{"too short", "08180", now, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := totp.Validate(rfcSecret, tt.code, tt.at)
+			if ok != tt.ok {
+				t.Fatalf("Validate(%q) ok = %v; expected %v", tt.code, ok, tt.ok)
+			}
+			if ok && step != totp.Step(now) {
+				t.Errorf("step = %d; expected %d", step, totp.Step(now))
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret returned error: %v", err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("secret %q has %d characters; expected 32", secret, len(secret))
+	}
+	if _, err := totp.Code(secret, 1); err != nil {
+		t.Errorf("generated secret cannot be used: %v", err)
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri, err := url.Parse(totp.ProvisioningURI("CryptoTracker", "alice", rfcSecret))
+	if err != nil {
+		t.Fatalf("invalid URI: %v", err)
+	}
+
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/CryptoTracker:alice" {
+		t.Errorf("unexpected URI %s", uri)
+	}
+	query := uri.Query()
+	if query.Get("secret") != rfcSecret || query.Get("issuer") != "CryptoTracker" || query.Get("digits") != "6" || query.Get("period") != "30" {
+		t.Errorf("unexpected parameters %v", query)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := totp.RecoveryCodes(10)
+	if err != nil {
+		t.Fatalf("RecoveryCodes returned error: %v", err)
+	}
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' || !totp.IsRecoveryCode(code) {
+			t.Errorf("malformed recovery code %q", code)
+		}
+		if seen[code] {
+			t.Errorf("duplicate recovery code %q", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"k7qm2-x4ntb", "k7qm2-x4ntb"},
+		{"K7QM2-X4NTB", "k7qm2-x4ntb"},
+		{"k7qm2x4ntb", "k7qm2-x4ntb"},
+		{" k7qm2 x4ntb ", "k7qm2-x4ntb"},
+	}
+
+	for _, tt := range tests {
+		if got := totp.NormalizeRecoveryCode(tt.input); got != tt.expected {
+			t.Errorf("NormalizeRecoveryCode(%q) = %q; expected %q", tt.input, got, tt.expected)
+		}
+	}
+
+	if totp.IsRecoveryCode("123456") || strings.Contains(totp.NormalizeRecoveryCode("123456"), "-") {
+		t.Error("a TOTP code was taken for a recovery code")
+	}
+}
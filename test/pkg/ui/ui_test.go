@@ -65,7 +65,8 @@ func TestDisplayAuthMenu(t *testing.T) {
 		color.BlueString("==================================\n\n\n") +
 		color.BlueString("1. Login\n") +
 		color.BlueString("2. SignUp\n") +
-		color.BlueString("3. Exit\n") +
+		color.BlueString("3. Forgot Password\n") +
+		color.BlueString("4. Exit\n") +
 		"\n"
 	output := captureOutput(ui.DisplayAuthMenu)
 	if output != expectedOutput {
@@ -82,7 +83,13 @@ func TestDisplayMainMenu(t *testing.T) {
 		color.GreenString("3. Set Price Alert\n") +
 		//color.GreenString("4. Check if user is Admin\n") +
 		color.GreenString("4. User Profile\n") +
-		color.RedString("5. Logout\n") +
+		color.GreenString("5. Alert History\n") +
+		color.GreenString("6. Manage Alert Groups\n") +
+		color.GreenString("7. Digest Settings\n") +
+		color.GreenString("8. Two-Factor Authentication\n") +
+		color.GreenString("9. Active Sessions\n") +
+		color.GreenString("10. API Keys\n") +
+		color.RedString("11. Logout\n") +
 		"\n"
 	output := captureOutput(ui.DisplayMainMenu)
 	if output != expectedOutput {
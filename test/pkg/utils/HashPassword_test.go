@@ -1,23 +1,83 @@
 package utils
 
 import (
+	"cryptotracker/pkg/config"
 	"cryptotracker/pkg/utils"
+	"strings"
 	"testing"
 )
 
 func TestHashPassword(t *testing.T) {
+	first, err := utils.HashPassword("Rishabh@123")
+	if err != nil {
+		t.Fatalf("HashPassword() unexpected error: %v", err)
+	}
+	second, err := utils.HashPassword("Rishabh@123")
+	if err != nil {
+		t.Fatalf("HashPassword() unexpected error: %v", err)
+	}
+
+	if !strings.HasPrefix(first, "$argon2id$v=19$m=65536,t=3,p=2$") {
+		t.Errorf("HashPassword() = %q; expected an encoded argon2id hash with the default policy", first)
+	}
+	if first == second {
+		t.Errorf("HashPassword() returned the same hash twice; expected a random salt")
+	}
+}
+
+func TestVerifyPassword(t *testing.T) {
+	hash, err := utils.HashPassword("admin_password")
+	if err != nil {
+		t.Fatalf("HashPassword() unexpected error: %v", err)
+	}
+
 	tests := []struct {
-		password string
-		expected string
+		name        string
+		password    string
+		encoded     string
+		match       bool
+		needsRehash bool
+		wantErr     bool
 	}{
-		{"admin_password", "6d4525c2a21f9be1cca9e41f3aa402e0765ee5fcc3e7fea34a169b1730ae386e"},
-		{"Rishabh@123", "f6d8b1203495a937b766051690a69a3176ceb7f5937c885672c4b3779e1ac07a"},
+		{"argon2id match", "admin_password", hash, true, false, false},
+		{"argon2id mismatch", "wrong_password", hash, false, false, false},
+		{"legacy SHA-256 match", "admin_password", "6d4525c2a21f9be1cca9e41f3aa402e0765ee5fcc3e7fea34a169b1730ae386e", true, true, false},
+		{"legacy SHA-256 mismatch", "Rishabh@123", "6d4525c2a21f9be1cca9e41f3aa402e0765ee5fcc3e7fea34a169b1730ae386e", false, false, false},
+		{"malformed hash", "admin_password", "$argon2id$v=19$m=65536", false, false, true},
+		{"unknown algorithm", "admin_password", "$2a$10$abcdefghijklmnopqrstuv", false, false, true},
 	}
 
-	for _, test := range tests {
-		result := utils.HashPassword(test.password)
-		if result != test.expected {
-			t.Errorf("HashPassword(%q) = %q; expected %q", test.password, result, test.expected)
-		}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, needsRehash, err := utils.VerifyPassword(tt.password, tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyPassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if match != tt.match || needsRehash != tt.needsRehash {
+				t.Errorf("VerifyPassword() = %v, %v; expected %v, %v", match, needsRehash, tt.match, tt.needsRehash)
+			}
+		})
+	}
+}
+
+func TestVerifyPasswordPolicyChange(t *testing.T) {
+	defer func() { config.AppConfig.PasswordHashing = config.PasswordHashing{} }()
+
+	config.AppConfig.PasswordHashing = config.PasswordHashing{MemoryKiB: 8 * 1024, Iterations: 1, Parallelism: 1}
+	hash, err := utils.HashPassword("admin_password")
+	if err != nil {
+		t.Fatalf("HashPassword() unexpected error: %v", err)
+	}
+	if !strings.Contains(hash, "$m=8192,t=1,p=1$") {
+		t.Errorf("HashPassword() = %q; expected the configured cost parameters", hash)
+	}
+
+	config.AppConfig.PasswordHashing = config.PasswordHashing{}
+	match, needsRehash, err := utils.VerifyPassword("admin_password", hash)
+	if err != nil || !match {
+		t.Fatalf("VerifyPassword() = %v, %v; expected a match under the old parameters", match, err)
+	}
+	if !needsRehash {
+		t.Errorf("VerifyPassword() needsRehash = false; expected a rehash after the policy changed")
 	}
 }
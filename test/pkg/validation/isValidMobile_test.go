@@ -5,26 +5,76 @@ import (
 	"testing"
 )
 
+// TestNormalizeMobile tests the NormalizeMobile function
+func TestNormalizeMobile(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"+91 98765 43210", "+919876543210", false},
+		{"+1 (415) 555-0100", "+14155550100", false},
+		{"0044 20 7946 0958", "+442079460958", false},
+		{"+39 06 6988 4857", "+390669884857", false},
+		{"+683 4002", "+6834002", false},
+		{"  +971-50-123-4567 ", "+971501234567", false},
+		{"9876543210", "", true},
+		{"+91 98765 4321", "", true},
+		{"+1 415 555 01000", "", true},
+		{"+0 123456789", "", true},
+		{"+999 123456789", "", true},
+		{"+91 98765 4321O", "", true},
+		{"+1234567890123456", "", true},
+		{"+", "", true},
+		{"", "", true},
+	}
+
+	for _, test := range tests {
+		result, err := validation.NormalizeMobile(test.input)
+		if (err != nil) != test.wantErr || result != test.expected {
+			t.Errorf("NormalizeMobile(%q) = %q, %v; expected %q, wantErr %v", test.input, result, err, test.expected, test.wantErr)
+		}
+	}
+}
+
 // TestIsValidMobile tests the IsValidMobile function
 func TestIsValidMobile(t *testing.T) {
 	tests := []struct {
-		mobile   int
+		mobile   string
 		expected bool
 	}{
-		{1234567890, true},
-		{9876543210, true},
-		{123456789, false},
-		{12345678901, false},
-		{12345678, false},
-		{12345678901234, false},
-		{0, false},
-		{-1234567890, false},
+		{"+919876543210", true},
+		{"+442079460958", true},
+		{"+91 98765 43210", false},
+		{"9876543210", false},
+		{"", false},
 	}
 
 	for _, test := range tests {
 		result := validation.IsValidMobile(test.mobile)
 		if result != test.expected {
-			t.Errorf("IsValidMobile(%d) = %v; expected %v", test.mobile, result, test.expected)
+			t.Errorf("IsValidMobile(%q) = %v; expected %v", test.mobile, result, test.expected)
+		}
+	}
+}
+
+// TestFormatMobile tests the FormatMobile function
+func TestFormatMobile(t *testing.T) {
+	tests := []struct {
+		mobile   string
+		expected string
+	}{
+		{"+919876543210", "+91 9876543210"},
+		{"+14155550100", "+1 4155550100"},
+		{"+971501234567", "+971 501234567"},
+		{"", ""},
+		{"12345", "12345"},
+	}
+
+	for _, test := range tests {
+		result := validation.FormatMobile(test.mobile)
+		if result != test.expected {
+			t.Errorf("FormatMobile(%q) = %q; expected %q", test.mobile, result, test.expected)
 		}
 	}
 }
@@ -0,0 +1,28 @@
+package validation
+
+import (
+	"cryptotracker/pkg/validation"
+	"testing"
+)
+
+// TestIsValidTimezone tests the IsValidTimezone function
+func TestIsValidTimezone(t *testing.T) {
+	tests := []struct {
+		timezone string
+		expected bool
+	}{
+		{"UTC", true},
+		{"Asia/Kolkata", true},
+		{"America/New_York", true},
+		{"", false},
+		{"Local", false},
+		{"Mars/Olympus_Mons", false},
+	}
+
+	for _, test := range tests {
+		result := validation.IsValidTimezone(test.timezone)
+		if result != test.expected {
+			t.Errorf("IsValidTimezone(%q) = %v; expected %v", test.timezone, result, test.expected)
+		}
+	}
+}